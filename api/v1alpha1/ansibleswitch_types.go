@@ -24,6 +24,7 @@ import (
 	"github.com/Hellcatlk/network-operator/pkg/utils/credentials"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -85,6 +86,19 @@ func (a *AnsibleSwitch) GetConfiguration(ctx context.Context, client client.Clie
 	}, nil
 }
 
+// ReferencedSecrets return the credentials secret
+func (a *AnsibleSwitch) ReferencedSecrets() []types.NamespacedName {
+	if a.Spec.Credentials == nil {
+		return nil
+	}
+	// The default namespace of `Credentials` is the same as `AnsibleSwitch`
+	namespace := a.Spec.Credentials.Namespace
+	if namespace == "" {
+		namespace = a.Namespace
+	}
+	return []types.NamespacedName{{Name: a.Spec.Credentials.Name, Namespace: namespace}}
+}
+
 // +kubebuilder:object:root=true
 
 // AnsibleSwitchList contains a list of AnsibleSwitch
@@ -96,4 +110,5 @@ type AnsibleSwitchList struct {
 
 func init() {
 	SchemeBuilder.Register(&AnsibleSwitch{}, &AnsibleSwitchList{})
+	provider.Register("AnsibleSwitch", func() provider.Switch { return &AnsibleSwitch{} })
 }
@@ -0,0 +1,148 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	"github.com/Hellcatlk/network-operator/pkg/provider"
+	"github.com/Hellcatlk/network-operator/pkg/utils/credentials"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ConfigMapReference represents a ConfigMap
+type ConfigMapReference struct {
+	Name string `json:"name"`
+
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// CLISwitchSpec defines the desired state of CLISwitch
+type CLISwitchSpec struct {
+	// The host of SSH, `<host>[:<port>]` whose port is 22 by default
+	Host string `json:"host"`
+
+	// The OS of switch, the builtin templates of the OS are used if they exist,
+	// such as `ios` and `ios-xe`
+	OS string `json:"os,omitempty"`
+
+	// A secret containing the switch credentials
+	// The default namespace is the same as `CLISwitch`
+	Credentials *corev1.SecretReference `json:"credentials"`
+
+	// A ConfigMap containing the templates which replace the builtin ones of OS
+	// The default namespace is the same as `CLISwitch`
+	Templates *ConfigMapReference `json:"templates,omitempty"`
+
+	// The public key of switch in the format of authorized_keys, the key of
+	// switch isn't verified if it isn't set
+	HostKey string `json:"hostKey,omitempty"`
+}
+
+// CLISwitchStatus defines the observed state of CLISwitch
+type CLISwitchStatus struct {
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="OS",type="string",JSONPath=".spec.os",description="os"
+// +kubebuilder:printcolumn:name="HOST",type="string",JSONPath=".spec.host",description="host"
+
+// CLISwitch is the Schema for the cliswitches API
+type CLISwitch struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CLISwitchSpec   `json:"spec,omitempty"`
+	Status CLISwitchStatus `json:"status,omitempty"`
+}
+
+// GetConfiguration generate configuration from cli switch
+func (c *CLISwitch) GetConfiguration(ctx context.Context, client client.Client) (*provider.SwitchConfiguration, error) {
+	// Set the default namespace of `Credentials` to the same as `CLISwitch`
+	if c.Spec.Credentials.Namespace == "" {
+		c.Spec.Credentials.Namespace = c.Namespace
+	}
+	cert, err := credentials.Fetch(ctx, client, c.Spec.Credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &provider.SwitchConfiguration{
+		OS:          c.Spec.OS,
+		Host:        c.Spec.Host,
+		Backend:     "cli",
+		Credentials: cert,
+		Options:     map[string]interface{}{},
+	}
+	if c.Spec.HostKey != "" {
+		config.Options["hostKey"] = c.Spec.HostKey
+	}
+	for _, name := range c.ReferencedConfigMaps() {
+		templates := &corev1.ConfigMap{}
+		err = client.Get(ctx, name, templates)
+		if err != nil {
+			return nil, err
+		}
+		config.Options["templates"] = templates.Data
+	}
+
+	return config, nil
+}
+
+// ReferencedSecrets return the credentials secret
+func (c *CLISwitch) ReferencedSecrets() []types.NamespacedName {
+	if c.Spec.Credentials == nil {
+		return nil
+	}
+	// The default namespace of `Credentials` is the same as `CLISwitch`
+	namespace := c.Spec.Credentials.Namespace
+	if namespace == "" {
+		namespace = c.Namespace
+	}
+	return []types.NamespacedName{{Name: c.Spec.Credentials.Name, Namespace: namespace}}
+}
+
+// ReferencedConfigMaps return the templates ConfigMap
+func (c *CLISwitch) ReferencedConfigMaps() []types.NamespacedName {
+	if c.Spec.Templates == nil {
+		return nil
+	}
+	// The default namespace of `Templates` is the same as `CLISwitch`
+	namespace := c.Spec.Templates.Namespace
+	if namespace == "" {
+		namespace = c.Namespace
+	}
+	return []types.NamespacedName{{Name: c.Spec.Templates.Name, Namespace: namespace}}
+}
+
+// +kubebuilder:object:root=true
+
+// CLISwitchList contains a list of CLISwitch
+type CLISwitchList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CLISwitch `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CLISwitch{}, &CLISwitchList{})
+	provider.Register("CLISwitch", func() provider.Switch { return &CLISwitch{} })
+}
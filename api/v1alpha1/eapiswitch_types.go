@@ -0,0 +1,117 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/Hellcatlk/network-operator/pkg/provider"
+	"github.com/Hellcatlk/network-operator/pkg/utils/credentials"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// EAPISwitchSpec defines the desired state of EAPISwitch
+type EAPISwitchSpec struct {
+	// The host of eAPI, `<host>[:<port>]` which uses HTTPS, or the URL of eAPI
+	Host string `json:"host"`
+
+	// A secret containing the switch credentials
+	// The default namespace is the same as `EAPISwitch`
+	Credentials *corev1.SecretReference `json:"credentials"`
+
+	// The PEM encoded CA bundle verifying the certificate of switch, the CA of
+	// system is used if it isn't set
+	CABundle []byte `json:"caBundle,omitempty"`
+
+	// Don't verify the certificate of switch
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// EAPISwitchStatus defines the observed state of EAPISwitch
+type EAPISwitchStatus struct {
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// EAPISwitch is the Schema for the eapiswitches API
+type EAPISwitch struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   EAPISwitchSpec   `json:"spec,omitempty"`
+	Status EAPISwitchStatus `json:"status,omitempty"`
+}
+
+// GetConfiguration generate configuration from eapi switch
+func (e *EAPISwitch) GetConfiguration(ctx context.Context, client client.Client) (*provider.SwitchConfiguration, error) {
+	// Set the default namespace of `Credentials` to the same as `EAPISwitch`
+	if e.Spec.Credentials.Namespace == "" {
+		e.Spec.Credentials.Namespace = e.Namespace
+	}
+	cert, err := credentials.Fetch(ctx, client, e.Spec.Credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &provider.SwitchConfiguration{
+		OS:          "eos",
+		Host:        e.Spec.Host,
+		Backend:     "eapi",
+		Credentials: cert,
+		Options:     map[string]interface{}{},
+	}
+	if len(e.Spec.CABundle) != 0 {
+		config.Options["caBundle"] = string(e.Spec.CABundle)
+	}
+	if e.Spec.InsecureSkipVerify {
+		config.Options["insecureSkipVerify"] = strconv.FormatBool(e.Spec.InsecureSkipVerify)
+	}
+
+	return config, nil
+}
+
+// ReferencedSecrets return the credentials secret
+func (e *EAPISwitch) ReferencedSecrets() []types.NamespacedName {
+	if e.Spec.Credentials == nil {
+		return nil
+	}
+	// The default namespace of `Credentials` is the same as `EAPISwitch`
+	namespace := e.Spec.Credentials.Namespace
+	if namespace == "" {
+		namespace = e.Namespace
+	}
+	return []types.NamespacedName{{Name: e.Spec.Credentials.Name, Namespace: namespace}}
+}
+
+// +kubebuilder:object:root=true
+
+// EAPISwitchList contains a list of EAPISwitch
+type EAPISwitchList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []EAPISwitch `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&EAPISwitch{}, &EAPISwitchList{})
+	provider.Register("EAPISwitch", func() provider.Switch { return &EAPISwitch{} })
+}
@@ -0,0 +1,165 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/Hellcatlk/network-operator/pkg/provider"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// FakeSwitchSpec defines the desired state of FakeSwitch
+type FakeSwitchSpec struct {
+	// The ports of switch, any port exists if it's empty
+	Ports []string `json:"ports,omitempty"`
+
+	// The range of VLANs which can be used, such as `1-100`
+	// All VLANs can be used if it's empty
+	// +kubebuilder:validation:Pattern=`([0-9]{1,})|([0-9]{1,}-[0-9]{1,})(,([0-9]{1,})|([0-9]{1,}-[0-9]{1,}))*`
+	VLANRange string `json:"vlanRange,omitempty"`
+
+	// The size of VLAN table include VLAN 1, it's unlimited if it's 0
+	// +kubebuilder:validation:Minimum=0
+	MaxVLANs int `json:"maxVLANs,omitempty"`
+
+	// The fields of SwitchPortConfiguration which aren't supported by switch
+	Unsupported []FakeSwitchField `json:"unsupported,omitempty"`
+
+	// The faults injected into the operations of switch
+	Faults *FakeSwitchFaults `json:"faults,omitempty"`
+}
+
+// FakeSwitchField is a field of SwitchPortConfiguration, it's named by the JSON
+// field of SwitchCapabilities
+// +kubebuilder:validation:Enum=acls;taggedVLANs;nativeVLAN;disable;mtu
+type FakeSwitchField string
+
+// FakeSwitchFaults are the faults injected into the operations of FakeSwitch
+type FakeSwitchFaults struct {
+	// The latency of every operation
+	Latency *metav1.Duration `json:"latency,omitempty"`
+
+	// Every operation blocks until it times out
+	Timeout bool `json:"timeout,omitempty"`
+
+	// Every operation fails with the authentication error
+	AuthFailure bool `json:"authFailure,omitempty"`
+
+	// Every operation fails with the transient error
+	Unreachable bool `json:"unreachable,omitempty"`
+
+	// The ports whose changes fail with the transient error after their VLANs
+	// are changed
+	FailPorts []string `json:"failPorts,omitempty"`
+
+	// The percent of reading a port which has been changed randomly out of band
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	DriftPercent int `json:"driftPercent,omitempty"`
+
+	// The seed of the random drift, it's random if it's 0
+	Seed int64 `json:"seed,omitempty"`
+}
+
+// FakeSwitchStatus defines the observed state of FakeSwitch
+type FakeSwitchStatus struct {
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// FakeSwitch is the Schema for the fakeswitches API
+type FakeSwitch struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   FakeSwitchSpec   `json:"spec,omitempty"`
+	Status FakeSwitchStatus `json:"status,omitempty"`
+}
+
+// GetConfiguration generate configuration from fake switch, the simulated
+// switch is identified by `<namespace>/<name>` of FakeSwitch
+func (f *FakeSwitch) GetConfiguration(ctx context.Context, client client.Client) (*provider.SwitchConfiguration, error) {
+	config := &provider.SwitchConfiguration{
+		Host:    types.NamespacedName{Name: f.Name, Namespace: f.Namespace}.String(),
+		Backend: "fake",
+		Options: map[string]interface{}{},
+	}
+	if len(f.Spec.Ports) != 0 {
+		config.Options["ports"] = strings.Join(f.Spec.Ports, ",")
+	}
+	if f.Spec.VLANRange != "" {
+		config.Options["vlanRange"] = f.Spec.VLANRange
+	}
+	if f.Spec.MaxVLANs != 0 {
+		config.Options["maxVLANs"] = strconv.Itoa(f.Spec.MaxVLANs)
+	}
+	if len(f.Spec.Unsupported) != 0 {
+		fields := []string{}
+		for _, field := range f.Spec.Unsupported {
+			fields = append(fields, string(field))
+		}
+		config.Options["unsupported"] = strings.Join(fields, ",")
+	}
+
+	faults := f.Spec.Faults
+	if faults == nil {
+		return config, nil
+	}
+	if faults.Latency != nil {
+		config.Options["latency"] = faults.Latency.Duration.String()
+	}
+	if faults.Timeout {
+		config.Options["timeout"] = strconv.FormatBool(faults.Timeout)
+	}
+	if faults.AuthFailure {
+		config.Options["authFailure"] = strconv.FormatBool(faults.AuthFailure)
+	}
+	if faults.Unreachable {
+		config.Options["unreachable"] = strconv.FormatBool(faults.Unreachable)
+	}
+	if len(faults.FailPorts) != 0 {
+		config.Options["failPorts"] = strings.Join(faults.FailPorts, ",")
+	}
+	if faults.DriftPercent != 0 {
+		config.Options["driftPercent"] = strconv.Itoa(faults.DriftPercent)
+	}
+	if faults.Seed != 0 {
+		config.Options["seed"] = strconv.FormatInt(faults.Seed, 10)
+	}
+
+	return config, nil
+}
+
+// +kubebuilder:object:root=true
+
+// FakeSwitchList contains a list of FakeSwitch
+type FakeSwitchList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []FakeSwitch `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&FakeSwitch{}, &FakeSwitchList{})
+	provider.Register("FakeSwitch", func() provider.Switch { return &FakeSwitch{} })
+}
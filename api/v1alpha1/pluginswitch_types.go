@@ -0,0 +1,130 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Hellcatlk/network-operator/pkg/provider"
+	"github.com/Hellcatlk/network-operator/pkg/utils/credentials"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// PluginSwitchSpec defines the desired state of PluginSwitch
+type PluginSwitchSpec struct {
+	// The endpoint of a running plugin, `unix://<path>` or `<host>:<port>`,
+	// `<host>:<port>` is connected with TLS
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// The executable of plugin in the plugin directory of manager, it's started
+	// by manager. Only one of `Endpoint` and `Executable` can be set
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9][A-Za-z0-9._-]*$`
+	Executable string `json:"executable,omitempty"`
+
+	OS string `json:"os,omitempty"`
+
+	Host string `json:"host"`
+
+	// A secret containing the switch credentials
+	// The default namespace is the same as `PluginSwitch`
+	Credentials *corev1.SecretReference `json:"credentials,omitempty"`
+
+	// The options passed to plugin
+	Options map[string]string `json:"options,omitempty"`
+}
+
+// PluginSwitchStatus defines the observed state of PluginSwitch
+type PluginSwitchStatus struct {
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// PluginSwitch is the Schema for the pluginswitches API
+type PluginSwitch struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PluginSwitchSpec   `json:"spec,omitempty"`
+	Status PluginSwitchStatus `json:"status,omitempty"`
+}
+
+// GetConfiguration generate configuration from plugin switch
+func (p *PluginSwitch) GetConfiguration(ctx context.Context, client client.Client) (*provider.SwitchConfiguration, error) {
+	if (p.Spec.Endpoint == "") == (p.Spec.Executable == "") {
+		return nil, fmt.Errorf("only one of endpoint and executable is required")
+	}
+
+	config := &provider.SwitchConfiguration{
+		OS:      p.Spec.OS,
+		Host:    p.Spec.Host,
+		Backend: "plugin",
+		Options: map[string]interface{}{},
+	}
+	for key, value := range p.Spec.Options {
+		config.Options[key] = value
+	}
+	// Set after the options of plugin, so they can't be overridden
+	config.Options["endpoint"] = p.Spec.Endpoint
+	config.Options["executable"] = p.Spec.Executable
+
+	if p.Spec.Credentials != nil {
+		// Set the default namespace of `Credentials` to the same as `PluginSwitch`
+		if p.Spec.Credentials.Namespace == "" {
+			p.Spec.Credentials.Namespace = p.Namespace
+		}
+
+		cert, err := credentials.Fetch(ctx, client, p.Spec.Credentials)
+		if err != nil {
+			return nil, err
+		}
+		config.Credentials = cert
+	}
+
+	return config, nil
+}
+
+// ReferencedSecrets return the credentials secret
+func (p *PluginSwitch) ReferencedSecrets() []types.NamespacedName {
+	if p.Spec.Credentials == nil {
+		return nil
+	}
+	// The default namespace of `Credentials` is the same as `PluginSwitch`
+	namespace := p.Spec.Credentials.Namespace
+	if namespace == "" {
+		namespace = p.Namespace
+	}
+	return []types.NamespacedName{{Name: p.Spec.Credentials.Name, Namespace: namespace}}
+}
+
+// +kubebuilder:object:root=true
+
+// PluginSwitchList contains a list of PluginSwitch
+type PluginSwitchList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PluginSwitch `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PluginSwitch{}, &PluginSwitchList{})
+	provider.Register("PluginSwitch", func() provider.Switch { return &PluginSwitch{} })
+}
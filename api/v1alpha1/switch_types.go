@@ -19,11 +19,12 @@ package v1alpha1
 import (
 	"context"
 	"fmt"
-	"strconv"
+	stdstrings "strings"
 
 	"github.com/Hellcatlk/network-operator/pkg/machine"
 	"github.com/Hellcatlk/network-operator/pkg/provider"
 	"github.com/Hellcatlk/network-operator/pkg/utils/strings"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -31,7 +32,9 @@ import (
 
 // SwitchProviderReference is the reference for SwitchProvider CR
 type SwitchProviderReference struct {
-	// +kubebuilder:validation:Enum=AnsibleSwitch
+	// The kind of provider switch, such as `SwitchProvider`, `AnsibleSwitch`
+	// and `PluginSwitch`, it must be registered by `provider.Register`
+	// +kubebuilder:validation:MinLength=1
 	Kind string `json:"kind"`
 
 	Name string `json:"name"`
@@ -42,32 +45,34 @@ type SwitchProviderReference struct {
 }
 
 // Fetch the instance
-func (ref *SwitchProviderReference) Fetch(ctx context.Context, client client.Client) (provider.Switch, error) {
+func (ref *SwitchProviderReference) Fetch(ctx context.Context, c client.Client) (provider.Switch, error) {
 	if ref == nil {
 		return nil, fmt.Errorf("provider reference is nil")
 	}
 
-	var instance provider.Switch
-	var err error
-
-	switch ref.Kind {
-	case "FakeSwitch":
-		instance = &provider.FakeSwitch{}
-
-	case "AnsibleSwitch":
-		a := &AnsibleSwitch{}
-		err = client.Get(
-			ctx,
-			types.NamespacedName{
-				Name:      ref.Name,
-				Namespace: ref.Namespace,
-			},
-			a,
-		)
-		instance = a
+	instance := provider.New(ref.Kind)
+	if instance == nil {
+		return nil, fmt.Errorf("unknown provider switch kind %q", ref.Kind)
+	}
 
-	default:
-		err = fmt.Errorf("unknown provider switch kind")
+	// The provider switches which aren't objects need no fetching
+	obj, ok := instance.(client.Object)
+	if !ok {
+		return instance, nil
+	}
+	err := c.Get(
+		ctx,
+		types.NamespacedName{
+			Name:      ref.Name,
+			Namespace: ref.Namespace,
+		},
+		obj,
+	)
+	// FakeSwitch was built in without an object, so the simulated switch with
+	// the default options is used if it isn't created
+	if fake, ok := obj.(*FakeSwitch); ok && errors.IsNotFound(err) {
+		fake.Name, fake.Namespace = ref.Name, ref.Namespace
+		return fake, nil
 	}
 
 	return instance, err
@@ -83,15 +88,15 @@ type Port struct {
 
 	// Indicates the range of VLANs allowed
 	// +kubebuilder:validation:Pattern=`([0-9]{1,})|([0-9]{1,}-[0-9]{1,})(,([0-9]{1,})|([0-9]{1,}-[0-9]{1,}))*`
-	// +kubebuilder:default:="1-4096"
+	// +kubebuilder:default:="2-4094"
 	VLANRange string `json:"vlanRange,omitempty"`
 
 	// True if this port can be used as a trunk port, false otherwise
 	TrunkDisabled bool `json:"trunkDisable,omitempty"`
 }
 
-// Verify configuration
-func (p *Port) Verify(configuration *SwitchPortConfiguration) error {
+// Verify configuration, the vlans in reservedVLANs and DefaultReservedVLAN can't be used
+func (p *Port) Verify(configuration *SwitchPortConfiguration, reservedVLANs string) error {
 	if p == nil {
 		return fmt.Errorf("the port is nil")
 	}
@@ -112,18 +117,20 @@ func (p *Port) Verify(configuration *SwitchPortConfiguration) error {
 		return fmt.Errorf("the port can be used as a trunk port")
 	}
 
+	vlanRange, err := configuration.Spec.VLANRange()
+	if err != nil {
+		return err
+	}
+	err = VerifyVLANs(vlanRange, reservedVLANs)
+	if err != nil {
+		return fmt.Errorf("vlan configuration %s verify failed: %s", vlanRange, err)
+	}
+
 	if p.VLANRange == "" {
 		return nil
 	}
 
-	vlanRange := configuration.Spec.TaggedVLANRange
-	if configuration.Spec.UntaggedVLAN != nil {
-		if vlanRange != "" {
-			vlanRange = vlanRange + ","
-		}
-		vlanRange = vlanRange + strconv.Itoa(*configuration.Spec.UntaggedVLAN)
-	}
-	err := strings.RangeContains(p.VLANRange, vlanRange)
+	err = strings.RangeContains(p.VLANRange, vlanRange)
 	if err != nil {
 		return fmt.Errorf("vlan configuration %s verify failed: %s", vlanRange, err)
 	}
@@ -131,6 +138,55 @@ func (p *Port) Verify(configuration *SwitchPortConfiguration) error {
 	return nil
 }
 
+// SwitchCapabilities are the fields of SwitchPortConfiguration supported by the
+// backend of switch
+type SwitchCapabilities struct {
+	// True if the ACLs can be set
+	ACLs bool `json:"acls"`
+
+	// True if the tagged VLANs can be set, the port is a trunk port
+	TaggedVLANs bool `json:"taggedVLANs"`
+
+	// True if the untagged VLAN can be set with the tagged VLANs, it's the native
+	// VLAN of trunk port
+	NativeVLAN bool `json:"nativeVLAN"`
+
+	// True if the port can be disabled
+	Disable bool `json:"disable"`
+
+	// True if the MTU can be set
+	MTU bool `json:"mtu"`
+}
+
+// Verify the configuration only uses the supported fields, all of fields are
+// supported if the capabilities are nil
+func (c *SwitchCapabilities) Verify(spec *SwitchPortConfigurationSpec) error {
+	if c == nil || spec == nil {
+		return nil
+	}
+
+	unsupported := []string{}
+	if !c.ACLs && len(spec.ACLs) != 0 {
+		unsupported = append(unsupported, "acls")
+	}
+	if !c.TaggedVLANs && spec.TaggedVLANRange != "" {
+		unsupported = append(unsupported, "taggedVLANRange")
+	}
+	if !c.NativeVLAN && spec.TaggedVLANRange != "" && spec.UntaggedVLAN != nil {
+		unsupported = append(unsupported, "untaggedVLAN with taggedVLANRange")
+	}
+	if !c.Disable && spec.Disable {
+		unsupported = append(unsupported, "disable")
+	}
+	if !c.MTU && spec.MTU != nil {
+		unsupported = append(unsupported, "mtu")
+	}
+	if len(unsupported) != 0 {
+		return fmt.Errorf("the switch doesn't support %s", stdstrings.Join(unsupported, ", "))
+	}
+	return nil
+}
+
 // SwitchSpec defines the desired state of Switch
 type SwitchSpec struct {
 	// The reference of provider
@@ -138,6 +194,38 @@ type SwitchSpec struct {
 
 	// Restricted ports in the switch
 	Ports map[string]*Port `json:"ports,omitempty"`
+
+	// The vlans can't be used by any port, such as the native management vlan
+	// and the provisioning vlan. VLAN 1 is always reserved.
+	// +kubebuilder:validation:Pattern=`([0-9]{1,})|([0-9]{1,}-[0-9]{1,})(,([0-9]{1,})|([0-9]{1,}-[0-9]{1,}))*`
+	ReservedVLANs string `json:"reservedVLANs,omitempty"`
+
+	// True if the existing configuration of ports is adopted when the SwitchPorts
+	// are created, the configured ports are bound to the generated
+	// SwitchPortConfigurations and start in `Active` state without reconfiguration
+	Adopt bool `json:"adopt,omitempty"`
+
+	// The action taken when the configuration of port is changed externally,
+	// it's used by the SwitchPorts without drift policy
+	// +kubebuilder:default:=Remediate
+	DriftPolicy DriftPolicy `json:"driftPolicy,omitempty"`
+}
+
+// SwitchAudit is the result of auditing the ports and VLANs of the whole switch
+type SwitchAudit struct {
+	// The time of the audit
+	Time metav1.Time `json:"time"`
+
+	// The ports which aren't managed by SwitchPorts while carrying the VLANs of
+	// SwitchResources, the value is the VLANs carried
+	UnmanagedPorts map[string]string `json:"unmanagedPorts,omitempty"`
+
+	// The VLANs existing on the switch which aren't used by any SwitchPort and
+	// aren't reserved
+	ForeignVLANs string `json:"foreignVLANs,omitempty"`
+
+	// The error of the audit, such as the backend can't read the whole switch
+	Error string `json:"error,omitempty"`
 }
 
 // SwitchStatus defines the observed state of Switch
@@ -151,6 +239,19 @@ type SwitchStatus struct {
 	// Restricted ports in the switch
 	Ports map[string]*Port `json:"ports,omitempty"`
 
+	// The vlans can't be used by any port, include VLAN 1
+	ReservedVLANs string `json:"reservedVLANs,omitempty"`
+
+	// The fields of configuration supported by the backend of switch
+	Capabilities *SwitchCapabilities `json:"capabilities,omitempty"`
+
+	// The ports whose configuration can't be adopted with the reasons, their
+	// SwitchPorts are created without configuration
+	UnadoptedPorts map[string]string `json:"unadoptedPorts,omitempty"`
+
+	// The result of the last audit of the whole switch
+	Audit *SwitchAudit `json:"audit,omitempty"`
+
 	// The error message of the port
 	Error string `json:"error,omitempty"`
 }
@@ -168,6 +269,10 @@ const (
 	// SwitchRunning means all of SwitchPort have been created
 	SwitchRunning machine.StateType = "Running"
 
+	// SwitchUnreachable means the switch failed repeatedly, we are probing it with backoff
+	// and its SwitchPorts wait until it comes back
+	SwitchUnreachable machine.StateType = "Unreachable"
+
 	// SwitchDeleting means we are deleting SwitchPort
 	SwitchDeleting machine.StateType = "Deleting"
 )
@@ -1,6 +1,16 @@
 package v1alpha1
 
-import "testing"
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/Hellcatlk/network-operator/pkg/provider"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
 
 func TestPortVerify(t *testing.T) {
 	untaggedVLAN := 20
@@ -8,6 +18,7 @@ func TestPortVerify(t *testing.T) {
 		name          string
 		port          *Port
 		configuration *SwitchPortConfiguration
+		reservedVLANs string
 		expectedError bool
 	}{
 		{
@@ -60,12 +71,50 @@ func TestPortVerify(t *testing.T) {
 			},
 			configuration: &SwitchPortConfiguration{
 				Spec: SwitchPortConfigurationSpec{
-					TaggedVLANRange: "1-10",
+					TaggedVLANRange: "2-10",
 					UntaggedVLAN:    &untaggedVLAN,
 				},
 			},
 			expectedError: false,
 		},
+		{
+			name: "vlan 1 is reserved",
+			port: &Port{
+				PhysicalPortName: "test",
+				VLANRange:        "1-20",
+			},
+			configuration: &SwitchPortConfiguration{
+				Spec: SwitchPortConfigurationSpec{
+					TaggedVLANRange: "1-10",
+				},
+			},
+			expectedError: true,
+		},
+		{
+			name: "vlan is reserved",
+			port: &Port{
+				PhysicalPortName: "test",
+			},
+			configuration: &SwitchPortConfiguration{
+				Spec: SwitchPortConfigurationSpec{
+					UntaggedVLAN: &untaggedVLAN,
+				},
+			},
+			reservedVLANs: "10-20",
+			expectedError: true,
+		},
+		{
+			name: "vlan is invalid",
+			port: &Port{
+				PhysicalPortName: "test",
+			},
+			configuration: &SwitchPortConfiguration{
+				Spec: SwitchPortConfigurationSpec{
+					TaggedVLANRange: "4090-4095",
+				},
+			},
+			expectedError: true,
+		},
 		{
 			name: "vlan in range",
 			port: &Port{
@@ -83,10 +132,134 @@ func TestPortVerify(t *testing.T) {
 
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
-			err := c.port.Verify(c.configuration)
+			err := c.port.Verify(c.configuration, c.reservedVLANs)
 			if (err != nil) != c.expectedError {
 				t.Errorf("Got unexpected error: %v", err)
 			}
 		})
 	}
 }
+
+func TestCapabilitiesVerify(t *testing.T) {
+	vlan := 10
+	mtu := 9000
+	cases := []struct {
+		name          string
+		capabilities  *SwitchCapabilities
+		configuration *SwitchPortConfigurationSpec
+		expectedError string
+	}{
+		{
+			name:          "unknown capabilities",
+			configuration: &SwitchPortConfigurationSpec{ACLs: []ACL{{}}, MTU: &mtu},
+		},
+		{
+			name:          "access port",
+			capabilities:  &SwitchCapabilities{},
+			configuration: &SwitchPortConfigurationSpec{UntaggedVLAN: &vlan},
+		},
+		{
+			name:          "native VLAN",
+			capabilities:  &SwitchCapabilities{TaggedVLANs: true},
+			configuration: &SwitchPortConfigurationSpec{UntaggedVLAN: &vlan, TaggedVLANRange: "11-20"},
+			expectedError: "the switch doesn't support untaggedVLAN with taggedVLANRange",
+		},
+		{
+			name:          "unsupported fields",
+			capabilities:  &SwitchCapabilities{TaggedVLANs: true, NativeVLAN: true},
+			configuration: &SwitchPortConfigurationSpec{ACLs: []ACL{{}}, TaggedVLANRange: "11-20", Disable: true, MTU: &mtu},
+			expectedError: "the switch doesn't support acls, disable, mtu",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.capabilities.Verify(c.configuration)
+			if (err != nil || c.expectedError != "") && fmt.Sprint(err) != c.expectedError {
+				t.Errorf("expected error: %q, got: %v", c.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestSwitchProviderReferenceFetch(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = AddToScheme(scheme)
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&SwitchProvider{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+			Spec: SwitchProviderSpec{
+				Backend:     "ansible",
+				OS:          "junos",
+				Host:        "192.168.0.1",
+				Credentials: &corev1.SecretReference{Name: "test"},
+				Options:     map[string]string{"vrf": "management"},
+			},
+		},
+		&FakeSwitch{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+			Data:       map[string][]byte{"username": []byte("admin"), "password": []byte("secret")},
+		},
+	).Build()
+
+	cases := []struct {
+		name            string
+		ref             *SwitchProviderReference
+		expectedBackend string
+		expectedError   bool
+	}{
+		{
+			name:            "fake switch",
+			ref:             &SwitchProviderReference{Kind: "FakeSwitch", Name: "test", Namespace: "default"},
+			expectedBackend: "fake",
+		},
+		{
+			name:            "fake switch isn't created",
+			ref:             &SwitchProviderReference{Kind: "FakeSwitch", Name: "not-existed", Namespace: "default"},
+			expectedBackend: "fake",
+		},
+		{
+			name:            "switch provider",
+			ref:             &SwitchProviderReference{Kind: "SwitchProvider", Name: "test", Namespace: "default"},
+			expectedBackend: "ansible",
+		},
+		{
+			name:          "not existed provider",
+			ref:           &SwitchProviderReference{Kind: "SwitchProvider", Name: "not-existed", Namespace: "default"},
+			expectedError: true,
+		},
+		{
+			name:          "unregistered kind",
+			ref:           &SwitchProviderReference{Kind: "NotRegistered", Name: "test", Namespace: "default"},
+			expectedError: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			instance, err := c.ref.Fetch(context.Background(), client)
+			if (err != nil) != c.expectedError {
+				t.Fatalf("got unexpected error: %v", err)
+			}
+			if c.expectedError {
+				return
+			}
+
+			config, err := instance.GetConfiguration(context.Background(), client)
+			if err != nil {
+				t.Fatalf("get configuration failed: %v", err)
+			}
+			if config.Backend != c.expectedBackend {
+				t.Errorf("expected backend: %s, got: %s", c.expectedBackend, config.Backend)
+			}
+		})
+	}
+
+	if _, ok := provider.New("SwitchProvider").(provider.SecretReferrer); !ok {
+		t.Errorf("expected SwitchProvider to reference secrets")
+	}
+}
@@ -54,10 +54,56 @@ func (ref *SwitchPortReference) Fetch(ctx context.Context, client client.Client)
 	return instance, err
 }
 
+// DriftPolicy is the action taken when the configuration of port is changed externally
+// +kubebuilder:validation:Enum=Remediate;Report;Ignore
+type DriftPolicy string
+
+const (
+	// DriftRemediate means the port is reconfigured with the desired configuration
+	DriftRemediate DriftPolicy = "Remediate"
+
+	// DriftReport means the drift is reported by the `DriftDetected` condition
+	// and event, the port isn't reconfigured until it's re-applied
+	DriftReport DriftPolicy = "Report"
+
+	// DriftIgnore means the configuration on the device isn't checked
+	DriftIgnore DriftPolicy = "Ignore"
+)
+
+// SwitchPortDriftDetected is the type of condition which is true if the
+// configuration of port is different from the desired configuration
+const SwitchPortDriftDetected string = "DriftDetected"
+
+// ReapplyAnnotation is the annotation of SwitchPort triggering re-applying the
+// configuration once when its value is changed, such as a timestamp
+const ReapplyAnnotation string = "metal3.io/reapply"
+
+// AdoptedAnnotation is the annotation of the SwitchPort created by the adoption
+// of Switch, the new SwitchPort starts in `Active` state with the configuration
+// it references, so the port isn't configured again
+const AdoptedAnnotation string = "metal3.io/adopted"
+
 // SwitchPortSpec defines the desired state of SwitchPort
 type SwitchPortSpec struct {
 	// The reference of PortConfiguration CR
 	Configuration *SwitchPortConfigurationReference `json:"configuration,omitempty"`
+
+	// The action taken when the configuration of port is changed externally,
+	// if empty use the drift policy of switch
+	DriftPolicy DriftPolicy `json:"driftPolicy,omitempty"`
+}
+
+// ConfigurationDiff is the difference of a field between the desired and the
+// actual configuration of port
+type ConfigurationDiff struct {
+	// The JSON name of field in SwitchPortConfiguration
+	Field string `json:"field"`
+
+	// The desired value, empty if it isn't set
+	Desired string `json:"desired,omitempty"`
+
+	// The actual value on the device, empty if it isn't set
+	Actual string `json:"actual,omitempty"`
 }
 
 // SwitchPortStatus defines the observed state of SwitchPort
@@ -73,6 +119,18 @@ type SwitchPortStatus struct {
 
 	// The name of physics port
 	PhysicalPortName string `json:"physicalPortName,omitempty"`
+
+	// The differences between the current configuration and the port when the
+	// drift is detected with the `Report` drift policy
+	Drift []ConfigurationDiff `json:"drift,omitempty"`
+
+	// The value of `metal3.io/reapply` annotation which has been handled
+	Reapplied string `json:"reapplied,omitempty"`
+
+	// The conditions of port, such as `DriftDetected`
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 const (
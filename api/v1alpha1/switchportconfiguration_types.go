@@ -18,8 +18,10 @@ package v1alpha1
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"reflect"
+	"strconv"
 
 	"github.com/Hellcatlk/network-operator/pkg/utils/strings"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -92,6 +94,11 @@ type SwitchPortConfigurationSpec struct {
 
 	// Disable port
 	Disable bool `json:"disable,omitempty"`
+
+	// The MTU of port, if empty the MTU isn't managed
+	// +kubebuilder:validation:Minimum=68
+	// +kubebuilder:validation:Maximum=65535
+	MTU *int `json:"mtu,omitempty"`
 }
 
 // IsEqual check configuration is equal or not
@@ -122,9 +129,81 @@ func (target *SwitchPortConfigurationSpec) IsEqual(actual *SwitchPortConfigurati
 	targetCopy.TaggedVLANRange = ""
 	actualCopy := actual.DeepCopy()
 	actualCopy.TaggedVLANRange = ""
+	// The MTU isn't managed if target doesn't set it
+	if targetCopy.MTU == nil {
+		actualCopy.MTU = nil
+	}
 	return reflect.DeepEqual(targetCopy, actualCopy)
 }
 
+// Diff return the differences between target and actual configuration, as same as IsEqual
+// the MTU isn't compared if target doesn't set it
+func (target *SwitchPortConfigurationSpec) Diff(actual *SwitchPortConfigurationSpec) []ConfigurationDiff {
+	if target == nil {
+		target = &SwitchPortConfigurationSpec{}
+	}
+	if actual == nil {
+		actual = &SwitchPortConfigurationSpec{}
+	}
+
+	var diffs []ConfigurationDiff
+	compare := func(field string, desired string, actual string) {
+		if desired != actual {
+			diffs = append(diffs, ConfigurationDiff{Field: field, Desired: desired, Actual: actual})
+		}
+	}
+	compare("acls", formatACLs(target.ACLs), formatACLs(actual.ACLs))
+	compare("untaggedVLAN", formatInt(target.UntaggedVLAN), formatInt(actual.UntaggedVLAN))
+	compare("taggedVLANRange", formatRange(target.TaggedVLANRange), formatRange(actual.TaggedVLANRange))
+	compare("disable", strconv.FormatBool(target.Disable), strconv.FormatBool(actual.Disable))
+	if target.MTU != nil {
+		compare("mtu", formatInt(target.MTU), formatInt(actual.MTU))
+	}
+	return diffs
+}
+
+// formatACLs return the ACLs in JSON, empty if there isn't any ACL
+func formatACLs(acls []ACL) string {
+	if len(acls) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(acls)
+	if err != nil {
+		return fmt.Sprint(acls)
+	}
+	return string(data)
+}
+
+// formatInt return the value, empty if it's nil
+func formatInt(value *int) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.Itoa(*value)
+}
+
+// formatRange return the normalized range, such as `1-3` for `3,1,2`
+func formatRange(value string) string {
+	slice, err := strings.RangeToSlice(value)
+	if err != nil {
+		return value
+	}
+	return strings.SliceToRange(slice)
+}
+
+// VLANRange return all vlans used by the configuration, include untagged vlan and tagged vlans
+func (target *SwitchPortConfigurationSpec) VLANRange() (string, error) {
+	if target == nil {
+		return "", nil
+	}
+
+	if target.UntaggedVLAN == nil {
+		return target.TaggedVLANRange, nil
+	}
+
+	return strings.Expansion(target.TaggedVLANRange, strconv.Itoa(*target.UntaggedVLAN))
+}
+
 // SwitchPortConfigurationStatus defines the observed state of SwitchPortConfiguration
 type SwitchPortConfigurationStatus struct {
 }
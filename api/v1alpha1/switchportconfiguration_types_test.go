@@ -1,6 +1,9 @@
 package v1alpha1
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+)
 
 func TestIsEqual(t *testing.T) {
 	cases := []struct {
@@ -57,6 +60,22 @@ func TestIsEqual(t *testing.T) {
 			},
 			expected: true,
 		},
+		{
+			target: &SwitchPortConfigurationSpec{},
+			actual: &SwitchPortConfigurationSpec{
+				MTU: intPtr(1500),
+			},
+			expected: true,
+		},
+		{
+			target: &SwitchPortConfigurationSpec{
+				MTU: intPtr(9000),
+			},
+			actual: &SwitchPortConfigurationSpec{
+				MTU: intPtr(1500),
+			},
+			expected: false,
+		},
 	}
 
 	for _, c := range cases {
@@ -68,3 +87,56 @@ func TestIsEqual(t *testing.T) {
 		})
 	}
 }
+
+func TestDiff(t *testing.T) {
+	cases := []struct {
+		name     string
+		target   *SwitchPortConfigurationSpec
+		actual   *SwitchPortConfigurationSpec
+		expected []ConfigurationDiff
+	}{
+		{
+			name:   "equal",
+			target: &SwitchPortConfigurationSpec{UntaggedVLAN: intPtr(10), TaggedVLANRange: "11,12,13"},
+			actual: &SwitchPortConfigurationSpec{UntaggedVLAN: intPtr(10), TaggedVLANRange: "11-13", MTU: intPtr(1500)},
+		},
+		{
+			name:   "VLANs",
+			target: &SwitchPortConfigurationSpec{UntaggedVLAN: intPtr(10), TaggedVLANRange: "11-13"},
+			actual: &SwitchPortConfigurationSpec{TaggedVLANRange: "11,13"},
+			expected: []ConfigurationDiff{
+				{Field: "untaggedVLAN", Desired: "10"},
+				{Field: "taggedVLANRange", Desired: "11-13", Actual: "11,13"},
+			},
+		},
+		{
+			name:   "nil actual",
+			target: &SwitchPortConfigurationSpec{Disable: true, MTU: intPtr(9000)},
+			expected: []ConfigurationDiff{
+				{Field: "disable", Desired: "true", Actual: "false"},
+				{Field: "mtu", Desired: "9000"},
+			},
+		},
+		{
+			name:   "ACLs",
+			target: &SwitchPortConfigurationSpec{},
+			actual: &SwitchPortConfigurationSpec{ACLs: []ACL{{Action: "deny"}}},
+			expected: []ConfigurationDiff{
+				{Field: "acls", Actual: `[{"action":"deny"}]`},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := c.target.Diff(c.actual)
+			if !reflect.DeepEqual(c.expected, got) {
+				t.Errorf("Expected: %+v, got: %+v", c.expected, got)
+			}
+		})
+	}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
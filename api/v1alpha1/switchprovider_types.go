@@ -0,0 +1,119 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	"github.com/Hellcatlk/network-operator/pkg/provider"
+	"github.com/Hellcatlk/network-operator/pkg/utils/credentials"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// SwitchProviderSpec defines the desired state of SwitchProvider
+type SwitchProviderSpec struct {
+	// The name of backend, such as `ansible` and `plugin`
+	// +kubebuilder:validation:MinLength=1
+	Backend string `json:"backend"`
+
+	OS string `json:"os,omitempty"`
+
+	Host string `json:"host"`
+
+	// A secret containing the switch credentials
+	// The default namespace is the same as `SwitchProvider`
+	Credentials *corev1.SecretReference `json:"credentials,omitempty"`
+
+	// The options of backend, they are validated by the backend
+	Options map[string]string `json:"options,omitempty"`
+}
+
+// SwitchProviderStatus defines the observed state of SwitchProvider
+type SwitchProviderStatus struct {
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="BACKEND",type="string",JSONPath=".spec.backend",description="backend"
+// +kubebuilder:printcolumn:name="HOST",type="string",JSONPath=".spec.host",description="host"
+
+// SwitchProvider is the Schema for the switchproviders API
+type SwitchProvider struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SwitchProviderSpec   `json:"spec,omitempty"`
+	Status SwitchProviderStatus `json:"status,omitempty"`
+}
+
+// GetConfiguration generate configuration from switch provider
+func (s *SwitchProvider) GetConfiguration(ctx context.Context, client client.Client) (*provider.SwitchConfiguration, error) {
+	config := &provider.SwitchConfiguration{
+		OS:      s.Spec.OS,
+		Host:    s.Spec.Host,
+		Backend: s.Spec.Backend,
+		Options: map[string]interface{}{},
+	}
+	for key, value := range s.Spec.Options {
+		config.Options[key] = value
+	}
+
+	if s.Spec.Credentials != nil {
+		// Set the default namespace of `Credentials` to the same as `SwitchProvider`
+		if s.Spec.Credentials.Namespace == "" {
+			s.Spec.Credentials.Namespace = s.Namespace
+		}
+
+		cert, err := credentials.Fetch(ctx, client, s.Spec.Credentials)
+		if err != nil {
+			return nil, err
+		}
+		config.Credentials = cert
+	}
+
+	return config, nil
+}
+
+// ReferencedSecrets return the credentials secret
+func (s *SwitchProvider) ReferencedSecrets() []types.NamespacedName {
+	if s.Spec.Credentials == nil {
+		return nil
+	}
+	// The default namespace of `Credentials` is the same as `SwitchProvider`
+	namespace := s.Spec.Credentials.Namespace
+	if namespace == "" {
+		namespace = s.Namespace
+	}
+	return []types.NamespacedName{{Name: s.Spec.Credentials.Name, Namespace: namespace}}
+}
+
+// +kubebuilder:object:root=true
+
+// SwitchProviderList contains a list of SwitchProvider
+type SwitchProviderList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SwitchProvider `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SwitchProvider{}, &SwitchProviderList{})
+	provider.Register("SwitchProvider", func() provider.Switch { return &SwitchProvider{} })
+}
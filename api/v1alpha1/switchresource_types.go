@@ -38,6 +38,12 @@ func (l *TenantLimit) Verify(available *SwitchResourceStatus) error {
 		return nil
 	}
 
+	// Reserved vlans can't be assigned to tenants
+	err := VerifyVLANs(l.VLANRange, available.ReservedVLANs)
+	if err != nil {
+		return err
+	}
+
 	// Get allowed vlan range
 	vlanRange, err := strings.RangeToSlice(available.AvailableVLAN)
 	if err != nil {
@@ -65,6 +71,51 @@ func (l *TenantLimit) Verify(available *SwitchResourceStatus) error {
 	return nil
 }
 
+// Reserve changes the reserved vlans, reserved vlans are removed from `status.availableVLAN`,
+// and the vlans which are no longer reserved are returned to it. The vlans assigned to tenants
+// can't be reserved. Nothing is changed if an error is returned.
+func (sr *SwitchResource) Reserve(reservedVLANs string) error {
+	reservedVLANs, err := ReservedVLANs(reservedVLANs)
+	if err != nil {
+		return err
+	}
+
+	for name, limit := range sr.Status.TenantLimits {
+		if limit == nil {
+			continue
+		}
+		used, err := strings.Intersection(limit.VLANRange, reservedVLANs)
+		if err != nil {
+			return err
+		}
+		if used != "" {
+			return fmt.Errorf("vlan %s can't be reserved, it's assigned to %s", used, name)
+		}
+	}
+
+	released, err := strings.Shrink(sr.Status.ReservedVLANs, reservedVLANs)
+	if err != nil {
+		return err
+	}
+	released, err = strings.Intersection(released, sr.Spec.VLANRange)
+	if err != nil {
+		return err
+	}
+	available, err := strings.Expansion(sr.Status.AvailableVLAN, released)
+	if err != nil {
+		return err
+	}
+	available, err = strings.Shrink(available, reservedVLANs)
+	if err != nil {
+		return err
+	}
+
+	sr.Status.AvailableVLAN = available
+	sr.Status.ReservedVLANs = reservedVLANs
+
+	return nil
+}
+
 // Expansion ...
 func (sr *SwitchResource) Expansion(limit *TenantLimit) error {
 	if limit == nil {
@@ -97,6 +148,50 @@ func (sr *SwitchResource) Shrink(limit *TenantLimit) error {
 	return nil
 }
 
+// Resize changes the vlan range of the tenant from old to new without recreating
+// the SwitchResourceLimit. The added vlans must be available and the removed vlans
+// must be unused. Nothing is changed if an error is returned.
+func (sr *SwitchResource) Resize(resourceLimit *SwitchResourceLimit, old, new *TenantLimit) error {
+	if resourceLimit == nil || old == nil || new == nil {
+		return fmt.Errorf("SwitchResourceLimit or TenantLimit is nil")
+	}
+
+	added, err := strings.Shrink(new.VLANRange, old.VLANRange)
+	if err != nil {
+		return err
+	}
+	removed, err := strings.Shrink(old.VLANRange, new.VLANRange)
+	if err != nil {
+		return err
+	}
+
+	err = strings.RangeContains(sr.Status.AvailableVLAN, added)
+	if err != nil {
+		return fmt.Errorf("vlan can't be added to the tenant, %s", err)
+	}
+	used, err := strings.Intersection(removed, resourceLimit.Status.UsedVLAN)
+	if err != nil {
+		return err
+	}
+	if used != "" {
+		return fmt.Errorf("vlan %s can't be removed from the tenant, it's still being used", used)
+	}
+
+	available, err := strings.Expansion(sr.Status.AvailableVLAN, removed)
+	if err != nil {
+		return err
+	}
+	available, err = strings.Shrink(available, added)
+	if err != nil {
+		return err
+	}
+
+	sr.Status.AvailableVLAN = available
+	resourceLimit.Status.VLANRange = new.VLANRange
+
+	return nil
+}
+
 // FetchSwitchResourceLimit fetch the SwitchResourceLimit/user-limit instance
 func (l *TenantLimit) FetchSwitchResourceLimit(ctx context.Context, client client.Client) (*SwitchResourceLimit, error) {
 	if l == nil {
@@ -149,7 +244,21 @@ func (sr *SwitchResource) SetError(err error) {
 
 // VerifyConfiguration verify that the configuration meets the limit.
 func (l *TenantLimit) VerifyConfiguration(configuration *SwitchPortConfiguration) error {
-	if l == nil || l.VLANRange == "" {
+	if l == nil {
+		return nil
+	}
+
+	if l.MaxTaggedVLANsPerPort > 0 {
+		taggedVLANs, err := strings.RangeToSlice(configuration.Spec.TaggedVLANRange)
+		if err != nil {
+			return err
+		}
+		if len(taggedVLANs) > l.MaxTaggedVLANsPerPort {
+			return fmt.Errorf("the number of tagged vlans %d exceeds the limit %d", len(taggedVLANs), l.MaxTaggedVLANsPerPort)
+		}
+	}
+
+	if l.VLANRange == "" {
 		return nil
 	}
 
@@ -168,6 +277,30 @@ func (l *TenantLimit) VerifyConfiguration(configuration *SwitchPortConfiguration
 	return nil
 }
 
+// VerifyUsage verify that the port can be used without exceeding the quota of tenant.
+// The port that has been recorded in used will not be counted again.
+func (l *TenantLimit) VerifyUsage(used *SwitchResourceLimit, port string, configuration *SwitchPortConfiguration) error {
+	if l == nil || used == nil {
+		return nil
+	}
+
+	usage, recorded := used.Status.Ports[port]
+	if l.MaxPorts > 0 && !recorded && len(used.Status.Ports) >= l.MaxPorts {
+		return fmt.Errorf("the number of ports has reached the limit %d", l.MaxPorts)
+	}
+
+	if configuration == nil || configuration.Spec.TaggedVLANRange == "" {
+		return nil
+	}
+
+	recordedTrunk := recorded && usage != nil && usage.Trunk
+	if l.MaxTrunkPorts > 0 && !recordedTrunk && used.TrunkPorts() >= l.MaxTrunkPorts {
+		return fmt.Errorf("the number of trunk ports has reached the limit %d", l.MaxTrunkPorts)
+	}
+
+	return nil
+}
+
 const (
 	// SwitchResourceNone means the CR has just been created
 	SwitchResourceNone machine.StateType = ""
@@ -190,6 +323,11 @@ type SwitchResourceSpec struct {
 	// Indicates the initial allocatable vlan range
 	VLANRange    string                  `json:"vlanRange,omitempty"`
 	TenantLimits map[string]*TenantLimit `json:"tenantLimits,omitempty"`
+
+	// The vlans can't be assigned to tenants, such as the native management vlan
+	// and the provisioning vlan. VLAN 1 is always reserved.
+	// +kubebuilder:validation:Pattern=`([0-9]{1,})|([0-9]{1,}-[0-9]{1,})(,([0-9]{1,})|([0-9]{1,}-[0-9]{1,}))*`
+	ReservedVLANs string `json:"reservedVLANs,omitempty"`
 }
 
 // SwitchResourceStatus defines the observed state of SwitchResource
@@ -198,6 +336,8 @@ type SwitchResourceStatus struct {
 	// can assign to the user currently.
 	AvailableVLAN string                  `json:"availableVLAN,omitempty"`
 	TenantLimits  map[string]*TenantLimit `json:"tenantLimits,omitempty"`
+	// The vlans can't be assigned to tenants, include VLAN 1
+	ReservedVLANs string `json:"reservedVLANs,omitempty"`
 	// The error message of the port
 	Error string `json:"error,omitempty"`
 	// The current configuration status of the SwitchResource
@@ -208,6 +348,18 @@ type SwitchResourceStatus struct {
 type TenantLimit struct {
 	Namespace string `json:"namespace,omitempty"`
 	VLANRange string `json:"vlanRange,omitempty"`
+
+	// The maximum number of ports the tenant can use, 0 means unlimited
+	// +kubebuilder:validation:Minimum=0
+	MaxPorts int `json:"maxPorts,omitempty"`
+
+	// The maximum number of trunk ports the tenant can use, 0 means unlimited
+	// +kubebuilder:validation:Minimum=0
+	MaxTrunkPorts int `json:"maxTrunkPorts,omitempty"`
+
+	// The maximum number of tagged vlans per port, 0 means unlimited
+	// +kubebuilder:validation:Minimum=0
+	MaxTaggedVLANsPerPort int `json:"maxTaggedVLANsPerPort,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -0,0 +1,305 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/Hellcatlk/network-operator/pkg/utils/strings"
+)
+
+func TestTenantLimitVerifyConfiguration(t *testing.T) {
+	untaggedVLAN := 5
+	cases := []struct {
+		name          string
+		limit         *TenantLimit
+		configuration *SwitchPortConfiguration
+		expectedError bool
+	}{
+		{
+			name:  "nil limit",
+			limit: nil,
+			configuration: &SwitchPortConfiguration{
+				Spec: SwitchPortConfigurationSpec{
+					TaggedVLANRange: "1-10",
+				},
+			},
+			expectedError: false,
+		},
+		{
+			name: "vlan is out of range",
+			limit: &TenantLimit{
+				VLANRange: "1-4",
+			},
+			configuration: &SwitchPortConfiguration{
+				Spec: SwitchPortConfigurationSpec{
+					UntaggedVLAN: &untaggedVLAN,
+				},
+			},
+			expectedError: true,
+		},
+		{
+			name: "too many tagged vlans",
+			limit: &TenantLimit{
+				VLANRange:             "1-100",
+				MaxTaggedVLANsPerPort: 5,
+			},
+			configuration: &SwitchPortConfiguration{
+				Spec: SwitchPortConfigurationSpec{
+					TaggedVLANRange: "1-10",
+				},
+			},
+			expectedError: true,
+		},
+		{
+			name: "tagged vlans in limit",
+			limit: &TenantLimit{
+				MaxTaggedVLANsPerPort: 10,
+			},
+			configuration: &SwitchPortConfiguration{
+				Spec: SwitchPortConfigurationSpec{
+					TaggedVLANRange: "1-10",
+					UntaggedVLAN:    &untaggedVLAN,
+				},
+			},
+			expectedError: false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.limit.VerifyConfiguration(c.configuration)
+			if (err != nil) != c.expectedError {
+				t.Errorf("Got unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestTenantLimitVerifyUsage(t *testing.T) {
+	access := &SwitchPortConfiguration{}
+	trunk := &SwitchPortConfiguration{
+		Spec: SwitchPortConfigurationSpec{
+			TaggedVLANRange: "1-10",
+		},
+	}
+	used := &SwitchResourceLimit{
+		Status: SwitchResourceLimitStatus{
+			Ports: map[string]*PortUsage{
+				"default/port1": {
+					VLANRange: "1",
+				},
+				"default/port2": {
+					VLANRange: "1-10",
+					Trunk:     true,
+				},
+			},
+		},
+	}
+
+	cases := []struct {
+		name          string
+		limit         *TenantLimit
+		port          string
+		configuration *SwitchPortConfiguration
+		expectedError bool
+	}{
+		{
+			name:          "unlimited",
+			limit:         &TenantLimit{},
+			port:          "default/port3",
+			configuration: trunk,
+			expectedError: false,
+		},
+		{
+			name: "ports reach the limit",
+			limit: &TenantLimit{
+				MaxPorts: 2,
+			},
+			port:          "default/port3",
+			configuration: access,
+			expectedError: true,
+		},
+		{
+			name: "port has been counted",
+			limit: &TenantLimit{
+				MaxPorts: 2,
+			},
+			port:          "default/port1",
+			configuration: access,
+			expectedError: false,
+		},
+		{
+			name: "trunk ports reach the limit",
+			limit: &TenantLimit{
+				MaxTrunkPorts: 1,
+			},
+			port:          "default/port1",
+			configuration: trunk,
+			expectedError: true,
+		},
+		{
+			name: "trunk port has been counted",
+			limit: &TenantLimit{
+				MaxTrunkPorts: 1,
+			},
+			port:          "default/port2",
+			configuration: trunk,
+			expectedError: false,
+		},
+		{
+			name: "access port isn't limited by trunk ports",
+			limit: &TenantLimit{
+				MaxTrunkPorts: 1,
+			},
+			port:          "default/port3",
+			configuration: access,
+			expectedError: false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.limit.VerifyUsage(used, c.port, c.configuration)
+			if (err != nil) != c.expectedError {
+				t.Errorf("Got unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestSwitchResourceResize(t *testing.T) {
+	cases := []struct {
+		name                  string
+		availableVLAN         string
+		usedVLAN              string
+		old                   *TenantLimit
+		new                   *TenantLimit
+		expectedAvailableVLAN string
+		expectedVLANRange     string
+		expectedError         bool
+	}{
+		{
+			name:                  "expand",
+			availableVLAN:         "11-100",
+			usedVLAN:              "1-5",
+			old:                   &TenantLimit{VLANRange: "1-10"},
+			new:                   &TenantLimit{VLANRange: "1-20"},
+			expectedAvailableVLAN: "21-100",
+			expectedVLANRange:     "1-20",
+		},
+		{
+			name:                  "expand with unavailable vlan",
+			availableVLAN:         "11-100",
+			old:                   &TenantLimit{VLANRange: "1-10"},
+			new:                   &TenantLimit{VLANRange: "1-200"},
+			expectedAvailableVLAN: "11-100",
+			expectedVLANRange:     "1-10",
+			expectedError:         true,
+		},
+		{
+			name:                  "shrink unused vlan",
+			availableVLAN:         "11-100",
+			usedVLAN:              "1-5",
+			old:                   &TenantLimit{VLANRange: "1-10"},
+			new:                   &TenantLimit{VLANRange: "1-5"},
+			expectedAvailableVLAN: "6-100",
+			expectedVLANRange:     "1-5",
+		},
+		{
+			name:                  "shrink used vlan",
+			availableVLAN:         "11-100",
+			usedVLAN:              "1-5",
+			old:                   &TenantLimit{VLANRange: "1-10"},
+			new:                   &TenantLimit{VLANRange: "1-4,11"},
+			expectedAvailableVLAN: "11-100",
+			expectedVLANRange:     "1-10",
+			expectedError:         true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			sr := &SwitchResource{}
+			sr.Status.AvailableVLAN = c.availableVLAN
+			rl := &SwitchResourceLimit{}
+			rl.Status.VLANRange = c.old.VLANRange
+			rl.Status.UsedVLAN = c.usedVLAN
+
+			err := sr.Resize(rl, c.old, c.new)
+			if (err != nil) != c.expectedError {
+				t.Errorf("Got unexpected error: %v", err)
+			}
+			if c.expectedAvailableVLAN != sr.Status.AvailableVLAN {
+				t.Errorf("Expected available vlan: %s, got: %s", c.expectedAvailableVLAN, sr.Status.AvailableVLAN)
+			}
+			if c.expectedVLANRange != rl.Status.VLANRange {
+				t.Errorf("Expected vlan range: %s, got: %s", c.expectedVLANRange, rl.Status.VLANRange)
+			}
+		})
+	}
+}
+
+func TestSwitchResourceReserve(t *testing.T) {
+	cases := []struct {
+		name                  string
+		oldReservedVLANs      string
+		reservedVLANs         string
+		tenantVLANRange       string
+		expectedAvailableVLAN string
+		expectedReservedVLANs string
+		expectedError         bool
+	}{
+		{
+			name:                  "vlan 1 is always reserved",
+			expectedAvailableVLAN: "2-100",
+			expectedReservedVLANs: "1",
+		},
+		{
+			name:                  "reserve vlans",
+			oldReservedVLANs:      "1",
+			reservedVLANs:         "50-60,200",
+			expectedAvailableVLAN: "2-49,61-100",
+			expectedReservedVLANs: "1,50-60,200",
+		},
+		{
+			name:                  "release vlans",
+			oldReservedVLANs:      "1,50-60,200",
+			reservedVLANs:         "60",
+			expectedAvailableVLAN: "2-59,61-100",
+			expectedReservedVLANs: "1,60",
+		},
+		{
+			name:                  "reserve vlan assigned to tenant",
+			oldReservedVLANs:      "1",
+			reservedVLANs:         "10",
+			tenantVLANRange:       "5-15",
+			expectedAvailableVLAN: "2-100",
+			expectedReservedVLANs: "1",
+			expectedError:         true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			sr := &SwitchResource{}
+			sr.Spec.VLANRange = "1-100"
+			sr.Status.ReservedVLANs = c.oldReservedVLANs
+			sr.Status.AvailableVLAN, _ = strings.Shrink(sr.Spec.VLANRange, c.oldReservedVLANs)
+			if c.tenantVLANRange != "" {
+				sr.Status.TenantLimits = map[string]*TenantLimit{
+					"user": {VLANRange: c.tenantVLANRange},
+				}
+			}
+
+			err := sr.Reserve(c.reservedVLANs)
+			if (err != nil) != c.expectedError {
+				t.Errorf("Got unexpected error: %v", err)
+			}
+			if c.expectedAvailableVLAN != sr.Status.AvailableVLAN {
+				t.Errorf("Expected available vlan: %s, got: %s", c.expectedAvailableVLAN, sr.Status.AvailableVLAN)
+			}
+			if c.expectedReservedVLANs != sr.Status.ReservedVLANs {
+				t.Errorf("Expected reserved vlans: %s, got: %s", c.expectedReservedVLANs, sr.Status.ReservedVLANs)
+			}
+		})
+	}
+}
@@ -19,7 +19,6 @@ package v1alpha1
 import (
 	"context"
 	"fmt"
-	"strconv"
 
 	"github.com/Hellcatlk/network-operator/pkg/utils/strings"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -47,53 +46,116 @@ func (rl *SwitchResourceLimit) FetchSwitchResource(ctx context.Context, client c
 	return instance, err
 }
 
-// Expansion ...
-func (rl *SwitchResourceLimit) Expansion(configuration *SwitchPortConfigurationSpec) error {
+// Expansion records the resources used by the port. If the port has been
+// recorded, the old record will be replaced.
+func (rl *SwitchResourceLimit) Expansion(port string, configuration *SwitchPortConfigurationSpec) error {
 	if configuration == nil {
 		return nil
 	}
-	var err error
-	useVLAN := configuration.TaggedVLANRange
-	if configuration.UntaggedVLAN != nil {
-		useVLAN, err = strings.Expansion(configuration.TaggedVLANRange, strconv.Itoa(*configuration.UntaggedVLAN))
-		if err != nil {
-			return err
-		}
-	}
 
-	result, err := strings.Expansion(rl.Status.UsedVLAN, useVLAN)
+	vlanRange, err := configuration.VLANRange()
 	if err != nil {
 		return err
 	}
 
-	rl.Status.UsedVLAN = result
+	if rl.Status.Ports == nil {
+		rl.Status.Ports = make(map[string]*PortUsage)
+	}
+	rl.Status.Ports[port] = &PortUsage{
+		VLANRange: vlanRange,
+		Trunk:     configuration.TaggedVLANRange != "",
+	}
 
-	return nil
+	return rl.updateUsedVLAN()
 }
 
-// Shrink ...
-func (rl *SwitchResourceLimit) Shrink(configuration *SwitchPortConfigurationSpec) error {
-	if configuration == nil {
+// Shrink removes the record of the port
+func (rl *SwitchResourceLimit) Shrink(port string) error {
+	if _, exist := rl.Status.Ports[port]; !exist {
 		return nil
 	}
-	var err error
-	useVLAN := configuration.TaggedVLANRange
-	if configuration.UntaggedVLAN != nil {
-		useVLAN, err = strings.Expansion(configuration.TaggedVLANRange, strconv.Itoa(*configuration.UntaggedVLAN))
+
+	delete(rl.Status.Ports, port)
+
+	return rl.updateUsedVLAN()
+}
+
+// Rebuild recalculates the records from the actual state of SwitchPorts.
+// Active ports which use the configuration in the namespace of the limit are recorded,
+// the ports which are configuring or cleaning keep their old records because their
+// usage may be in flight, the others are removed.
+func (rl *SwitchResourceLimit) Rebuild(ports []SwitchPort) error {
+	old := rl.Status.Ports
+	rl.Status.Ports = nil
+
+	for i := range ports {
+		port := &ports[i]
+		if port.Spec.Configuration == nil || port.Spec.Configuration.Namespace != rl.Namespace {
+			continue
+		}
+
+		key := types.NamespacedName{Name: port.Name, Namespace: port.Namespace}.String()
+		switch port.Status.State {
+		case SwitchPortActive:
+			err := rl.Expansion(key, port.Status.Configuration)
+			if err != nil {
+				return err
+			}
+		case SwitchPortConfiguring, SwitchPortCleaning:
+			usage, exist := old[key]
+			if !exist {
+				continue
+			}
+			if rl.Status.Ports == nil {
+				rl.Status.Ports = make(map[string]*PortUsage)
+			}
+			rl.Status.Ports[key] = usage
+		}
+	}
+
+	return rl.updateUsedVLAN()
+}
+
+// TrunkPorts return the number of trunk ports recorded
+func (rl *SwitchResourceLimit) TrunkPorts() int {
+	count := 0
+	for _, usage := range rl.Status.Ports {
+		if usage != nil && usage.Trunk {
+			count++
+		}
+	}
+	return count
+}
+
+// updateUsedVLAN calculates `status.usedVLAN` from the records of all ports,
+// so a vlan is released only when no port uses it.
+func (rl *SwitchResourceLimit) updateUsedVLAN() error {
+	usedVLAN := ""
+	for _, usage := range rl.Status.Ports {
+		if usage == nil {
+			continue
+		}
+		var err error
+		usedVLAN, err = strings.Expansion(usedVLAN, usage.VLANRange)
 		if err != nil {
 			return err
 		}
 	}
-	result, err := strings.Shrink(rl.Status.UsedVLAN, useVLAN)
-	if err != nil {
-		return err
-	}
 
-	rl.Status.UsedVLAN = result
+	rl.Status.UsedVLAN = usedVLAN
 
 	return nil
 }
 
+// PortUsage indicates the resources used by a SwitchPort
+type PortUsage struct {
+	// The vlans used by the port
+	VLANRange string `json:"vlanRange,omitempty"`
+
+	// True if the port is used as a trunk port
+	Trunk bool `json:"trunk,omitempty"`
+}
+
 // SwitchResourceLimitSpec defines the desired state of SwitchResourceLimit
 type SwitchResourceLimitSpec struct {
 }
@@ -102,10 +164,14 @@ type SwitchResourceLimitSpec struct {
 type SwitchResourceLimitStatus struct {
 	// Indicates the range of VLANs allowed
 	// +kubebuilder:validation:Pattern=`([0-9]{1,})|([0-9]{1,}-[0-9]{1,})(,([0-9]{1,})|([0-9]{1,}-[0-9]{1,}))*`
-	// +kubebuilder:default:="1-4096"
+	// +kubebuilder:default:="2-4094"
 	VLANRange         string            `json:"vlanRange,omitempty"`
 	SwitchResourceRef SwitchResourceRef `json:"switchResourceRef,omitempty"`
-	UsedVLAN          string            `json:"usedVLAN,omitempty"`
+	// Indicates the vlans used by all ports, it's calculated from `ports`
+	UsedVLAN string `json:"usedVLAN,omitempty"`
+
+	// The resources used by every SwitchPort, the key is `<namespace>/<name>` of the SwitchPort
+	Ports map[string]*PortUsage `json:"ports,omitempty"`
 }
 
 // SwitchResourceRef is the reference for SwitchResource CR
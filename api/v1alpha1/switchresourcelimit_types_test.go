@@ -0,0 +1,159 @@
+package v1alpha1
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Hellcatlk/network-operator/pkg/machine"
+)
+
+func TestSwitchResourceLimitExpansionAndShrink(t *testing.T) {
+	vlan100 := 100
+	vlan200 := 200
+	rl := &SwitchResourceLimit{}
+
+	cases := []struct {
+		name             string
+		port             string
+		configuration    *SwitchPortConfigurationSpec
+		shrink           bool
+		expectedUsedVLAN string
+		expectedPorts    int
+		expectedTrunks   int
+	}{
+		{
+			name: "port1 uses vlan 100",
+			port: "default/port1",
+			configuration: &SwitchPortConfigurationSpec{
+				UntaggedVLAN: &vlan100,
+			},
+			expectedUsedVLAN: "100",
+			expectedPorts:    1,
+		},
+		{
+			name: "port2 uses vlan 100 too",
+			port: "default/port2",
+			configuration: &SwitchPortConfigurationSpec{
+				UntaggedVLAN:    &vlan100,
+				TaggedVLANRange: "10-12",
+			},
+			expectedUsedVLAN: "10-12,100",
+			expectedPorts:    2,
+			expectedTrunks:   1,
+		},
+		{
+			name: "port1 is recorded again",
+			port: "default/port1",
+			configuration: &SwitchPortConfigurationSpec{
+				UntaggedVLAN: &vlan200,
+			},
+			expectedUsedVLAN: "10-12,100,200",
+			expectedPorts:    2,
+			expectedTrunks:   1,
+		},
+		{
+			name:             "port2 is cleaned, vlan 100 is released",
+			port:             "default/port2",
+			shrink:           true,
+			expectedUsedVLAN: "200",
+			expectedPorts:    1,
+		},
+		{
+			name:             "port3 isn't recorded",
+			port:             "default/port3",
+			shrink:           true,
+			expectedUsedVLAN: "200",
+			expectedPorts:    1,
+		},
+		{
+			name:             "port1 is cleaned",
+			port:             "default/port1",
+			shrink:           true,
+			expectedUsedVLAN: "",
+			expectedPorts:    0,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var err error
+			if c.shrink {
+				err = rl.Shrink(c.port)
+			} else {
+				err = rl.Expansion(c.port, c.configuration)
+			}
+			if err != nil {
+				t.Errorf("Got unexpected error: %v", err)
+			}
+			if c.expectedUsedVLAN != rl.Status.UsedVLAN {
+				t.Errorf("Expected used vlan: %s, got: %s", c.expectedUsedVLAN, rl.Status.UsedVLAN)
+			}
+			if c.expectedPorts != len(rl.Status.Ports) {
+				t.Errorf("Expected ports: %d, got: %d", c.expectedPorts, len(rl.Status.Ports))
+			}
+			if c.expectedTrunks != rl.TrunkPorts() {
+				t.Errorf("Expected trunk ports: %d, got: %d", c.expectedTrunks, rl.TrunkPorts())
+			}
+		})
+	}
+}
+
+func TestSwitchResourceLimitRebuild(t *testing.T) {
+	vlan100 := 100
+	newPort := func(name string, namespace string, state machine.StateType, configuration *SwitchPortConfigurationSpec) SwitchPort {
+		port := SwitchPort{}
+		port.Name = name
+		port.Namespace = "default"
+		port.Spec.Configuration = &SwitchPortConfigurationReference{
+			Name:      "configuration",
+			Namespace: namespace,
+		}
+		port.Status.State = state
+		port.Status.Configuration = configuration
+		return port
+	}
+
+	rl := &SwitchResourceLimit{}
+	rl.Namespace = "tenant"
+	rl.Status.UsedVLAN = "1-200"
+	rl.Status.Ports = map[string]*PortUsage{
+		"default/lost": {
+			VLANRange: "1-200",
+		},
+		"default/configuring": {
+			VLANRange: "10",
+		},
+	}
+
+	ports := []SwitchPort{
+		newPort("active", "tenant", SwitchPortActive, &SwitchPortConfigurationSpec{
+			UntaggedVLAN:    &vlan100,
+			TaggedVLANRange: "20-21",
+		}),
+		newPort("configuring", "tenant", SwitchPortConfiguring, &SwitchPortConfigurationSpec{}),
+		newPort("idle", "tenant", SwitchPortIdle, nil),
+		newPort("other", "other", SwitchPortActive, &SwitchPortConfigurationSpec{
+			UntaggedVLAN: &vlan100,
+		}),
+	}
+
+	err := rl.Rebuild(ports)
+	if err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	expected := map[string]*PortUsage{
+		"default/active": {
+			VLANRange: "20-21,100",
+			Trunk:     true,
+		},
+		"default/configuring": {
+			VLANRange: "10",
+		},
+	}
+	if !reflect.DeepEqual(expected, rl.Status.Ports) {
+		t.Errorf("Expected ports: %v, got: %v", expected, rl.Status.Ports)
+	}
+	if rl.Status.UsedVLAN != "10,20-21,100" {
+		t.Errorf("Expected used vlan: %s, got: %s", "10,20-21,100", rl.Status.UsedVLAN)
+	}
+}
@@ -0,0 +1,71 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	"github.com/Hellcatlk/network-operator/pkg/utils/strings"
+)
+
+const (
+	// MinVLAN is the minimum valid vlan id
+	MinVLAN int = 1
+
+	// MaxVLAN is the maximum valid vlan id, 4095 is reserved by 802.1Q
+	MaxVLAN int = 4094
+
+	// DefaultReservedVLAN is always reserved, it's the default vlan of most switches
+	DefaultReservedVLAN string = "1"
+)
+
+// ReservedVLANs return the reserved vlans including DefaultReservedVLAN
+func ReservedVLANs(reserved string) (string, error) {
+	return strings.Expansion(DefaultReservedVLAN, reserved)
+}
+
+// VerifyVLANs verify that the vlans are valid and aren't reserved
+func VerifyVLANs(vlanRange string, reserved string) error {
+	vlans, err := strings.RangeToSlice(vlanRange)
+	if err != nil {
+		return err
+	}
+
+	reserved, err = ReservedVLANs(reserved)
+	if err != nil {
+		return err
+	}
+	reservedSlice, err := strings.RangeToSlice(reserved)
+	if err != nil {
+		return err
+	}
+	reservedMap := make(map[int]struct{})
+	for _, vlan := range reservedSlice {
+		reservedMap[vlan] = struct{}{}
+	}
+
+	for _, vlan := range vlans {
+		if vlan < MinVLAN || vlan > MaxVLAN {
+			return fmt.Errorf("vlan %d is invalid, it must be in %d-%d", vlan, MinVLAN, MaxVLAN)
+		}
+		if _, exist := reservedMap[vlan]; exist {
+			return fmt.Errorf("vlan %d is reserved", vlan)
+		}
+	}
+
+	return nil
+}
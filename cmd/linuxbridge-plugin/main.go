@@ -0,0 +1,48 @@
+// The linuxbridge-plugin serves the linuxbridge backend as a plugin, so the
+// bridges of remote hosts can be configured by running it on the hosts.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Hellcatlk/network-operator/pkg/backends/switches/linuxbridge"
+	"github.com/Hellcatlk/network-operator/pkg/backends/switches/plugin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+func main() {
+	var endpoint, certFile, keyFile string
+	flag.StringVar(&endpoint, "endpoint", os.Getenv(plugin.EndpointEnv),
+		"The endpoint the plugin listens on, `unix://<path>` or `<host>:<port>`.")
+	flag.StringVar(&certFile, "tls-cert-file", "", "The certificate of TLS, it's required by `<host>:<port>`.")
+	flag.StringVar(&keyFile, "tls-key-file", "", "The private key of TLS, it's required by `<host>:<port>`.")
+	flag.Parse()
+
+	if endpoint == "" {
+		fmt.Fprintf(os.Stderr, "--endpoint or %s is required\n", plugin.EndpointEnv)
+		os.Exit(1)
+	}
+	var opts []grpc.ServerOption
+	if !strings.HasPrefix(endpoint, "unix://") {
+		// The manager sends the credentials only with TLS
+		if certFile == "" || keyFile == "" {
+			fmt.Fprintln(os.Stderr, "--tls-cert-file and --tls-key-file are required by <host>:<port>")
+			os.Exit(1)
+		}
+		creds, err := credentials.NewServerTLSFromFile(certFile, keyFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+	err := plugin.ListenAndServe(endpoint, linuxbridge.New, opts...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
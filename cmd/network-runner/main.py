@@ -1,10 +1,21 @@
 #!/usr/bin/python3
 
 import json
+import os
+import socketserver
 import sys
+import tempfile
+import threading
 from network_runner import api
 from network_runner.models.inventory import Host, Inventory
 
+# PROTOCOL_VERSION is the version of the JSON-RPC protocol in serve mode
+PROTOCOL_VERSION = "1"
+
+# CONTRACT_VERSIONS are the supported versions of the operation, the
+# operation without version is the legacy one passing credentials by argv
+CONTRACT_VERSIONS = ["1"]
+
 
 def _get_port_conf(port):
     """Get port configuration
@@ -12,11 +23,10 @@ def _get_port_conf(port):
     :param port: port ID
     :type data: String
 
-    :returns: None
+    :returns: Dict of port configuration
     """
 
-    network_runner.get_port_conf("network-operator", port, True)
-    return
+    return network_runner.get_port_conf("network-operator", port, True)
 
 
 def _config_access_port(port, untaggedVLAN):
@@ -87,23 +97,19 @@ def _delete_port(port, bridge):
     return
 
 
-if __name__ == '__main__':
-    # Parse json data
-    # format:
-    # {
-    #     "host": "192.168.0.1",
-    #     "credentials": {
-    #         "username": "admin",
-    #         "password": "admin"
-    #     },
-    #     "os": "fos",
-    #     "bridge": "",
-    #     "operator": "getPortConf/configAccessPort/configTrunkPort/deletePort",
-    #     "port": "0/32",
-    #     "untaggedVLAN": 0
-    #     "vlans": [1,2,3]
-    # }
-    data = json.loads(sys.argv[1])
+def _run(data):
+    """Run the operator of data
+
+    :param data: the request of operator, see the format in __main__
+    :type data: Dict
+
+    :returns: Dict of port configuration for getPortConf, otherwise None
+    """
+
+    global network_runner
+
+    if "version" in data and data["version"] not in CONTRACT_VERSIONS:
+        raise ValueError("unsupported contract version %s" % data["version"])
 
     # Initial network runner
     host = Host(name="network-operator",
@@ -117,7 +123,7 @@ if __name__ == '__main__':
 
     # Deal operator
     if data["operator"] == "getPortConf":
-        _get_port_conf(data["port"])
+        return _get_port_conf(data["port"])
     elif data["operator"] == "configAccessPort":
         _config_access_port(data["port"], data["untaggedVLAN"])
     elif data["operator"] == "configTrunkPort":
@@ -126,5 +132,159 @@ if __name__ == '__main__':
     elif data["operator"] == "deletePort":
         _delete_port(data["port"], data.get("bridge"))
     else:
-        print("invalid operator")
+        raise ValueError("invalid operator")
+
+
+class RunnerError(Exception):
+    """The error of operator, the output of ansible is kept in log"""
+
+    def __init__(self, message, log):
+        super().__init__(message)
+        self.log = log
+
+
+def _call(data, timeout):
+    """Run the operator in a forked worker, so the output of ansible can be
+    captured and the run can be killed when timeout.
+
+    :param data: the request of operator
+    :type data: Dict
+
+    :param timeout: seconds to wait, 0 means no timeout
+    :type timeout: Int
+
+    :returns: Dict of result
+
+    :raises: RunnerError if the operator failed
+    """
+
+    reader, writer = os.pipe()
+    pid = os.fork()
+    if pid == 0:
+        os.close(reader)
+        output = tempfile.TemporaryFile()
+        os.dup2(output.fileno(), 1)
+        os.dup2(output.fileno(), 2)
+        response = {}
+        try:
+            result = _run(data)
+            if data["operator"] == "getPortConf":
+                response["result"] = {"portConfiguration": result}
+            else:
+                response["result"] = {}
+        except BaseException as e:
+            sys.stdout.flush()
+            sys.stderr.flush()
+            output.seek(0)
+            response["error"] = str(e) or type(e).__name__
+            response["log"] = output.read().decode(errors="replace")
+        os.write(writer, json.dumps(response).encode())
+        os._exit(0)
+
+    os.close(writer)
+    timed_out = threading.Event()
+
+    def _kill():
+        timed_out.set()
+        os.kill(pid, 9)
+
+    if timeout > 0:
+        timer = threading.Timer(timeout, _kill)
+        timer.start()
+    with os.fdopen(reader, "rb") as f:
+        payload = f.read()
+    os.waitpid(pid, 0)
+    if timeout > 0:
+        timer.cancel()
+    if timed_out.is_set():
+        raise RuntimeError("timed out after %d seconds" % timeout)
+    if not payload:
+        raise RuntimeError("worker crashed")
+    response = json.loads(payload)
+    if "error" in response:
+        raise RunnerError(response["error"], response["log"])
+    return response["result"]
+
+
+class _Handler(socketserver.StreamRequestHandler):
+    """Handle JSON-RPC 2.0 requests, one request per line"""
+
+    def handle(self):
+        for line in self.rfile:
+            response = {"jsonrpc": "2.0", "id": None}
+            try:
+                request = json.loads(line)
+                response["id"] = request.get("id")
+                if request.get("method") == "version":
+                    response["result"] = {"version": PROTOCOL_VERSION}
+                else:
+                    data = dict(request.get("params") or {})
+                    data["operator"] = request.get("method")
+                    response["result"] = _call(
+                        data, request.get("timeout", 0))
+            except RunnerError as e:
+                response["error"] = {"code": -32000, "message": str(e),
+                                     "data": {"log": e.log}}
+            except Exception as e:
+                response["error"] = {"code": -32000, "message": str(e)}
+            self.wfile.write((json.dumps(response) + "\n").encode())
+            self.wfile.flush()
+
+
+class _Server(socketserver.ThreadingMixIn, socketserver.UnixStreamServer):
+    daemon_threads = True
+
+
+def _serve(path):
+    """Serve the JSON-RPC requests on unix socket
+
+    :param path: the path of unix socket
+    :type path: String
+
+    :returns: None
+    """
+
+    if os.path.exists(path):
+        os.remove(path)
+    with _Server(path, _Handler) as server:
+        os.chmod(path, 0o600)
+        server.serve_forever()
+
+
+if __name__ == '__main__':
+    # Serve mode: network-runner --serve <socket>
+    if len(sys.argv) == 3 and sys.argv[1] == "--serve":
+        _serve(sys.argv[2])
+        exit(0)
+
+    # Parse json data, see the contract in docs/network-runner.md
+    # format:
+    # {
+    #     "version": "1",
+    #     "host": "192.168.0.1",
+    #     "os": "fos",
+    #     "bridge": "",
+    #     "operator": "getPortConf/configAccessPort/configTrunkPort/deletePort",
+    #     "port": "0/32",
+    #     "untaggedVLAN": 0
+    #     "vlans": [1,2,3]
+    # }
+    data = json.loads(sys.argv[1])
+
+    # The credentials are passed by stdin, so they can't be seen in the
+    # process list:
+    # {
+    #     "username": "admin",
+    #     "password": "admin"
+    # }
+    if "credentials" not in data:
+        data["credentials"] = json.load(sys.stdin)
+
+    try:
+        result = _run(data)
+    except ValueError as e:
+        print(e)
         exit(1)
+    # The result is printed last, after the output of ansible
+    if data["operator"] == "getPortConf":
+        print(json.dumps(result))
@@ -0,0 +1,111 @@
+// The switch-emulator serves an SSH server emulating the CLI of Cisco IOS on
+// in-memory interfaces and VLANs, so the SSH based backends, like ansible and
+// cli, can be tested end to end without switches.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/Hellcatlk/network-operator/pkg/backends/switches/cli/clitest"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	usernameEnv = "SWITCH_EMULATOR_USERNAME"
+	passwordEnv = "SWITCH_EMULATOR_PASSWORD"
+)
+
+// interfaceRange matches the interfaces like `GigabitEthernet1/0/1-48`
+var interfaceRange = regexp.MustCompile(`^(.*\D)(\d+)-(\d+)$`)
+
+func main() {
+	var listen, username, password, hostname, interfaces, hostKey string
+	flag.StringVar(&listen, "listen", ":2222", "The address the SSH server listens on.")
+	flag.StringVar(&username, "username", os.Getenv(usernameEnv), "The username of clients, it's "+usernameEnv+" by default.")
+	flag.StringVar(&password, "password", os.Getenv(passwordEnv), "The password of clients, it's "+passwordEnv+" by default.")
+	flag.StringVar(&hostname, "hostname", "switch", "The hostname of switch.")
+	flag.StringVar(&interfaces, "interfaces", "GigabitEthernet1/0/1-48",
+		"The comma-separated interfaces of switch, the last number of interface can be a range like GigabitEthernet1/0/1-48.")
+	flag.StringVar(&hostKey, "host-key", "", "The private key file of SSH server, the key is generated if it's empty.")
+	flag.Parse()
+
+	err := run(listen, username, password, hostname, interfaces, hostKey)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(listen, username, password, hostname, interfaces, hostKey string) error {
+	if username == "" || password == "" {
+		return fmt.Errorf("--username and --password are required")
+	}
+	names, err := expandInterfaces(interfaces)
+	if err != nil {
+		return err
+	}
+	server, err := clitest.NewServer(username, password, names...)
+	if err != nil {
+		return err
+	}
+	server.SetHostname(hostname)
+	if hostKey != "" {
+		key, err := ioutil.ReadFile(hostKey) // #nosec G304
+		if err != nil {
+			return err
+		}
+		signer, err := ssh.ParsePrivateKey(key)
+		if err != nil {
+			return fmt.Errorf("parse host key %s failed: %v", hostKey, err)
+		}
+		server.SetHostKey(signer)
+	}
+
+	address, err := server.Start(listen)
+	if err != nil {
+		return err
+	}
+	defer server.Close()
+	fmt.Printf("listening on %s with %d interfaces, host key: %s", address, len(names), ssh.MarshalAuthorizedKey(server.HostKey()))
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	<-signals
+	return nil
+}
+
+// expandInterfaces return the names of interfaces in the comma-separated list
+func expandInterfaces(list string) ([]string, error) {
+	names := []string{}
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		matches := interfaceRange.FindStringSubmatch(item)
+		if matches == nil {
+			names = append(names, item)
+			continue
+		}
+		first, _ := strconv.Atoi(matches[2])
+		last, _ := strconv.Atoi(matches[3])
+		if first > last {
+			return nil, fmt.Errorf("invalid range of interfaces %q", item)
+		}
+		for n := first; n <= last; n++ {
+			names = append(names, matches[1]+strconv.Itoa(n))
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("--interfaces is required")
+	}
+	return names, nil
+}
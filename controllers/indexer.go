@@ -0,0 +1,271 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/Hellcatlk/network-operator/api/v1alpha1"
+	"github.com/Hellcatlk/network-operator/pkg/provider"
+	"github.com/Hellcatlk/network-operator/pkg/utils/strings"
+)
+
+const (
+	// switchPortConfigurationField indexes SwitchPort by `<namespace>/<name>` of the SwitchPortConfiguration
+	switchPortConfigurationField string = ".spec.configuration"
+
+	// switchPortConfigurationNamespaceField indexes SwitchPort by the namespace of the SwitchPortConfiguration
+	switchPortConfigurationNamespaceField string = ".spec.configuration.namespace"
+
+	// switchPortOwnerField indexes SwitchPort by `<namespace>/<name>` of the owner Switch
+	switchPortOwnerField string = ".metadata.ownerReferences.switch"
+
+	// switchProviderField indexes Switch by `<kind>/<namespace>/<name>` of the provider
+	switchProviderField string = ".spec.provider"
+
+	// providerSecretsField indexes the provider switches by `<namespace>/<name>` of the secrets they use
+	providerSecretsField string = ".spec.credentials"
+
+	// providerConfigMapsField indexes the provider switches by `<namespace>/<name>` of the ConfigMaps they use
+	providerConfigMapsField string = ".spec.configMaps"
+)
+
+// SetupIndexers registers the field indexers used by the watches of controllers,
+// it must be called before the controllers are set up.
+func SetupIndexers(ctx context.Context, mgr ctrl.Manager) error {
+	indexer := mgr.GetFieldIndexer()
+
+	err := indexer.IndexField(ctx, &v1alpha1.SwitchPort{}, switchPortConfigurationField, func(obj client.Object) []string {
+		ref := obj.(*v1alpha1.SwitchPort).Spec.Configuration
+		if ref == nil {
+			return nil
+		}
+		return []string{types.NamespacedName{Name: ref.Name, Namespace: ref.Namespace}.String()}
+	})
+	if err != nil {
+		return err
+	}
+
+	err = indexer.IndexField(ctx, &v1alpha1.SwitchPort{}, switchPortConfigurationNamespaceField, func(obj client.Object) []string {
+		ref := obj.(*v1alpha1.SwitchPort).Spec.Configuration
+		if ref == nil {
+			return nil
+		}
+		return []string{ref.Namespace}
+	})
+	if err != nil {
+		return err
+	}
+
+	err = indexer.IndexField(ctx, &v1alpha1.SwitchPort{}, switchPortOwnerField, func(obj client.Object) []string {
+		sp := obj.(*v1alpha1.SwitchPort)
+		if len(sp.OwnerReferences) == 0 || sp.OwnerReferences[0].Kind != "Switch" {
+			return nil
+		}
+		return []string{types.NamespacedName{Name: sp.OwnerReferences[0].Name, Namespace: sp.Namespace}.String()}
+	})
+	if err != nil {
+		return err
+	}
+
+	err = indexer.IndexField(ctx, &v1alpha1.Switch{}, switchProviderField, func(obj client.Object) []string {
+		sw := obj.(*v1alpha1.Switch)
+		keys := []string{}
+		for _, ref := range []*v1alpha1.SwitchProviderReference{sw.Spec.Provider, sw.Status.Provider} {
+			if ref == nil {
+				continue
+			}
+			key := providerKey(ref.Kind, types.NamespacedName{Name: ref.Name, Namespace: ref.Namespace})
+			if !strings.SliceContains(keys, key) {
+				keys = append(keys, key)
+			}
+		}
+		return keys
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, obj := range providerObjects() {
+		if _, ok := obj.(provider.SecretReferrer); !ok {
+			continue
+		}
+		err = indexer.IndexField(ctx, obj, providerSecretsField, func(obj client.Object) []string {
+			keys := []string{}
+			for _, secret := range obj.(provider.SecretReferrer).ReferencedSecrets() {
+				keys = append(keys, secret.String())
+			}
+			return keys
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, obj := range providerObjects() {
+		if _, ok := obj.(provider.ConfigMapReferrer); !ok {
+			continue
+		}
+		err = indexer.IndexField(ctx, obj, providerConfigMapsField, func(obj client.Object) []string {
+			keys := []string{}
+			for _, configMap := range obj.(provider.ConfigMapReferrer).ReferencedConfigMaps() {
+				keys = append(keys, configMap.String())
+			}
+			return keys
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// providerObjects return the registered provider switches which are objects by kind
+func providerObjects() map[string]client.Object {
+	objects := map[string]client.Object{}
+	for _, kind := range provider.Kinds() {
+		if obj, ok := provider.New(kind).(client.Object); ok {
+			objects[kind] = obj
+		}
+	}
+	return objects
+}
+
+// providerKey return the index key of provider
+func providerKey(kind string, name types.NamespacedName) string {
+	return kind + "/" + name.String()
+}
+
+// switchesForProvider return the requests of Switches which use the provider
+func switchesForProvider(ctx context.Context, c client.Client, kind string, provider types.NamespacedName) []reconcile.Request {
+	switches := &v1alpha1.SwitchList{}
+	err := c.List(ctx, switches, client.MatchingFields{
+		switchProviderField: providerKey(kind, provider),
+	})
+	if err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "list switches failed", "kind", kind, "provider", provider)
+		return nil
+	}
+
+	requests := []reconcile.Request{}
+	for i := range switches.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&switches.Items[i])})
+	}
+	return requests
+}
+
+// switchesForSecret return the requests of Switches whose provider use the secret
+func switchesForSecret(ctx context.Context, c client.Client, secret types.NamespacedName) []reconcile.Request {
+	return switchesForReferencedObject(ctx, c, providerSecretsField, secret, func(obj client.Object) bool {
+		_, ok := obj.(provider.SecretReferrer)
+		return ok
+	})
+}
+
+// switchesForConfigMap return the requests of Switches whose provider use the ConfigMap
+func switchesForConfigMap(ctx context.Context, c client.Client, configMap types.NamespacedName) []reconcile.Request {
+	return switchesForReferencedObject(ctx, c, providerConfigMapsField, configMap, func(obj client.Object) bool {
+		_, ok := obj.(provider.ConfigMapReferrer)
+		return ok
+	})
+}
+
+// switchesForReferencedObject return the requests of Switches whose provider
+// is indexed by the field of referenced object, the providers which aren't
+// referrers are skipped
+func switchesForReferencedObject(ctx context.Context, c client.Client, field string, name types.NamespacedName, referrer func(client.Object) bool) []reconcile.Request {
+	requests := []reconcile.Request{}
+	for kind, obj := range providerObjects() {
+		if !referrer(obj) {
+			continue
+		}
+
+		gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+		if err != nil {
+			ctrl.LoggerFrom(ctx).Error(err, "get kind of provider failed", "kind", kind)
+			continue
+		}
+		list, err := c.Scheme().New(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err != nil {
+			ctrl.LoggerFrom(ctx).Error(err, "new list of provider failed", "kind", kind)
+			continue
+		}
+		providers, ok := list.(client.ObjectList)
+		if !ok {
+			continue
+		}
+		err = c.List(ctx, providers, client.MatchingFields{
+			field: name.String(),
+		})
+		if err != nil {
+			ctrl.LoggerFrom(ctx).Error(err, "list providers failed", "kind", kind, "referenced", name)
+			continue
+		}
+
+		_ = meta.EachListItem(providers, func(item runtime.Object) error {
+			requests = append(requests, switchesForProvider(ctx, c, kind, client.ObjectKeyFromObject(item.(client.Object)))...)
+			return nil
+		})
+	}
+	return requests
+}
+
+// switchPortsForSwitches return the requests of SwitchPorts which belong to the Switches
+func switchPortsForSwitches(ctx context.Context, c client.Client, switches []reconcile.Request) []reconcile.Request {
+	requests := []reconcile.Request{}
+	for _, sw := range switches {
+		switchPorts := &v1alpha1.SwitchPortList{}
+		err := c.List(ctx, switchPorts, client.MatchingFields{
+			switchPortOwnerField: sw.NamespacedName.String(),
+		})
+		if err != nil {
+			ctrl.LoggerFrom(ctx).Error(err, "list switchPorts failed", "switch", sw.NamespacedName)
+			continue
+		}
+		for i := range switchPorts.Items {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&switchPorts.Items[i])})
+		}
+	}
+	return requests
+}
+
+// switchPortsForConfiguration return the requests of SwitchPorts which use the SwitchPortConfiguration
+func switchPortsForConfiguration(ctx context.Context, c client.Client, configuration types.NamespacedName) []reconcile.Request {
+	switchPorts := &v1alpha1.SwitchPortList{}
+	err := c.List(ctx, switchPorts, client.MatchingFields{
+		switchPortConfigurationField: configuration.String(),
+	})
+	if err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "list switchPorts failed", "configuration", configuration)
+		return nil
+	}
+
+	requests := []reconcile.Request{}
+	for i := range switchPorts.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&switchPorts.Items[i])})
+	}
+	return requests
+}
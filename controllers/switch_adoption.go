@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Hellcatlk/network-operator/api/v1alpha1"
+	"github.com/Hellcatlk/network-operator/pkg/backends"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// isDefaultPort check the port is an access port of VLAN 1 without other configuration, the MTU
+// is ignored because most backends report it even if the port has never been configured
+func isDefaultPort(actual *v1alpha1.SwitchPortConfigurationSpec) bool {
+	if actual == nil {
+		return true
+	}
+	return len(actual.ACLs) == 0 &&
+		actual.TaggedVLANRange == "" &&
+		!actual.Disable &&
+		(actual.UntaggedVLAN == nil || *actual.UntaggedVLAN == 1)
+}
+
+// adoptedConfiguration return the configuration adopted from the actual configuration of port,
+// it's nil if the port isn't configured, and error if the configuration can't be represented
+// by a SwitchPortConfiguration meeting the restrictions of switch
+func adoptedConfiguration(port *v1alpha1.Port, actual *v1alpha1.SwitchPortConfigurationSpec,
+	reservedVLANs string, capabilities *v1alpha1.SwitchCapabilities) (*v1alpha1.SwitchPortConfigurationSpec, error) {
+	if isDefaultPort(actual) {
+		return nil, nil
+	}
+
+	configuration := &v1alpha1.SwitchPortConfiguration{Spec: *actual.DeepCopy()}
+	err := port.Verify(configuration, reservedVLANs)
+	if err != nil {
+		return nil, err
+	}
+	err = capabilities.Verify(&configuration.Spec)
+	if err != nil {
+		return nil, err
+	}
+
+	return &configuration.Spec, nil
+}
+
+// adoptPort create the SwitchPort with the configuration adopted from the port, the SwitchPort
+// is bound to a SwitchPortConfiguration of the same name owned by the switch, and is marked by
+// the adopted annotation so it starts in `Active` state. If the configuration can't be adopted
+// the SwitchPort is created without configuration and the reason is returned
+func adoptPort(ctx context.Context, c client.Client, sw *v1alpha1.Switch, backend backends.Switch, switchPort *v1alpha1.SwitchPort) (string, error) {
+	port := sw.Status.Ports[switchPort.Name]
+	if port == nil {
+		return "", fmt.Errorf("port %s isn't found in switch %s", switchPort.Name, sw.Name)
+	}
+
+	backendCtx, cancel := context.WithTimeout(ctx, backendTimeout)
+	defer cancel()
+	actual, err := backend.GetPortAttr(backendCtx, port.PhysicalPortName)
+	if backends.IsTransient(err) {
+		return "", err
+	}
+	var configuration *v1alpha1.SwitchPortConfigurationSpec
+	if err == nil {
+		configuration, err = adoptedConfiguration(port, actual, sw.Status.ReservedVLANs, sw.Status.Capabilities)
+	}
+	reason := ""
+	if err != nil {
+		reason = err.Error()
+	}
+	if configuration == nil {
+		return reason, c.Create(ctx, switchPort)
+	}
+
+	// Create SwitchPortConfiguration, it may have been created by the last adoption
+	portConfiguration := &v1alpha1.SwitchPortConfiguration{}
+	portConfiguration.Name = switchPort.Name
+	portConfiguration.Namespace = switchPort.Namespace
+	portConfiguration.OwnerReferences = []metav1.OwnerReference{
+		{
+			APIVersion: sw.APIVersion,
+			Kind:       sw.Kind,
+			Name:       sw.Name,
+			UID:        sw.UID,
+		},
+	}
+	portConfiguration.Spec = *configuration.DeepCopy()
+	err = c.Create(ctx, portConfiguration)
+	if errors.IsAlreadyExists(err) {
+		portConfiguration = &v1alpha1.SwitchPortConfiguration{}
+		err = c.Get(ctx, client.ObjectKeyFromObject(switchPort), portConfiguration)
+		if err != nil {
+			return "", err
+		}
+		if !portConfiguration.Spec.IsEqual(configuration) {
+			reason = fmt.Sprintf("SwitchPortConfiguration %s already exists with different configuration", portConfiguration.Name)
+			return reason, c.Create(ctx, switchPort)
+		}
+	} else if err != nil {
+		return "", err
+	}
+
+	// Create SwitchPort, it's moved to `Active` by the SwitchPort controller
+	if switchPort.Annotations == nil {
+		switchPort.Annotations = map[string]string{}
+	}
+	switchPort.Annotations[v1alpha1.AdoptedAnnotation] = "true"
+	switchPort.Spec.Configuration = &v1alpha1.SwitchPortConfigurationReference{
+		Name:      portConfiguration.Name,
+		Namespace: portConfiguration.Namespace,
+	}
+	return "", c.Create(ctx, switchPort)
+}
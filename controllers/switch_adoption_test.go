@@ -0,0 +1,201 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Hellcatlk/network-operator/api/v1alpha1"
+	"github.com/Hellcatlk/network-operator/pkg/backends/switches/fake"
+	"github.com/Hellcatlk/network-operator/pkg/machine"
+	"github.com/Hellcatlk/network-operator/pkg/provider"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestAdoptedConfiguration(t *testing.T) {
+	vlan1 := 1
+	vlan10 := 10
+	mtu := 9000
+	mtu1500 := 1500
+	cases := []struct {
+		name                  string
+		port                  *v1alpha1.Port
+		actual                *v1alpha1.SwitchPortConfigurationSpec
+		capabilities          *v1alpha1.SwitchCapabilities
+		expectedConfiguration *v1alpha1.SwitchPortConfigurationSpec
+		expectedError         bool
+	}{
+		{
+			name:   "default port",
+			port:   &v1alpha1.Port{PhysicalPortName: "port0"},
+			actual: &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan1},
+		},
+		{
+			name:   "default port reporting MTU",
+			port:   &v1alpha1.Port{PhysicalPortName: "port0"},
+			actual: &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan1, MTU: &mtu1500},
+		},
+		{
+			name:                  "access port",
+			port:                  &v1alpha1.Port{PhysicalPortName: "port0"},
+			actual:                &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan10, MTU: &mtu},
+			expectedConfiguration: &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan10, MTU: &mtu},
+		},
+		{
+			name:          "reserved VLAN",
+			port:          &v1alpha1.Port{PhysicalPortName: "port0"},
+			actual:        &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan1, TaggedVLANRange: "20"},
+			expectedError: true,
+		},
+		{
+			name:          "trunk disabled",
+			port:          &v1alpha1.Port{PhysicalPortName: "port0", TrunkDisabled: true},
+			actual:        &v1alpha1.SwitchPortConfigurationSpec{TaggedVLANRange: "20"},
+			expectedError: true,
+		},
+		{
+			name:          "unsupported field",
+			port:          &v1alpha1.Port{PhysicalPortName: "port0"},
+			actual:        &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan10, MTU: &mtu},
+			capabilities:  &v1alpha1.SwitchCapabilities{TaggedVLANs: true, NativeVLAN: true},
+			expectedError: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			configuration, err := adoptedConfiguration(c.port, c.actual, "1", c.capabilities)
+			if (err != nil) != c.expectedError {
+				t.Errorf("expected error: %v, got: %v", c.expectedError, err)
+			}
+			if (c.expectedConfiguration == nil) != (configuration == nil) || (configuration != nil && !c.expectedConfiguration.IsEqual(configuration)) {
+				t.Errorf("expected configuration: %+v, got: %+v", c.expectedConfiguration, configuration)
+			}
+		})
+	}
+}
+
+func TestAdoptPort(t *testing.T) {
+	vlan10 := 10
+	vlan20 := 20
+	cases := []struct {
+		name                  string
+		actual                *v1alpha1.SwitchPortConfigurationSpec
+		existed               *v1alpha1.SwitchPortConfigurationSpec
+		expectedState         string
+		expectedConfiguration bool
+		expectedReason        bool
+	}{
+		{
+			name:          "default port",
+			expectedState: string(v1alpha1.SwitchPortIdle),
+		},
+		{
+			name:                  "configured port",
+			actual:                &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan10, TaggedVLANRange: "11-12"},
+			expectedState:         string(v1alpha1.SwitchPortActive),
+			expectedConfiguration: true,
+		},
+		{
+			name:                  "configuration created by the last adoption",
+			actual:                &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan10},
+			existed:               &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan10},
+			expectedState:         string(v1alpha1.SwitchPortActive),
+			expectedConfiguration: true,
+		},
+		{
+			name:           "configuration existed with different configuration",
+			actual:         &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan10},
+			existed:        &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan20},
+			expectedState:  string(v1alpha1.SwitchPortIdle),
+			expectedReason: true,
+		},
+		{
+			name:           "reserved VLAN",
+			actual:         &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan20},
+			expectedState:  string(v1alpha1.SwitchPortIdle),
+			expectedReason: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			backend, err := fake.New(context.Background(), &provider.SwitchConfiguration{Host: t.Name(), Backend: "fake"})
+			if err != nil {
+				t.Fatalf("new backend failed: %v", err)
+			}
+			t.Cleanup(func() { fake.Delete(t.Name()) })
+			if c.actual != nil {
+				err = backend.SetPortAttr(context.Background(), "port0", c.actual)
+				if err != nil {
+					t.Fatalf("set port failed: %v", err)
+				}
+			}
+
+			sw := &v1alpha1.Switch{}
+			sw.Name = "switch"
+			sw.Namespace = "default"
+			sw.Kind = "Switch"
+			sw.UID = "switch-uid"
+			sw.Status.Ports = map[string]*v1alpha1.Port{"port": {PhysicalPortName: "port0"}}
+			sw.Status.ReservedVLANs = "1,20"
+
+			scheme := runtime.NewScheme()
+			_ = v1alpha1.AddToScheme(scheme)
+			builder := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(sw.DeepCopy())
+			if c.existed != nil {
+				builder = builder.WithObjects(&v1alpha1.SwitchPortConfiguration{
+					ObjectMeta: metav1.ObjectMeta{Name: "port", Namespace: "default"},
+					Spec:       *c.existed,
+				})
+			}
+			client := builder.Build()
+
+			switchPort := &v1alpha1.SwitchPort{}
+			switchPort.Name = "port"
+			switchPort.Namespace = "default"
+			switchPort.OwnerReferences = []metav1.OwnerReference{{Kind: "Switch", Name: sw.Name, UID: sw.UID}}
+			reason, err := adoptPort(context.Background(), client, sw, backend, switchPort)
+			if err != nil {
+				t.Fatalf("adopt port failed: %v", err)
+			}
+			if (reason != "") != c.expectedReason {
+				t.Errorf("expected reason: %v, got: %q", c.expectedReason, reason)
+			}
+
+			created := &v1alpha1.SwitchPort{}
+			err = client.Get(context.Background(), types.NamespacedName{Name: "port", Namespace: "default"}, created)
+			if err != nil {
+				t.Fatalf("get SwitchPort failed: %v", err)
+			}
+			if (created.Spec.Configuration != nil) != c.expectedConfiguration {
+				t.Errorf("expected configuration: %v, got: %+v", c.expectedConfiguration, created.Spec.Configuration)
+			}
+			if c.expectedConfiguration && c.existed == nil {
+				portConfiguration := &v1alpha1.SwitchPortConfiguration{}
+				err = client.Get(context.Background(), types.NamespacedName{Name: "port", Namespace: "default"}, portConfiguration)
+				if err != nil {
+					t.Fatalf("get SwitchPortConfiguration failed: %v", err)
+				}
+				if len(portConfiguration.OwnerReferences) != 1 || portConfiguration.OwnerReferences[0].UID != sw.UID {
+					t.Errorf("expected SwitchPortConfiguration is owned by switch, got: %+v", portConfiguration.OwnerReferences)
+				}
+			}
+
+			// The SwitchPort controller moves the adopted port to `Active` without configuring it
+			r := &SwitchPortReconciler{}
+			state, _, err := r.noneHandler(context.Background(), &machine.ReconcileInfo{Client: client}, created)
+			if err != nil {
+				t.Fatalf("none handler failed: %v", err)
+			}
+			if string(state) != c.expectedState {
+				t.Errorf("expected state: %q, got: %q", c.expectedState, state)
+			}
+			if c.expectedConfiguration && (!created.Status.Configuration.IsEqual(c.actual) || created.Status.PhysicalPortName != "port0") {
+				t.Errorf("expected configuration: %+v of port0, got: %+v of %s", c.actual, created.Status.Configuration, created.Status.PhysicalPortName)
+			}
+		})
+	}
+}
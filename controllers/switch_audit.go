@@ -0,0 +1,155 @@
+package controllers
+
+import (
+	"context"
+	"sort"
+
+	"github.com/Hellcatlk/network-operator/api/v1alpha1"
+	"github.com/Hellcatlk/network-operator/pkg/backends"
+	"github.com/Hellcatlk/network-operator/pkg/utils/strings"
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	unmanagedPortsGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "network_operator_switch_unmanaged_ports",
+		Help: "The number of ports carrying the VLANs of SwitchResources which aren't managed by SwitchPorts.",
+	}, []string{"switch"})
+
+	foreignVLANsGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "network_operator_switch_foreign_vlans",
+		Help: "The number of VLANs on the switch which aren't used by any SwitchPort and aren't reserved.",
+	}, []string{"switch"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(unmanagedPortsGauge, foreignVLANsGauge)
+}
+
+// auditSwitch reads the whole switch, return the ports carrying the VLANs of any SwitchResource
+// which aren't managed and the VLANs which aren't used by any SwitchPort. The errors of backend are recorded in the result and the transient ones count
+// as failures of the switch, only the errors of reading the resources are returned
+func (r *SwitchReconciler) auditSwitch(ctx context.Context, c client.Client, sw *v1alpha1.Switch, backend backends.Switch) (*v1alpha1.SwitchAudit, error) {
+	audit := &v1alpha1.SwitchAudit{Time: metav1.Now()}
+	inventory, ok := backend.(backends.Inventory)
+	if !ok {
+		audit.Error = "the backend of switch can't list the ports and VLANs"
+		return audit, nil
+	}
+
+	// The VLANs of pools in all namespaces, the switch may be shared by the SwitchResources of
+	// several namespaces
+	resources := &v1alpha1.SwitchResourceList{}
+	err := c.List(ctx, resources)
+	if err != nil {
+		return nil, err
+	}
+	pools := map[int]bool{}
+	for _, resource := range resources.Items {
+		vlans, err := strings.RangeToSlice(resource.Spec.VLANRange)
+		if err != nil {
+			continue
+		}
+		for _, vid := range vlans {
+			pools[vid] = true
+		}
+	}
+
+	// The VLANs used by SwitchPorts and reserved
+	switchPorts := &v1alpha1.SwitchPortList{}
+	err = c.List(ctx, switchPorts, client.InNamespace(sw.Namespace))
+	if err != nil {
+		return nil, err
+	}
+	used := map[int]bool{}
+	for _, switchPort := range switchPorts.Items {
+		if len(switchPort.OwnerReferences) == 0 || switchPort.OwnerReferences[0].Kind != "Switch" ||
+			switchPort.OwnerReferences[0].Name != sw.Name {
+			continue
+		}
+		vlanRange, err := switchPort.Status.Configuration.VLANRange()
+		if err != nil {
+			continue
+		}
+		vlans, _ := strings.RangeToSlice(vlanRange)
+		for _, vid := range vlans {
+			used[vid] = true
+		}
+	}
+	reserved, err := v1alpha1.ReservedVLANs(sw.Status.ReservedVLANs)
+	if err != nil {
+		return nil, err
+	}
+	vlans, _ := strings.RangeToSlice(reserved)
+	for _, vid := range vlans {
+		used[vid] = true
+	}
+
+	managed := map[string]bool{}
+	for _, port := range sw.Status.Ports {
+		if port != nil {
+			managed[port.PhysicalPortName] = true
+		}
+	}
+	ports, err := inventory.ListPortVLANs(ctx)
+	if err != nil {
+		return r.auditFailed(sw, audit, err), nil
+	}
+	for port, vlans := range ports {
+		if managed[port] {
+			continue
+		}
+		leaked := []int{}
+		for _, vid := range vlans {
+			if pools[vid] {
+				leaked = append(leaked, vid)
+			}
+		}
+		if len(leaked) != 0 {
+			if audit.UnmanagedPorts == nil {
+				audit.UnmanagedPorts = map[string]string{}
+			}
+			audit.UnmanagedPorts[port] = strings.SliceToRange(leaked)
+		}
+	}
+
+	vlans, err = inventory.ListVLANs(ctx)
+	if err != nil {
+		return r.auditFailed(sw, audit, err), nil
+	}
+	foreign := []int{}
+	for _, vid := range vlans {
+		if !used[vid] {
+			foreign = append(foreign, vid)
+		}
+	}
+	sort.Ints(foreign)
+	audit.ForeignVLANs = strings.SliceToRange(foreign)
+
+	return audit, nil
+}
+
+// auditFailed records the error of backend in the audit, the transient error is a failure of switch
+func (r *SwitchReconciler) auditFailed(sw *v1alpha1.Switch, audit *v1alpha1.SwitchAudit, err error) *v1alpha1.SwitchAudit {
+	if backends.IsTransient(err) {
+		r.Health.Failure(switchKey(sw))
+	}
+	audit.Error = err.Error()
+	return audit
+}
+
+// setAuditMetrics exports the result of audit, the metrics are removed if the audit is nil
+func setAuditMetrics(sw *v1alpha1.Switch) {
+	key := switchKey(sw)
+	if sw.Status.Audit == nil || sw.Status.Audit.Error != "" {
+		unmanagedPortsGauge.DeleteLabelValues(key)
+		foreignVLANsGauge.DeleteLabelValues(key)
+		return
+	}
+	foreign, _ := strings.RangeToSlice(sw.Status.Audit.ForeignVLANs)
+	unmanagedPortsGauge.WithLabelValues(key).Set(float64(len(sw.Status.Audit.UnmanagedPorts)))
+	foreignVLANsGauge.WithLabelValues(key).Set(float64(len(foreign)))
+}
@@ -0,0 +1,144 @@
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Hellcatlk/network-operator/api/v1alpha1"
+	"github.com/Hellcatlk/network-operator/pkg/backends"
+	"github.com/Hellcatlk/network-operator/pkg/backends/switches/fake"
+	"github.com/Hellcatlk/network-operator/pkg/health"
+	"github.com/Hellcatlk/network-operator/pkg/provider"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// noInventory is a backend which can't read the whole switch
+type noInventory struct {
+	backends.Switch
+}
+
+func TestAuditSwitch(t *testing.T) {
+	vlan10 := 10
+	vlan20 := 20
+	vlan30 := 30
+	cases := []struct {
+		name                   string
+		ports                  map[string]*v1alpha1.SwitchPortConfigurationSpec
+		withoutInventory       bool
+		unreachable            bool
+		expectedUnmanagedPorts map[string]string
+		expectedForeignVLANs   string
+		expectedError          bool
+	}{
+		{
+			name: "managed ports",
+			ports: map[string]*v1alpha1.SwitchPortConfigurationSpec{
+				"port0": {UntaggedVLAN: &vlan10},
+			},
+		},
+		{
+			name: "unmanaged port carrying VLANs of pool",
+			ports: map[string]*v1alpha1.SwitchPortConfigurationSpec{
+				"port0": {UntaggedVLAN: &vlan10},
+				"port1": {UntaggedVLAN: &vlan20, TaggedVLANRange: "10,30"},
+			},
+			expectedUnmanagedPorts: map[string]string{"port1": "10,20,30"},
+			expectedForeignVLANs:   "20,30",
+		},
+		{
+			name: "unmanaged port carrying VLANs of pool in other namespace",
+			ports: map[string]*v1alpha1.SwitchPortConfigurationSpec{
+				"port0": {UntaggedVLAN: &vlan10},
+				"port1": {UntaggedVLAN: &vlan30},
+			},
+			expectedUnmanagedPorts: map[string]string{"port1": "30"},
+			expectedForeignVLANs:   "30",
+		},
+		{
+			name:             "backend without inventory",
+			withoutInventory: true,
+			expectedError:    true,
+		},
+		{
+			name:          "unreachable switch",
+			unreachable:   true,
+			expectedError: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			backend, err := fake.New(context.Background(), &provider.SwitchConfiguration{Host: t.Name(), Backend: "fake"})
+			if err != nil {
+				t.Fatalf("new backend failed: %v", err)
+			}
+			t.Cleanup(func() { fake.Delete(t.Name()) })
+			for port, configuration := range c.ports {
+				err = backend.SetPortAttr(context.Background(), port, configuration)
+				if err != nil {
+					t.Fatalf("set port failed: %v", err)
+				}
+			}
+			if c.withoutInventory {
+				backend = noInventory{backend}
+			}
+			if c.unreachable {
+				backend, err = fake.New(context.Background(), &provider.SwitchConfiguration{
+					Host: t.Name(), Backend: "fake", Options: map[string]interface{}{"unreachable": "true"},
+				})
+				if err != nil {
+					t.Fatalf("new backend failed: %v", err)
+				}
+			}
+
+			scheme := runtime.NewScheme()
+			_ = v1alpha1.AddToScheme(scheme)
+			client := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(
+				&v1alpha1.SwitchResource{
+					ObjectMeta: metav1.ObjectMeta{Name: "resource", Namespace: "default"},
+					Spec:       v1alpha1.SwitchResourceSpec{VLANRange: "10-20"},
+				},
+				&v1alpha1.SwitchResource{
+					ObjectMeta: metav1.ObjectMeta{Name: "resource", Namespace: "other"},
+					Spec:       v1alpha1.SwitchResourceSpec{VLANRange: "21-40"},
+				},
+				&v1alpha1.SwitchPort{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:            "port",
+						Namespace:       "default",
+						OwnerReferences: []metav1.OwnerReference{{Kind: "Switch", Name: "switch"}},
+					},
+					Status: v1alpha1.SwitchPortStatus{
+						Configuration: &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan10},
+					},
+				},
+			).Build()
+
+			sw := &v1alpha1.Switch{}
+			sw.Name = "switch"
+			sw.Namespace = "default"
+			sw.Status.Ports = map[string]*v1alpha1.Port{"port": {PhysicalPortName: "port0"}}
+			r := &SwitchReconciler{Health: health.New(1, time.Second, time.Minute)}
+			audit, err := r.auditSwitch(context.Background(), client, sw, backend)
+			if err != nil {
+				t.Fatalf("audit switch failed: %v", err)
+			}
+			if (audit.Error != "") != c.expectedError {
+				t.Errorf("expected error: %v, got: %q", c.expectedError, audit.Error)
+			}
+			if !reflect.DeepEqual(audit.UnmanagedPorts, c.expectedUnmanagedPorts) {
+				t.Errorf("expected unmanaged ports: %v, got: %v", c.expectedUnmanagedPorts, audit.UnmanagedPorts)
+			}
+			if audit.ForeignVLANs != c.expectedForeignVLANs {
+				t.Errorf("expected foreign VLANs: %q, got: %q", c.expectedForeignVLANs, audit.ForeignVLANs)
+			}
+			if r.Health.IsOpen(switchKey(sw)) != c.unreachable {
+				t.Errorf("expected circuit is open: %v, got: %v", c.unreachable, r.Health.IsOpen(switchKey(sw)))
+			}
+		})
+	}
+}
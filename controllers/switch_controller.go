@@ -18,14 +18,21 @@ package controllers
 
 import (
 	"context"
+	"time"
 
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlbuilder "sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	metal3iov1alpha1 "github.com/Hellcatlk/network-operator/api/v1alpha1"
+	"github.com/Hellcatlk/network-operator/pkg/health"
 	"github.com/Hellcatlk/network-operator/pkg/machine"
 )
 
@@ -34,6 +41,16 @@ type SwitchReconciler struct {
 	client.Client
 	Log    logr.Logger
 	Scheme *runtime.Scheme
+
+	// The interval of checking the configuration on the device, if zero use 1 minute
+	DriftCheckInterval time.Duration
+
+	// The interval of auditing the ports and VLANs of the whole switch, it's
+	// checked when the switch is checked, if zero the switches aren't audited
+	AuditInterval time.Duration
+
+	// Health tracks the health of switches, if nil the circuit is never opened
+	Health *health.Tracker
 }
 
 // +kubebuilder:rbac:groups=metal3.io,resources=switches,verbs=get;list;watch;create;update;patch;delete
@@ -44,7 +61,28 @@ type SwitchReconciler struct {
 // +kubebuilder:rbac:groups=metal3.io,resources=ansibleswitches/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=metal3.io,resources=ansibleswitches/finalizers,verbs=update
 
+// +kubebuilder:rbac:groups=metal3.io,resources=pluginswitches,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=metal3.io,resources=pluginswitches/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=metal3.io,resources=pluginswitches/finalizers,verbs=update
+
+// +kubebuilder:rbac:groups=metal3.io,resources=switchproviders,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=metal3.io,resources=switchproviders/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=metal3.io,resources=switchproviders/finalizers,verbs=update
+
+// +kubebuilder:rbac:groups=metal3.io,resources=eapiswitches,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=metal3.io,resources=eapiswitches/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=metal3.io,resources=eapiswitches/finalizers,verbs=update
+
+// +kubebuilder:rbac:groups=metal3.io,resources=cliswitches,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=metal3.io,resources=cliswitches/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=metal3.io,resources=cliswitches/finalizers,verbs=update
+
+// +kubebuilder:rbac:groups=metal3.io,resources=fakeswitches,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=metal3.io,resources=fakeswitches/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=metal3.io,resources=fakeswitches/finalizers,verbs=update
+
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
 // +kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile switch resources
@@ -75,6 +113,7 @@ func (r *SwitchReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 			metal3iov1alpha1.SwitchVerifying:   r.verifyingHandler,
 			metal3iov1alpha1.SwitchConfiguring: r.configuringHandler,
 			metal3iov1alpha1.SwitchRunning:     r.runningHandler,
+			metal3iov1alpha1.SwitchUnreachable: r.unreachableHandler,
 			metal3iov1alpha1.SwitchDeleting:    r.deletingHandler,
 		},
 	)
@@ -108,7 +147,33 @@ func (r *SwitchReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 
 // SetupWithManager register reconciler
 func (r *SwitchReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	ctx := ctrl.LoggerInto(context.Background(), r.Log)
+	builder := ctrl.NewControllerManagedBy(mgr).
 		For(&metal3iov1alpha1.Switch{}).
-		Complete(r)
+		Owns(&metal3iov1alpha1.SwitchPort{}).
+		// Only the metadata of Secrets and ConfigMaps is cached, their data is read from the API server
+		Watches(
+			&source.Kind{Type: &corev1.Secret{}},
+			handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+				return switchesForSecret(ctx, r.Client, client.ObjectKeyFromObject(obj))
+			}),
+			ctrlbuilder.OnlyMetadata,
+		).
+		Watches(
+			&source.Kind{Type: &corev1.ConfigMap{}},
+			handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+				return switchesForConfigMap(ctx, r.Client, client.ObjectKeyFromObject(obj))
+			}),
+			ctrlbuilder.OnlyMetadata,
+		)
+	for kind, obj := range providerObjects() {
+		kind := kind
+		builder = builder.Watches(
+			&source.Kind{Type: obj},
+			handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+				return switchesForProvider(ctx, r.Client, kind, client.ObjectKeyFromObject(obj))
+			}),
+		)
+	}
+	return builder.Complete(r)
 }
@@ -3,8 +3,10 @@ package controllers
 import (
 	"context"
 	"reflect"
+	"time"
 
 	"github.com/Hellcatlk/network-operator/api/v1alpha1"
+	"github.com/Hellcatlk/network-operator/pkg/backends"
 	"github.com/Hellcatlk/network-operator/pkg/machine"
 	"github.com/Hellcatlk/network-operator/pkg/utils/finalizer"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -53,17 +55,37 @@ func (r *SwitchReconciler) verifyingHandler(ctx context.Context, info *machine.R
 	if err != nil {
 		return machine.ResultContinue(v1alpha1.SwitchVerifying, requeueAfterTime, err)
 	}
-	err = backend.IsAvailable()
-	if err != nil {
+	backendCtx, cancel := context.WithTimeout(ctx, backendTimeout)
+	defer cancel()
+	err = backend.IsAvailable(backendCtx)
+	if backends.IsTransient(err) {
+		if r.Health.Failure(switchKey(i)) {
+			return machine.ResultContinue(v1alpha1.SwitchUnreachable, 0, err)
+		}
 		return machine.ResultContinue(v1alpha1.SwitchVerifying, requeueAfterTime, err)
 	}
+	r.Health.Success(switchKey(i))
+	if err != nil {
+		return machine.ResultContinue(v1alpha1.SwitchVerifying, failureRequeueTime, err)
+	}
+	capabilities, err := backend.Capabilities(backendCtx)
+	if err != nil {
+		return machine.ResultContinue(v1alpha1.SwitchVerifying, errorRequeueTime(err), err)
+	}
 
 	if i.Status.Provider == nil {
 		i.Status.Provider = i.Spec.Provider.DeepCopy()
 	} else {
 		info.Logger.Info("the provider field is not allowed to be edited")
 	}
+	reservedVLANs, err := v1alpha1.ReservedVLANs(i.Spec.ReservedVLANs)
+	if err != nil {
+		return machine.ResultContinue(v1alpha1.SwitchVerifying, requeueAfterTime, err)
+	}
+
 	i.Status.Ports = i.Spec.Ports
+	i.Status.ReservedVLANs = reservedVLANs
+	i.Status.Capabilities = capabilities
 	return machine.ResultContinue(v1alpha1.SwitchConfiguring, 0, nil)
 }
 
@@ -74,6 +96,21 @@ func (r *SwitchReconciler) configuringHandler(ctx context.Context, info *machine
 		return machine.ResultContinue(v1alpha1.SwitchDeleting, 0, nil)
 	}
 
+	// Check connection with switch, the ports are read for the adoption
+	var backend backends.Switch
+	if i.Spec.Adopt {
+		var err error
+		backend, err = getSwitchBackend(ctx, info.Client, i)
+		if err != nil {
+			return machine.ResultContinue(v1alpha1.SwitchConfiguring, requeueAfterTime, err)
+		}
+	}
+	for name := range i.Status.UnadoptedPorts {
+		if _, exist := i.Status.Ports[name]; !exist || !i.Spec.Adopt {
+			delete(i.Status.UnadoptedPorts, name)
+		}
+	}
+
 	// Create SwitchPorts
 	for name := range i.Status.Ports {
 		switchPort := &v1alpha1.SwitchPort{}
@@ -90,6 +127,29 @@ func (r *SwitchReconciler) configuringHandler(ctx context.Context, info *machine
 		}
 		*switchPort.OwnerReferences[0].BlockOwnerDeletion = true
 
+		// Adopt the configuration of port if the SwitchPort isn't existed
+		if backend != nil {
+			err := info.Client.Get(ctx, client.ObjectKeyFromObject(switchPort), &v1alpha1.SwitchPort{})
+			if err == nil {
+				continue
+			}
+			if !errors.IsNotFound(err) {
+				return machine.ResultContinue(v1alpha1.SwitchConfiguring, requeueAfterTime, err)
+			}
+			reason, err := adoptPort(ctx, info.Client, i, backend, switchPort)
+			if reason != "" {
+				info.Logger.Info("the configuration of port can't be adopted", "port", name, "reason", reason)
+				if i.Status.UnadoptedPorts == nil {
+					i.Status.UnadoptedPorts = map[string]string{}
+				}
+				i.Status.UnadoptedPorts[name] = reason
+			}
+			if err != nil && !errors.IsAlreadyExists(err) {
+				return machine.ResultContinue(v1alpha1.SwitchConfiguring, errorRequeueTime(err), err)
+			}
+			continue
+		}
+
 		// Create SwitchPort
 		err := info.Client.Create(ctx, switchPort)
 		if err != nil {
@@ -111,7 +171,8 @@ func (r *SwitchReconciler) runningHandler(ctx context.Context, info *machine.Rec
 		return machine.ResultContinue(v1alpha1.SwitchDeleting, 0, nil)
 	}
 
-	if !reflect.DeepEqual(i.Spec.Ports, i.Status.Ports) {
+	reservedVLANs, err := v1alpha1.ReservedVLANs(i.Spec.ReservedVLANs)
+	if err != nil || reservedVLANs != i.Status.ReservedVLANs || !reflect.DeepEqual(i.Spec.Ports, i.Status.Ports) {
 		return machine.ResultContinue(v1alpha1.SwitchVerifying, 0, nil)
 	}
 
@@ -134,17 +195,94 @@ func (r *SwitchReconciler) runningHandler(ctx context.Context, info *machine.Rec
 		}
 	}
 
+	// The circuit may be opened by the failures of SwitchPorts
+	if r.Health.IsOpen(switchKey(i)) {
+		return machine.ResultContinue(v1alpha1.SwitchUnreachable, 0, nil)
+	}
+
 	// Check connection with switch
 	backend, err := getSwitchBackend(ctx, info.Client, i)
 	if err != nil {
 		return machine.ResultContinue(v1alpha1.SwitchRunning, requeueAfterTime, err)
 	}
-	err = backend.IsAvailable()
-	if err != nil {
+	backendCtx, cancel := context.WithTimeout(ctx, backendTimeout)
+	defer cancel()
+	err = backend.IsAvailable(backendCtx)
+	if backends.IsTransient(err) {
+		if r.Health.Failure(switchKey(i)) {
+			return machine.ResultContinue(v1alpha1.SwitchUnreachable, 0, err)
+		}
 		return machine.ResultContinue(v1alpha1.SwitchRunning, requeueAfterTime, err)
 	}
+	r.Health.Success(switchKey(i))
+	if err != nil {
+		return machine.ResultContinue(v1alpha1.SwitchRunning, failureRequeueTime, err)
+	}
+
+	// The capabilities may be changed by the provider, such as the templates of CLISwitch
+	capabilities, err := backend.Capabilities(backendCtx)
+	if err != nil {
+		return machine.ResultContinue(v1alpha1.SwitchRunning, errorRequeueTime(err), err)
+	}
+	i.Status.Capabilities = capabilities
 
-	return machine.ResultContinue(v1alpha1.SwitchRunning, requeueAfterTime, nil)
+	// Audit the whole switch periodically
+	if r.AuditInterval == 0 {
+		i.Status.Audit = nil
+	} else if i.Status.Audit == nil || time.Since(i.Status.Audit.Time.Time) >= r.AuditInterval {
+		audit, err := r.auditSwitch(backendCtx, info.Client, i, backend)
+		if err != nil {
+			return machine.ResultContinue(v1alpha1.SwitchRunning, requeueAfterTime, err)
+		}
+		if len(audit.UnmanagedPorts) != 0 || audit.ForeignVLANs != "" {
+			info.Logger.Info("switch audit found unmanaged ports or foreign VLANs",
+				"unmanagedPorts", audit.UnmanagedPorts, "foreignVLANs", audit.ForeignVLANs)
+		}
+		i.Status.Audit = audit
+	}
+	setAuditMetrics(i)
+	// The circuit may be opened by the failures of audit
+	if r.Health.IsOpen(switchKey(i)) {
+		return machine.ResultContinue(v1alpha1.SwitchUnreachable, 0, nil)
+	}
+
+	return machine.ResultContinue(v1alpha1.SwitchRunning, driftCheckInterval(r.DriftCheckInterval), nil)
+}
+
+// unreachableHandler probes the switch with backoff, when the switch comes back
+// return to `Verifying` state, the SwitchPorts are re-driven by the status change
+func (r *SwitchReconciler) unreachableHandler(ctx context.Context, info *machine.ReconcileInfo, instance interface{}) (machine.StateType, ctrl.Result, error) {
+	i := instance.(*v1alpha1.Switch)
+
+	if !i.DeletionTimestamp.IsZero() {
+		return machine.ResultContinue(v1alpha1.SwitchDeleting, 0, nil)
+	}
+
+	allow, wait := r.Health.Allow(switchKey(i))
+	if !allow {
+		return machine.ResultContinue(v1alpha1.SwitchUnreachable, wait, nil)
+	}
+
+	backend, err := getSwitchBackend(ctx, info.Client, i)
+	if err != nil {
+		return machine.ResultContinue(v1alpha1.SwitchUnreachable, requeueAfterTime, err)
+	}
+	backendCtx, cancel := context.WithTimeout(ctx, backendTimeout)
+	defer cancel()
+	err = backend.IsAvailable(backendCtx)
+	if backends.IsTransient(err) {
+		r.Health.Failure(switchKey(i))
+		if allow, wait := r.Health.Allow(switchKey(i)); !allow {
+			return machine.ResultContinue(v1alpha1.SwitchUnreachable, wait, err)
+		}
+		return machine.ResultContinue(v1alpha1.SwitchUnreachable, requeueAfterTime, err)
+	}
+	// The switch is reachable even if it rejects the operation,
+	// the error is handled in `Verifying` state
+	r.Health.Success(switchKey(i))
+
+	info.Logger.Info("switch is reachable again")
+	return machine.ResultContinue(v1alpha1.SwitchVerifying, 0, nil)
 }
 
 func (r *SwitchReconciler) deletingHandler(ctx context.Context, info *machine.ReconcileInfo, instance interface{}) (machine.StateType, ctrl.Result, error) {
@@ -159,6 +297,8 @@ func (r *SwitchReconciler) deletingHandler(ctx context.Context, info *machine.Re
 
 	// Remove finalizer
 	finalizer.Remove(&i.Finalizers, finalizerKey)
+	i.Status.Audit = nil
+	setAuditMetrics(i)
 
 	return machine.ResultComplete(v1alpha1.SwitchDeleting, nil)
 }
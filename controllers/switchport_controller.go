@@ -19,15 +19,28 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"time"
 
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlbuilder "sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	metal3iov1alpha1 "github.com/Hellcatlk/network-operator/api/v1alpha1"
+	"github.com/Hellcatlk/network-operator/pkg/health"
 	"github.com/Hellcatlk/network-operator/pkg/machine"
+	"github.com/Hellcatlk/network-operator/pkg/utils/lock"
 )
 
 // SwitchPortReconciler reconciles a SwitchPort object
@@ -35,6 +48,24 @@ type SwitchPortReconciler struct {
 	client.Client
 	Log    logr.Logger
 	Scheme *runtime.Scheme
+
+	// The interval of checking the configuration on the device, if zero use 1 minute
+	DriftCheckInterval time.Duration
+
+	// The maximum number of SwitchPorts which can be reconciled concurrently
+	MaxConcurrentReconciles int
+
+	// SwitchLock serializes the mutating backend operations on the same Switch,
+	// if nil the operations aren't serialized
+	SwitchLock *lock.KeyedLock
+
+	// Health tracks the health of switches, it should be shared with SwitchReconciler.
+	// If nil the SwitchPorts only wait when the switch is `Unreachable`
+	Health *health.Tracker
+
+	// Recorder emits the events of SwitchPorts, such as the drift detected,
+	// if nil the events aren't emitted
+	Recorder record.EventRecorder
 }
 
 // +kubebuilder:rbac:groups=metal3.io,resources=switchports,verbs=get;list;watch;create;update;patch;delete
@@ -53,6 +84,8 @@ type SwitchPortReconciler struct {
 // +kubebuilder:rbac:groups=metal3.io,resources=switchresources/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=metal3.io,resources=switchresources/finalizers,verbs=update
 
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
 // Reconcile switch port resources
 func (r *SwitchPortReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := r.Log.WithValues("switchport", req.NamespacedName)
@@ -118,9 +151,69 @@ func (r *SwitchPortReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	return result, err
 }
 
+// switchChangedPredicate only passes the changes of Switch used by its SwitchPorts,
+// the other changes such as the result of audit don't enqueue the ports
+var switchChangedPredicate = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldSwitch, ok := e.ObjectOld.(*metal3iov1alpha1.Switch)
+		if !ok {
+			return true
+		}
+		newSwitch, ok := e.ObjectNew.(*metal3iov1alpha1.Switch)
+		if !ok {
+			return true
+		}
+		return oldSwitch.Generation != newSwitch.Generation ||
+			oldSwitch.Status.State != newSwitch.Status.State ||
+			oldSwitch.Status.ReservedVLANs != newSwitch.Status.ReservedVLANs ||
+			!reflect.DeepEqual(oldSwitch.Status.Provider, newSwitch.Status.Provider) ||
+			!reflect.DeepEqual(oldSwitch.Status.Ports, newSwitch.Status.Ports) ||
+			!reflect.DeepEqual(oldSwitch.Status.Capabilities, newSwitch.Status.Capabilities)
+	},
+}
+
 // SetupWithManager register reconciler
 func (r *SwitchPortReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	ctx := ctrl.LoggerInto(context.Background(), r.Log)
+	builder := ctrl.NewControllerManagedBy(mgr).
 		For(&metal3iov1alpha1.SwitchPort{}).
-		Complete(r)
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
+		Watches(
+			&source.Kind{Type: &metal3iov1alpha1.SwitchPortConfiguration{}},
+			handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+				return switchPortsForConfiguration(ctx, r.Client, client.ObjectKeyFromObject(obj))
+			}),
+		).
+		Watches(
+			&source.Kind{Type: &metal3iov1alpha1.Switch{}},
+			handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+				return switchPortsForSwitches(ctx, r.Client, []reconcile.Request{{NamespacedName: client.ObjectKeyFromObject(obj)}})
+			}),
+			ctrlbuilder.WithPredicates(switchChangedPredicate),
+		).
+		// Only the metadata of Secrets and ConfigMaps is cached, their data is read from the API server
+		Watches(
+			&source.Kind{Type: &corev1.Secret{}},
+			handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+				return switchPortsForSwitches(ctx, r.Client, switchesForSecret(ctx, r.Client, client.ObjectKeyFromObject(obj)))
+			}),
+			ctrlbuilder.OnlyMetadata,
+		).
+		Watches(
+			&source.Kind{Type: &corev1.ConfigMap{}},
+			handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+				return switchPortsForSwitches(ctx, r.Client, switchesForConfigMap(ctx, r.Client, client.ObjectKeyFromObject(obj)))
+			}),
+			ctrlbuilder.OnlyMetadata,
+		)
+	for kind, obj := range providerObjects() {
+		kind := kind
+		builder = builder.Watches(
+			&source.Kind{Type: obj},
+			handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+				return switchPortsForSwitches(ctx, r.Client, switchesForProvider(ctx, r.Client, kind, client.ObjectKeyFromObject(obj)))
+			}),
+		)
+	}
+	return builder.Complete(r)
 }
@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Hellcatlk/network-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestSwitchChangedPredicate(t *testing.T) {
+	cases := []struct {
+		name     string
+		update   func(sw *v1alpha1.Switch)
+		expected bool
+	}{
+		{
+			name:     "spec changed",
+			update:   func(sw *v1alpha1.Switch) { sw.Generation++ },
+			expected: true,
+		},
+		{
+			name:     "state changed",
+			update:   func(sw *v1alpha1.Switch) { sw.Status.State = v1alpha1.SwitchUnreachable },
+			expected: true,
+		},
+		{
+			name: "ports changed",
+			update: func(sw *v1alpha1.Switch) {
+				sw.Status.Ports = map[string]*v1alpha1.Port{"port": {PhysicalPortName: "port1"}}
+			},
+			expected: true,
+		},
+		{
+			name: "capabilities changed",
+			update: func(sw *v1alpha1.Switch) {
+				sw.Status.Capabilities = &v1alpha1.SwitchCapabilities{ACLs: true}
+			},
+			expected: true,
+		},
+		{
+			name: "audit changed",
+			update: func(sw *v1alpha1.Switch) {
+				sw.Status.Audit = &v1alpha1.SwitchAudit{Time: metav1.NewTime(time.Now())}
+			},
+			expected: false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			old := &v1alpha1.Switch{}
+			old.Generation = 1
+			old.Status.State = v1alpha1.SwitchRunning
+			old.Status.Ports = map[string]*v1alpha1.Port{"port": {PhysicalPortName: "port0"}}
+			updated := old.DeepCopy()
+			c.update(updated)
+
+			result := switchChangedPredicate.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: updated})
+			if result != c.expected {
+				t.Errorf("expected: %v, got: %v", c.expected, result)
+			}
+		})
+	}
+}
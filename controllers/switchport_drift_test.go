@@ -0,0 +1,149 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Hellcatlk/network-operator/api/v1alpha1"
+	"github.com/Hellcatlk/network-operator/pkg/backends/switches/fake"
+	"github.com/Hellcatlk/network-operator/pkg/machine"
+	"github.com/Hellcatlk/network-operator/pkg/provider"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestDriftPolicy(t *testing.T) {
+	vlan10 := 10
+	vlan20 := 20
+	cases := []struct {
+		name              string
+		switchPolicy      v1alpha1.DriftPolicy
+		portPolicy        v1alpha1.DriftPolicy
+		reapply           string
+		actual            *v1alpha1.SwitchPortConfigurationSpec
+		expectedState     machine.StateType
+		expectedDrift     []v1alpha1.ConfigurationDiff
+		expectedCondition metav1.ConditionStatus
+		expectedEvent     bool
+	}{
+		{
+			name:          "no drift",
+			switchPolicy:  v1alpha1.DriftReport,
+			actual:        &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan10},
+			expectedState: v1alpha1.SwitchPortActive,
+		},
+		{
+			name:          "remediate by default",
+			actual:        &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan20},
+			expectedState: v1alpha1.SwitchPortConfiguring,
+		},
+		{
+			name:              "report",
+			switchPolicy:      v1alpha1.DriftReport,
+			actual:            &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan20},
+			expectedState:     v1alpha1.SwitchPortActive,
+			expectedDrift:     []v1alpha1.ConfigurationDiff{{Field: "untaggedVLAN", Desired: "10", Actual: "20"}},
+			expectedCondition: metav1.ConditionTrue,
+			expectedEvent:     true,
+		},
+		{
+			name:          "policy of port overrides switch",
+			switchPolicy:  v1alpha1.DriftReport,
+			portPolicy:    v1alpha1.DriftRemediate,
+			actual:        &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan20},
+			expectedState: v1alpha1.SwitchPortConfiguring,
+		},
+		{
+			name:          "ignore",
+			portPolicy:    v1alpha1.DriftIgnore,
+			actual:        &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan20},
+			expectedState: v1alpha1.SwitchPortActive,
+		},
+		{
+			name:          "reapply",
+			switchPolicy:  v1alpha1.DriftReport,
+			reapply:       "1",
+			actual:        &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan20},
+			expectedState: v1alpha1.SwitchPortConfiguring,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			backend, err := fake.New(context.Background(), &provider.SwitchConfiguration{Host: "default/fake", Backend: "fake"})
+			if err != nil {
+				t.Fatalf("new backend failed: %v", err)
+			}
+			t.Cleanup(func() { fake.Delete("default/fake") })
+			err = backend.SetPortAttr(context.Background(), "port0", c.actual)
+			if err != nil {
+				t.Fatalf("set port failed: %v", err)
+			}
+
+			sw := &v1alpha1.Switch{
+				ObjectMeta: metav1.ObjectMeta{Name: "switch", Namespace: "default"},
+				Spec:       v1alpha1.SwitchSpec{DriftPolicy: c.switchPolicy},
+				Status: v1alpha1.SwitchStatus{
+					Provider: &v1alpha1.SwitchProviderReference{Kind: "FakeSwitch", Name: "fake", Namespace: "default"},
+					Ports:    map[string]*v1alpha1.Port{"port": {PhysicalPortName: "port0"}},
+				},
+			}
+			configuration := &v1alpha1.SwitchPortConfiguration{
+				ObjectMeta: metav1.ObjectMeta{Name: "configuration", Namespace: "default"},
+				Spec:       v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan10},
+			}
+			scheme := runtime.NewScheme()
+			_ = v1alpha1.AddToScheme(scheme)
+			client := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(
+				sw, configuration, &v1alpha1.FakeSwitch{ObjectMeta: metav1.ObjectMeta{Name: "fake", Namespace: "default"}},
+			).Build()
+
+			instance := &v1alpha1.SwitchPort{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "port",
+					Namespace:       "default",
+					OwnerReferences: []metav1.OwnerReference{{Kind: "Switch", Name: "switch"}},
+				},
+				Spec: v1alpha1.SwitchPortSpec{
+					Configuration: &v1alpha1.SwitchPortConfigurationReference{Name: "configuration", Namespace: "default"},
+					DriftPolicy:   c.portPolicy,
+				},
+				Status: v1alpha1.SwitchPortStatus{
+					State:            v1alpha1.SwitchPortActive,
+					Configuration:    configuration.Spec.DeepCopy(),
+					PhysicalPortName: "port0",
+				},
+			}
+			if c.reapply != "" {
+				instance.Annotations = map[string]string{v1alpha1.ReapplyAnnotation: c.reapply}
+			}
+
+			recorder := record.NewFakeRecorder(10)
+			r := SwitchPortReconciler{Recorder: recorder}
+			state, _, err := r.activeHandler(context.Background(), &machine.ReconcileInfo{Client: client, Logger: log.NullLogger{}}, instance)
+			if err != nil {
+				t.Fatalf("active handler failed: %v", err)
+			}
+			if state != c.expectedState {
+				t.Errorf("expected state: %s, got: %s", c.expectedState, state)
+			}
+			if len(instance.Status.Drift) != len(c.expectedDrift) || (len(c.expectedDrift) != 0 && instance.Status.Drift[0] != c.expectedDrift[0]) {
+				t.Errorf("expected drift: %+v, got: %+v", c.expectedDrift, instance.Status.Drift)
+			}
+			condition := meta.FindStatusCondition(instance.Status.Conditions, v1alpha1.SwitchPortDriftDetected)
+			if (condition == nil && c.expectedCondition != "") || (condition != nil && condition.Status != c.expectedCondition) {
+				t.Errorf("expected condition: %q, got: %+v", c.expectedCondition, condition)
+			}
+			if (len(recorder.Events) != 0) != c.expectedEvent {
+				t.Errorf("expected event: %v, got: %d events", c.expectedEvent, len(recorder.Events))
+			}
+			if instance.Status.Reapplied != c.reapply {
+				t.Errorf("expected reapplied: %q, got: %q", c.reapply, instance.Status.Reapplied)
+			}
+		})
+	}
+}
@@ -3,6 +3,8 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"strings"
 	"time"
 
 	"github.com/Hellcatlk/network-operator/api/v1alpha1"
@@ -11,7 +13,11 @@ import (
 	"github.com/Hellcatlk/network-operator/pkg/machine"
 	"github.com/Hellcatlk/network-operator/pkg/provider"
 	"github.com/Hellcatlk/network-operator/pkg/utils/finalizer"
+	"github.com/Hellcatlk/network-operator/pkg/utils/lock"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -19,6 +25,76 @@ import (
 const finalizerKey string = "metal3.io"
 const requeueAfterTime time.Duration = time.Second * 10
 
+// defaultDriftCheckInterval is the interval of checking the configuration on the device
+// when `DriftCheckInterval` of reconciler isn't set
+const defaultDriftCheckInterval time.Duration = time.Minute
+
+// lockTimeout is the maximum time of waiting for the lock of switch
+const lockTimeout time.Duration = time.Second * 10
+
+// unreachableWaitTime is the delay of retrying when the switch is unreachable,
+// the SwitchPorts are also re-driven when the switch comes back
+const unreachableWaitTime time.Duration = time.Minute
+
+// failureRequeueTime is the delay of retrying when the backend error needs user action,
+// such as wrong credentials and invalid configuration. The changes made by user are
+// watched, so they don't need to wait for this delay.
+const failureRequeueTime time.Duration = time.Minute * 5
+
+// backendTimeout is the maximum time of a backend operation
+const backendTimeout time.Duration = time.Minute * 5
+
+// lockRetryTime is the delay of retrying when failed to get the lock of switch
+const lockRetryTime time.Duration = time.Second
+
+// driftCheckInterval return the interval, if it's zero return defaultDriftCheckInterval
+func driftCheckInterval(interval time.Duration) time.Duration {
+	if interval == 0 {
+		return defaultDriftCheckInterval
+	}
+	return interval
+}
+
+// lockSwitch acquires the lock of the switch before the mutating backend operations,
+// it gives up after lockTimeout so that the worker isn't blocked too long
+func (r *SwitchPortReconciler) lockSwitch(ctx context.Context, sw *v1alpha1.Switch, priority lock.Priority) (func(), error) {
+	if r.SwitchLock == nil {
+		return func() {}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, lockTimeout)
+	defer cancel()
+	return r.SwitchLock.Lock(ctx, switchKey(sw), priority)
+}
+
+// switchKey return the key of switch used by lock and health tracker
+func switchKey(sw *v1alpha1.Switch) string {
+	return client.ObjectKeyFromObject(sw).String()
+}
+
+// switchUnreachable return true if the backend operations on the switch should wait
+func (r *SwitchPortReconciler) switchUnreachable(sw *v1alpha1.Switch) bool {
+	return sw.Status.State == v1alpha1.SwitchUnreachable || r.Health.IsOpen(switchKey(sw))
+}
+
+// backendResult feeds the result of backend operation to the health tracker, only
+// the transient errors mean the switch may be unreachable
+func (r *SwitchPortReconciler) backendResult(sw *v1alpha1.Switch, err error) {
+	if backends.IsTransient(err) {
+		r.Health.Failure(switchKey(sw))
+		return
+	}
+	r.Health.Success(switchKey(sw))
+}
+
+// errorRequeueTime return the delay of retrying after the backend error
+func errorRequeueTime(err error) time.Duration {
+	if backends.IsTransient(err) {
+		return requeueAfterTime
+	}
+	return failureRequeueTime
+}
+
 // getSwitchBackend return switch backend
 func getSwitchBackend(ctx context.Context, client client.Client, sw *v1alpha1.Switch) (backends.Switch, error) {
 	var provider provider.Switch
@@ -46,14 +122,39 @@ func getSwitchBackend(ctx context.Context, client client.Client, sw *v1alpha1.Sw
 	return switches.New(ctx, config)
 }
 
-// noneHandler add finalizers to CR
+// noneHandler add finalizers to CR, the adopted port starts in `Active` state once its usage is
+// recorded in the SwitchResourceLimit
 func (r *SwitchPortReconciler) noneHandler(ctx context.Context, info *machine.ReconcileInfo, instance interface{}) (machine.StateType, ctrl.Result, error) {
 	i := instance.(*v1alpha1.SwitchPort)
 
 	// Add finalizer
 	finalizer.Add(&i.Finalizers, finalizerKey)
 
-	return machine.ResultContinue(v1alpha1.SwitchPortIdle, 0, nil)
+	if _, adopted := i.Annotations[v1alpha1.AdoptedAnnotation]; !adopted || i.Spec.Configuration == nil {
+		return machine.ResultContinue(v1alpha1.SwitchPortIdle, 0, nil)
+	}
+
+	// The configuration is already on the port, so it's copied without configuring
+	owner, err := i.FetchOwnerReference(ctx, info.Client)
+	if err != nil {
+		return machine.ResultContinue(v1alpha1.SwitchPortNone, requeueAfterTime, err)
+	}
+	port := owner.Status.Ports[i.Name]
+	if port == nil {
+		return machine.ResultContinue(v1alpha1.SwitchPortNone, requeueAfterTime, fmt.Errorf("port %s isn't found in switch %s", i.Name, owner.Name))
+	}
+	configuration, err := i.Spec.Configuration.Fetch(ctx, info.Client)
+	if err != nil {
+		return machine.ResultContinue(v1alpha1.SwitchPortNone, requeueAfterTime, err)
+	}
+	// The adopted port is counted by the tenant limit like the configured ones
+	err = recordUsage(ctx, info.Client, i, &configuration.Spec)
+	if err != nil {
+		return machine.ResultContinue(v1alpha1.SwitchPortNone, requeueAfterTime, err)
+	}
+	i.Status.Configuration = &configuration.Spec
+	i.Status.PhysicalPortName = port.PhysicalPortName
+	return machine.ResultContinue(v1alpha1.SwitchPortActive, 0, nil)
 }
 
 // idleHandler check spec.configurationRef's value, if isn't nil set the state of CR to `Validating`
@@ -92,43 +193,46 @@ func (r *SwitchPortReconciler) validatingHandler(ctx context.Context, info *mach
 	}
 
 	// Check switch port limit
-	err = owner.Status.Ports[i.Name].Verify(configuration)
+	err = owner.Status.Ports[i.Name].Verify(configuration, owner.Status.ReservedVLANs)
 	if err != nil {
 		return machine.ResultContinue(v1alpha1.SwitchPortValidating, requeueAfterTime, err)
 	}
 
+	// Check the fields of configuration are supported by switch
+	err = owner.Status.Capabilities.Verify(&configuration.Spec)
+	if err != nil {
+		return machine.ResultContinue(v1alpha1.SwitchPortValidating, requeueAfterTime,
+			fmt.Errorf("configuration %s is rejected: %s", configuration.Name, err),
+		)
+	}
+
 	// Check user limit
 	resourceLimit, err := i.FetchSwitchResourceLimit(ctx, info.Client)
 	if err != nil && !errors.IsNotFound(err) {
 		return machine.ResultContinue(v1alpha1.SwitchPortValidating, requeueAfterTime, err)
 	}
 	if !errors.IsNotFound(err) && resourceLimit != nil {
-		resource, err := resourceLimit.FetchSwitchResource(ctx, info.Client)
+		err = verifyTenantLimit(ctx, info.Client, resourceLimit, client.ObjectKeyFromObject(i).String(), configuration)
 		if err != nil {
 			return machine.ResultContinue(v1alpha1.SwitchPortValidating, requeueAfterTime, err)
 		}
-		for _, limit := range resource.Status.TenantLimits {
-			if limit.Namespace == resourceLimit.Namespace {
-				err = limit.VerifyConfiguration(configuration)
-				if err != nil {
-					return machine.ResultContinue(v1alpha1.SwitchPortValidating,
-						requeueAfterTime,
-						fmt.Errorf("%s, %s", err, "please check `SwitchResourceLimit/user-limit`"),
-					)
-				}
-			}
-		}
-
 	}
 
 	// Check connection with switch
+	if r.switchUnreachable(owner) {
+		info.Logger.Info("switch is unreachable, wait for it to come back")
+		return machine.ResultContinue(v1alpha1.SwitchPortValidating, unreachableWaitTime, nil)
+	}
 	backend, err := getSwitchBackend(ctx, info.Client, owner)
 	if err != nil {
 		return machine.ResultContinue(v1alpha1.SwitchPortValidating, requeueAfterTime, err)
 	}
-	err = backend.IsAvailable()
+	backendCtx, cancel := context.WithTimeout(ctx, backendTimeout)
+	defer cancel()
+	err = backend.IsAvailable(backendCtx)
+	r.backendResult(owner, err)
 	if err != nil {
-		return machine.ResultContinue(v1alpha1.SwitchPortValidating, requeueAfterTime, err)
+		return machine.ResultContinue(v1alpha1.SwitchPortValidating, errorRequeueTime(err), err)
 	}
 
 	// Copy configuration to Status.Configuration
@@ -145,41 +249,99 @@ func (r *SwitchPortReconciler) configuringHandler(ctx context.Context, info *mac
 		return machine.ResultContinue(v1alpha1.SwitchPortCleaning, 0, nil)
 	}
 
-	resourceLimit, err := i.FetchSwitchResourceLimit(ctx, info.Client)
-	if err != nil && !errors.IsNotFound(err) {
-		return machine.ResultContinue(v1alpha1.SwitchPortConfiguring, requeueAfterTime, err)
-	}
-
 	// Set configuration to port
 	owner, err := i.FetchOwnerReference(ctx, info.Client)
 	if err != nil {
 		return machine.ResultContinue(v1alpha1.SwitchPortConfiguring, requeueAfterTime, err)
 	}
+	if r.switchUnreachable(owner) {
+		info.Logger.Info("switch is unreachable, wait for it to come back")
+		return machine.ResultContinue(v1alpha1.SwitchPortConfiguring, unreachableWaitTime, nil)
+	}
 	backend, err := getSwitchBackend(ctx, info.Client, owner)
 	if err != nil {
 		return machine.ResultContinue(v1alpha1.SwitchPortConfiguring, requeueAfterTime, err)
 	}
-	err = backend.SetPortAttr(ctx, i.Status.PhysicalPortName, i.Status.Configuration)
+	unlock, err := r.lockSwitch(ctx, owner, lock.Normal)
+	if err != nil {
+		info.Logger.Info("switch is busy, wait for other operations")
+		return machine.ResultContinue(v1alpha1.SwitchPortConfiguring, lockRetryTime, nil)
+	}
+	defer unlock()
+
+	// The limit is checked again, other ports may have been recorded since validating
+	err = recordUsage(ctx, info.Client, i, i.Status.Configuration)
 	if err != nil {
 		return machine.ResultContinue(v1alpha1.SwitchPortConfiguring, requeueAfterTime, err)
 	}
 
-	if resourceLimit.GetName() != "" {
-		err = resourceLimit.Expansion(i.Status.Configuration)
-		if err != nil {
-			return machine.ResultContinue(v1alpha1.SwitchPortConfiguring, requeueAfterTime, err)
+	backendCtx, cancel := context.WithTimeout(ctx, backendTimeout)
+	defer cancel()
+	err = backend.SetPortAttr(backendCtx, i.Status.PhysicalPortName, i.Status.Configuration)
+	r.backendResult(owner, err)
+	if err != nil {
+		return machine.ResultContinue(v1alpha1.SwitchPortConfiguring, errorRequeueTime(err), err)
+	}
+
+	return machine.ResultContinue(v1alpha1.SwitchPortActive, 0, nil)
+}
+
+// verifyTenantLimit verify the configuration and the usage of port meet the TenantLimit of the
+// SwitchResourceLimit, the records of ports are seeded first
+func verifyTenantLimit(ctx context.Context, c client.Client, resourceLimit *v1alpha1.SwitchResourceLimit,
+	port string, configuration *v1alpha1.SwitchPortConfiguration) error {
+	err := seedUsage(ctx, c, resourceLimit)
+	if err != nil {
+		return err
+	}
+	resource, err := resourceLimit.FetchSwitchResource(ctx, c)
+	if err != nil {
+		return err
+	}
+	for _, limit := range resource.Status.TenantLimits {
+		if limit.Namespace != resourceLimit.Namespace {
+			continue
+		}
+		err = limit.VerifyConfiguration(configuration)
+		if err == nil {
+			err = limit.VerifyUsage(resourceLimit, port, configuration)
 		}
-		err = info.Client.Status().Update(ctx, resourceLimit)
 		if err != nil {
-			return machine.ResultContinue(v1alpha1.SwitchPortConfiguring, requeueAfterTime, err)
+			return fmt.Errorf("%s, %s", err, "please check `SwitchResourceLimit/user-limit`")
 		}
 	}
+	return nil
+}
 
-	return machine.ResultContinue(v1alpha1.SwitchPortActive, 0, nil)
+// recordUsage verify the configuration of port against the TenantLimit and record the usage of
+// port in SwitchResourceLimit by the same update, so the concurrent records conflict instead of
+// exceeding the limit. Nothing is done if the tenant has no SwitchResourceLimit.
+func recordUsage(ctx context.Context, c client.Client, sp *v1alpha1.SwitchPort, configuration *v1alpha1.SwitchPortConfigurationSpec) error {
+	resourceLimit, err := sp.FetchSwitchResourceLimit(ctx, c)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if resourceLimit.GetName() == "" {
+		return nil
+	}
+
+	port := client.ObjectKeyFromObject(sp).String()
+	err = verifyTenantLimit(ctx, c, resourceLimit, port, &v1alpha1.SwitchPortConfiguration{Spec: *configuration})
+	if err != nil {
+		return err
+	}
+	err = resourceLimit.Expansion(port, configuration)
+	if err != nil {
+		return err
+	}
+	return c.Status().Update(ctx, resourceLimit)
 }
 
 // activeHandler check whether the target configuration is consistent with the actual configuration,
-// return to `Configuring` state when inconsistent
+// the inconsistency is handled by the drift policy
 func (r *SwitchPortReconciler) activeHandler(ctx context.Context, info *machine.ReconcileInfo, instance interface{}) (machine.StateType, ctrl.Result, error) {
 	i := instance.(*v1alpha1.SwitchPort)
 
@@ -196,24 +358,102 @@ func (r *SwitchPortReconciler) activeHandler(ctx context.Context, info *machine.
 		return machine.ResultContinue(v1alpha1.SwitchPortCleaning, 0, nil)
 	}
 
+	// Re-apply the configuration once when the annotation is changed
+	if reapply := i.Annotations[v1alpha1.ReapplyAnnotation]; reapply != "" && reapply != i.Status.Reapplied {
+		info.Logger.Info("re-applying configuration of port", "reapply", reapply)
+		i.Status.Reapplied = reapply
+		return machine.ResultContinue(v1alpha1.SwitchPortConfiguring, 0, nil)
+	}
+
 	// Check status.Configuration as same as switch's port configuration or not
 	owner, err := i.FetchOwnerReference(ctx, info.Client)
 	if err != nil {
 		return machine.ResultContinue(v1alpha1.SwitchPortActive, requeueAfterTime, err)
 	}
+	policy := driftPolicy(i, owner)
+	if policy == v1alpha1.DriftIgnore {
+		i.Status.Drift = nil
+		meta.RemoveStatusCondition(&i.Status.Conditions, v1alpha1.SwitchPortDriftDetected)
+		return machine.ResultComplete(v1alpha1.SwitchPortActive, nil)
+	}
+	if r.switchUnreachable(owner) {
+		info.Logger.Info("switch is unreachable, wait for it to come back")
+		return machine.ResultContinue(v1alpha1.SwitchPortActive, unreachableWaitTime, nil)
+	}
 	backend, err := getSwitchBackend(ctx, info.Client, owner)
 	if err != nil {
 		return machine.ResultContinue(v1alpha1.SwitchPortActive, requeueAfterTime, err)
 	}
-	actualConfiguration, err := backend.GetPortAttr(ctx, i.Status.PhysicalPortName)
-	if err != nil || i.Status.Configuration.IsEqual(actualConfiguration) {
-		return machine.ResultContinue(v1alpha1.SwitchPortActive, requeueAfterTime, err)
+	backendCtx, cancel := context.WithTimeout(ctx, backendTimeout)
+	defer cancel()
+	actualConfiguration, err := backend.GetPortAttr(backendCtx, i.Status.PhysicalPortName)
+	r.backendResult(owner, err)
+	if err != nil {
+		return machine.ResultContinue(v1alpha1.SwitchPortActive, errorRequeueTime(err), err)
+	}
+	// Changes of the referenced objects are watched, so only the drift on the device need to be polled
+	if i.Status.Configuration.IsEqual(actualConfiguration) {
+		r.setDrift(i, nil)
+		return machine.ResultContinue(v1alpha1.SwitchPortActive, driftCheckInterval(r.DriftCheckInterval), nil)
+	}
+
+	if policy == v1alpha1.DriftReport {
+		r.setDrift(i, i.Status.Configuration.Diff(actualConfiguration))
+		return machine.ResultContinue(v1alpha1.SwitchPortActive, driftCheckInterval(r.DriftCheckInterval), nil)
 	}
 
 	info.Logger.Info("configuration of port has been changed externally")
 	return machine.ResultContinue(v1alpha1.SwitchPortConfiguring, 0, nil)
 }
 
+// driftPolicy return the drift policy of port, if it's empty use the policy of switch,
+// `Remediate` is used if neither is set
+func driftPolicy(sp *v1alpha1.SwitchPort, sw *v1alpha1.Switch) v1alpha1.DriftPolicy {
+	if sp.Spec.DriftPolicy != "" {
+		return sp.Spec.DriftPolicy
+	}
+	if sw.Spec.DriftPolicy != "" {
+		return sw.Spec.DriftPolicy
+	}
+	return v1alpha1.DriftRemediate
+}
+
+// setDrift records the differences in status and the `DriftDetected` condition, the event
+// is emitted when the differences are changed
+func (r *SwitchPortReconciler) setDrift(sp *v1alpha1.SwitchPort, diffs []v1alpha1.ConfigurationDiff) {
+	if len(diffs) == 0 {
+		sp.Status.Drift = nil
+		if meta.FindStatusCondition(sp.Status.Conditions, v1alpha1.SwitchPortDriftDetected) != nil {
+			meta.SetStatusCondition(&sp.Status.Conditions, metav1.Condition{
+				Type:               v1alpha1.SwitchPortDriftDetected,
+				Status:             metav1.ConditionFalse,
+				ObservedGeneration: sp.Generation,
+				Reason:             "ConfigurationMatched",
+				Message:            "the configuration of port is as same as the desired configuration",
+			})
+		}
+		return
+	}
+
+	changes := make([]string, 0, len(diffs))
+	for _, diff := range diffs {
+		changes = append(changes, fmt.Sprintf("%s: desired %q, actual %q", diff.Field, diff.Desired, diff.Actual))
+	}
+	message := "the configuration of port has been changed externally, " + strings.Join(changes, "; ")
+	changed := !reflect.DeepEqual(sp.Status.Drift, diffs)
+	sp.Status.Drift = diffs
+	meta.SetStatusCondition(&sp.Status.Conditions, metav1.Condition{
+		Type:               v1alpha1.SwitchPortDriftDetected,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: sp.Generation,
+		Reason:             "ConfigurationChanged",
+		Message:            message,
+	})
+	if changed && r.Recorder != nil {
+		r.Recorder.Event(sp, corev1.EventTypeWarning, "DriftDetected", message)
+	}
+}
+
 // cleaningHandler will be called when deleting network configuration, when finished clean spec.configurationRef and status.configurationRef then set CR's state to `Idle` state.
 func (r *SwitchPortReconciler) cleaningHandler(ctx context.Context, info *machine.ReconcileInfo, instance interface{}) (machine.StateType, ctrl.Result, error) {
 	i := instance.(*v1alpha1.SwitchPort)
@@ -226,17 +466,35 @@ func (r *SwitchPortReconciler) cleaningHandler(ctx context.Context, info *machin
 	if err != nil {
 		return machine.ResultContinue(v1alpha1.SwitchPortCleaning, requeueAfterTime, err)
 	}
+	if r.switchUnreachable(owner) {
+		info.Logger.Info("switch is unreachable, wait for it to come back")
+		return machine.ResultContinue(v1alpha1.SwitchPortCleaning, unreachableWaitTime, nil)
+	}
 	backend, err := getSwitchBackend(ctx, info.Client, owner)
 	if err != nil {
 		return machine.ResultContinue(v1alpha1.SwitchPortCleaning, requeueAfterTime, err)
 	}
-	err = backend.ResetPort(ctx, i.Status.PhysicalPortName, i.Status.Configuration)
+	// Cleaning has priority over configuring, so the resources are released as soon as possible
+	unlock, err := r.lockSwitch(ctx, owner, lock.High)
 	if err != nil {
-		return machine.ResultContinue(v1alpha1.SwitchPortCleaning, requeueAfterTime, err)
+		info.Logger.Info("switch is busy, wait for other operations")
+		return machine.ResultContinue(v1alpha1.SwitchPortCleaning, lockRetryTime, nil)
+	}
+	defer unlock()
+	backendCtx, cancel := context.WithTimeout(ctx, backendTimeout)
+	defer cancel()
+	err = backend.ResetPort(backendCtx, i.Status.PhysicalPortName, i.Status.Configuration)
+	r.backendResult(owner, err)
+	if err != nil {
+		return machine.ResultContinue(v1alpha1.SwitchPortCleaning, errorRequeueTime(err), err)
 	}
 
 	if resourceLimit.GetName() != "" {
-		err = resourceLimit.Shrink(i.Status.Configuration)
+		err = seedUsage(ctx, info.Client, resourceLimit)
+		if err != nil {
+			return machine.ResultContinue(v1alpha1.SwitchPortCleaning, requeueAfterTime, err)
+		}
+		err = resourceLimit.Shrink(client.ObjectKeyFromObject(i).String())
 		if err != nil {
 			return machine.ResultContinue(v1alpha1.SwitchPortCleaning, requeueAfterTime, err)
 		}
@@ -12,6 +12,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
@@ -41,6 +42,12 @@ func (c *fakeClient) Get(ctx context.Context, key types.NamespacedName, obj clie
 		*obj.(*v1alpha1.SwitchPortConfiguration) = v1alpha1.SwitchPortConfiguration{}
 	case "Secret":
 		*obj.(*corev1.Secret) = corev1.Secret{}
+	case "user-limit":
+		*obj.(*v1alpha1.SwitchResourceLimit) = v1alpha1.SwitchResourceLimit{
+			Status: v1alpha1.SwitchResourceLimitStatus{
+				VLANRange: "2-10",
+			},
+		}
 	}
 
 	return nil
@@ -183,3 +190,151 @@ func TestSwitchPortStateMachine(t *testing.T) {
 		})
 	}
 }
+
+func TestRecordUsage(t *testing.T) {
+	vlan10 := 10
+	scheme := runtime.NewScheme()
+	_ = v1alpha1.AddToScheme(scheme)
+	c := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&v1alpha1.SwitchResource{
+			ObjectMeta: metav1.ObjectMeta{Name: "resource", Namespace: "default"},
+			Status: v1alpha1.SwitchResourceStatus{
+				TenantLimits: map[string]*v1alpha1.TenantLimit{
+					"tenant": {Namespace: "tenant", VLANRange: "2-100", MaxPorts: 1},
+				},
+			},
+		},
+		&v1alpha1.SwitchResourceLimit{
+			ObjectMeta: metav1.ObjectMeta{Name: "user-limit", Namespace: "tenant"},
+			Status: v1alpha1.SwitchResourceLimitStatus{
+				VLANRange:         "2-100",
+				SwitchResourceRef: v1alpha1.SwitchResourceRef{Name: "resource", Namespace: "default"},
+			},
+		},
+	).Build()
+
+	cases := []struct {
+		name          string
+		port          string
+		expectedError bool
+	}{
+		{
+			name: "first port",
+			port: "port1",
+		},
+		{
+			name:          "port exceeding the limit",
+			port:          "port2",
+			expectedError: true,
+		},
+		{
+			name: "recorded port",
+			port: "port1",
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			sp := &v1alpha1.SwitchPort{}
+			sp.Name = cs.port
+			sp.Namespace = "default"
+			sp.Spec.Configuration = &v1alpha1.SwitchPortConfigurationReference{Name: "configuration", Namespace: "tenant"}
+			err := recordUsage(context.Background(), c, sp, &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan10})
+			if (err != nil) != cs.expectedError {
+				t.Errorf("expected error: %v, got: %v", cs.expectedError, err)
+			}
+
+			resourceLimit := &v1alpha1.SwitchResourceLimit{}
+			err = c.Get(context.Background(), types.NamespacedName{Name: "user-limit", Namespace: "tenant"}, resourceLimit)
+			if err != nil {
+				t.Fatalf("get SwitchResourceLimit failed: %v", err)
+			}
+			if _, ok := resourceLimit.Status.Ports["default/port1"]; len(resourceLimit.Status.Ports) != 1 || !ok {
+				t.Errorf("expected only default/port1 is recorded, got: %v", resourceLimit.Status.Ports)
+			}
+		})
+	}
+}
+
+func TestAdoptedPortUsage(t *testing.T) {
+	vlan10 := 10
+	cases := []struct {
+		name          string
+		maxPorts      int
+		expectedState machine.StateType
+		expectedPorts int
+	}{
+		{
+			name:          "adopted port exceeding the limit",
+			maxPorts:      1,
+			expectedState: v1alpha1.SwitchPortNone,
+			expectedPorts: 1,
+		},
+		{
+			name:          "adopted port",
+			maxPorts:      2,
+			expectedState: v1alpha1.SwitchPortActive,
+			expectedPorts: 2,
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			_ = v1alpha1.AddToScheme(scheme)
+			c := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(
+				&v1alpha1.Switch{
+					ObjectMeta: metav1.ObjectMeta{Name: "switch", Namespace: "default"},
+					Status: v1alpha1.SwitchStatus{
+						Ports: map[string]*v1alpha1.Port{
+							"port2": {PhysicalPortName: "eth2"},
+						},
+					},
+				},
+				&v1alpha1.SwitchPortConfiguration{
+					ObjectMeta: metav1.ObjectMeta{Name: "configuration", Namespace: "tenant"},
+					Spec:       v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan10},
+				},
+				&v1alpha1.SwitchResource{
+					ObjectMeta: metav1.ObjectMeta{Name: "resource", Namespace: "default"},
+					Status: v1alpha1.SwitchResourceStatus{
+						TenantLimits: map[string]*v1alpha1.TenantLimit{
+							"tenant": {Namespace: "tenant", VLANRange: "2-100", MaxPorts: cs.maxPorts},
+						},
+					},
+				},
+				&v1alpha1.SwitchResourceLimit{
+					ObjectMeta: metav1.ObjectMeta{Name: "user-limit", Namespace: "tenant"},
+					Status: v1alpha1.SwitchResourceLimitStatus{
+						VLANRange:         "2-100",
+						SwitchResourceRef: v1alpha1.SwitchResourceRef{Name: "resource", Namespace: "default"},
+						Ports: map[string]*v1alpha1.PortUsage{
+							"default/port1": {VLANRange: "10"},
+						},
+					},
+				},
+			).Build()
+
+			sp := &v1alpha1.SwitchPort{}
+			sp.Name = "port2"
+			sp.Namespace = "default"
+			sp.Annotations = map[string]string{v1alpha1.AdoptedAnnotation: ""}
+			sp.OwnerReferences = []metav1.OwnerReference{{Name: "switch"}}
+			sp.Spec.Configuration = &v1alpha1.SwitchPortConfigurationReference{Name: "configuration", Namespace: "tenant"}
+			r := &SwitchPortReconciler{}
+			state, _, _ := r.noneHandler(context.Background(), &machine.ReconcileInfo{Client: c, Logger: log.Log}, sp)
+			if state != cs.expectedState {
+				t.Errorf("expected state: %v, got: %v", cs.expectedState, state)
+			}
+
+			resourceLimit := &v1alpha1.SwitchResourceLimit{}
+			err := c.Get(context.Background(), types.NamespacedName{Name: "user-limit", Namespace: "tenant"}, resourceLimit)
+			if err != nil {
+				t.Fatalf("get SwitchResourceLimit failed: %v", err)
+			}
+			if len(resourceLimit.Status.Ports) != cs.expectedPorts {
+				t.Errorf("expected %d ports are recorded, got: %v", cs.expectedPorts, resourceLimit.Status.Ports)
+			}
+		})
+	}
+}
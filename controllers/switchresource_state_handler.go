@@ -8,6 +8,7 @@ import (
 	"github.com/Hellcatlk/network-operator/api/v1alpha1"
 	"github.com/Hellcatlk/network-operator/pkg/machine"
 	"github.com/Hellcatlk/network-operator/pkg/utils/finalizer"
+	"github.com/Hellcatlk/network-operator/pkg/utils/strings"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -23,6 +24,10 @@ func (r *SwitchResourceReconciler) noneHandler(ctx context.Context, info *machin
 	finalizer.Add(&i.Finalizers, finalizerKey)
 
 	i.Status.AvailableVLAN = i.Spec.VLANRange
+	err := i.Reserve(i.Spec.ReservedVLANs)
+	if err != nil {
+		return machine.ResultContinue(v1alpha1.SwitchResourceNone, 0, err)
+	}
 
 	for _, limit := range i.Spec.TenantLimits {
 		err := limit.Verify(&i.Status)
@@ -41,41 +46,78 @@ func (r *SwitchResourceReconciler) verifyingHandler(ctx context.Context, info *m
 		return machine.ResultContinue(v1alpha1.SwitchResourceDeleting, 0, nil)
 	}
 
-	// Delete SwitchResourceLimit which isn't included i.Spec
+	// Resize or delete SwitchResourceLimit which isn't same as i.Spec
 	for name, limit := range i.Status.TenantLimits {
-		_, exit := i.Spec.TenantLimits[name]
-		if !exit || !reflect.DeepEqual(i.Spec.TenantLimits[name], i.Status.TenantLimits[name]) {
-			sr, err := limit.FetchSwitchResourceLimit(ctx, info.Client)
-			if err != nil {
-				if errors.IsNotFound(err) {
-					continue
-				}
-				return machine.ResultContinue(v1alpha1.SwitchResourceVerifying, requeueAfterTime, err)
-			}
-			if sr.Status.UsedVLAN != "" {
-				err = fmt.Errorf("SwitchResourceLimit %s still has vlan %s being used and cannot be deleted", sr.Name, sr.Status.UsedVLAN)
-				return machine.ResultContinue(v1alpha1.SwitchResourceVerifying, requeueAfterTime, err)
+		target, exist := i.Spec.TenantLimits[name]
+		if exist && reflect.DeepEqual(target, limit) {
+			continue
+		}
+
+		sr, err := limit.FetchSwitchResourceLimit(ctx, info.Client)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
 			}
+			return machine.ResultContinue(v1alpha1.SwitchResourceVerifying, requeueAfterTime, err)
+		}
 
-			switchResourceLimit := &v1alpha1.SwitchResourceLimit{}
-			switchResourceLimit.Name = "user-limit"
-			switchResourceLimit.Namespace = limit.Namespace
-			err = info.Client.Delete(ctx, switchResourceLimit)
+		// Resize SwitchResourceLimit in place if the tenant isn't changed. Only the status of
+		// SwitchResource is changed here, `status.vlanRange` of SwitchResourceLimit is synchronized
+		// from it in `Creating` state, so a failed write can be retried without charging twice.
+		if exist && target != nil && target.Namespace == limit.Namespace {
+			err = i.Resize(sr, limit, target)
 			if err != nil {
-				if errors.IsNotFound(err) {
-					continue
-				}
+				err = fmt.Errorf("can't resize switchResourceLimit for %s, %s", name, err)
 				return machine.ResultContinue(v1alpha1.SwitchResourceVerifying, requeueAfterTime, err)
 			}
-			err = i.Expansion(limit)
-			if err != nil {
-				return machine.ResultContinue(v1alpha1.SwitchResourceVerifying, 0, err)
+			i.Status.TenantLimits[name] = target.DeepCopy()
+			continue
+		}
+
+		if sr.Status.UsedVLAN != "" {
+			err = fmt.Errorf("SwitchResourceLimit %s still has vlan %s being used and cannot be deleted", sr.Name, sr.Status.UsedVLAN)
+			return machine.ResultContinue(v1alpha1.SwitchResourceVerifying, requeueAfterTime, err)
+		}
+
+		switchResourceLimit := &v1alpha1.SwitchResourceLimit{}
+		switchResourceLimit.Name = "user-limit"
+		switchResourceLimit.Namespace = limit.Namespace
+		err = info.Client.Delete(ctx, switchResourceLimit)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
 			}
+			return machine.ResultContinue(v1alpha1.SwitchResourceVerifying, requeueAfterTime, err)
+		}
+		err = i.Expansion(limit)
+		if err != nil {
+			return machine.ResultContinue(v1alpha1.SwitchResourceVerifying, 0, err)
 		}
 	}
 
 	i.Status.TenantLimits = i.Spec.TenantLimits
 
+	// The tenants created before the default vlan was reserved may have been assigned it,
+	// it can't be fixed automatically, so stop here until `spec.tenantLimits` is changed
+	for name, limit := range i.Status.TenantLimits {
+		if limit == nil {
+			continue
+		}
+		used, err := strings.Intersection(limit.VLANRange, v1alpha1.DefaultReservedVLAN)
+		if err != nil {
+			return machine.ResultContinue(v1alpha1.SwitchResourceVerifying, requeueAfterTime, err)
+		}
+		if used != "" {
+			err = fmt.Errorf("vlan %s is reserved as the default vlan, please remove it from the tenant limit %s", used, name)
+			return machine.ResultComplete(v1alpha1.SwitchResourceVerifying, err)
+		}
+	}
+
+	err := i.Reserve(i.Spec.ReservedVLANs)
+	if err != nil {
+		return machine.ResultContinue(v1alpha1.SwitchResourceVerifying, requeueAfterTime, err)
+	}
+
 	return machine.ResultContinue(v1alpha1.SwitchResourceCreating, 0, nil)
 }
 
@@ -89,14 +131,23 @@ func (r *SwitchResourceReconciler) creatingHandler(ctx context.Context, info *ma
 	// Create SwitchResourceLimit
 	for name, limit := range i.Status.TenantLimits {
 		// Get switchResourceLimit
+		resourceLimit := &v1alpha1.SwitchResourceLimit{}
 		err := info.Client.Get(
 			ctx, types.NamespacedName{
 				Name:      "user-limit",
 				Namespace: limit.Namespace,
 			},
-			&v1alpha1.SwitchResourceLimit{},
+			resourceLimit,
 		)
 		if err == nil {
+			// Synchronize the vlan range resized in place
+			if resourceLimit.Status.VLANRange != limit.VLANRange {
+				resourceLimit.Status.VLANRange = limit.VLANRange
+				err = info.Client.Status().Update(ctx, resourceLimit)
+				if err != nil {
+					return machine.ResultContinue(v1alpha1.SwitchResourceCreating, requeueAfterTime, err)
+				}
+			}
 			continue
 		}
 		if err != nil {
@@ -107,7 +158,7 @@ func (r *SwitchResourceReconciler) creatingHandler(ctx context.Context, info *ma
 
 		err = limit.Verify(&i.Status)
 		if err != nil {
-			err = fmt.Errorf("cann't create switchResourceLimit for %s, %s", name, err)
+			err = fmt.Errorf("can't create switchResourceLimit for %s, %s", name, err)
 			return machine.ResultContinue(v1alpha1.SwitchResourceCreating, 0, err)
 		}
 		switchResourceLimit := &v1alpha1.SwitchResourceLimit{}
@@ -152,19 +203,21 @@ func (r *SwitchResourceReconciler) runningHandler(ctx context.Context, info *mac
 		return machine.ResultContinue(v1alpha1.SwitchResourceDeleting, 0, nil)
 	}
 
-	if !reflect.DeepEqual(i.Spec.TenantLimits, i.Status.TenantLimits) {
+	reservedVLANs, err := v1alpha1.ReservedVLANs(i.Spec.ReservedVLANs)
+	if err != nil || reservedVLANs != i.Status.ReservedVLANs || !reflect.DeepEqual(i.Spec.TenantLimits, i.Status.TenantLimits) {
 		return machine.ResultContinue(v1alpha1.SwitchResourceVerifying, 0, nil)
 	}
 
 	// Check switchResourceLimit are existed
 	for _, limit := range i.Status.TenantLimits {
 		// Get switchResourceLimit
+		resourceLimit := &v1alpha1.SwitchResourceLimit{}
 		err := info.Client.Get(
 			ctx, types.NamespacedName{
 				Name:      "user-limit",
 				Namespace: limit.Namespace,
 			},
-			&v1alpha1.SwitchResourceLimit{},
+			resourceLimit,
 		)
 		if err != nil {
 			// If switchResourceLimit isn't find, return creating state and create it
@@ -173,6 +226,10 @@ func (r *SwitchResourceReconciler) runningHandler(ctx context.Context, info *mac
 			}
 			return machine.ResultContinue(v1alpha1.SwitchResourceRunning, requeueAfterTime, err)
 		}
+		// If the vlan range isn't synchronized, return creating state and synchronize it
+		if resourceLimit.Status.VLANRange != limit.VLANRange {
+			return machine.ResultContinue(v1alpha1.SwitchResourceCreating, 0, nil)
+		}
 	}
 
 	return machine.ResultContinue(v1alpha1.SwitchResourceRunning, requeueAfterTime, nil)
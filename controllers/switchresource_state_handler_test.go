@@ -7,6 +7,9 @@ import (
 	"github.com/Hellcatlk/network-operator/api/v1alpha1"
 	"github.com/Hellcatlk/network-operator/pkg/machine"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
@@ -18,7 +21,7 @@ func TestSwitchResourceStateMachine(t *testing.T) {
 			TenantLimits: map[string]*v1alpha1.TenantLimit{
 				"user1": {
 					Namespace: "test1",
-					VLANRange: "1-10",
+					VLANRange: "2-10",
 				},
 			},
 		},
@@ -103,3 +106,84 @@ func TestSwitchResourceStateMachine(t *testing.T) {
 		})
 	}
 }
+
+func TestSwitchResourceResizeInPlace(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = v1alpha1.AddToScheme(scheme)
+	resourceLimit := &v1alpha1.SwitchResourceLimit{
+		ObjectMeta: metav1.ObjectMeta{Name: "user-limit", Namespace: "test1"},
+		Status: v1alpha1.SwitchResourceLimitStatus{
+			VLANRange: "2-10",
+		},
+	}
+	client := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(resourceLimit).Build()
+
+	r := SwitchResourceReconciler{}
+	instance := &v1alpha1.SwitchResource{
+		Spec: v1alpha1.SwitchResourceSpec{
+			VLANRange: "2-100",
+			TenantLimits: map[string]*v1alpha1.TenantLimit{
+				"user1": {Namespace: "test1", VLANRange: "2-20"},
+			},
+		},
+		Status: v1alpha1.SwitchResourceStatus{
+			State:         v1alpha1.SwitchResourceVerifying,
+			AvailableVLAN: "11-100",
+			TenantLimits: map[string]*v1alpha1.TenantLimit{
+				"user1": {Namespace: "test1", VLANRange: "2-10"},
+			},
+		},
+	}
+	info := &machine.ReconcileInfo{Client: client, Logger: log.NullLogger{}}
+
+	// The SwitchResourceLimit isn't changed until the status of SwitchResource is saved
+	state, _, err := r.verifyingHandler(context.TODO(), info, instance)
+	if err != nil || state != v1alpha1.SwitchResourceCreating {
+		t.Fatalf("expected state %s, got: %s, %v", v1alpha1.SwitchResourceCreating, state, err)
+	}
+	if instance.Status.AvailableVLAN != "21-100" {
+		t.Errorf("expected available vlan: %q, got: %q", "21-100", instance.Status.AvailableVLAN)
+	}
+	current := &v1alpha1.SwitchResourceLimit{}
+	_ = client.Get(context.TODO(), types.NamespacedName{Name: "user-limit", Namespace: "test1"}, current)
+	if current.Status.VLANRange != "2-10" {
+		t.Errorf("expected vlan range of limit: %q, got: %q", "2-10", current.Status.VLANRange)
+	}
+
+	state, _, err = r.creatingHandler(context.TODO(), info, instance)
+	if err != nil || state != v1alpha1.SwitchResourceRunning {
+		t.Fatalf("expected state %s, got: %s, %v", v1alpha1.SwitchResourceRunning, state, err)
+	}
+	_ = client.Get(context.TODO(), types.NamespacedName{Name: "user-limit", Namespace: "test1"}, current)
+	if current.Status.VLANRange != "2-20" {
+		t.Errorf("expected vlan range of limit: %q, got: %q", "2-20", current.Status.VLANRange)
+	}
+}
+
+func TestSwitchResourceDefaultVLANAssigned(t *testing.T) {
+	r := SwitchResourceReconciler{}
+	instance := &v1alpha1.SwitchResource{
+		Spec: v1alpha1.SwitchResourceSpec{
+			VLANRange: "1-100",
+			TenantLimits: map[string]*v1alpha1.TenantLimit{
+				"user1": {Namespace: "test1", VLANRange: "1-10"},
+			},
+		},
+		Status: v1alpha1.SwitchResourceStatus{
+			State:         v1alpha1.SwitchResourceVerifying,
+			AvailableVLAN: "11-100",
+			TenantLimits: map[string]*v1alpha1.TenantLimit{
+				"user1": {Namespace: "test1", VLANRange: "1-10"},
+			},
+		},
+	}
+	info := &machine.ReconcileInfo{Client: &fakeClient{}, Logger: log.NullLogger{}}
+
+	state, result, err := r.verifyingHandler(context.TODO(), info, instance)
+	if err == nil {
+		t.Errorf("expected error for the default vlan assigned to tenant")
+	}
+	if state != v1alpha1.SwitchResourceVerifying || result.Requeue {
+		t.Errorf("expected to stop in %s, got: %s, %+v", v1alpha1.SwitchResourceVerifying, state, result)
+	}
+}
@@ -0,0 +1,139 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/Hellcatlk/network-operator/api/v1alpha1"
+)
+
+// UsageResyncTime is the default interval of rebuilding the usage of SwitchResourceLimit
+const UsageResyncTime time.Duration = time.Minute * 5
+
+// SwitchResourceLimitReconciler rebuilds the usage of SwitchResourceLimit from the actual state of SwitchPorts
+type SwitchResourceLimitReconciler struct {
+	client.Client
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+
+	// The interval of rebuilding the usage, if zero use 5 minutes
+	ResyncPeriod time.Duration
+}
+
+// +kubebuilder:rbac:groups=metal3.io,resources=switchresourcelimits,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=metal3.io,resources=switchresourcelimits/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=metal3.io,resources=switchports,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// Reconcile switch resource limits
+func (r *SwitchResourceLimitReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := r.Log.WithValues("switchresourcelimit", req.NamespacedName)
+
+	resyncPeriod := r.ResyncPeriod
+	if resyncPeriod == 0 {
+		resyncPeriod = UsageResyncTime
+	}
+
+	// Fetch the instance
+	instance := &v1alpha1.SwitchResourceLimit{}
+	err := r.Get(ctx, req.NamespacedName, instance)
+	if err != nil {
+		// The object has been deleted
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		// Requeue when other error
+		return ctrl.Result{}, err
+	}
+
+	// Rebuild usage from the SwitchPorts which use the configurations of tenant
+	switchPorts, err := switchPortsForLimit(ctx, r.Client, instance)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	rebuilt := instance.DeepCopy()
+	err = rebuilt.Rebuild(switchPorts)
+	if err != nil {
+		logger.Error(err, "rebuild usage failed")
+		return ctrl.Result{}, err
+	}
+	if reflect.DeepEqual(rebuilt.Status, instance.Status) {
+		return ctrl.Result{RequeueAfter: resyncPeriod}, nil
+	}
+
+	message := fmt.Sprintf("usage is corrected, used vlan from %q to %q, used ports from %d to %d",
+		instance.Status.UsedVLAN, rebuilt.Status.UsedVLAN, len(instance.Status.Ports), len(rebuilt.Status.Ports))
+	logger.Info(message)
+	err = r.Status().Update(ctx, rebuilt)
+	if err != nil {
+		logger.Error(err, "update switchResourceLimit status failed")
+		return ctrl.Result{}, err
+	}
+	if r.Recorder != nil {
+		r.Recorder.Event(rebuilt, corev1.EventTypeWarning, "UsageCorrected", message)
+	}
+
+	return ctrl.Result{RequeueAfter: resyncPeriod}, nil
+}
+
+// seedUsage rebuilds the records of ports for the SwitchResourceLimit whose usage was
+// recorded before the records of ports were added, otherwise the usage would be dropped
+// by the first change
+func seedUsage(ctx context.Context, c client.Client, limit *v1alpha1.SwitchResourceLimit) error {
+	if len(limit.Status.Ports) != 0 || limit.Status.UsedVLAN == "" {
+		return nil
+	}
+
+	switchPorts, err := switchPortsForLimit(ctx, c, limit)
+	if err != nil {
+		return err
+	}
+	return limit.Rebuild(switchPorts)
+}
+
+// switchPortsForLimit return the SwitchPorts which use the SwitchPortConfigurations
+// in the namespace of the SwitchResourceLimit
+func switchPortsForLimit(ctx context.Context, c client.Client, limit *v1alpha1.SwitchResourceLimit) ([]v1alpha1.SwitchPort, error) {
+	switchPorts := &v1alpha1.SwitchPortList{}
+	err := c.List(ctx, switchPorts, client.MatchingFields{
+		switchPortConfigurationNamespaceField: limit.Namespace,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return switchPorts.Items, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *SwitchResourceLimitReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.SwitchResourceLimit{}).
+		Complete(r)
+}
@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Hellcatlk/network-operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSeedUsage(t *testing.T) {
+	vlan10 := 10
+	cases := []struct {
+		name             string
+		status           v1alpha1.SwitchResourceLimitStatus
+		expectedUsedVLAN string
+		expectedPorts    int
+	}{
+		{
+			name: "usage recorded before the records of ports",
+			status: v1alpha1.SwitchResourceLimitStatus{
+				UsedVLAN: "10-11",
+			},
+			expectedUsedVLAN: "10",
+			expectedPorts:    1,
+		},
+		{
+			name: "records of ports exist",
+			status: v1alpha1.SwitchResourceLimitStatus{
+				UsedVLAN: "11",
+				Ports: map[string]*v1alpha1.PortUsage{
+					"default/port1": {VLANRange: "11"},
+				},
+			},
+			expectedUsedVLAN: "11",
+			expectedPorts:    1,
+		},
+		{
+			name:             "no usage",
+			expectedUsedVLAN: "",
+			expectedPorts:    0,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			_ = v1alpha1.AddToScheme(scheme)
+			client := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(&v1alpha1.SwitchPort{
+				ObjectMeta: metav1.ObjectMeta{Name: "port0", Namespace: "default"},
+				Spec: v1alpha1.SwitchPortSpec{
+					Configuration: &v1alpha1.SwitchPortConfigurationReference{Name: "configuration", Namespace: "tenant"},
+				},
+				Status: v1alpha1.SwitchPortStatus{
+					State:         v1alpha1.SwitchPortActive,
+					Configuration: &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan10},
+				},
+			}).Build()
+
+			limit := &v1alpha1.SwitchResourceLimit{
+				ObjectMeta: metav1.ObjectMeta{Name: "user-limit", Namespace: "tenant"},
+				Status:     c.status,
+			}
+			err := seedUsage(context.Background(), client, limit)
+			if err != nil {
+				t.Fatalf("seed usage failed: %v", err)
+			}
+			if limit.Status.UsedVLAN != c.expectedUsedVLAN {
+				t.Errorf("expected used vlan: %q, got: %q", c.expectedUsedVLAN, limit.Status.UsedVLAN)
+			}
+			if len(limit.Status.Ports) != c.expectedPorts {
+				t.Errorf("expected %d ports, got: %+v", c.expectedPorts, limit.Status.Ports)
+			}
+		})
+	}
+}
@@ -43,7 +43,7 @@ spec:
   tenantLimits:
     \"user-1\":
       namespace: default
-      vlanRange: 1-100
+      vlanRange: 2-100
 ---
 apiVersion: metal3.io/v1alpha1
 kind: AnsibleSwitch
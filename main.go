@@ -18,17 +18,24 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"os"
+	"time"
 
-	reaper "github.com/ramr/go-reaper"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	metal3iov1alpha1 "github.com/Hellcatlk/network-operator/api/v1alpha1"
 	"github.com/Hellcatlk/network-operator/controllers"
+	"github.com/Hellcatlk/network-operator/pkg/backends/switches/ansible"
+	"github.com/Hellcatlk/network-operator/pkg/backends/switches/plugin"
+	"github.com/Hellcatlk/network-operator/pkg/health"
+	"github.com/Hellcatlk/network-operator/pkg/utils/lock"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -47,16 +54,55 @@ func init() {
 func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
+	var usageResyncPeriod time.Duration
+	var driftCheckInterval time.Duration
+	var auditInterval time.Duration
+	var maxConcurrentReconciles int
+	var maxOperationsPerSwitch int
+	var unreachableThreshold int
+	var unreachableMaxBackoff time.Duration
+	var runnerMode string
+	var runnerSocket string
+	var pluginDir string
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.DurationVar(&usageResyncPeriod, "usage-resync-period", controllers.UsageResyncTime,
+		"The interval of rebuilding the usage of SwitchResourceLimit from the actual state of SwitchPorts.")
+	flag.DurationVar(&driftCheckInterval, "drift-check-interval", time.Minute,
+		"The interval of checking whether the configuration on the device has been changed externally.")
+	flag.DurationVar(&auditInterval, "audit-interval", 10*time.Minute,
+		"The interval of auditing the unmanaged ports and the foreign VLANs of the whole switch, 0 disables the audit.")
+	flag.IntVar(&maxConcurrentReconciles, "max-concurrent-reconciles", 1,
+		"The maximum number of SwitchPorts which can be reconciled concurrently.")
+	flag.IntVar(&maxOperationsPerSwitch, "max-operations-per-switch", 1,
+		"The maximum number of mutating operations which can run on the same switch concurrently.")
+	flag.IntVar(&unreachableThreshold, "unreachable-threshold", 3,
+		"The number of consecutive failures after which a switch is considered unreachable.")
+	flag.DurationVar(&unreachableMaxBackoff, "unreachable-max-backoff", 5*time.Minute,
+		"The maximum backoff of probing an unreachable switch.")
+	flag.StringVar(&runnerMode, "runner", "exec",
+		"How the ansible backend runs network runner. "+
+			"\"exec\" forks a process for every operation, "+
+			"\"subprocess\" starts a long-lived service as a subprocess, "+
+			"\"sidecar\" uses the service started by a sidecar container.")
+	flag.StringVar(&runnerSocket, "runner-socket", "/tmp/network-runner.sock",
+		"The unix socket of the network runner service.")
+	flag.StringVar(&pluginDir, "plugin-dir", "/plugins",
+		"The directory of the executables of backend plugins which can be started by PluginSwitch.")
 	flag.Parse()
 
 	ctrl.SetLogger(zap.New(zap.UseDevMode(true)))
 
-	// Recycling zombie processes
-	go reaper.Reap()
+	// The orphaned processes of ansible are reaped by the init process of container,
+	// so the exit status of network runner is only waited by the manager
+	switch runnerMode {
+	case "exec", "subprocess", "sidecar":
+	default:
+		setupLog.Error(fmt.Errorf("invalid runner mode %q", runnerMode), "unable to start manager")
+		os.Exit(1)
+	}
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:             scheme,
@@ -64,24 +110,49 @@ func main() {
 		Port:               9443,
 		LeaderElection:     enableLeaderElection,
 		LeaderElectionID:   "7b519e70",
+		// Secrets and ConfigMaps are watched by metadata only, so they are read from the API server
+		ClientDisableCacheFor: []client.Object{&corev1.Secret{}, &corev1.ConfigMap{}},
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
+	ctx := ctrl.SetupSignalHandler()
+	if runnerMode == "subprocess" {
+		go ansible.RunService(ctx, "network-runner", runnerSocket, ctrl.Log.WithName("network-runner"))
+	}
+	if runnerMode != "exec" {
+		ansible.UseService(runnerSocket)
+	}
+	plugin.SetDirectory(pluginDir)
+	// The health of switches is shared by the Switch and SwitchPort controllers
+	tracker := health.New(unreachableThreshold, 10*time.Second, unreachableMaxBackoff)
+
+	if err = controllers.SetupIndexers(ctx, mgr); err != nil {
+		setupLog.Error(err, "unable to setup indexers")
+		os.Exit(1)
+	}
 	if err = (&controllers.SwitchReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("Switch"),
-		Scheme: mgr.GetScheme(),
+		Client:             mgr.GetClient(),
+		Log:                ctrl.Log.WithName("controllers").WithName("Switch"),
+		Scheme:             mgr.GetScheme(),
+		DriftCheckInterval: driftCheckInterval,
+		AuditInterval:      auditInterval,
+		Health:             tracker,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Switch")
 		os.Exit(1)
 	}
 	if err = (&controllers.SwitchPortReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("SwitchPort"),
-		Scheme: mgr.GetScheme(),
+		Client:                  mgr.GetClient(),
+		Log:                     ctrl.Log.WithName("controllers").WithName("SwitchPort"),
+		Scheme:                  mgr.GetScheme(),
+		DriftCheckInterval:      driftCheckInterval,
+		MaxConcurrentReconciles: maxConcurrentReconciles,
+		SwitchLock:              lock.New(maxOperationsPerSwitch),
+		Health:                  tracker,
+		Recorder:                mgr.GetEventRecorderFor("switchport-controller"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "SwitchPort")
 		os.Exit(1)
@@ -94,10 +165,20 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "SwitchResource")
 		os.Exit(1)
 	}
+	if err = (&controllers.SwitchResourceLimitReconciler{
+		Client:       mgr.GetClient(),
+		Log:          ctrl.Log.WithName("controllers").WithName("SwitchResourceLimit"),
+		Scheme:       mgr.GetScheme(),
+		Recorder:     mgr.GetEventRecorderFor("switchresourcelimit-controller"),
+		ResyncPeriod: usageResyncPeriod,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SwitchResourceLimit")
+		os.Exit(1)
+	}
 	// +kubebuilder:scaffold:builder
 
 	setupLog.Info("starting manager")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	if err := mgr.Start(ctx); err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
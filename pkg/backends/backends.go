@@ -6,10 +6,11 @@ import (
 	"github.com/Hellcatlk/network-operator/api/v1alpha1"
 )
 
-// Switch is a interface for switch backend
+// Switch is a interface for switch backend, all of methods must honour the
+// deadline of context and return the errors typed by `Error`
 type Switch interface {
 	// IsAvailable check switch is available or not
-	IsAvailable() error
+	IsAvailable(ctx context.Context) error
 
 	// GetPortAttr get the port's configuration
 	GetPortAttr(ctx context.Context, port string) (*v1alpha1.SwitchPortConfigurationSpec, error)
@@ -19,4 +20,24 @@ type Switch interface {
 
 	// ResetPort remove all configure of the port
 	ResetPort(ctx context.Context, port string, configuration *v1alpha1.SwitchPortConfigurationSpec) error
+
+	// Capabilities return the fields of configuration supported by the switch
+	Capabilities(ctx context.Context) (*v1alpha1.SwitchCapabilities, error)
+}
+
+// Inventory is implemented by the switch backends which can read the whole
+// switch, it's used to audit the ports which aren't managed. The methods return
+// the errors typed by `Error` as same as Switch.
+type Inventory interface {
+	// ListPortVLANs return the VLANs of every port having VLANs, include the
+	// untagged VLAN
+	ListPortVLANs(ctx context.Context) (map[string][]int, error)
+
+	// ListVLANs return the VLANs existing on the switch
+	ListVLANs(ctx context.Context) ([]int, error)
+}
+
+// AllCapabilities return the capabilities supporting all of fields
+func AllCapabilities() *v1alpha1.SwitchCapabilities {
+	return &v1alpha1.SwitchCapabilities{ACLs: true, TaggedVLANs: true, NativeVLAN: true, Disable: true, MTU: true}
 }
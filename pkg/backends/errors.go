@@ -0,0 +1,80 @@
+package backends
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrorType is the type of backend error
+type ErrorType string
+
+const (
+	// Transient means the operation may succeed if retried, such as timeout and connection refused
+	Transient ErrorType = "transient"
+
+	// Authentication means the credentials are rejected by the switch
+	Authentication ErrorType = "authentication"
+
+	// Unsupported means the operation isn't supported by the backend or the switch
+	Unsupported ErrorType = "unsupported"
+
+	// InvalidConfiguration means the configuration can't be applied to the port
+	InvalidConfiguration ErrorType = "invalid configuration"
+
+	// PortNotFound means the port doesn't exist in the switch
+	PortNotFound ErrorType = "port not found"
+)
+
+// Error is the typed error returned by backends
+type Error struct {
+	Type ErrorType
+	Err  error
+}
+
+// Error implements error interface
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %v", e.Type, e.Err)
+}
+
+// Unwrap return the underlying error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// NewError return a typed error, return nil if err is nil
+func NewError(errorType ErrorType, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Type: errorType, Err: err}
+}
+
+// Errorf return a typed error according to a format specifier
+func Errorf(errorType ErrorType, format string, a ...interface{}) error {
+	return &Error{Type: errorType, Err: fmt.Errorf(format, a...)}
+}
+
+// TypeOf return the type of error, the untyped errors and the context
+// errors are treated as transient errors
+func TypeOf(err error) ErrorType {
+	if err == nil {
+		return ""
+	}
+
+	e := &Error{}
+	if errors.As(err, &e) {
+		return e.Type
+	}
+	return Transient
+}
+
+// IsTransient return true if the operation should be retried soon
+func IsTransient(err error) bool {
+	return err != nil && TypeOf(err) == Transient
+}
+
+// FromContext return a transient error if the context is done, otherwise return nil
+func FromContext(ctx context.Context) error {
+	return NewError(Transient, ctx.Err())
+}
@@ -0,0 +1,52 @@
+package backends
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestTypeOf(t *testing.T) {
+	cases := []struct {
+		name              string
+		err               error
+		expectedType      ErrorType
+		expectedTransient bool
+	}{
+		{
+			name:              "nil",
+			err:               nil,
+			expectedType:      "",
+			expectedTransient: false,
+		},
+		{
+			name:              "untyped error",
+			err:               errors.New("timeout"),
+			expectedType:      Transient,
+			expectedTransient: true,
+		},
+		{
+			name:              "typed error",
+			err:               Errorf(Authentication, "permission denied"),
+			expectedType:      Authentication,
+			expectedTransient: false,
+		},
+		{
+			name:              "wrapped typed error",
+			err:               fmt.Errorf("configure port: %w", NewError(PortNotFound, errors.New("eth1"))),
+			expectedType:      PortNotFound,
+			expectedTransient: false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if errorType := TypeOf(c.err); errorType != c.expectedType {
+				t.Errorf("expected type: %q, got: %q", c.expectedType, errorType)
+			}
+			if transient := IsTransient(c.err); transient != c.expectedTransient {
+				t.Errorf("expected transient: %v, got: %v", c.expectedTransient, transient)
+			}
+		})
+	}
+}
@@ -2,10 +2,8 @@ package ansible
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net"
-	"os/exec"
 	"strings"
 
 	"github.com/Hellcatlk/network-operator/api/v1alpha1"
@@ -26,14 +24,30 @@ func New(ctx context.Context, config *provider.SwitchConfiguration) (backends.Sw
 		return nil, fmt.Errorf("certificate of switch(%s) is nil", config.OS)
 	}
 
+	if !ustrings.SliceContains(supportedOS, config.OS) {
+		return nil, backends.Errorf(backends.InvalidConfiguration, "os %q isn't supported by ansible backend", config.OS)
+	}
+	for key := range config.Options {
+		if key != "bridge" {
+			return nil, backends.Errorf(backends.InvalidConfiguration, "unknown option %q of ansible backend", key)
+		}
+	}
+	bridge, _ := config.Options["bridge"].(string)
+	if config.OS == "openvswitch" && bridge == "" {
+		return nil, backends.Errorf(backends.InvalidConfiguration, "for openvswitch bridge is required")
+	}
+
 	return &ansible{
 		host:        config.Host,
 		credentials: config.Credentials,
 		os:          config.OS,
-		bridge:      config.Options["bridge"].(string),
+		bridge:      bridge,
 	}, nil
 }
 
+// supportedOS are the `ansible_network_os` supported by network runner
+var supportedOS = []string{"openvswitch", "junos", "nxos", "eos", "enos", "cumulus", "dellos10", "fos"}
+
 // ansible backend
 type ansible struct {
 	host        string
@@ -43,7 +57,7 @@ type ansible struct {
 }
 
 // IsAvailable check switch is available or not
-func (a *ansible) IsAvailable() error {
+func (a *ansible) IsAvailable(ctx context.Context) error {
 	config := &ssh.ClientConfig{
 		Auth: []ssh.AuthMethod{
 			ssh.Password(a.credentials.Password),
@@ -59,15 +73,33 @@ func (a *ansible) IsAvailable() error {
 	if !strings.Contains(address, ":") {
 		address = a.host + ":22"
 	}
-	client, err := ssh.Dial("tcp", address, config)
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
 	if err != nil {
-		return err
+		return backends.NewError(backends.Transient, err)
 	}
+	defer conn.Close()
+	// Interrupt the handshake when the context is done
+	if deadline, ok := ctx.Deadline(); ok {
+		err = conn.SetDeadline(deadline)
+		if err != nil {
+			return backends.NewError(backends.Transient, err)
+		}
+	}
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, address, config)
+	if err != nil {
+		if strings.Contains(err.Error(), "unable to authenticate") {
+			return backends.NewError(backends.Authentication, err)
+		}
+		return backends.NewError(backends.Transient, err)
+	}
+	client := ssh.NewClient(sshConn, chans, reqs)
 	defer client.Close()
 
 	session, err := client.NewSession()
 	if err != nil {
-		return err
+		return backends.NewError(backends.Transient, err)
 	}
 	defer session.Close()
 
@@ -76,7 +108,7 @@ func (a *ansible) IsAvailable() error {
 
 // GetPortAttr return the port's configuration
 func (a *ansible) GetPortAttr(ctx context.Context, port string) (*v1alpha1.SwitchPortConfigurationSpec, error) {
-	portConfiguration, err := a.getPortConf(port)
+	portConfiguration, err := a.getPortConf(ctx, port)
 	if err != nil {
 		return nil, err
 	}
@@ -89,25 +121,45 @@ func (a *ansible) GetPortAttr(ctx context.Context, port string) (*v1alpha1.Switc
 
 // SetPortAttr set the configuration to the port
 func (a *ansible) SetPortAttr(ctx context.Context, port string, configuration *v1alpha1.SwitchPortConfigurationSpec) error {
+	if configuration.MTU != nil {
+		return backends.Errorf(backends.Unsupported, "MTU isn't supported by ansible backend")
+	}
+	if len(configuration.ACLs) != 0 {
+		return backends.Errorf(backends.Unsupported, "ACLs aren't supported by ansible backend")
+	}
+	if configuration.Disable {
+		return backends.Errorf(backends.Unsupported, "disabling port isn't supported by ansible backend")
+	}
+
 	if configuration.TaggedVLANRange == "" {
-		return a.configureAccessPort(port, configuration.UntaggedVLAN)
+		return a.configureAccessPort(ctx, port, configuration.UntaggedVLAN)
 	}
 
 	vlans, err := ustrings.RangeToSlice(configuration.TaggedVLANRange)
 	if err != nil {
-		return err
+		return backends.NewError(backends.InvalidConfiguration, err)
 	}
-	return a.configureTrunkPort(port, configuration.UntaggedVLAN, vlans)
+	return a.configureTrunkPort(ctx, port, configuration.UntaggedVLAN, vlans)
 }
 
 // ResetPort clean the configuration in the port
 func (a *ansible) ResetPort(ctx context.Context, port string, configuration *v1alpha1.SwitchPortConfigurationSpec) error {
-	return a.deletePort(port)
+	return a.deletePort(ctx, port)
+}
+
+// Capabilities return the fields supported by network runner, the ACLs, admin
+// state and MTU of ports aren't configured by it
+func (a *ansible) Capabilities(ctx context.Context) (*v1alpha1.SwitchCapabilities, error) {
+	return &v1alpha1.SwitchCapabilities{TaggedVLANs: true, NativeVLAN: true}, nil
 }
 
+// networkRunnerData is the operation of network runner, see docs/network-runner.md
 type networkRunnerData struct {
-	Host        string                   `json:"host"`
-	Credentials *credentials.Credentials `json:"credentials"`
+	// Version is the version of the contract, it's set by runners
+	Version string `json:"version"`
+	Host    string `json:"host"`
+	// Credentials is omitted from the arguments of process, it's passed by stdin
+	Credentials *credentials.Credentials `json:"credentials,omitempty"`
 	OS          string                   `json:"os"`
 	// Bridge only use for openvswitch
 	Bridge       string `json:"bridge,omitempty"`
@@ -123,8 +175,8 @@ type portConfiguration struct {
 	TrunkedVLANs string `json:"trunked_vlans,omitempty"`
 }
 
-func (a *ansible) getPortConf(port string) (*portConfiguration, error) {
-	data, err := json.Marshal(networkRunnerData{
+func (a *ansible) getPortConf(ctx context.Context, port string) (*portConfiguration, error) {
+	result, err := defaultRunner.Run(ctx, &networkRunnerData{
 		Host:        a.host,
 		Credentials: a.credentials,
 		OS:          a.os,
@@ -135,29 +187,15 @@ func (a *ansible) getPortConf(port string) (*portConfiguration, error) {
 	if err != nil {
 		return nil, err
 	}
-
-	// Execute network runner
-	output, err := exec.Command("network-runner", string(data)).CombinedOutput() // #nosec
-	if err != nil && err.Error()[:4] != "wait" {
-		return nil, fmt.Errorf("%s[%s]", output, err)
-	}
-
-	// Find last json string from output
-	output, err = ustrings.LastJSON(string(output))
-	if err != nil {
-		return nil, err
-	}
-	portConfiguration := &portConfiguration{}
-	err = json.Unmarshal(output, portConfiguration)
-	if err != nil {
-		return nil, err
+	if result.PortConfiguration == nil {
+		return nil, backends.Errorf(backends.Transient, "network runner returned no port configuration")
 	}
 
-	return portConfiguration, nil
+	return result.PortConfiguration, nil
 }
 
-func (a *ansible) configureAccessPort(port string, untaggedVLAN *int) error {
-	data, err := json.Marshal(networkRunnerData{
+func (a *ansible) configureAccessPort(ctx context.Context, port string, untaggedVLAN *int) error {
+	_, err := defaultRunner.Run(ctx, &networkRunnerData{
 		Host:         a.host,
 		Credentials:  a.credentials,
 		OS:           a.os,
@@ -165,20 +203,11 @@ func (a *ansible) configureAccessPort(port string, untaggedVLAN *int) error {
 		Port:         port,
 		UntaggedVLAN: untaggedVLAN,
 	})
-	if err != nil {
-		return err
-	}
-
-	output, err := exec.Command("network-runner", string(data)).CombinedOutput() // #nosec
-	if err != nil && err.Error()[:4] != "wait" {
-		return fmt.Errorf("%s[%s]", output, err)
-	}
-
-	return nil
+	return err
 }
 
-func (a *ansible) configureTrunkPort(port string, untaggedVLAN *int, vlans []int) error {
-	data, err := json.Marshal(networkRunnerData{
+func (a *ansible) configureTrunkPort(ctx context.Context, port string, untaggedVLAN *int, vlans []int) error {
+	_, err := defaultRunner.Run(ctx, &networkRunnerData{
 		Host:         a.host,
 		Credentials:  a.credentials,
 		OS:           a.os,
@@ -187,20 +216,11 @@ func (a *ansible) configureTrunkPort(port string, untaggedVLAN *int, vlans []int
 		UntaggedVLAN: untaggedVLAN,
 		VLANs:        vlans,
 	})
-	if err != nil {
-		return err
-	}
-
-	output, err := exec.Command("network-runner", string(data)).CombinedOutput() // #nosec
-	if err != nil && err.Error()[:4] != "wait" {
-		return fmt.Errorf("%s[%s]", output, err)
-	}
-
-	return nil
+	return err
 }
 
-func (a *ansible) deletePort(port string) error {
-	data, err := json.Marshal(networkRunnerData{
+func (a *ansible) deletePort(ctx context.Context, port string) error {
+	_, err := defaultRunner.Run(ctx, &networkRunnerData{
 		Host:        a.host,
 		Credentials: a.credentials,
 		OS:          a.os,
@@ -208,14 +228,27 @@ func (a *ansible) deletePort(port string) error {
 		Operator:    "deletePort",
 		Port:        port,
 	})
-	if err != nil {
-		return err
-	}
+	return err
+}
 
-	output, err := exec.Command("network-runner", string(data)).CombinedOutput() // #nosec
-	if err != nil && err.Error()[:4] != "wait" {
-		return fmt.Errorf("%s[%s]", output, err)
+// classify return the type of error according to the output of network runner
+func classify(output string) backends.ErrorType {
+	output = strings.ToLower(output)
+	switch {
+	case strings.Contains(output, "authentication failed") ||
+		strings.Contains(output, "permission denied") ||
+		strings.Contains(output, "unable to authenticate"):
+		return backends.Authentication
+	case strings.Contains(output, "not supported") ||
+		strings.Contains(output, "unsupported"):
+		return backends.Unsupported
+	case strings.Contains(output, "no such port") ||
+		strings.Contains(output, "port not found") ||
+		strings.Contains(output, "invalid interface"):
+		return backends.PortNotFound
+	case strings.Contains(output, "invalid"):
+		return backends.InvalidConfiguration
+	default:
+		return backends.Transient
 	}
-
-	return nil
 }
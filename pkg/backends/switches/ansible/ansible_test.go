@@ -0,0 +1,105 @@
+package ansible
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Hellcatlk/network-operator/pkg/backends"
+	"github.com/Hellcatlk/network-operator/pkg/provider"
+	"github.com/Hellcatlk/network-operator/pkg/utils/credentials"
+)
+
+func TestClassify(t *testing.T) {
+	cases := []struct {
+		name         string
+		output       string
+		expectedType backends.ErrorType
+	}{
+		{
+			name:         "authentication",
+			output:       "fatal: [switch]: FAILED! => {\"msg\": \"Authentication failed.\"}",
+			expectedType: backends.Authentication,
+		},
+		{
+			name:         "unsupported",
+			output:       "the operation is not supported by the platform",
+			expectedType: backends.Unsupported,
+		},
+		{
+			name:         "port not found",
+			output:       "% Invalid interface format",
+			expectedType: backends.PortNotFound,
+		},
+		{
+			name:         "invalid configuration",
+			output:       "% Invalid input detected at '^' marker.",
+			expectedType: backends.InvalidConfiguration,
+		},
+		{
+			name:         "unknown",
+			output:       "fatal: [switch]: UNREACHABLE!",
+			expectedType: backends.Transient,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if errorType := classify(c.output); errorType != c.expectedType {
+				t.Errorf("expected type: %q, got: %q", c.expectedType, errorType)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	cases := []struct {
+		name              string
+		os                string
+		options           map[string]interface{}
+		expectedErrorType backends.ErrorType
+	}{
+		{
+			name:    "openvswitch with bridge",
+			os:      "openvswitch",
+			options: map[string]interface{}{"bridge": "br0"},
+		},
+		{
+			name:    "junos without options",
+			os:      "junos",
+			options: map[string]interface{}{},
+		},
+		{
+			name:              "openvswitch without bridge",
+			os:                "openvswitch",
+			options:           map[string]interface{}{},
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+		{
+			name:              "unsupported os",
+			os:                "vendor-os",
+			options:           map[string]interface{}{},
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+		{
+			name:              "unknown option",
+			os:                "junos",
+			options:           map[string]interface{}{"vrf": "management"},
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := New(context.Background(), &provider.SwitchConfiguration{
+				OS:          c.os,
+				Host:        "192.168.0.1",
+				Credentials: &credentials.Credentials{Username: "admin", Password: "admin"},
+				Backend:     "ansible",
+				Options:     c.options,
+			})
+			if errorType := backends.TypeOf(err); errorType != c.expectedErrorType {
+				t.Errorf("expected error type: %q, got: %v", c.expectedErrorType, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package ansible
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/Hellcatlk/network-operator/pkg/backends"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ProtocolVersion is the version of the JSON-RPC protocol spoken with the network runner service
+const ProtocolVersion string = "1"
+
+// rpcRequest is a JSON-RPC 2.0 request, one request per line
+type rpcRequest struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      uint64      `json:"id"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+	// Timeout is the seconds the service can spend on the request, 0 means no timeout
+	Timeout int `json:"timeout,omitempty"`
+}
+
+// rpcResponse is a JSON-RPC 2.0 response
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      uint64          `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int           `json:"code"`
+	Message string        `json:"message"`
+	Data    *rpcErrorData `json:"data,omitempty"`
+}
+
+// rpcErrorData is the detail of the failed operation
+type rpcErrorData struct {
+	// Log is the output of ansible
+	Log string `json:"log,omitempty"`
+}
+
+type versionResult struct {
+	Version string `json:"version"`
+}
+
+// rpcRunner sends the operations to the network runner service
+type rpcRunner struct {
+	socket string
+	lastID uint64
+}
+
+// Run sends the operation to the service, the connection is closed when the context is done
+func (r *rpcRunner) Run(ctx context.Context, data *networkRunnerData) (*runnerResult, error) {
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "unix", r.socket)
+	if err != nil {
+		return nil, backends.NewError(backends.Transient, err)
+	}
+	defer conn.Close()
+
+	// Interrupt the reading and writing when the context is done
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.SetDeadline(time.Now())
+		case <-stop:
+		}
+	}()
+
+	reader := bufio.NewReader(conn)
+	version := &versionResult{}
+	err = r.call(ctx, conn, reader, "version", nil, version)
+	if err != nil {
+		return nil, err
+	}
+	if version.Version != ProtocolVersion {
+		return nil, backends.Errorf(backends.Unsupported, "network runner service speaks protocol %q, expected %q", version.Version, ProtocolVersion)
+	}
+
+	// The socket is only accessible by manager, so the credentials are sent with the operation
+	payload := *data
+	payload.Version = ContractVersion
+	result := &runnerResult{}
+	err = r.call(ctx, conn, reader, data.Operator, &payload, result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// call sends a request and waits for the response with the same id
+func (r *rpcRunner) call(ctx context.Context, conn net.Conn, reader *bufio.Reader, method string, params interface{}, result interface{}) error {
+	request := rpcRequest{
+		JSONRPC: "2.0",
+		ID:      atomic.AddUint64(&r.lastID, 1),
+		Method:  method,
+		Params:  params,
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		request.Timeout = int(math.Ceil(time.Until(deadline).Seconds()))
+	}
+
+	err := json.NewEncoder(conn).Encode(request)
+	if err != nil {
+		return r.connectionError(ctx, err)
+	}
+	line, err := reader.ReadBytes('\n')
+	if err != nil {
+		return r.connectionError(ctx, err)
+	}
+
+	response := &rpcResponse{}
+	err = json.Unmarshal(line, response)
+	if err != nil {
+		return backends.NewError(backends.Transient, err)
+	}
+	if response.ID != request.ID {
+		return backends.Errorf(backends.Transient, "response id %d doesn't match request id %d", response.ID, request.ID)
+	}
+	if response.Error != nil {
+		// The type of error may only be found in the output of ansible, but the output is
+		// logged instead of being put in the error
+		output := response.Error.Message
+		if response.Error.Data != nil && response.Error.Data.Log != "" {
+			output += "\n" + response.Error.Data.Log
+			log.FromContext(ctx).Info("network runner failed", "method", method, "output", response.Error.Data.Log)
+		}
+		return backends.NewError(classify(output), errors.New(response.Error.Message))
+	}
+
+	err = json.Unmarshal(response.Result, result)
+	if err != nil {
+		return backends.NewError(backends.Transient, fmt.Errorf("invalid result of %s: %v", method, err))
+	}
+	return nil
+}
+
+// connectionError return the context error if the connection is interrupted by the context
+func (r *rpcRunner) connectionError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return backends.FromContext(ctx)
+	}
+	return backends.NewError(backends.Transient, err)
+}
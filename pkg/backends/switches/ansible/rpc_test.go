@@ -0,0 +1,136 @@
+package ansible
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/Hellcatlk/network-operator/pkg/backends"
+)
+
+// serveFake serves the JSON-RPC requests with the handler until the listener is closed
+func serveFake(t *testing.T, version string, handler func(request *rpcRequest) *rpcResponse) string {
+	socket := filepath.Join(t.TempDir(), "runner.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					request := &rpcRequest{}
+					if json.Unmarshal(scanner.Bytes(), request) != nil {
+						return
+					}
+					response := &rpcResponse{JSONRPC: "2.0", ID: request.ID}
+					if request.Method == "version" {
+						response.Result, _ = json.Marshal(versionResult{Version: version})
+					} else {
+						response = handler(request)
+					}
+					_ = json.NewEncoder(conn).Encode(response)
+				}
+			}(conn)
+		}
+	}()
+
+	return socket
+}
+
+func TestRPCRunner(t *testing.T) {
+	vlan := 10
+	cases := []struct {
+		name              string
+		version           string
+		operator          string
+		handler           func(request *rpcRequest) *rpcResponse
+		expectedResult    *runnerResult
+		expectedErrorType backends.ErrorType
+	}{
+		{
+			name:     "get port configuration",
+			version:  ProtocolVersion,
+			operator: "getPortConf",
+			handler: func(request *rpcRequest) *rpcResponse {
+				result, _ := json.Marshal(runnerResult{PortConfiguration: &portConfiguration{Mode: "access", VLAN: &vlan}})
+				return &rpcResponse{JSONRPC: "2.0", ID: request.ID, Result: result}
+			},
+			expectedResult: &runnerResult{PortConfiguration: &portConfiguration{Mode: "access", VLAN: &vlan}},
+		},
+		{
+			name:     "typed error",
+			version:  ProtocolVersion,
+			operator: "configAccessPort",
+			handler: func(request *rpcRequest) *rpcResponse {
+				return &rpcResponse{JSONRPC: "2.0", ID: request.ID, Error: &rpcError{Code: -32000, Message: "Authentication failed."}}
+			},
+			expectedErrorType: backends.Authentication,
+		},
+		{
+			name:     "typed error in log",
+			version:  ProtocolVersion,
+			operator: "configAccessPort",
+			handler: func(request *rpcRequest) *rpcResponse {
+				return &rpcResponse{JSONRPC: "2.0", ID: request.ID, Error: &rpcError{
+					Code:    -32000,
+					Message: "playbook failed",
+					Data:    &rpcErrorData{Log: "fatal: [network-operator]: {\"msg\": \"Authentication failed.\"}"},
+				}}
+			},
+			expectedErrorType: backends.Authentication,
+		},
+		{
+			name:     "mismatched id",
+			version:  ProtocolVersion,
+			operator: "deletePort",
+			handler: func(request *rpcRequest) *rpcResponse {
+				return &rpcResponse{JSONRPC: "2.0", ID: request.ID + 1, Result: json.RawMessage("{}")}
+			},
+			expectedErrorType: backends.Transient,
+		},
+		{
+			name:              "unsupported protocol version",
+			version:           "0",
+			operator:          "deletePort",
+			expectedErrorType: backends.Unsupported,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := &rpcRunner{socket: serveFake(t, c.version, c.handler)}
+			result, err := r.Run(context.Background(), &networkRunnerData{Operator: c.operator})
+			if errorType := backends.TypeOf(err); errorType != c.expectedErrorType {
+				t.Errorf("expected error type: %q, got: %v", c.expectedErrorType, err)
+			}
+			if c.expectedResult == nil {
+				return
+			}
+			expected, _ := json.Marshal(c.expectedResult)
+			got, _ := json.Marshal(result)
+			if string(expected) != string(got) {
+				t.Errorf("expected: %s, got: %s", expected, got)
+			}
+		})
+	}
+}
+
+func TestRPCRunnerUnavailable(t *testing.T) {
+	r := &rpcRunner{socket: filepath.Join(t.TempDir(), "not-existed.sock")}
+	_, err := r.Run(context.Background(), &networkRunnerData{Operator: "getPortConf"})
+	if !backends.IsTransient(err) {
+		t.Errorf("expected transient error, got: %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package ansible
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+
+	"github.com/Hellcatlk/network-operator/pkg/backends"
+	ustrings "github.com/Hellcatlk/network-operator/pkg/utils/strings"
+)
+
+// runner executes the operators of network runner
+type runner interface {
+	Run(ctx context.Context, data *networkRunnerData) (*runnerResult, error)
+}
+
+// runnerResult is the structured result of network runner
+type runnerResult struct {
+	// PortConfiguration is only returned by `getPortConf`
+	PortConfiguration *portConfiguration `json:"portConfiguration,omitempty"`
+}
+
+// ContractVersion is the version of the operation contract of network runner
+const ContractVersion string = "1"
+
+// defaultRunner is used by all of ansible backends, it's set before the controllers start
+var defaultRunner runner = &execRunner{}
+
+// UseExec makes the backend fork a network runner process for every operation
+func UseExec() {
+	defaultRunner = &execRunner{}
+}
+
+// UseService makes the backend send the operations to the network runner service
+// listening on the unix socket
+func UseService(socket string) {
+	defaultRunner = &rpcRunner{socket: socket}
+}
+
+// execRunner forks a network runner process for every operation
+type execRunner struct{}
+
+// Run executes network runner, the process is killed when the context is done
+func (r *execRunner) Run(ctx context.Context, data *networkRunnerData) (*runnerResult, error) {
+	// The arguments can be seen by anyone who can list processes, so the
+	// credentials are passed by stdin
+	payload := *data
+	payload.Version = ContractVersion
+	payload.Credentials = nil
+	input, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	stdin, err := json.Marshal(data.Credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.CommandContext(ctx, "network-runner", string(input)) // #nosec
+	cmd.Stdin = bytes.NewReader(stdin)
+	output, err := cmd.CombinedOutput()
+	// The process is killed when the context is done, its output and exit status
+	// can't be trusted even if it looks successful
+	if ctx.Err() != nil {
+		return nil, backends.FromContext(ctx)
+	}
+	// An exit status which isn't known, such as the process has been reaped by
+	// others, is an error too
+	if err != nil {
+		return nil, backends.NewError(classify(string(output)), fmt.Errorf("%s[%s]", output, err))
+	}
+
+	if data.Operator != "getPortConf" {
+		return &runnerResult{}, nil
+	}
+
+	// Find last json string from output
+	output, err = ustrings.LastJSON(string(output))
+	if err != nil {
+		return nil, backends.NewError(backends.Transient, err)
+	}
+	result := &runnerResult{PortConfiguration: &portConfiguration{}}
+	err = json.Unmarshal(output, result.PortConfiguration)
+	if err != nil {
+		return nil, backends.NewError(backends.Transient, err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,103 @@
+package ansible
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Hellcatlk/network-operator/pkg/utils/credentials"
+)
+
+// fakeNetworkRunner reports where the credentials are passed by the mode of port
+const fakeNetworkRunner = `#!/bin/sh
+case "$1" in
+*secret*) echo '{"mode": "argv"}'; exit 0;;
+esac
+case "$(cat)" in
+*secret*) echo '{"mode": "stdin"}';;
+*) echo '{"mode": "none"}';;
+esac
+`
+
+// prependPath puts the directory in front of PATH until the test finishes
+func prependPath(t *testing.T, dir string) {
+	path := os.Getenv("PATH")
+	t.Cleanup(func() { _ = os.Setenv("PATH", path) })
+	err := os.Setenv("PATH", dir+string(os.PathListSeparator)+path)
+	if err != nil {
+		t.Fatalf("set PATH failed: %v", err)
+	}
+}
+
+func TestExecRunnerCredentials(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "network-runner"), []byte(fakeNetworkRunner), 0700) // #nosec
+	if err != nil {
+		t.Fatalf("write fake network runner failed: %v", err)
+	}
+	prependPath(t, dir)
+
+	r := &execRunner{}
+	result, err := r.Run(context.Background(), &networkRunnerData{
+		Host:        "test",
+		Credentials: &credentials.Credentials{Username: "admin", Password: "secret"},
+		Operator:    "getPortConf",
+		Port:        "eth1",
+	})
+	if err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	if result.PortConfiguration.Mode != "stdin" {
+		t.Errorf("expected credentials passed by stdin, got: %s", result.PortConfiguration.Mode)
+	}
+}
+
+func TestExecRunnerResult(t *testing.T) {
+	cases := []struct {
+		name          string
+		script        string
+		timeout       time.Duration
+		expectedError bool
+	}{
+		{
+			name:   "success",
+			script: "#!/bin/sh\ncat >/dev/null\necho '{}'\n",
+		},
+		{
+			name:          "failed",
+			script:        "#!/bin/sh\ncat >/dev/null\necho 'FAILED'\nexit 2\n",
+			expectedError: true,
+		},
+		{
+			name:          "timed out",
+			script:        "#!/bin/sh\ncat >/dev/null\nexec sleep 10\n",
+			timeout:       time.Millisecond * 200,
+			expectedError: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir := t.TempDir()
+			err := os.WriteFile(filepath.Join(dir, "network-runner"), []byte(c.script), 0700) // #nosec
+			if err != nil {
+				t.Fatalf("write fake network runner failed: %v", err)
+			}
+			prependPath(t, dir)
+
+			ctx := context.Background()
+			if c.timeout != 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, c.timeout)
+				defer cancel()
+			}
+			r := &execRunner{}
+			_, err = r.Run(ctx, &networkRunnerData{Host: "test", Operator: "setPortConf", Port: "eth1"})
+			if (err != nil) != c.expectedError {
+				t.Errorf("expected error: %v, got: %v", c.expectedError, err)
+			}
+		})
+	}
+}
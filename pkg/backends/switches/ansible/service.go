@@ -0,0 +1,36 @@
+package ansible
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+// serviceRestartTime is the delay of restarting the network runner service after it exits
+const serviceRestartTime time.Duration = time.Second * 5
+
+// RunService runs network runner in serve mode as a subprocess listening on the
+// unix socket, and restarts it when it exits. It returns when the context is done.
+func RunService(ctx context.Context, command string, socket string, logger logr.Logger) {
+	for {
+		cmd := exec.CommandContext(ctx, command, "--serve", socket) // #nosec
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+
+		logger.Info("starting network runner service", "socket", socket)
+		err := cmd.Run()
+		if ctx.Err() != nil {
+			return
+		}
+		logger.Error(err, "network runner service exited, restart it later")
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(serviceRestartTime):
+		}
+	}
+}
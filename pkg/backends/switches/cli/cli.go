@@ -0,0 +1,237 @@
+// Package cli is the switch backend which drives the CLI of switch over SSH,
+// the commands are rendered by the Go templates of the OS and the outputs of
+// show commands are parsed by TextFSM, so a new OS can be supported by the
+// templates without code change.
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/Hellcatlk/network-operator/api/v1alpha1"
+	"github.com/Hellcatlk/network-operator/pkg/backends"
+	"github.com/Hellcatlk/network-operator/pkg/provider"
+	"github.com/Hellcatlk/network-operator/pkg/utils/credentials"
+	"golang.org/x/crypto/ssh"
+)
+
+// New return cli backend, the host is `<host>[:<port>]` whose port is 22 by
+// default
+func New(ctx context.Context, config *provider.SwitchConfiguration) (backends.Switch, error) {
+	if config == nil {
+		return nil, fmt.Errorf("configure of switch is nil")
+	}
+	if config.Credentials == nil {
+		return nil, backends.Errorf(backends.InvalidConfiguration, "credentials of cli backend are required")
+	}
+	if config.Host == "" {
+		return nil, backends.Errorf(backends.InvalidConfiguration, "host of cli backend is empty")
+	}
+
+	c := &cli{address: config.Host, credentials: config.Credentials}
+	if _, _, err := net.SplitHostPort(config.Host); err != nil {
+		c.address = net.JoinHostPort(config.Host, "22")
+	}
+
+	data := map[string]string{}
+	for key, value := range config.Options {
+		switch key {
+		case "templates":
+			// The templates are the data of ConfigMap
+			switch v := value.(type) {
+			case map[string]string:
+				data = v
+			case map[string]interface{}:
+				for name, text := range v {
+					data[name] = fmt.Sprint(text)
+				}
+			default:
+				return nil, backends.Errorf(backends.InvalidConfiguration, "templates of cli backend aren't a map of strings")
+			}
+		case "hostKey":
+			key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(fmt.Sprint(value)))
+			if err != nil {
+				return nil, backends.Errorf(backends.InvalidConfiguration, "invalid hostKey of cli backend: %v", err)
+			}
+			c.hostKey = key
+		default:
+			return nil, backends.Errorf(backends.InvalidConfiguration, "unknown option %q of cli backend", key)
+		}
+	}
+
+	var err error
+	c.templates, err = loadTemplates(config.OS, data)
+	if err != nil {
+		return nil, backends.NewError(backends.InvalidConfiguration, err)
+	}
+	return c, nil
+}
+
+// cli backend
+type cli struct {
+	address     string
+	credentials *credentials.Credentials
+	// hostKey verifies the switch, the switch isn't verified if it's nil
+	hostKey   ssh.PublicKey
+	templates *templates
+}
+
+// IsAvailable check the shell of switch can be opened
+func (c *cli) IsAvailable(ctx context.Context) error {
+	s, err := c.setup(ctx)
+	if err != nil {
+		return err
+	}
+	s.Close()
+	return nil
+}
+
+// GetPortAttr return the configuration of port parsed from the output of
+// show commands
+func (c *cli) GetPortAttr(ctx context.Context, port string) (*v1alpha1.SwitchPortConfigurationSpec, error) {
+	s, err := c.setup(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+
+	output, err := c.show(ctx, s, port)
+	if err != nil {
+		return nil, err
+	}
+	return c.templates.readConfiguration(port, output)
+}
+
+// SetPortAttr run the apply commands, the port is checked by the show
+// commands first
+func (c *cli) SetPortAttr(ctx context.Context, port string, configuration *v1alpha1.SwitchPortConfigurationSpec) error {
+	data, err := newPortData(port, configuration)
+	if err != nil {
+		return err
+	}
+	cmds, err := commands(c.templates.apply, data)
+	if err != nil {
+		return err
+	}
+	return c.configure(ctx, port, cmds)
+}
+
+// ResetPort run the reset commands, the port is checked by the show commands
+// first
+func (c *cli) ResetPort(ctx context.Context, port string, configuration *v1alpha1.SwitchPortConfigurationSpec) error {
+	data, err := newPortData(port, configuration)
+	if err != nil {
+		return err
+	}
+	cmds, err := commands(c.templates.reset, data)
+	if err != nil {
+		return err
+	}
+	return c.configure(ctx, port, cmds)
+}
+
+// Capabilities return the fields supported by the templates
+func (c *cli) Capabilities(ctx context.Context) (*v1alpha1.SwitchCapabilities, error) {
+	capabilities := *c.templates.capabilities
+	return &capabilities, nil
+}
+
+// setup opens the shell and runs the setup commands
+func (c *cli) setup(ctx context.Context) (*shell, error) {
+	cmds, err := commands(c.templates.setup, nil)
+	if err != nil {
+		return nil, err
+	}
+	s, err := c.openShell(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, cmd := range cmds {
+		_, err = c.run(ctx, s, cmd)
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// show return the output of show commands, it return PortNotFound if the
+// output matches the portNotFound regexp
+func (c *cli) show(ctx context.Context, s *shell, port string) (string, error) {
+	cmds, err := commands(c.templates.show, &portData{Port: port})
+	if err != nil {
+		return "", err
+	}
+	outputs := []string{}
+	for _, cmd := range cmds {
+		output, err := s.Run(ctx, cmd)
+		if err != nil {
+			return "", err
+		}
+		if line := matchedLine(c.templates.portNotFound, output); line != "" {
+			return "", backends.Errorf(backends.PortNotFound, "port %s isn't found by %q: %s", port, cmd, line)
+		}
+		err = c.check(cmd, output)
+		if err != nil {
+			return "", err
+		}
+		outputs = append(outputs, output)
+	}
+	return strings.Join(outputs, ""), nil
+}
+
+// configure runs the commands after checking the port, the commands after
+// the failed one aren't run
+func (c *cli) configure(ctx context.Context, port string, cmds []string) error {
+	s, err := c.setup(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	_, err = c.show(ctx, s, port)
+	if err != nil {
+		return err
+	}
+	for _, cmd := range cmds {
+		_, err = c.run(ctx, s, cmd)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// run runs the command and checks its output
+func (c *cli) run(ctx context.Context, s *shell, cmd string) (string, error) {
+	output, err := s.Run(ctx, cmd)
+	if err != nil {
+		return "", err
+	}
+	return output, c.check(cmd, output)
+}
+
+// check return InvalidConfiguration if the output matches the errors regexp
+func (c *cli) check(cmd string, output string) error {
+	if line := matchedLine(c.templates.errors, output); line != "" {
+		return backends.Errorf(backends.InvalidConfiguration, "command %q failed: %s", cmd, line)
+	}
+	return nil
+}
+
+// matchedLine return the first line of output which matches the regexp
+func matchedLine(r *regexp.Regexp, output string) string {
+	if r == nil {
+		return ""
+	}
+	for _, line := range strings.Split(output, "\n") {
+		if r.MatchString(line) {
+			return strings.TrimSpace(line)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,462 @@
+package cli
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/Hellcatlk/network-operator/api/v1alpha1"
+	"github.com/Hellcatlk/network-operator/pkg/backends"
+	"github.com/Hellcatlk/network-operator/pkg/backends/switches/cli/clitest"
+	"github.com/Hellcatlk/network-operator/pkg/provider"
+	"github.com/Hellcatlk/network-operator/pkg/utils/credentials"
+	"golang.org/x/crypto/ssh"
+)
+
+// serve starts the stand-in and return the backend verifying its host key
+func serve(t *testing.T, server *clitest.Server, cert *credentials.Credentials, options map[string]interface{}) backends.Switch {
+	address, err := server.Start("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("start server failed: %v", err)
+	}
+	t.Cleanup(func() { server.Close() })
+
+	if options == nil {
+		options = map[string]interface{}{"hostKey": string(ssh.MarshalAuthorizedKey(server.HostKey()))}
+	}
+	backend, err := New(context.Background(), &provider.SwitchConfiguration{
+		OS:          "ios",
+		Host:        address,
+		Backend:     "cli",
+		Credentials: cert,
+		Options:     options,
+	})
+	if err != nil {
+		t.Fatalf("new backend failed: %v", err)
+	}
+	return backend
+}
+
+func TestCLI(t *testing.T) {
+	vlan := 10
+	mtu := 9000
+	cases := []struct {
+		name              string
+		configuration     *v1alpha1.SwitchPortConfigurationSpec
+		expectedInterface *clitest.Interface
+	}{
+		{
+			name:              "access port",
+			configuration:     &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan},
+			expectedInterface: &clitest.Interface{Mode: "access", AccessVLAN: 10, NativeVLAN: 1, AllowedVLANs: "ALL"},
+		},
+		{
+			name:              "disabled trunk port",
+			configuration:     &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan, TaggedVLANRange: "11-12", Disable: true},
+			expectedInterface: &clitest.Interface{Mode: "trunk", AccessVLAN: 1, NativeVLAN: 10, AllowedVLANs: "10-12", Shutdown: true},
+		},
+		{
+			name:              "trunk port without untagged VLAN",
+			configuration:     &v1alpha1.SwitchPortConfigurationSpec{TaggedVLANRange: "11,13"},
+			expectedInterface: &clitest.Interface{Mode: "trunk", AccessVLAN: 1, NativeVLAN: 1, AllowedVLANs: "11,13"},
+		},
+		{
+			name:              "port without VLAN",
+			configuration:     &v1alpha1.SwitchPortConfigurationSpec{},
+			expectedInterface: &clitest.Interface{Mode: "trunk", AccessVLAN: 1, NativeVLAN: 1, AllowedVLANs: "NONE"},
+		},
+		{
+			name:              "MTU",
+			configuration:     &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan, MTU: &mtu},
+			expectedInterface: &clitest.Interface{Mode: "access", AccessVLAN: 10, NativeVLAN: 1, AllowedVLANs: "ALL", MTU: 9000},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server, err := clitest.NewServer("admin", "password", "GigabitEthernet1/0/1", "GigabitEthernet1/0/2")
+			if err != nil {
+				t.Fatalf("new server failed: %v", err)
+			}
+			backend := serve(t, server, &credentials.Credentials{Username: "admin", Password: "password"}, nil)
+
+			err = backend.IsAvailable(context.Background())
+			if err != nil {
+				t.Fatalf("IsAvailable failed: %v", err)
+			}
+
+			// Configure twice, the second one replaces the first one
+			err = backend.SetPortAttr(context.Background(), "GigabitEthernet1/0/1", &v1alpha1.SwitchPortConfigurationSpec{TaggedVLANRange: "20"})
+			if err != nil {
+				t.Fatalf("SetPortAttr failed: %v", err)
+			}
+			err = backend.SetPortAttr(context.Background(), "GigabitEthernet1/0/1", c.configuration)
+			if err != nil {
+				t.Fatalf("SetPortAttr failed: %v", err)
+			}
+			if i := server.Interface("GigabitEthernet1/0/1"); !reflect.DeepEqual(i, c.expectedInterface) {
+				t.Errorf("expected interface: %+v, got: %+v", c.expectedInterface, i)
+			}
+
+			configuration, err := backend.GetPortAttr(context.Background(), "GigabitEthernet1/0/1")
+			if err != nil {
+				t.Fatalf("GetPortAttr failed: %v", err)
+			}
+			if !c.configuration.IsEqual(configuration) {
+				t.Errorf("expected configuration: %+v, got: %+v", c.configuration, configuration)
+			}
+
+			err = backend.ResetPort(context.Background(), "GigabitEthernet1/0/1", c.configuration)
+			if err != nil {
+				t.Fatalf("ResetPort failed: %v", err)
+			}
+			if i := server.Interface("GigabitEthernet1/0/1"); !reflect.DeepEqual(i, server.Interface("GigabitEthernet1/0/2")) {
+				t.Errorf("expected interface is reset, got: %+v", i)
+			}
+			if vlans := server.VLANs(); vlans[len(vlans)-1] != 20 {
+				t.Errorf("expected VLANs are kept, got: %v", vlans)
+			}
+		})
+	}
+}
+
+func TestGetPortAttr(t *testing.T) {
+	vlan1 := 1
+	vlan10 := 10
+	mtu := 9000
+	cases := []struct {
+		name                  string
+		cmds                  []string
+		expectedConfiguration *v1alpha1.SwitchPortConfigurationSpec
+	}{
+		{
+			name:                  "default port",
+			expectedConfiguration: &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan1},
+		},
+		{
+			name:                  "access port",
+			cmds:                  []string{"switchport access vlan 10", "mtu 9000"},
+			expectedConfiguration: &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan10, MTU: &mtu},
+		},
+		{
+			name:                  "trunk port allowing all VLANs",
+			cmds:                  []string{"switchport mode trunk", "shutdown"},
+			expectedConfiguration: &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan1, TaggedVLANRange: "2-4094", Disable: true},
+		},
+		{
+			name:                  "native VLAN isn't allowed",
+			cmds:                  []string{"switchport mode trunk", "switchport trunk native vlan 10", "switchport trunk allowed vlan 20-21"},
+			expectedConfiguration: &v1alpha1.SwitchPortConfigurationSpec{TaggedVLANRange: "20-21"},
+		},
+		{
+			name:                  "allowed VLANs are added",
+			cmds:                  []string{"switchport mode trunk", "switchport trunk allowed vlan 10", "switchport trunk allowed vlan add 12,11"},
+			expectedConfiguration: &v1alpha1.SwitchPortConfigurationSpec{TaggedVLANRange: "10-12"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server, err := clitest.NewServer("admin", "password", "GigabitEthernet1")
+			if err != nil {
+				t.Fatalf("new server failed: %v", err)
+			}
+			err = server.Configure(append([]string{"interface GigabitEthernet1"}, c.cmds...)...)
+			if err != nil {
+				t.Fatalf("configure failed: %v", err)
+			}
+			backend := serve(t, server, &credentials.Credentials{Username: "admin", Password: "password"}, nil)
+			configuration, err := backend.GetPortAttr(context.Background(), "GigabitEthernet1")
+			if err != nil {
+				t.Fatalf("GetPortAttr failed: %v", err)
+			}
+			if !reflect.DeepEqual(configuration, c.expectedConfiguration) {
+				t.Errorf("expected configuration: %+v, got: %+v", c.expectedConfiguration, configuration)
+			}
+		})
+	}
+}
+
+// TestTemplates checks the templates of an OS which isn't builtin
+func TestTemplates(t *testing.T) {
+	server, err := clitest.NewServer("admin", "password", "GigabitEthernet1")
+	if err != nil {
+		t.Fatalf("new server failed: %v", err)
+	}
+	address, err := server.Start("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("start server failed: %v", err)
+	}
+	defer server.Close()
+
+	backend, err := New(context.Background(), &provider.SwitchConfiguration{
+		OS:          "custom",
+		Host:        address,
+		Backend:     "cli",
+		Credentials: &credentials.Credentials{Username: "admin", Password: "password"},
+		Options: map[string]interface{}{"templates": map[string]string{
+			"prompt": `^\S+#$`,
+			"apply":  "configure terminal\ninterface {{.Port}}\nswitchport access vlan {{.UntaggedVLAN}}\nend",
+			"reset":  "configure terminal\ninterface {{.Port}}\nno switchport access vlan\nend",
+			"show":   "show running-config interface {{.Port}}",
+			"parse":  "Value AccessVLAN (\\d+)\n\nStart\n  ^\\s+switchport access vlan ${AccessVLAN}\n",
+			"read":   "untaggedVLAN: {{or .Values.AccessVLAN 1}}",
+		}},
+	})
+	if err != nil {
+		t.Fatalf("new backend failed: %v", err)
+	}
+
+	vlan := 10
+	err = backend.SetPortAttr(context.Background(), "GigabitEthernet1", &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan})
+	if err != nil {
+		t.Fatalf("SetPortAttr failed: %v", err)
+	}
+	configuration, err := backend.GetPortAttr(context.Background(), "GigabitEthernet1")
+	if err != nil {
+		t.Fatalf("GetPortAttr failed: %v", err)
+	}
+	if configuration.UntaggedVLAN == nil || *configuration.UntaggedVLAN != vlan {
+		t.Errorf("expected untagged VLAN: %d, got: %+v", vlan, configuration)
+	}
+	for _, cmd := range server.Commands() {
+		if cmd == "terminal length 0" {
+			t.Errorf("expected no setup commands, got: %v", server.Commands())
+		}
+	}
+}
+
+func TestCLIError(t *testing.T) {
+	vlan := 4095
+	mtu := 1000
+	cases := []struct {
+		name              string
+		port              string
+		configuration     *v1alpha1.SwitchPortConfigurationSpec
+		credentials       *credentials.Credentials
+		options           map[string]interface{}
+		expectedErrorType backends.ErrorType
+	}{
+		{
+			name:              "port not found",
+			port:              "GigabitEthernet9",
+			configuration:     &v1alpha1.SwitchPortConfigurationSpec{},
+			expectedErrorType: backends.PortNotFound,
+		},
+		{
+			name:              "vlan out of range",
+			port:              "GigabitEthernet1",
+			configuration:     &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan},
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+		{
+			name:              "MTU rejected by switch",
+			port:              "GigabitEthernet1",
+			configuration:     &v1alpha1.SwitchPortConfigurationSpec{MTU: &mtu},
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+		{
+			name:              "ACLs",
+			port:              "GigabitEthernet1",
+			configuration:     &v1alpha1.SwitchPortConfigurationSpec{ACLs: []v1alpha1.ACL{{IPVersion: "4", Action: "deny", Protocol: "ALL"}}},
+			expectedErrorType: backends.Unsupported,
+		},
+		{
+			name:              "wrong password",
+			port:              "GigabitEthernet1",
+			configuration:     &v1alpha1.SwitchPortConfigurationSpec{},
+			credentials:       &credentials.Credentials{Username: "admin", Password: "wrong"},
+			expectedErrorType: backends.Authentication,
+		},
+		{
+			name:              "host key mismatch",
+			port:              "GigabitEthernet1",
+			configuration:     &v1alpha1.SwitchPortConfigurationSpec{},
+			options:           map[string]interface{}{"hostKey": "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl"},
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server, err := clitest.NewServer("admin", "password", "GigabitEthernet1")
+			if err != nil {
+				t.Fatalf("new server failed: %v", err)
+			}
+			cert := c.credentials
+			if cert == nil {
+				cert = &credentials.Credentials{Username: "admin", Password: "password"}
+			}
+			backend := serve(t, server, cert, c.options)
+			err = backend.SetPortAttr(context.Background(), c.port, c.configuration)
+			if errorType := backends.TypeOf(err); errorType != c.expectedErrorType {
+				t.Errorf("expected error type: %q, got: %v", c.expectedErrorType, err)
+			}
+		})
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	cases := []struct {
+		name                 string
+		os                   string
+		templates            map[string]string
+		expectedCapabilities *v1alpha1.SwitchCapabilities
+	}{
+		{
+			name:                 "builtin templates",
+			os:                   "ios",
+			expectedCapabilities: &v1alpha1.SwitchCapabilities{TaggedVLANs: true, NativeVLAN: true, Disable: true, MTU: true},
+		},
+		{
+			name:                 "capabilities replacing the builtin ones",
+			os:                   "ios",
+			templates:            map[string]string{"capabilities": "taggedVLANs: true"},
+			expectedCapabilities: &v1alpha1.SwitchCapabilities{TaggedVLANs: true},
+		},
+		{
+			name: "templates without capabilities",
+			os:   "vendor-os",
+			templates: map[string]string{
+				"prompt": "#$", "apply": "a", "reset": "r", "show": "s", "parse": "Start", "read": "{}",
+			},
+			expectedCapabilities: backends.AllCapabilities(),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			backend, err := New(context.Background(), &provider.SwitchConfiguration{
+				OS: c.os, Host: "192.168.0.1", Backend: "cli",
+				Credentials: &credentials.Credentials{Username: "admin", Password: "password"},
+				Options:     map[string]interface{}{"templates": c.templates},
+			})
+			if err != nil {
+				t.Fatalf("new backend failed: %v", err)
+			}
+			capabilities, err := backend.Capabilities(context.Background())
+			if err != nil {
+				t.Fatalf("Capabilities failed: %v", err)
+			}
+			if !reflect.DeepEqual(capabilities, c.expectedCapabilities) {
+				t.Errorf("expected capabilities: %+v, got: %+v", c.expectedCapabilities, capabilities)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	cases := []struct {
+		name               string
+		os                 string
+		host               string
+		options            map[string]interface{}
+		withoutCredentials bool
+		expectedAddress    string
+		expectedErrorType  backends.ErrorType
+	}{
+		{
+			name:            "host",
+			os:              "ios",
+			host:            "192.168.0.1",
+			expectedAddress: "192.168.0.1:22",
+		},
+		{
+			name:            "host with port",
+			os:              "ios-xe",
+			host:            "switch.example.com:2222",
+			expectedAddress: "switch.example.com:2222",
+		},
+		{
+			name:            "IPv6 host",
+			os:              "ios",
+			host:            "fd00::1",
+			expectedAddress: "[fd00::1]:22",
+		},
+		{
+			name:              "empty host",
+			os:                "ios",
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+		{
+			name:               "without credentials",
+			os:                 "ios",
+			host:               "192.168.0.1",
+			withoutCredentials: true,
+			expectedErrorType:  backends.InvalidConfiguration,
+		},
+		{
+			name:              "OS without templates",
+			os:                "junos",
+			host:              "192.168.0.1",
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+		{
+			name:            "templates replacing the builtin ones",
+			os:              "ios",
+			host:            "192.168.0.1",
+			options:         map[string]interface{}{"templates": map[string]interface{}{"setup": "terminal length 0\nterminal width 0"}},
+			expectedAddress: "192.168.0.1:22",
+		},
+		{
+			name:              "invalid template",
+			os:                "ios",
+			host:              "192.168.0.1",
+			options:           map[string]interface{}{"templates": map[string]string{"apply": "{{.Port"}},
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+		{
+			name:              "unknown template",
+			os:                "ios",
+			host:              "192.168.0.1",
+			options:           map[string]interface{}{"templates": map[string]string{"delete": "no interface {{.Port}}"}},
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+		{
+			name:              "invalid templates",
+			os:                "ios",
+			host:              "192.168.0.1",
+			options:           map[string]interface{}{"templates": "apply"},
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+		{
+			name:              "invalid hostKey",
+			os:                "ios",
+			host:              "192.168.0.1",
+			options:           map[string]interface{}{"hostKey": "ssh-ed25519"},
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+		{
+			name:              "invalid capabilities",
+			os:                "ios",
+			host:              "192.168.0.1",
+			options:           map[string]interface{}{"templates": map[string]string{"capabilities": "vlan: true"}},
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+		{
+			name:              "unknown option",
+			os:                "ios",
+			host:              "192.168.0.1",
+			options:           map[string]interface{}{"database": "4"},
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cert := &credentials.Credentials{Username: "admin", Password: "password"}
+			if c.withoutCredentials {
+				cert = nil
+			}
+			backend, err := New(context.Background(), &provider.SwitchConfiguration{
+				OS: c.os, Host: c.host, Backend: "cli", Credentials: cert, Options: c.options,
+			})
+			if errorType := backends.TypeOf(err); errorType != c.expectedErrorType {
+				t.Errorf("expected error type: %q, got: %v", c.expectedErrorType, err)
+			}
+			if err == nil && backend.(*cli).address != c.expectedAddress {
+				t.Errorf("expected address: %s, got: %s", c.expectedAddress, backend.(*cli).address)
+			}
+		})
+	}
+}
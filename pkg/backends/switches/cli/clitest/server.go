@@ -0,0 +1,828 @@
+// Package clitest provides an SSH server emulating the CLI of Cisco IOS on
+// in-memory interfaces and VLANs, it serves the interactive shell and the exec
+// requests of SSH.
+package clitest
+
+import (
+	"bufio"
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	ustrings "github.com/Hellcatlk/network-operator/pkg/utils/strings"
+	"golang.org/x/crypto/ssh"
+)
+
+// Interface is the configuration of an interface
+type Interface struct {
+	// Mode is `access`, `trunk` or `routed` after `no switchport`
+	Mode       string
+	AccessVLAN int
+	NativeVLAN int
+	// AllowedVLANs is `ALL`, `NONE` or the range of VLANs
+	AllowedVLANs string
+	Shutdown     bool
+	// MTU is 0 if it isn't set
+	MTU int
+}
+
+// Server is the SSH server emulating the CLI
+type Server struct {
+	mutex      sync.Mutex
+	hostname   string
+	config     *ssh.ServerConfig
+	hostKey    ssh.PublicKey
+	interfaces map[string]*Interface
+	vlans      map[int]string
+	listener   net.Listener
+	// commands are the commands run by the clients
+	commands []string
+}
+
+// NewServer return a server whose interfaces are the access ports of the
+// default VLAN, the clients are authenticated by the username and password
+func NewServer(username string, password string, interfaces ...string) (*Server, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		hostname:   "switch",
+		hostKey:    signer.PublicKey(),
+		interfaces: map[string]*Interface{},
+		vlans:      map[int]string{1: "default"},
+	}
+	s.config = &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, p []byte) (*ssh.Permissions, error) {
+			if conn.User() == username && string(p) == password {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("password rejected for %s", conn.User())
+		},
+	}
+	s.config.AddHostKey(signer)
+	for _, name := range interfaces {
+		s.interfaces[name] = defaultInterface()
+	}
+	return s, nil
+}
+
+func defaultInterface() *Interface {
+	return &Interface{Mode: "access", AccessVLAN: 1, NativeVLAN: 1, AllowedVLANs: "ALL"}
+}
+
+// HostKey return the public key of server
+func (s *Server) HostKey() ssh.PublicKey {
+	return s.hostKey
+}
+
+// SetHostKey replaces the generated host key, it must be called before Start
+func (s *Server) SetHostKey(signer ssh.Signer) {
+	s.config = &ssh.ServerConfig{PasswordCallback: s.config.PasswordCallback}
+	s.config.AddHostKey(signer)
+	s.hostKey = signer.PublicKey()
+}
+
+// SetHostname sets the hostname in the prompt, it's `switch` by default
+func (s *Server) SetHostname(hostname string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.hostname = hostname
+}
+
+// Start listens on the address and serves the connections in background, it
+// return the address listened
+func (s *Server) Start(address string) (string, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return "", err
+	}
+	s.listener = listener
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serveConn(conn)
+		}
+	}()
+	return listener.Addr().String(), nil
+}
+
+// Close stops listening, the connections aren't closed
+func (s *Server) Close() error {
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Close()
+}
+
+// Interface return a copy of the interface, it's nil if the interface doesn't
+// exist
+func (s *Server) Interface(name string) *Interface {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	i, ok := s.interfaces[name]
+	if !ok {
+		return nil
+	}
+	copied := *i
+	return &copied
+}
+
+// VLANs return the VLANs in order
+func (s *Server) VLANs() []int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	vlans := []int{}
+	for vid := range s.vlans {
+		vlans = append(vlans, vid)
+	}
+	sort.Ints(vlans)
+	return vlans
+}
+
+// Commands return the commands run by the clients
+func (s *Server) Commands() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]string{}, s.commands...)
+}
+
+// Configure runs the commands in the global configuration mode, it return the
+// error printed by the failed command
+func (s *Server) Configure(cmds ...string) error {
+	cli := &cli{server: s, mode: "config"}
+	for _, cmd := range cmds {
+		output := cli.run(cmd)
+		if strings.HasPrefix(output, "%") {
+			return fmt.Errorf("%s: %s", cmd, strings.TrimSpace(output))
+		}
+	}
+	return nil
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	sshConn, chans, reqs, err := ssh.NewServerConn(conn, s.config)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	defer sshConn.Close()
+	go ssh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+		go s.serveSession(channel, requests)
+	}
+}
+
+// serveSession serves the shell or the exec request of session
+func (s *Server) serveSession(channel ssh.Channel, requests <-chan *ssh.Request) {
+	defer channel.Close()
+	for req := range requests {
+		switch req.Type {
+		case "pty-req", "env", "window-change":
+			_ = req.Reply(true, nil)
+		case "shell":
+			_ = req.Reply(true, nil)
+			go ssh.DiscardRequests(requests)
+			s.shell(channel)
+			_, _ = channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{0}))
+			return
+		case "exec":
+			payload := struct{ Command string }{}
+			err := ssh.Unmarshal(req.Payload, &payload)
+			_ = req.Reply(err == nil, nil)
+			if err != nil {
+				return
+			}
+			cli := &cli{server: s, mode: "exec"}
+			for _, cmd := range strings.Split(payload.Command, ";") {
+				_, _ = io.WriteString(channel, crlf(cli.run(strings.TrimSpace(cmd))))
+			}
+			_, _ = channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{0}))
+			return
+		default:
+			_ = req.Reply(false, nil)
+		}
+	}
+}
+
+// shell serves the interactive shell, the commands are echoed like a switch
+func (s *Server) shell(channel ssh.Channel) {
+	cli := &cli{server: s, mode: "exec"}
+	_, _ = io.WriteString(channel, cli.prompt())
+	reader := &lineReader{reader: bufio.NewReader(channel)}
+	for {
+		cmd, err := reader.readLine()
+		if err != nil {
+			return
+		}
+		_, _ = io.WriteString(channel, cmd+"\r\n")
+		output := cli.run(cmd)
+		if cli.exited {
+			return
+		}
+		_, _ = io.WriteString(channel, crlf(output)+cli.prompt())
+	}
+}
+
+// lineReader reads the lines ended by `\r`, `\n` or `\r\n`, the terminals
+// like ansible end the commands by `\r`
+type lineReader struct {
+	reader *bufio.Reader
+	// cr is set if the last line is ended by `\r`
+	cr bool
+}
+
+func (r *lineReader) readLine() (string, error) {
+	line := []byte{}
+	for {
+		b, err := r.reader.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		cr := r.cr
+		r.cr = false
+		switch {
+		case b == '\r':
+			r.cr = true
+			return string(line), nil
+		case b == '\n':
+			if cr && len(line) == 0 {
+				continue
+			}
+			return string(line), nil
+		case b >= ' ' || b == '\t':
+			line = append(line, b)
+		}
+	}
+}
+
+func crlf(output string) string {
+	return strings.ReplaceAll(output, "\n", "\r\n")
+}
+
+// cli is the state of a CLI session
+type cli struct {
+	server *Server
+	// mode is `exec`, `config`, `config-if` or `config-vlan`
+	mode string
+	// iface is the interface configured in `config-if`
+	iface string
+	// vlans are the VLANs configured in `config-vlan`
+	vlans  []int
+	exited bool
+}
+
+func (c *cli) prompt() string {
+	c.server.mutex.Lock()
+	defer c.server.mutex.Unlock()
+	if c.mode == "exec" {
+		return c.server.hostname + "#"
+	}
+	return c.server.hostname + "(" + c.mode + ")#"
+}
+
+const (
+	invalidInput = "% Invalid input detected at '^' marker.\n"
+	incomplete   = "% Incomplete command.\n"
+)
+
+// run runs the command and return its output, the output of show commands
+// can be filtered by `| include`, `| exclude`, `| begin` and `| section`
+func (c *cli) run(cmd string) string {
+	pipes := strings.Split(cmd, "|")
+	words := strings.Fields(pipes[0])
+	if len(words) == 0 {
+		return ""
+	}
+	c.server.mutex.Lock()
+	defer c.server.mutex.Unlock()
+	c.server.commands = append(c.server.commands, cmd)
+
+	switch c.mode {
+	case "exec":
+		output := c.exec(words)
+		if len(pipes) > 1 && match(words, "show") && !strings.HasPrefix(output, "%") {
+			return filter(output, pipes[1:])
+		}
+		return output
+	case "config-if":
+		if output, ok := c.configInterface(words); ok {
+			return output
+		}
+	case "config-vlan":
+		if output, ok := c.configVLAN(words); ok {
+			return output
+		}
+	}
+	// The commands of global configuration mode are accepted by the sub-modes
+	return c.config(words)
+}
+
+// match check the words start with the keywords, the keywords can be
+// abbreviated
+func match(words []string, keywords ...string) bool {
+	if len(words) < len(keywords) {
+		return false
+	}
+	for i, keyword := range keywords {
+		if !strings.HasPrefix(keyword, strings.ToLower(words[i])) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *cli) exec(words []string) string {
+	switch {
+	case match(words, "terminal", "length"), match(words, "terminal", "width"), match(words, "enable"):
+		return ""
+	case match(words, "configure", "terminal"):
+		c.mode = "config"
+		return "Enter configuration commands, one per line.  End with CNTL/Z.\n"
+	case match(words, "exit"), match(words, "quit"), match(words, "logout"):
+		c.exited = true
+		return ""
+	case match(words, "write", "memory"), match(words, "copy", "running-config", "startup-config"):
+		return "Building configuration...\n[OK]\n"
+	case len(words) == 4 && match(words, "show", "interfaces") && match(words[3:], "switchport"):
+		name, i := c.server.lookup(words[2])
+		if i == nil {
+			return invalidInput
+		}
+		return showSwitchport(name, i, c.server.vlans)
+	case len(words) == 4 && match(words, "show", "running-config", "interface"):
+		name, i := c.server.lookup(words[3])
+		if i == nil {
+			return invalidInput
+		}
+		return runningConfig(interfaceConfig(name, i))
+	case len(words) == 2 && match(words, "show", "running-config"):
+		return runningConfig(c.server.configLines())
+	case len(words) == 2 && match(words, "show", "version"):
+		return fmt.Sprintf(version, c.server.hostname)
+	case len(words) == 3 && match(words, "show", "vlan", "brief"):
+		return c.server.showVLANs()
+	}
+	return invalidInput
+}
+
+func (c *cli) config(words []string) string {
+	switch {
+	case match(words, "end"):
+		c.mode = "exec"
+		return ""
+	case match(words, "exit"):
+		if c.mode == "config" {
+			c.mode = "exec"
+		} else {
+			c.mode = "config"
+		}
+		return ""
+	case match(words, "hostname"):
+		if len(words) != 2 {
+			return incomplete
+		}
+		c.server.hostname = words[1]
+		return ""
+	case match(words, "interface"):
+		if len(words) < 2 {
+			return incomplete
+		}
+		name, i := c.server.lookup(strings.Join(words[1:], ""))
+		if i == nil {
+			return invalidInput
+		}
+		c.mode, c.iface = "config-if", name
+		return ""
+	case match(words, "vlan"):
+		if len(words) != 2 {
+			return incomplete
+		}
+		vlans, err := parseVLANs(words[1])
+		if err != nil {
+			return "% Bad VLAN list\n"
+		}
+		for _, vid := range vlans {
+			c.server.createVLAN(vid)
+		}
+		c.mode, c.vlans = "config-vlan", vlans
+		return ""
+	case match(words, "no", "vlan"):
+		if len(words) != 3 {
+			return incomplete
+		}
+		vlans, err := parseVLANs(words[2])
+		if err != nil {
+			return "% Bad VLAN list\n"
+		}
+		for _, vid := range vlans {
+			if vid != 1 {
+				delete(c.server.vlans, vid)
+			}
+		}
+		return ""
+	}
+	return invalidInput
+}
+
+func (c *cli) configVLAN(words []string) (string, bool) {
+	if match(words, "name") && len(words) >= 2 {
+		for _, vid := range c.vlans {
+			c.server.vlans[vid] = strings.Join(words[1:], " ")
+		}
+		return "", true
+	}
+	return "", false
+}
+
+func (c *cli) configInterface(words []string) (string, bool) {
+	i := c.server.interfaces[c.iface]
+	if i.Mode == "routed" && len(words) > 1 && match(words, "switchport") {
+		return invalidInput, true
+	}
+	switch {
+	case len(words) == 1 && match(words, "shutdown"):
+		i.Shutdown = true
+	case len(words) == 2 && match(words, "no", "shutdown"):
+		i.Shutdown = false
+	case match(words, "mtu"):
+		mtu, err := strconv.Atoi(words[len(words)-1])
+		if len(words) != 2 || err != nil || mtu < 1500 || mtu > 9198 {
+			return invalidInput, true
+		}
+		i.MTU = mtu
+	case len(words) == 2 && match(words, "no", "mtu"):
+		i.MTU = 0
+	case len(words) == 1 && match(words, "switchport"):
+		if i.Mode == "routed" {
+			i.Mode = "access"
+		}
+	case len(words) == 2 && match(words, "no", "switchport"):
+		*i = *defaultInterface()
+		i.Mode = "routed"
+	case len(words) == 3 && match(words, "switchport", "mode"):
+		switch {
+		case match(words[2:], "access"):
+			i.Mode = "access"
+		case match(words[2:], "trunk"):
+			i.Mode = "trunk"
+		default:
+			return invalidInput, true
+		}
+	case len(words) == 3 && match(words, "no", "switchport", "mode"):
+		i.Mode = "access"
+	case match(words, "switchport", "access", "vlan"):
+		vid, err := strconv.Atoi(words[len(words)-1])
+		if len(words) != 4 || err != nil || vid < 1 || vid > 4094 {
+			return invalidInput, true
+		}
+		c.server.createVLAN(vid)
+		i.AccessVLAN = vid
+	case match(words, "no", "switchport", "access", "vlan"):
+		i.AccessVLAN = 1
+	case match(words, "switchport", "trunk", "native", "vlan"):
+		vid, err := strconv.Atoi(words[len(words)-1])
+		if len(words) != 5 || err != nil || vid < 1 || vid > 4094 {
+			return invalidInput, true
+		}
+		i.NativeVLAN = vid
+	case match(words, "no", "switchport", "trunk", "native", "vlan"):
+		i.NativeVLAN = 1
+	case match(words, "switchport", "trunk", "allowed", "vlan"):
+		return c.allowVLANs(i, words[4:]), true
+	case match(words, "no", "switchport", "trunk", "allowed", "vlan"):
+		i.AllowedVLANs = "ALL"
+	case match(words, "description"):
+	default:
+		return "", false
+	}
+	return "", true
+}
+
+// allowVLANs sets the allowed VLANs of trunk by `<vlans>`, `all`, `none`,
+// `add <vlans>` or `remove <vlans>`
+func (c *cli) allowVLANs(i *Interface, words []string) string {
+	if len(words) == 0 {
+		return incomplete
+	}
+	allowed := map[int]bool{}
+	current, _ := expandVLANs(i.AllowedVLANs)
+	for _, vid := range current {
+		allowed[vid] = true
+	}
+
+	switch {
+	case len(words) == 1 && match(words, "all"):
+		i.AllowedVLANs = "ALL"
+		return ""
+	case len(words) == 1 && match(words, "none"):
+		i.AllowedVLANs = "NONE"
+		return ""
+	case len(words) == 1:
+		vlans, err := parseVLANs(words[0])
+		if err != nil {
+			return "% Bad VLAN list\n"
+		}
+		allowed = map[int]bool{}
+		for _, vid := range vlans {
+			allowed[vid] = true
+		}
+	case len(words) == 2 && (match(words, "add") || match(words, "remove")):
+		vlans, err := parseVLANs(words[1])
+		if err != nil {
+			return "% Bad VLAN list\n"
+		}
+		for _, vid := range vlans {
+			allowed[vid] = match(words, "add")
+		}
+	default:
+		return invalidInput
+	}
+
+	vlans := []int{}
+	for vid, ok := range allowed {
+		if ok {
+			vlans = append(vlans, vid)
+		}
+	}
+	switch {
+	case len(vlans) == 0:
+		i.AllowedVLANs = "NONE"
+	case len(vlans) == 4094:
+		i.AllowedVLANs = "ALL"
+	default:
+		i.AllowedVLANs = ustrings.SliceToRange(vlans)
+	}
+	return ""
+}
+
+// lookup return the interface by its name, the type of name can be
+// abbreviated like `Gi1/0/1`
+func (s *Server) lookup(name string) (string, *Interface) {
+	if i, ok := s.interfaces[name]; ok {
+		return name, i
+	}
+	index := strings.IndexAny(name, "0123456789")
+	if index <= 0 {
+		return "", nil
+	}
+	for full, i := range s.interfaces {
+		number := strings.IndexAny(full, "0123456789")
+		if number > 0 && full[number:] == name[index:] && strings.HasPrefix(strings.ToLower(full[:number]), strings.ToLower(name[:index])) {
+			return full, i
+		}
+	}
+	return "", nil
+}
+
+func (s *Server) createVLAN(vid int) {
+	if _, ok := s.vlans[vid]; !ok {
+		s.vlans[vid] = fmt.Sprintf("VLAN%04d", vid)
+	}
+}
+
+// parseVLANs parses the list of VLANs like `10,20-30`
+func parseVLANs(list string) ([]int, error) {
+	vlans, err := ustrings.RangeToSlice(list)
+	if err != nil || len(vlans) == 0 {
+		return nil, fmt.Errorf("bad VLAN list %q", list)
+	}
+	for _, vid := range vlans {
+		if vid < 1 || vid > 4094 {
+			return nil, fmt.Errorf("bad VLAN list %q", list)
+		}
+	}
+	return vlans, nil
+}
+
+// expandVLANs return the VLANs of `ALL`, `NONE` or the range
+func expandVLANs(allowed string) ([]int, error) {
+	switch allowed {
+	case "ALL":
+		return ustrings.RangeToSlice("1-4094")
+	case "NONE":
+		return nil, nil
+	}
+	return ustrings.RangeToSlice(allowed)
+}
+
+// shortName return the abbreviated name of interface like `Gi1/0/1`
+func shortName(name string) string {
+	index := strings.IndexAny(name, "0123456789")
+	if index > 2 {
+		return name[:2] + name[index:]
+	}
+	return name
+}
+
+func vlanName(vid int, vlans map[int]string) string {
+	if vid == 1 {
+		return "1 (default)"
+	}
+	if name, ok := vlans[vid]; ok {
+		return fmt.Sprintf("%d (%s)", vid, name)
+	}
+	return fmt.Sprintf("%d (Inactive)", vid)
+}
+
+func showSwitchport(name string, i *Interface, vlans map[int]string) string {
+	if i.Mode == "routed" {
+		return fmt.Sprintf("Name: %s\nSwitchport: Disabled\n", shortName(name))
+	}
+	mode := "static access"
+	if i.Mode == "trunk" {
+		mode = "trunk"
+	}
+	operational := mode
+	if i.Shutdown {
+		operational = "down"
+	}
+	lines := []string{
+		"Name: " + shortName(name),
+		"Switchport: Enabled",
+		"Administrative Mode: " + mode,
+		"Operational Mode: " + operational,
+		"Administrative Trunking Encapsulation: dot1q",
+		"Negotiation of Trunking: Off",
+		"Access Mode VLAN: " + vlanName(i.AccessVLAN, vlans),
+		"Trunking Native Mode VLAN: " + vlanName(i.NativeVLAN, vlans),
+		"Administrative Native VLAN tagging: enabled",
+		"Voice VLAN: none",
+		"Trunking VLANs Enabled: " + i.AllowedVLANs,
+		"Pruning VLANs Enabled: 2-1001",
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// interfaceConfig return the lines of running-config of interface
+func interfaceConfig(name string, i *Interface) []string {
+	lines := []string{"interface " + name}
+	switch {
+	case i.Mode == "routed":
+		lines = append(lines, " no switchport")
+	default:
+		if i.NativeVLAN != 1 {
+			lines = append(lines, fmt.Sprintf(" switchport trunk native vlan %d", i.NativeVLAN))
+		}
+		if i.AllowedVLANs != "ALL" {
+			lines = append(lines, " switchport trunk allowed vlan "+strings.ToLower(i.AllowedVLANs))
+		}
+		if i.AccessVLAN != 1 {
+			lines = append(lines, fmt.Sprintf(" switchport access vlan %d", i.AccessVLAN))
+		}
+		lines = append(lines, " switchport mode "+i.Mode)
+	}
+	if i.MTU != 0 {
+		lines = append(lines, fmt.Sprintf(" mtu %d", i.MTU))
+	}
+	if i.Shutdown {
+		lines = append(lines, " shutdown")
+	}
+	return lines
+}
+
+// configLines return the lines of running-config of switch
+func (s *Server) configLines() []string {
+	lines := []string{"!", "hostname " + s.hostname, "!"}
+	vlans := []int{}
+	for vid := range s.vlans {
+		vlans = append(vlans, vid)
+	}
+	sort.Ints(vlans)
+	for _, vid := range vlans {
+		if vid != 1 {
+			lines = append(lines, fmt.Sprintf("vlan %d", vid), " name "+s.vlans[vid], "!")
+		}
+	}
+	names := []string{}
+	for name := range s.interfaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		lines = append(lines, interfaceConfig(name, s.interfaces[name])...)
+		lines = append(lines, "!")
+	}
+	return lines
+}
+
+func runningConfig(lines []string) string {
+	config := strings.Join(append(lines, "end"), "\n") + "\n"
+	return fmt.Sprintf("Building configuration...\n\nCurrent configuration : %d bytes\n%s", len(config), config)
+}
+
+// filter filters the lines of output by the pipes
+func filter(output string, pipes []string) string {
+	lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+	for _, pipe := range pipes {
+		words := strings.Fields(pipe)
+		if len(words) < 2 {
+			return incomplete
+		}
+		r, err := regexp.Compile(strings.Join(words[1:], " "))
+		if err != nil {
+			return invalidInput
+		}
+		filtered := []string{}
+		switch {
+		case match(words, "include"), match(words, "exclude"):
+			for _, line := range lines {
+				if r.MatchString(line) == match(words, "include") {
+					filtered = append(filtered, line)
+				}
+			}
+		case match(words, "begin"):
+			for n, line := range lines {
+				if r.MatchString(line) {
+					filtered = lines[n:]
+					break
+				}
+			}
+		case match(words, "section"):
+			// The section is the matched line and its indented lines
+			matched := false
+			for _, line := range lines {
+				if !strings.HasPrefix(line, " ") {
+					matched = r.MatchString(line)
+				}
+				if matched || r.MatchString(line) {
+					filtered = append(filtered, line)
+				}
+			}
+		default:
+			return invalidInput
+		}
+		lines = filtered
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// version is the output of `show version`, ansible reads the version, model
+// and hostname of switch from it
+const version = `Cisco IOS Software, C2960X Software (C2960X-UNIVERSALK9-M), Version 15.2(7)E4, RELEASE SOFTWARE (fc2)
+Technical Support: http://www.cisco.com/techsupport
+
+ROM: Bootstrap program is C2960X boot loader
+
+%s uptime is 1 hour, 0 minutes
+System image file is "flash:c2960x-universalk9-mz.152-7.E4.bin"
+
+cisco WS-C2960X-48TS-L (APM86XXX) processor (revision D0) with 524288K bytes of memory.
+Processor board ID FOC0000X0XX
+Configuration register is 0xF
+`
+
+// showVLANs return the VLANs and their access ports
+func (s *Server) showVLANs() string {
+	vlans := []int{}
+	for vid := range s.vlans {
+		vlans = append(vlans, vid)
+	}
+	sort.Ints(vlans)
+	names := []string{}
+	for name := range s.interfaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "%-4s %-32s %-9s %s\n", "VLAN", "Name", "Status", "Ports")
+	fmt.Fprintf(b, "%s %s %s %s\n", strings.Repeat("-", 4), strings.Repeat("-", 32), strings.Repeat("-", 9), strings.Repeat("-", 31))
+	for _, vid := range vlans {
+		ports := []string{}
+		for _, name := range names {
+			if i := s.interfaces[name]; i.Mode == "access" && i.AccessVLAN == vid {
+				ports = append(ports, shortName(name))
+			}
+		}
+		fmt.Fprintf(b, "%-4d %-32s %-9s %s\n", vid, s.vlans[vid], "active", strings.Join(ports, ", "))
+	}
+	return b.String()
+}
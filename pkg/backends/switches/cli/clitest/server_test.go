@@ -0,0 +1,188 @@
+package clitest
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// dial starts the server and return the client logged in
+func dial(t *testing.T, s *Server) *ssh.Client {
+	address, err := s.Start("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("start server failed: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	client, err := ssh.Dial("tcp", address, &ssh.ClientConfig{
+		User:            "admin",
+		Auth:            []ssh.AuthMethod{ssh.Password("password")},
+		HostKeyCallback: ssh.FixedHostKey(s.HostKey()),
+	})
+	if err != nil {
+		t.Fatalf("dial server failed: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestShell(t *testing.T) {
+	cases := []struct {
+		name       string
+		terminator string
+	}{
+		{
+			name:       "CR",
+			terminator: "\r",
+		},
+		{
+			name:       "LF",
+			terminator: "\n",
+		},
+		{
+			name:       "CRLF",
+			terminator: "\r\n",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s, err := NewServer("admin", "password", "GigabitEthernet1/0/1")
+			if err != nil {
+				t.Fatalf("new server failed: %v", err)
+			}
+			session, err := dial(t, s).NewSession()
+			if err != nil {
+				t.Fatalf("new session failed: %v", err)
+			}
+			cmds := []string{"configure terminal", "hostname leaf1", "interface Gi1/0/1", "switchport mode trunk", "end", "exit"}
+			session.Stdin = strings.NewReader(strings.Join(cmds, c.terminator) + c.terminator)
+			output := &strings.Builder{}
+			session.Stdout = output
+			err = session.Shell()
+			if err != nil {
+				t.Fatalf("start shell failed: %v", err)
+			}
+			err = session.Wait()
+			if err != nil {
+				t.Fatalf("run shell failed: %v", err)
+			}
+
+			if i := s.Interface("GigabitEthernet1/0/1"); i.Mode != "trunk" {
+				t.Errorf("expected trunk port, got: %+v", i)
+			}
+			if !strings.Contains(output.String(), "leaf1(config-if)#") {
+				t.Errorf("expected prompt of hostname, got: %q", output.String())
+			}
+			if commands := s.Commands(); len(commands) != len(cmds) {
+				t.Errorf("expected commands: %q, got: %q", cmds, commands)
+			}
+		})
+	}
+}
+
+func TestShow(t *testing.T) {
+	cases := []struct {
+		name           string
+		configuration  []string
+		command        string
+		expectedOutput string
+	}{
+		{
+			name:           "version",
+			command:        "show version | include uptime",
+			expectedOutput: "switch uptime is 1 hour, 0 minutes\r\n",
+		},
+		{
+			name:          "section of running-config",
+			configuration: []string{"vlan 10", "name storage", "interface GigabitEthernet1/0/2", "switchport access vlan 10", "mtu 9000"},
+			command:       "show running-config | section interface GigabitEthernet1/0/2",
+			expectedOutput: "interface GigabitEthernet1/0/2\r\n switchport access vlan 10\r\n" +
+				" switchport mode access\r\n mtu 9000\r\n",
+		},
+		{
+			name:           "VLANs of running-config",
+			configuration:  []string{"vlan 10", "name storage"},
+			command:        "show running-config | section ^vlan",
+			expectedOutput: "vlan 10\r\n name storage\r\n",
+		},
+		{
+			name:           "routed port",
+			configuration:  []string{"interface GigabitEthernet1/0/1", "no switchport"},
+			command:        "show running-config interface Gi1/0/1 | include switchport",
+			expectedOutput: " no switchport\r\n",
+		},
+		{
+			name:           "invalid pipe",
+			command:        "show version | grep uptime",
+			expectedOutput: "% Invalid input detected at '^' marker.\r\n",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s, err := NewServer("admin", "password", "GigabitEthernet1/0/1", "GigabitEthernet1/0/2")
+			if err != nil {
+				t.Fatalf("new server failed: %v", err)
+			}
+			err = s.Configure(c.configuration...)
+			if err != nil {
+				t.Fatalf("configure server failed: %v", err)
+			}
+			session, err := dial(t, s).NewSession()
+			if err != nil {
+				t.Fatalf("new session failed: %v", err)
+			}
+			output, err := session.Output(c.command)
+			if err != nil {
+				t.Fatalf("run command failed: %v", err)
+			}
+			if string(output) != c.expectedOutput {
+				t.Errorf("expected output: %q, got: %q", c.expectedOutput, output)
+			}
+		})
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	cases := []struct {
+		name              string
+		configuration     []string
+		expectedError     bool
+		expectedInterface *Interface
+	}{
+		{
+			name:              "no switchport mode",
+			configuration:     []string{"interface Gi1/0/1", "switchport mode trunk", "no switchport mode"},
+			expectedInterface: &Interface{Mode: "access", AccessVLAN: 1, NativeVLAN: 1, AllowedVLANs: "ALL"},
+		},
+		{
+			name:              "switchport of routed port",
+			configuration:     []string{"interface Gi1/0/1", "no switchport", "switchport mode trunk"},
+			expectedError:     true,
+			expectedInterface: &Interface{Mode: "routed", AccessVLAN: 1, NativeVLAN: 1, AllowedVLANs: "ALL"},
+		},
+		{
+			name:              "switchport after no switchport",
+			configuration:     []string{"interface Gi1/0/1", "no switchport", "switchport", "switchport mode trunk"},
+			expectedInterface: &Interface{Mode: "trunk", AccessVLAN: 1, NativeVLAN: 1, AllowedVLANs: "ALL"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s, err := NewServer("admin", "password", "GigabitEthernet1/0/1")
+			if err != nil {
+				t.Fatalf("new server failed: %v", err)
+			}
+			err = s.Configure(c.configuration...)
+			if (err != nil) != c.expectedError {
+				t.Errorf("expected error: %v, got: %v", c.expectedError, err)
+			}
+			if i := s.Interface("GigabitEthernet1/0/1"); *i != *c.expectedInterface {
+				t.Errorf("expected interface: %+v, got: %+v", c.expectedInterface, i)
+			}
+		})
+	}
+}
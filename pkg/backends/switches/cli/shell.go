@@ -0,0 +1,183 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Hellcatlk/network-operator/pkg/backends"
+	"golang.org/x/crypto/ssh"
+)
+
+// shell is the interactive shell of switch, the commands are written to it
+// and their outputs are read until the prompt
+type shell struct {
+	client  *ssh.Client
+	session *ssh.Session
+	stdin   io.WriteCloser
+	// output is the output of shell, it's closed when the shell exits
+	output chan string
+	// done is closed when the shell is closed
+	done chan struct{}
+	// buffer is the output which isn't returned
+	buffer string
+	prompt *regexp.Regexp
+}
+
+// openShell logs in the switch and waits for the first prompt
+func (c *cli) openShell(ctx context.Context) (*shell, error) {
+	config := &ssh.ClientConfig{
+		User: c.credentials.Username,
+		Auth: []ssh.AuthMethod{
+			ssh.Password(c.credentials.Password),
+			// Some switches ask the password by keyboard-interactive
+			ssh.KeyboardInteractive(func(user, instruction string, questions []string, echos []bool) ([]string, error) {
+				answers := make([]string, len(questions))
+				for i := range answers {
+					answers[i] = c.credentials.Password
+				}
+				return answers, nil
+			}),
+		},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(), // #nosec G106
+	}
+	if c.hostKey != nil {
+		config.HostKeyCallback = ssh.FixedHostKey(c.hostKey)
+	}
+
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", c.address)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, backends.FromContext(ctx)
+		}
+		return nil, backends.NewError(backends.Transient, err)
+	}
+	// Interrupt the handshake when the context is done
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, c.address, config)
+	if err != nil {
+		conn.Close()
+		if strings.Contains(err.Error(), "unable to authenticate") {
+			return nil, backends.NewError(backends.Authentication, err)
+		}
+		if strings.Contains(err.Error(), "host key mismatch") {
+			return nil, backends.NewError(backends.InvalidConfiguration, err)
+		}
+		return nil, backends.NewError(backends.Transient, err)
+	}
+	// The deadline is checked by the reads of shell
+	_ = conn.SetDeadline(time.Time{})
+
+	s := &shell{client: ssh.NewClient(sshConn, chans, reqs), output: make(chan string, 16), done: make(chan struct{}), prompt: c.templates.prompt}
+	err = s.start()
+	if err != nil {
+		s.Close()
+		return nil, backends.NewError(backends.Transient, err)
+	}
+	_, err = s.readUntilPrompt(ctx)
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *shell) start() error {
+	var err error
+	s.session, err = s.client.NewSession()
+	if err != nil {
+		return err
+	}
+	// The lines of output are long enough for the lists of VLANs
+	err = s.session.RequestPty("vt100", 0, 511, ssh.TerminalModes{ssh.ECHO: 0})
+	if err != nil {
+		return err
+	}
+	s.stdin, err = s.session.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := s.session.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	err = s.session.Shell()
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		defer close(s.output)
+		buf := make([]byte, 4096)
+		for {
+			n, err := stdout.Read(buf)
+			if n > 0 {
+				select {
+				case s.output <- string(buf[:n]):
+				case <-s.done:
+					return
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// readUntilPrompt return the output until the prompt, the prompt is the last
+// line which matches the prompt regexp
+func (s *shell) readUntilPrompt(ctx context.Context) (string, error) {
+	for {
+		s.buffer = strings.ReplaceAll(s.buffer, "\r\n", "\n")
+		if i := strings.LastIndex(s.buffer, "\n"); s.prompt.MatchString(s.buffer[i+1:]) {
+			output := s.buffer[:i+1]
+			s.buffer = ""
+			return output, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", backends.FromContext(ctx)
+		case data, ok := <-s.output:
+			if !ok {
+				return "", backends.Errorf(backends.Transient, "the shell exited, the output isn't followed by the prompt: %q", s.buffer)
+			}
+			s.buffer += data
+		}
+	}
+}
+
+// Run runs the command and return its output without the echo of command
+func (s *shell) Run(ctx context.Context, cmd string) (string, error) {
+	_, err := fmt.Fprintf(s.stdin, "%s\n", cmd)
+	if err != nil {
+		return "", backends.NewError(backends.Transient, err)
+	}
+	output, err := s.readUntilPrompt(ctx)
+	if err != nil {
+		return "", err
+	}
+	// The command is echoed by the switches whatever the terminal mode is
+	if first := strings.Index(output, "\n"); first >= 0 && strings.TrimSpace(output[:first]) == strings.TrimSpace(cmd) {
+		output = output[first+1:]
+	}
+	return output, nil
+}
+
+// Close closes the session and the connection
+func (s *shell) Close() {
+	close(s.done)
+	if s.session != nil {
+		s.session.Close()
+	}
+	s.client.Close()
+}
@@ -0,0 +1,275 @@
+package cli
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/Hellcatlk/network-operator/api/v1alpha1"
+	"github.com/Hellcatlk/network-operator/pkg/backends"
+	ustrings "github.com/Hellcatlk/network-operator/pkg/utils/strings"
+	"sigs.k8s.io/yaml"
+)
+
+// builtin are the templates of OSes, they are in the format of the data of
+// ConfigMap
+//
+//go:embed templates/*.yaml
+var builtin embed.FS
+
+// osTemplates are the builtin templates of OSes
+var osTemplates = map[string]string{
+	"ios":    "templates/ios.yaml",
+	"ios-xe": "templates/ios.yaml",
+}
+
+// templates drive the CLI of an OS, the keys of their data are:
+//
+//   - prompt: the regexp matching the prompt at the end of output, required
+//   - errors: the regexp matching the error in the output of commands
+//   - portNotFound: the regexp matching the output of show commands if the port
+//     doesn't exist
+//   - setup: the commands run after login, such as disabling the pager
+//   - apply, reset and show: the commands setting, resetting and showing the port
+//   - parse: the TextFSM parsing the output of show commands
+//   - read: the configuration in YAML rendered from the records parsed
+//   - capabilities: the fields of configuration supported in YAML, such as
+//     `{acls: false, taggedVLANs: true, ...}`, all of them are supported if
+//     it isn't set
+type templates struct {
+	prompt       *regexp.Regexp
+	errors       *regexp.Regexp
+	portNotFound *regexp.Regexp
+	setup        *template.Template
+	apply        *template.Template
+	reset        *template.Template
+	show         *template.Template
+	parse        *textFSM
+	read         *template.Template
+	capabilities *v1alpha1.SwitchCapabilities
+}
+
+// loadTemplates return the builtin templates of the OS replaced by the data
+func loadTemplates(os string, data map[string]string) (*templates, error) {
+	all := map[string]string{}
+	if file, ok := osTemplates[os]; ok {
+		b, err := builtin.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		err = yaml.Unmarshal(b, &all)
+		if err != nil {
+			return nil, fmt.Errorf("invalid builtin templates of os %q: %v", os, err)
+		}
+	}
+	for key, value := range data {
+		all[key] = value
+	}
+
+	for _, key := range []string{"prompt", "apply", "reset", "show", "parse", "read"} {
+		if all[key] == "" {
+			return nil, fmt.Errorf("template %q of os %q isn't set", key, os)
+		}
+	}
+
+	t := &templates{capabilities: backends.AllCapabilities()}
+	var err error
+	for key, value := range all {
+		switch key {
+		case "prompt":
+			t.prompt, err = regexp.Compile(value)
+		case "errors":
+			t.errors, err = regexp.Compile(value)
+		case "portNotFound":
+			t.portNotFound, err = regexp.Compile(value)
+		case "setup":
+			t.setup, err = newTemplate(key, value)
+		case "apply":
+			t.apply, err = newTemplate(key, value)
+		case "reset":
+			t.reset, err = newTemplate(key, value)
+		case "show":
+			t.show, err = newTemplate(key, value)
+		case "parse":
+			t.parse, err = parseTextFSM(value)
+		case "read":
+			t.read, err = newTemplate(key, value)
+		case "capabilities":
+			t.capabilities = &v1alpha1.SwitchCapabilities{}
+			err = yaml.UnmarshalStrict([]byte(value), t.capabilities)
+		default:
+			err = fmt.Errorf("unknown template")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid template %q: %v", key, err)
+		}
+	}
+	return t, nil
+}
+
+// errUnsupported is returned by the `unsupported` function of templates
+type errUnsupported string
+
+func (e errUnsupported) Error() string {
+	return string(e)
+}
+
+func newTemplate(name string, text string) (*template.Template, error) {
+	return template.New(name).Option("missingkey=zero").Funcs(template.FuncMap{
+		// vlans return the VLANs of range, `ALL` is 1-4094 and `NONE` is empty
+		"vlans": func(r string) ([]int, error) {
+			switch strings.ToUpper(strings.TrimSpace(r)) {
+			case "ALL":
+				return ustrings.RangeToSlice("1-4094")
+			case "NONE", "":
+				return []int{}, nil
+			}
+			return ustrings.RangeToSlice(r)
+		},
+		// vlanRange return the range of VLANs
+		"vlanRange": func(vlans []int) string {
+			return ustrings.SliceToRange(append([]int{}, vlans...))
+		},
+		// hasVLAN check the VLAN is in the VLANs
+		"hasVLAN": func(vlans []int, vlan interface{}) bool {
+			vid, err := strconv.Atoi(fmt.Sprint(vlan))
+			if err != nil {
+				return false
+			}
+			for _, v := range vlans {
+				if v == vid {
+					return true
+				}
+			}
+			return false
+		},
+		// withoutVLAN return the VLANs except the VLAN
+		"withoutVLAN": func(vlans []int, vlan interface{}) []int {
+			result := []int{}
+			for _, v := range vlans {
+				if fmt.Sprint(v) != fmt.Sprint(vlan) {
+					result = append(result, v)
+				}
+			}
+			return result
+		},
+		// unsupported fails the template with the Unsupported error
+		"unsupported": func(message string) (string, error) {
+			return "", errUnsupported(message)
+		},
+	}).Parse(text)
+}
+
+// portData is the data of the templates of port
+type portData struct {
+	Port string
+	// Mode is `access` if only the untagged VLAN is set, otherwise it's
+	// `trunk` whose native VLAN is the untagged VLAN
+	Mode string
+	// UntaggedVLAN is 0 if it isn't set
+	UntaggedVLAN    int
+	TaggedVLANs     []int
+	TaggedVLANRange string
+	// VLANs are the untagged and tagged VLANs in order
+	VLANs   []int
+	Disable bool
+	// MTU is 0 if it isn't set
+	MTU  int
+	ACLs []v1alpha1.ACL
+}
+
+// newPortData return the data of the configuration of port
+func newPortData(port string, configuration *v1alpha1.SwitchPortConfigurationSpec) (*portData, error) {
+	data := &portData{Port: port}
+	if configuration == nil {
+		return data, nil
+	}
+
+	tagged, err := ustrings.RangeToSlice(configuration.TaggedVLANRange)
+	if err != nil {
+		return nil, backends.NewError(backends.InvalidConfiguration, err)
+	}
+	data.TaggedVLANs = append([]int{}, tagged...)
+	data.TaggedVLANRange = ustrings.SliceToRange(tagged)
+	data.VLANs = append([]int{}, data.TaggedVLANs...)
+	if configuration.UntaggedVLAN != nil {
+		data.UntaggedVLAN = *configuration.UntaggedVLAN
+		data.VLANs = append(data.VLANs, data.UntaggedVLAN)
+	}
+	sort.Ints(data.VLANs)
+	for _, vid := range data.VLANs {
+		if vid < 1 || vid > 4094 {
+			return nil, backends.Errorf(backends.InvalidConfiguration, "VLAN %d is out of range 1-4094", vid)
+		}
+	}
+	data.Mode = "trunk"
+	if len(tagged) == 0 && configuration.UntaggedVLAN != nil {
+		data.Mode = "access"
+	}
+	data.Disable = configuration.Disable
+	if configuration.MTU != nil {
+		data.MTU = *configuration.MTU
+	}
+	data.ACLs = configuration.ACLs
+	return data, nil
+}
+
+// commands return the commands rendered by the template, they are the lines
+// which aren't empty
+func commands(t *template.Template, data interface{}) ([]string, error) {
+	if t == nil {
+		return nil, nil
+	}
+	buf := &bytes.Buffer{}
+	err := t.Execute(buf, data)
+	if err != nil {
+		return nil, templateError(t, err)
+	}
+	cmds := []string{}
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if strings.TrimSpace(line) != "" {
+			cmds = append(cmds, strings.TrimRight(line, " \t\r"))
+		}
+	}
+	return cmds, nil
+}
+
+// readConfiguration return the configuration rendered by the read template
+// from the records parsed from the output
+func (t *templates) readConfiguration(port string, output string) (*v1alpha1.SwitchPortConfigurationSpec, error) {
+	records, err := t.parse.Parse(output)
+	if err != nil {
+		return nil, backends.Errorf(backends.Transient, "parse the output of show commands failed: %v", err)
+	}
+	values := t.parse.emptyRecord()
+	if len(records) != 0 {
+		values = records[0]
+	}
+
+	buf := &bytes.Buffer{}
+	err = t.read.Execute(buf, map[string]interface{}{"Port": port, "Values": values, "Records": records})
+	if err != nil {
+		return nil, templateError(t.read, err)
+	}
+	configuration := &v1alpha1.SwitchPortConfigurationSpec{}
+	err = yaml.UnmarshalStrict(buf.Bytes(), configuration)
+	if err != nil {
+		return nil, backends.Errorf(backends.Transient, "invalid configuration rendered by the read template: %v", err)
+	}
+	return configuration, nil
+}
+
+// templateError return the typed error of executing the template
+func templateError(t *template.Template, err error) error {
+	var unsupported errUnsupported
+	if errors.As(err, &unsupported) {
+		return backends.NewError(backends.Unsupported, unsupported)
+	}
+	return backends.Errorf(backends.InvalidConfiguration, "execute template %q failed: %v", t.Name(), err)
+}
@@ -0,0 +1,271 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// textFSM is a parser of the output of commands, it's a subset of TextFSM:
+//
+//	Value [Filldown,Required,List] <name> (<regexp>)
+//
+//	Start
+//	  ^<regexp with ${name}> [-> [Next|Continue][.Record|.Clear|.Clearall|.NoRecord] [<state>]]
+//	  ^<regexp> -> Error [<message>]
+//
+// The record is saved at the end of the output if it isn't empty
+type textFSM struct {
+	values []*fsmValue
+	states map[string][]*fsmRule
+}
+
+type fsmValue struct {
+	name     string
+	regexp   string
+	filldown bool
+	required bool
+	list     bool
+}
+
+type fsmRule struct {
+	regexp *regexp.Regexp
+	// lineAction is `Next` or `Continue`
+	lineAction string
+	// recordAction is `Record`, `Clear`, `Clearall` or empty
+	recordAction string
+	// state is the next state, it's empty if the state isn't changed
+	state string
+	// message is the message of `Error` action
+	err     bool
+	message string
+}
+
+var (
+	fsmValueLine  = regexp.MustCompile(`^Value\s+(?:((?:Filldown|Required|List)(?:,(?:Filldown|Required|List))*)\s+)?(\w+)\s+(\(.*\))\s*$`)
+	fsmStateLine  = regexp.MustCompile(`^(\w+)\s*$`)
+	fsmRuleLine   = regexp.MustCompile(`^\s+(\^.*?)(?:\s+->\s+(.*))?\s*$`)
+	fsmActionLine = regexp.MustCompile(`^(?:(Next|Continue)(?:\.(Record|NoRecord|Clear|Clearall))?|(Record|NoRecord|Clear|Clearall))?(?:\s*(\w+))?$`)
+	fsmErrorLine  = regexp.MustCompile(`^Error(?:\s+"?([^"]*)"?)?$`)
+	fsmVariable   = regexp.MustCompile(`\$\{(\w+)\}`)
+)
+
+// parseTextFSM return the parser of the template
+func parseTextFSM(template string) (*textFSM, error) {
+	fsm := &textFSM{states: map[string][]*fsmRule{}}
+	names := map[string]*fsmValue{}
+	state := ""
+	scanner := bufio.NewScanner(strings.NewReader(template))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		switch {
+		case strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "#"):
+		case state == "" && strings.HasPrefix(line, "Value "):
+			matches := fsmValueLine.FindStringSubmatch(line)
+			if matches == nil {
+				return nil, fmt.Errorf("line %d: invalid value %q", n, line)
+			}
+			v := &fsmValue{name: matches[2], regexp: matches[3]}
+			for _, option := range strings.Split(matches[1], ",") {
+				v.filldown = v.filldown || option == "Filldown"
+				v.required = v.required || option == "Required"
+				v.list = v.list || option == "List"
+			}
+			if _, err := regexp.Compile(v.regexp); err != nil {
+				return nil, fmt.Errorf("line %d: invalid regexp of value %s: %v", n, v.name, err)
+			}
+			names[v.name] = v
+			fsm.values = append(fsm.values, v)
+		case fsmStateLine.MatchString(line):
+			state = line
+			if _, ok := fsm.states[state]; ok {
+				return nil, fmt.Errorf("line %d: duplicate state %s", n, state)
+			}
+			fsm.states[state] = []*fsmRule{}
+		case state != "" && fsmRuleLine.MatchString(line):
+			rule, err := parseRule(line, names)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", n, err)
+			}
+			fsm.states[state] = append(fsm.states[state], rule)
+		default:
+			return nil, fmt.Errorf("line %d: invalid line %q", n, line)
+		}
+	}
+
+	if _, ok := fsm.states["Start"]; !ok {
+		return nil, fmt.Errorf("state Start isn't defined")
+	}
+	for _, rules := range fsm.states {
+		for _, rule := range rules {
+			if _, ok := fsm.states[rule.state]; rule.state != "" && rule.state != "End" && !ok {
+				return nil, fmt.Errorf("state %s isn't defined", rule.state)
+			}
+		}
+	}
+	return fsm, nil
+}
+
+func parseRule(line string, names map[string]*fsmValue) (*fsmRule, error) {
+	matches := fsmRuleLine.FindStringSubmatch(line)
+	var undefined error
+	expr := fsmVariable.ReplaceAllStringFunc(matches[1], func(variable string) string {
+		name := fsmVariable.FindStringSubmatch(variable)[1]
+		v, ok := names[name]
+		if !ok {
+			undefined = fmt.Errorf("value %s isn't defined", name)
+			return variable
+		}
+		return "(?P<" + name + ">" + v.regexp + ")"
+	})
+	if undefined != nil {
+		return nil, undefined
+	}
+	expr = strings.ReplaceAll(expr, "$$", "$")
+	r, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rule %q: %v", matches[1], err)
+	}
+
+	rule := &fsmRule{regexp: r, lineAction: "Next"}
+	action := strings.TrimSpace(matches[2])
+	if m := fsmErrorLine.FindStringSubmatch(action); m != nil {
+		rule.err = true
+		rule.message = m[1]
+		return rule, nil
+	}
+	m := fsmActionLine.FindStringSubmatch(action)
+	if m == nil {
+		return nil, fmt.Errorf("invalid action %q", action)
+	}
+	if m[1] != "" {
+		rule.lineAction = m[1]
+	}
+	rule.recordAction = m[2] + m[3]
+	rule.state = m[4]
+	if rule.lineAction == "Continue" && rule.state != "" {
+		return nil, fmt.Errorf("the state can't be changed by Continue")
+	}
+	return rule, nil
+}
+
+// Parse return the records of the output, the value of record is a string or
+// a list of strings for `List`
+func (fsm *textFSM) Parse(output string) ([]map[string]interface{}, error) {
+	records := []map[string]interface{}{}
+	current := map[string]interface{}{}
+	filled := false
+
+	record := func() {
+		if filled {
+			complete := true
+			for _, v := range fsm.values {
+				if v.required && isEmpty(current[v.name]) {
+					complete = false
+				}
+			}
+			if complete {
+				r := map[string]interface{}{}
+				for _, v := range fsm.values {
+					r[v.name] = current[v.name]
+					if r[v.name] == nil {
+						r[v.name] = empty(v)
+					}
+				}
+				records = append(records, r)
+			}
+		}
+		clearValues(fsm, current, false)
+		filled = false
+	}
+
+	state := "Start"
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() && state != "End" {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		for _, rule := range fsm.states[state] {
+			matches := rule.regexp.FindStringSubmatch(line)
+			if matches == nil {
+				continue
+			}
+			if rule.err {
+				return nil, fmt.Errorf("error of state %s at line %q: %s", state, line, rule.message)
+			}
+			for i, name := range rule.regexp.SubexpNames() {
+				v := fsm.value(name)
+				if v == nil {
+					continue
+				}
+				filled = true
+				if v.list {
+					list, _ := current[name].([]string)
+					current[name] = append(list, matches[i])
+				} else {
+					current[name] = matches[i]
+				}
+			}
+			switch rule.recordAction {
+			case "Record":
+				record()
+			case "Clear":
+				clearValues(fsm, current, false)
+			case "Clearall":
+				clearValues(fsm, current, true)
+			}
+			if rule.state != "" {
+				state = rule.state
+			}
+			if rule.lineAction == "Next" {
+				break
+			}
+		}
+	}
+	record()
+	return records, nil
+}
+
+// emptyRecord return the record whose values are empty
+func (fsm *textFSM) emptyRecord() map[string]interface{} {
+	record := map[string]interface{}{}
+	for _, v := range fsm.values {
+		record[v.name] = empty(v)
+	}
+	return record
+}
+
+func (fsm *textFSM) value(name string) *fsmValue {
+	for _, v := range fsm.values {
+		if v.name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// clearValues clears the values of record except the `Filldown` ones, all of them
+// are cleared if all is set
+func clearValues(fsm *textFSM, current map[string]interface{}, all bool) {
+	for _, v := range fsm.values {
+		if all || !v.filldown {
+			delete(current, v.name)
+		}
+	}
+}
+
+func empty(v *fsmValue) interface{} {
+	if v.list {
+		return []string{}
+	}
+	return ""
+}
+
+func isEmpty(value interface{}) bool {
+	switch v := value.(type) {
+	case string:
+		return v == ""
+	case []string:
+		return len(v) == 0
+	}
+	return true
+}
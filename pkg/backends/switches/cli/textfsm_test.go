@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTextFSM(t *testing.T) {
+	cases := []struct {
+		name            string
+		template        string
+		output          string
+		expectedRecords []map[string]interface{}
+		expectedError   bool
+	}{
+		{
+			name:     "implicit record",
+			template: "Value Mode (\\S+)\nValue VLAN (\\d+)\n\nStart\n  ^Mode: ${Mode}\n  ^VLAN: ${VLAN}\n",
+			output:   "Mode: access\nVLAN: 10\n",
+			expectedRecords: []map[string]interface{}{
+				{"Mode": "access", "VLAN": "10"},
+			},
+		},
+		{
+			name:            "no record",
+			template:        "Value Mode (\\S+)\n\nStart\n  ^Mode: ${Mode}\n",
+			output:          "% Invalid input\n",
+			expectedRecords: []map[string]interface{}{},
+		},
+		{
+			name: "records with filldown, required and list",
+			template: `Value Filldown Switch (\S+)
+Value Required Port (\S+)
+Value List VLANs (\d+)
+
+Start
+  ^Switch ${Switch}
+  ^Port ${Port} -> Continue
+  ^Port \S+ vlan ${VLANs} -> Continue
+  ^.*vlan \d+ vlan ${VLANs}
+  ^end -> Record
+`,
+			output: "Switch sw1\nend\nPort p1 vlan 10 vlan 20\nend\nPort p2\nend\n",
+			expectedRecords: []map[string]interface{}{
+				{"Switch": "sw1", "Port": "p1", "VLANs": []string{"10", "20"}},
+				{"Switch": "sw1", "Port": "p2", "VLANs": []string{}},
+			},
+		},
+		{
+			name: "states",
+			template: `Value Name (\S+)
+
+Start
+  ^interface -> Interface
+
+Interface
+  ^\s+name ${Name}$$ -> Record End
+`,
+			output: "name ignored\ninterface\n name eth0\n name eth1\n",
+			expectedRecords: []map[string]interface{}{
+				{"Name": "eth0"},
+			},
+		},
+		{
+			name:          "error action",
+			template:      "Value Mode (\\S+)\n\nStart\n  ^% -> Error \"invalid command\"\n",
+			output:        "% Invalid input\n",
+			expectedError: true,
+		},
+		{
+			name:          "undefined value",
+			template:      "Value Mode (\\S+)\n\nStart\n  ^Mode: ${VLAN}\n",
+			expectedError: true,
+		},
+		{
+			name:          "undefined state",
+			template:      "Value Mode (\\S+)\n\nStart\n  ^Mode: ${Mode} -> Mode\n",
+			expectedError: true,
+		},
+		{
+			name:          "without Start",
+			template:      "Value Mode (\\S+)\n\nInterface\n  ^Mode: ${Mode}\n",
+			expectedError: true,
+		},
+		{
+			name:          "changing state by Continue",
+			template:      "Value Mode (\\S+)\n\nStart\n  ^Mode: ${Mode} -> Continue Start\n",
+			expectedError: true,
+		},
+		{
+			name:          "invalid regexp",
+			template:      "Value Mode (\\S+\n\nStart\n  ^Mode: ${Mode}\n",
+			expectedError: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fsm, err := parseTextFSM(c.template)
+			var records []map[string]interface{}
+			if err == nil {
+				records, err = fsm.Parse(c.output)
+			}
+			if (err != nil) != c.expectedError {
+				t.Fatalf("expected error: %v, got: %v", c.expectedError, err)
+			}
+			if err == nil && !reflect.DeepEqual(records, c.expectedRecords) {
+				t.Errorf("expected records: %v, got: %v", c.expectedRecords, records)
+			}
+		})
+	}
+}
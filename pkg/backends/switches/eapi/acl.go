@@ -0,0 +1,187 @@
+package eapi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Hellcatlk/network-operator/api/v1alpha1"
+	"github.com/Hellcatlk/network-operator/pkg/backends"
+)
+
+// remarkPrefix is the prefix of the remark before the rules of every ACL, the
+// remark records the ACL so that it can be read back exactly
+const remarkPrefix string = "remark network-operator"
+
+// The protocols of the rules for the IP versions
+var protocols = map[string]map[string]string{
+	"4": {"TCP": "tcp", "UDP": "udp", "ICMP": "icmp", "ALL": "ip"},
+	"6": {"TCP": "tcp", "UDP": "udp", "ICMP": "icmpv6", "ALL": "ipv6"},
+}
+
+// toACLEntries return the entries of the access-lists for the IP versions, every
+// ACL is a remark followed by the rules of every pair of source and destination
+// port ranges
+func toACLEntries(acls []v1alpha1.ACL) (map[string][]string, error) {
+	entries := map[string][]string{}
+	for i, acl := range acls {
+		rules, err := aclRules(acl)
+		if err != nil {
+			return nil, backends.Errorf(backends.InvalidConfiguration, "invalid ACL %d: %v", i, err)
+		}
+		entries[acl.IPVersion] = append(entries[acl.IPVersion], remark(i, acl))
+		entries[acl.IPVersion] = append(entries[acl.IPVersion], rules...)
+	}
+	return entries, nil
+}
+
+// aclRules return the rules of the ACL
+func aclRules(acl v1alpha1.ACL) ([]string, error) {
+	protocol := protocols[acl.IPVersion][acl.Protocol]
+	if protocol == "" || (acl.Action != "allow" && acl.Action != "deny") {
+		return nil, fmt.Errorf("ipVersion, action and protocol are required by eapi backend")
+	}
+	action := "permit"
+	if acl.Action == "deny" {
+		action = "deny"
+	}
+
+	for _, value := range []string{acl.SourceIP, acl.DestinationIP, acl.SourcePortRange, acl.DestinationPortRange} {
+		if strings.ContainsAny(value, " =") {
+			return nil, fmt.Errorf("invalid value %q", value)
+		}
+	}
+	source, err := address(acl.SourceIP, acl.IPVersion)
+	if err != nil {
+		return nil, err
+	}
+	destination, err := address(acl.DestinationIP, acl.IPVersion)
+	if err != nil {
+		return nil, err
+	}
+	sources, err := portRanges(acl.SourcePortRange)
+	if err != nil {
+		return nil, fmt.Errorf("invalid source port range: %v", err)
+	}
+	destinations, err := portRanges(acl.DestinationPortRange)
+	if err != nil {
+		return nil, fmt.Errorf("invalid destination port range: %v", err)
+	}
+	if (acl.SourcePortRange != "" || acl.DestinationPortRange != "") && acl.Protocol != "TCP" && acl.Protocol != "UDP" {
+		return nil, fmt.Errorf("port range requires TCP or UDP")
+	}
+
+	rules := []string{}
+	for _, s := range sources {
+		for _, d := range destinations {
+			rules = append(rules, action+" "+protocol+" "+source+s+" "+destination+d)
+		}
+	}
+	return rules, nil
+}
+
+// address return the address of rule, it's `any`, `host <ip>` or the prefix
+func address(ip string, ipVersion string) (string, error) {
+	if ip == "" {
+		return "any", nil
+	}
+	if strings.Contains(ip, ":") != (ipVersion == "6") {
+		return "", fmt.Errorf("%s isn't an IPv%s address", ip, ipVersion)
+	}
+	if strings.Contains(ip, "/") {
+		return ip, nil
+	}
+	return "host " + ip, nil
+}
+
+// portRanges return the port matches of the comma separated list, it's [""]
+// for the empty list
+func portRanges(list string) ([]string, error) {
+	if list == "" {
+		return []string{""}, nil
+	}
+	matches := []string{}
+	for _, r := range strings.Split(list, ",") {
+		bounds := strings.Split(r, "-")
+		if len(bounds) > 2 {
+			return nil, fmt.Errorf("invalid range %q", r)
+		}
+		for _, bound := range bounds {
+			port, err := strconv.Atoi(bound)
+			if err != nil || port < 0 || port > 65535 {
+				return nil, fmt.Errorf("invalid port %q", bound)
+			}
+		}
+		if len(bounds) == 2 {
+			matches = append(matches, " range "+bounds[0]+" "+bounds[1])
+		} else {
+			matches = append(matches, " eq "+bounds[0])
+		}
+	}
+	return matches, nil
+}
+
+// remark return the remark recording the index and ACL, the empty fields are
+// omitted
+func remark(index int, acl v1alpha1.ACL) string {
+	text := remarkPrefix + " index=" + strconv.Itoa(index)
+	for _, field := range aclFields(&acl) {
+		if *field.value != "" {
+			text += " " + field.name + "=" + *field.value
+		}
+	}
+	return text
+}
+
+// aclField is a field of ACL recorded by remark
+type aclField struct {
+	name  string
+	value *string
+}
+
+func aclFields(acl *v1alpha1.ACL) []aclField {
+	return []aclField{
+		{"ipVersion", &acl.IPVersion},
+		{"action", &acl.Action},
+		{"protocol", &acl.Protocol},
+		{"sourceIP", &acl.SourceIP},
+		{"sourcePortRange", &acl.SourcePortRange},
+		{"destinationIP", &acl.DestinationIP},
+		{"destinationPortRange", &acl.DestinationPortRange},
+	}
+}
+
+// fromACLEntries adds the ACLs of the entries created by toACLEntries to acls
+// by their indexes, the ACL is ignored if its rules are changed
+func fromACLEntries(texts []string, acls map[int]v1alpha1.ACL) {
+	for i, text := range texts {
+		if !strings.HasPrefix(text, remarkPrefix) {
+			continue
+		}
+		acl := v1alpha1.ACL{}
+		index := -1
+		fields := aclFields(&acl)
+		for _, pair := range strings.Fields(strings.TrimPrefix(text, remarkPrefix)) {
+			if strings.HasPrefix(pair, "index=") {
+				index, _ = strconv.Atoi(strings.TrimPrefix(pair, "index="))
+			}
+			for _, field := range fields {
+				if strings.HasPrefix(pair, field.name+"=") {
+					*field.value = strings.TrimPrefix(pair, field.name+"=")
+				}
+			}
+		}
+
+		rules, err := aclRules(acl)
+		if err != nil || index < 0 || len(texts) < i+1+len(rules) {
+			continue
+		}
+		matched := true
+		for j, rule := range rules {
+			matched = matched && texts[i+1+j] == rule
+		}
+		if matched {
+			acls[index] = acl
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package eapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Hellcatlk/network-operator/pkg/backends"
+)
+
+// The JSON-RPC 2.0 of eAPI, see the Command API guide of Arista EOS
+
+// request is the request of `runCmds`
+type request struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  params `json:"params"`
+	ID      string `json:"id"`
+}
+
+type params struct {
+	Version int      `json:"version"`
+	Cmds    []string `json:"cmds"`
+	Format  string   `json:"format"`
+}
+
+// response is the response of `runCmds`
+type response struct {
+	Result []json.RawMessage `json:"result"`
+	Error  *rpcError         `json:"error"`
+}
+
+// rpcError is the error of `runCmds`, the data are the results of commands,
+// the failed command has `errors`
+type rpcError struct {
+	Code    int               `json:"code"`
+	Message string            `json:"message"`
+	Data    []json.RawMessage `json:"data"`
+}
+
+// codeInvalidCommand is the error code of eAPI for the commands refused by CLI
+const codeInvalidCommand int = 1002
+
+// runCmds runs the commands in order and return their results in JSON, the
+// commands after the failed one aren't run
+func (e *eapi) runCmds(ctx context.Context, cmds ...string) ([]json.RawMessage, error) {
+	body, err := json.Marshal(request{
+		JSONRPC: "2.0",
+		Method:  "runCmds",
+		Params:  params{Version: 1, Cmds: cmds, Format: "json"},
+		ID:      "network-operator",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, backends.NewError(backends.InvalidConfiguration, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.SetBasicAuth(e.credentials.Username, e.credentials.Password)
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, backends.FromContext(ctx)
+		}
+		return nil, backends.NewError(backends.Transient, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return nil, backends.Errorf(backends.Authentication, "eAPI responded %s", resp.Status)
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, backends.Errorf(backends.InvalidConfiguration, "eAPI responded %s, check `management api http-commands` is enabled", resp.Status)
+	case resp.StatusCode != http.StatusOK:
+		return nil, backends.Errorf(backends.Transient, "eAPI responded %s", resp.Status)
+	}
+
+	result := &response{}
+	err = json.NewDecoder(resp.Body).Decode(result)
+	if err != nil {
+		return nil, backends.Errorf(backends.Transient, "invalid response of eAPI: %v", err)
+	}
+	if result.Error != nil {
+		return nil, commandError(cmds, result.Error)
+	}
+	if len(result.Result) != len(cmds) {
+		return nil, backends.Errorf(backends.Transient, "eAPI returned %d results for %d commands", len(result.Result), len(cmds))
+	}
+	return result.Result, nil
+}
+
+// commandError return the typed error of the failed command
+func commandError(cmds []string, e *rpcError) error {
+	// The failed command is the last one which has result
+	messages := []string{}
+	command := ""
+	if len(e.Data) != 0 && len(e.Data) <= len(cmds) {
+		command = cmds[len(e.Data)-1]
+		failed := struct {
+			Errors []string `json:"errors"`
+		}{}
+		_ = json.Unmarshal(e.Data[len(e.Data)-1], &failed)
+		messages = failed.Errors
+	}
+	err := fmt.Errorf("%s: %s", e.Message, strings.Join(messages, "; "))
+	if command != "" {
+		err = fmt.Errorf("%q failed: %s", command, strings.Join(messages, "; "))
+	}
+
+	for _, message := range messages {
+		if strings.Contains(message, "Interface does not exist") {
+			return backends.NewError(backends.PortNotFound, err)
+		}
+	}
+	if e.Code == codeInvalidCommand {
+		return backends.NewError(backends.InvalidConfiguration, err)
+	}
+	return backends.NewError(backends.Transient, err)
+}
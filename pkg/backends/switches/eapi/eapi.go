@@ -0,0 +1,446 @@
+// Package eapi is the switch backend which configures the ports of Arista EOS
+// by eAPI, the JSON-RPC API running the commands of CLI.
+package eapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Hellcatlk/network-operator/api/v1alpha1"
+	"github.com/Hellcatlk/network-operator/pkg/backends"
+	"github.com/Hellcatlk/network-operator/pkg/provider"
+	"github.com/Hellcatlk/network-operator/pkg/utils/credentials"
+	"github.com/Hellcatlk/network-operator/pkg/utils/httpclient"
+	ustrings "github.com/Hellcatlk/network-operator/pkg/utils/strings"
+)
+
+// defaultPath is the path of eAPI
+const defaultPath string = "/command-api"
+
+// New return eapi backend, the host is `<host>[:<port>]` which uses HTTPS, or
+// the URL of eAPI
+func New(ctx context.Context, config *provider.SwitchConfiguration) (backends.Switch, error) {
+	if config == nil {
+		return nil, fmt.Errorf("configure of switch is nil")
+	}
+	if config.Credentials == nil {
+		return nil, backends.Errorf(backends.InvalidConfiguration, "credentials of eapi backend are required")
+	}
+
+	var caBundle string
+	var insecureSkipVerify bool
+	for key, value := range config.Options {
+		s, ok := value.(string)
+		if !ok {
+			return nil, backends.Errorf(backends.InvalidConfiguration, "option %q of eapi backend isn't a string", key)
+		}
+		switch key {
+		case "caBundle":
+			caBundle = s
+		case "insecureSkipVerify":
+			insecure, err := strconv.ParseBool(s)
+			if err != nil {
+				return nil, backends.Errorf(backends.InvalidConfiguration, "invalid insecureSkipVerify %q of eapi backend", s)
+			}
+			insecureSkipVerify = insecure
+		default:
+			return nil, backends.Errorf(backends.InvalidConfiguration, "unknown option %q of eapi backend", key)
+		}
+	}
+
+	u, err := parseHost(config.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := httpclient.Get(u, caBundle, insecureSkipVerify)
+	if err != nil {
+		return nil, backends.Errorf(backends.InvalidConfiguration, "caBundle of eapi backend has no PEM certificate")
+	}
+
+	return &eapi{
+		url:         u,
+		credentials: config.Credentials,
+		client:      client,
+	}, nil
+}
+
+// parseHost return the URL of eAPI
+func parseHost(host string) (string, error) {
+	if host == "" {
+		return "", backends.Errorf(backends.InvalidConfiguration, "host of eapi backend is empty")
+	}
+	if !strings.Contains(host, "://") {
+		return "https://" + host + defaultPath, nil
+	}
+
+	u, err := url.Parse(host)
+	if err != nil {
+		return "", backends.NewError(backends.InvalidConfiguration, err)
+	}
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return "", backends.Errorf(backends.InvalidConfiguration, "scheme %q of eapi backend isn't supported", u.Scheme)
+	}
+	if u.Path == "" {
+		u.Path = defaultPath
+	}
+	return u.String(), nil
+}
+
+// eapi backend
+type eapi struct {
+	url         string
+	credentials *credentials.Credentials
+	client      *http.Client
+}
+
+// IsAvailable check eAPI can be called
+func (e *eapi) IsAvailable(ctx context.Context) error {
+	_, err := e.runCmds(ctx, "show version")
+	return err
+}
+
+// runningConfig is the result of `show running-config` in JSON
+type runningConfig struct {
+	Cmds map[string]*runningConfig `json:"cmds"`
+}
+
+// accessLists is the result of `show ip access-lists` and `show ipv6 access-lists`
+type accessLists struct {
+	ACLList []struct {
+		Name     string `json:"name"`
+		Sequence []struct {
+			SequenceNumber int    `json:"sequenceNumber"`
+			Text           string `json:"text"`
+		} `json:"sequence"`
+	} `json:"aclList"`
+}
+
+// GetPortAttr return the VLANs, ACLs and shutdown of the interface
+func (e *eapi) GetPortAttr(ctx context.Context, port string) (*v1alpha1.SwitchPortConfigurationSpec, error) {
+	results, err := e.runCmds(ctx,
+		"show interfaces "+port,
+		"show running-config interfaces "+port,
+		"show ip access-lists "+aclName(port, "4"),
+		"show ipv6 access-lists "+aclName(port, "6"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &runningConfig{}
+	err = json.Unmarshal(results[1], config)
+	if err != nil {
+		return nil, backends.Errorf(backends.Transient, "invalid running-config of %s: %v", port, err)
+	}
+	lines := map[string]bool{}
+	if section := config.Cmds["interface "+port]; section != nil {
+		for line := range section.Cmds {
+			lines[line] = true
+		}
+	}
+	configuration := fromInterfaceLines(lines)
+
+	acls := map[int]v1alpha1.ACL{}
+	for i, version := range []string{"4", "6"} {
+		if !lines[accessGroup(port, version)] {
+			continue
+		}
+		lists := &accessLists{}
+		err = json.Unmarshal(results[2+i], lists)
+		if err != nil {
+			return nil, backends.Errorf(backends.Transient, "invalid access-lists of %s: %v", port, err)
+		}
+		for _, list := range lists.ACLList {
+			texts := []string{}
+			for _, entry := range list.Sequence {
+				texts = append(texts, entry.Text)
+			}
+			fromACLEntries(texts, acls)
+		}
+	}
+	indexes := []int{}
+	for index := range acls {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	for _, index := range indexes {
+		configuration.ACLs = append(configuration.ACLs, acls[index])
+	}
+	return configuration, nil
+}
+
+// ListPortVLANs return the VLANs of the switchports, the routed ports and the
+// other interfaces aren't included
+func (e *eapi) ListPortVLANs(ctx context.Context) (map[string][]int, error) {
+	results, err := e.runCmds(ctx, "show running-config")
+	if err != nil {
+		return nil, err
+	}
+	config := &runningConfig{}
+	err = json.Unmarshal(results[0], config)
+	if err != nil {
+		return nil, backends.Errorf(backends.Transient, "invalid running-config: %v", err)
+	}
+
+	ports := map[string][]int{}
+	for name, section := range config.Cmds {
+		port := strings.TrimPrefix(name, "interface ")
+		if port == name || !(strings.HasPrefix(port, "Ethernet") || strings.HasPrefix(port, "Port-Channel")) {
+			continue
+		}
+		lines := map[string]bool{}
+		if section != nil {
+			for line := range section.Cmds {
+				lines[line] = true
+			}
+		}
+		if lines["no switchport"] {
+			continue
+		}
+		configuration := fromInterfaceLines(lines)
+		vlans, _ := ustrings.RangeToSlice(configuration.TaggedVLANRange)
+		if configuration.UntaggedVLAN != nil {
+			vlans = append(vlans, *configuration.UntaggedVLAN)
+		}
+		if len(vlans) != 0 {
+			sort.Ints(vlans)
+			ports[port] = vlans
+		}
+	}
+	return ports, nil
+}
+
+// ListVLANs return the VLANs of `show vlan` in order
+func (e *eapi) ListVLANs(ctx context.Context) ([]int, error) {
+	results, err := e.runCmds(ctx, "show vlan")
+	if err != nil {
+		return nil, err
+	}
+	result := &struct {
+		VLANs map[string]json.RawMessage `json:"vlans"`
+	}{}
+	err = json.Unmarshal(results[0], result)
+	if err != nil {
+		return nil, backends.Errorf(backends.Transient, "invalid VLANs: %v", err)
+	}
+
+	vlans := []int{}
+	for id := range result.VLANs {
+		vid, err := strconv.Atoi(id)
+		if err != nil {
+			continue
+		}
+		vlans = append(vlans, vid)
+	}
+	sort.Ints(vlans)
+	return vlans, nil
+}
+
+// SetPortAttr set the VLANs, ACLs and shutdown of the interface, the missing
+// VLANs are created, and the ACLs are written to the access-lists of the
+// interface
+func (e *eapi) SetPortAttr(ctx context.Context, port string, configuration *v1alpha1.SwitchPortConfigurationSpec) error {
+	if configuration.MTU != nil {
+		return backends.Errorf(backends.Unsupported, "MTU isn't supported by eapi backend")
+	}
+	tagged, err := ustrings.RangeToSlice(configuration.TaggedVLANRange)
+	if err != nil {
+		return backends.NewError(backends.InvalidConfiguration, err)
+	}
+	vlans := append([]int{}, tagged...)
+	if configuration.UntaggedVLAN != nil {
+		vlans = append(vlans, *configuration.UntaggedVLAN)
+	}
+	for _, vid := range vlans {
+		if vid < 1 || vid > 4094 {
+			return backends.Errorf(backends.InvalidConfiguration, "VLAN %d is out of range 1-4094", vid)
+		}
+	}
+	entries, err := toACLEntries(configuration.ACLs)
+	if err != nil {
+		return err
+	}
+
+	cmds := []string{"enable", "configure"}
+	if len(vlans) != 0 {
+		cmds = append(cmds, "vlan "+ustrings.SliceToRange(vlans), "exit")
+	}
+	// The access-lists are created before they are bound
+	for _, version := range []string{"4", "6"} {
+		if len(entries[version]) != 0 {
+			cmds = append(cmds, "no "+aclKeyword(version)+" access-list "+aclName(port, version))
+			cmds = append(cmds, aclKeyword(version)+" access-list "+aclName(port, version))
+			cmds = append(cmds, entries[version]...)
+			cmds = append(cmds, "exit")
+		}
+	}
+
+	cmds = append(cmds, "interface "+port, "switchport")
+	cmds = append(cmds, vlanCommands(configuration.UntaggedVLAN, tagged)...)
+	for _, version := range []string{"4", "6"} {
+		if len(entries[version]) != 0 {
+			cmds = append(cmds, accessGroup(port, version))
+		} else {
+			cmds = append(cmds, "no "+accessGroup(port, version))
+		}
+	}
+	if configuration.Disable {
+		cmds = append(cmds, "shutdown")
+	} else {
+		cmds = append(cmds, "no shutdown")
+	}
+	cmds = append(cmds, "exit")
+
+	// The access-lists are deleted after they are unbound
+	for _, version := range []string{"4", "6"} {
+		if len(entries[version]) == 0 {
+			cmds = append(cmds, "no "+aclKeyword(version)+" access-list "+aclName(port, version))
+		}
+	}
+
+	_, err = e.runCmds(ctx, cmds...)
+	return err
+}
+
+// ResetPort set the interface to the default access port of VLAN 1 and
+// delete its access-lists, the VLANs are kept
+func (e *eapi) ResetPort(ctx context.Context, port string, configuration *v1alpha1.SwitchPortConfigurationSpec) error {
+	cmds := []string{
+		"enable",
+		"configure",
+		"interface " + port,
+		"switchport mode access",
+		"no switchport access vlan",
+		"no switchport trunk native vlan",
+		"no switchport trunk allowed vlan",
+		"no " + accessGroup(port, "4"),
+		"no " + accessGroup(port, "6"),
+		"no shutdown",
+		"exit",
+		"no ip access-list " + aclName(port, "4"),
+		"no ipv6 access-list " + aclName(port, "6"),
+	}
+	_, err := e.runCmds(ctx, cmds...)
+	return err
+}
+
+// Capabilities return the fields supported by eapi backend, the MTU isn't
+// supported
+func (e *eapi) Capabilities(ctx context.Context) (*v1alpha1.SwitchCapabilities, error) {
+	return &v1alpha1.SwitchCapabilities{ACLs: true, TaggedVLANs: true, NativeVLAN: true, Disable: true}, nil
+}
+
+// vlanCommands return the commands of the VLANs in the interface mode. The
+// interface is an access port if only untagged VLAN is set, otherwise it's a
+// trunk port whose native VLAN is the untagged VLAN
+func vlanCommands(untagged *int, tagged []int) []string {
+	switch {
+	case len(tagged) == 0 && untagged != nil:
+		return []string{
+			"switchport mode access",
+			"switchport access vlan " + strconv.Itoa(*untagged),
+			"no switchport trunk native vlan",
+			"no switchport trunk allowed vlan",
+		}
+	case len(tagged) == 0:
+		return []string{
+			"switchport mode trunk",
+			"no switchport access vlan",
+			"no switchport trunk native vlan",
+			"switchport trunk allowed vlan none",
+		}
+	}
+
+	// The native VLAN is tagged if there isn't untagged VLAN
+	native := "switchport trunk native vlan tag"
+	allowed := append([]int{}, tagged...)
+	if untagged != nil {
+		native = "switchport trunk native vlan " + strconv.Itoa(*untagged)
+		allowed = append(allowed, *untagged)
+	}
+	return []string{
+		"switchport mode trunk",
+		"no switchport access vlan",
+		native,
+		"switchport trunk allowed vlan " + ustrings.SliceToRange(allowed),
+	}
+}
+
+// fromInterfaceLines return the configuration of the lines in the running-config
+// of interface, the default lines aren't shown by EOS
+func fromInterfaceLines(lines map[string]bool) *v1alpha1.SwitchPortConfigurationSpec {
+	configuration := &v1alpha1.SwitchPortConfigurationSpec{Disable: lines["shutdown"]}
+
+	if !lines["switchport mode trunk"] {
+		vlan := 1
+		if value, ok := lineValue(lines, "switchport access vlan "); ok {
+			vlan, _ = strconv.Atoi(value)
+		}
+		configuration.UntaggedVLAN = &vlan
+		return configuration
+	}
+
+	allowed := "1-4094"
+	if value, ok := lineValue(lines, "switchport trunk allowed vlan "); ok {
+		allowed = value
+	}
+	if allowed == "none" {
+		allowed = ""
+	}
+	vlans, _ := ustrings.RangeToSlice(allowed)
+
+	native := 1
+	if value, ok := lineValue(lines, "switchport trunk native vlan "); ok {
+		native, _ = strconv.Atoi(value)
+	}
+	tagged := []int{}
+	for _, vid := range vlans {
+		if vid == native && !lines["switchport trunk native vlan tag"] {
+			untagged := vid
+			configuration.UntaggedVLAN = &untagged
+			continue
+		}
+		tagged = append(tagged, vid)
+	}
+	configuration.TaggedVLANRange = ustrings.SliceToRange(tagged)
+	return configuration
+}
+
+// lineValue return the rest of the line which has the prefix
+func lineValue(lines map[string]bool, prefix string) (string, bool) {
+	for line := range lines {
+		if strings.HasPrefix(line, prefix) {
+			return strings.TrimPrefix(line, prefix), true
+		}
+	}
+	return "", false
+}
+
+// aclName return the name of the access-list of the interface for the IP version
+func aclName(port string, ipVersion string) string {
+	name := "NETWORK-OPERATOR-" + strings.ReplaceAll(port, "/", "-")
+	if ipVersion == "6" {
+		name += "-V6"
+	}
+	return name
+}
+
+// aclKeyword return the keyword of the commands for the IP version
+func aclKeyword(ipVersion string) string {
+	if ipVersion == "6" {
+		return "ipv6"
+	}
+	return "ip"
+}
+
+// accessGroup return the command binding the access-list to the ingress of interface
+func accessGroup(port string, ipVersion string) string {
+	return aclKeyword(ipVersion) + " access-group " + aclName(port, ipVersion) + " in"
+}
@@ -0,0 +1,420 @@
+package eapi
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/Hellcatlk/network-operator/api/v1alpha1"
+	"github.com/Hellcatlk/network-operator/pkg/backends"
+	"github.com/Hellcatlk/network-operator/pkg/backends/switches/eapi/eapitest"
+	"github.com/Hellcatlk/network-operator/pkg/provider"
+	"github.com/Hellcatlk/network-operator/pkg/utils/credentials"
+)
+
+// serve serves the stand-in by HTTPS and return the backend trusting its
+// certificate
+func serve(t *testing.T, server *eapitest.Server, cert *credentials.Credentials) backends.Switch {
+	ts := httptest.NewTLSServer(server)
+	t.Cleanup(ts.Close)
+
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+	backend, err := New(context.Background(), &provider.SwitchConfiguration{
+		Host:        ts.URL,
+		Backend:     "eapi",
+		Credentials: cert,
+		Options:     map[string]interface{}{"caBundle": string(caBundle)},
+	})
+	if err != nil {
+		t.Fatalf("new backend failed: %v", err)
+	}
+	return backend
+}
+
+func TestEAPI(t *testing.T) {
+	vlan := 10
+	cases := []struct {
+		name              string
+		configuration     *v1alpha1.SwitchPortConfigurationSpec
+		expectedInterface *eapitest.Interface
+		expectedACLs      map[string][]string
+	}{
+		{
+			name:              "access port",
+			configuration:     &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan},
+			expectedInterface: &eapitest.Interface{Mode: "access", AccessVLAN: 10, NativeVLAN: 1, AccessGroups: map[string]string{}},
+		},
+		{
+			name:          "disabled trunk port",
+			configuration: &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan, TaggedVLANRange: "11-12", Disable: true},
+			expectedInterface: &eapitest.Interface{
+				Mode: "trunk", AccessVLAN: 1, NativeVLAN: 10, AllowedVLANs: "10-12", Shutdown: true, AccessGroups: map[string]string{},
+			},
+		},
+		{
+			name:          "trunk port without untagged VLAN",
+			configuration: &v1alpha1.SwitchPortConfigurationSpec{TaggedVLANRange: "11,13"},
+			expectedInterface: &eapitest.Interface{
+				Mode: "trunk", AccessVLAN: 1, NativeVLAN: 1, NativeTag: true, AllowedVLANs: "11,13", AccessGroups: map[string]string{},
+			},
+		},
+		{
+			name:          "port without VLAN",
+			configuration: &v1alpha1.SwitchPortConfigurationSpec{},
+			expectedInterface: &eapitest.Interface{
+				Mode: "trunk", AccessVLAN: 1, NativeVLAN: 1, AllowedVLANs: "none", AccessGroups: map[string]string{},
+			},
+		},
+		{
+			name: "ACLs",
+			configuration: &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan, ACLs: []v1alpha1.ACL{
+				{IPVersion: "6", Action: "deny", Protocol: "ALL", DestinationIP: "fd00::/8"},
+				{IPVersion: "4", Action: "allow", Protocol: "TCP", SourceIP: "10.0.0.1", DestinationPortRange: "22,8000-8080"},
+			}},
+			expectedInterface: &eapitest.Interface{
+				Mode: "access", AccessVLAN: 10, NativeVLAN: 1,
+				AccessGroups: map[string]string{"ip": "NETWORK-OPERATOR-Ethernet1-1", "ipv6": "NETWORK-OPERATOR-Ethernet1-1-V6"},
+			},
+			expectedACLs: map[string][]string{
+				"ip NETWORK-OPERATOR-Ethernet1-1": {
+					"remark network-operator index=1 ipVersion=4 action=allow protocol=TCP sourceIP=10.0.0.1 destinationPortRange=22,8000-8080",
+					"permit tcp host 10.0.0.1 any eq 22",
+					"permit tcp host 10.0.0.1 any range 8000 8080",
+				},
+				"ipv6 NETWORK-OPERATOR-Ethernet1-1-V6": {
+					"remark network-operator index=0 ipVersion=6 action=deny protocol=ALL destinationIP=fd00::/8",
+					"deny ipv6 any fd00::/8",
+				},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server := eapitest.NewServer("admin", "password", "Ethernet1/1", "Ethernet2/1")
+			backend := serve(t, server, &credentials.Credentials{Username: "admin", Password: "password"})
+
+			err := backend.IsAvailable(context.Background())
+			if err != nil {
+				t.Fatalf("IsAvailable failed: %v", err)
+			}
+
+			// Configure twice, the second one replaces the first one
+			err = backend.SetPortAttr(context.Background(), "Ethernet1/1", &v1alpha1.SwitchPortConfigurationSpec{
+				TaggedVLANRange: "20",
+				ACLs:            []v1alpha1.ACL{{IPVersion: "4", Action: "deny", Protocol: "UDP", SourcePortRange: "53"}},
+			})
+			if err != nil {
+				t.Fatalf("SetPortAttr failed: %v", err)
+			}
+			err = backend.SetPortAttr(context.Background(), "Ethernet1/1", c.configuration)
+			if err != nil {
+				t.Fatalf("SetPortAttr failed: %v", err)
+			}
+			if i := server.Interface("Ethernet1/1"); !reflect.DeepEqual(i, c.expectedInterface) {
+				t.Errorf("expected interface: %+v, got: %+v", c.expectedInterface, i)
+			}
+			for _, keyword := range []string{"ip", "ipv6"} {
+				name := aclName("Ethernet1/1", map[string]string{"ip": "4", "ipv6": "6"}[keyword])
+				if entries := server.AccessList(keyword, name); !reflect.DeepEqual(entries, c.expectedACLs[keyword+" "+name]) {
+					t.Errorf("expected %s access-list: %v, got: %v", keyword, c.expectedACLs[keyword+" "+name], entries)
+				}
+			}
+
+			configuration, err := backend.GetPortAttr(context.Background(), "Ethernet1/1")
+			if err != nil {
+				t.Fatalf("GetPortAttr failed: %v", err)
+			}
+			if !c.configuration.IsEqual(configuration) {
+				t.Errorf("expected configuration: %+v, got: %+v", c.configuration, configuration)
+			}
+
+			err = backend.ResetPort(context.Background(), "Ethernet1/1", c.configuration)
+			if err != nil {
+				t.Fatalf("ResetPort failed: %v", err)
+			}
+			if i := server.Interface("Ethernet1/1"); !reflect.DeepEqual(i, server.Interface("Ethernet2/1")) {
+				t.Errorf("expected interface is reset, got: %+v", i)
+			}
+			if entries := server.AccessList("ip", "NETWORK-OPERATOR-Ethernet1-1"); entries != nil {
+				t.Errorf("expected access-list is deleted, got: %v", entries)
+			}
+			if vlans := server.VLANs(); vlans[len(vlans)-1] != 20 {
+				t.Errorf("expected VLANs are kept, got: %v", vlans)
+			}
+		})
+	}
+}
+
+func TestGetPortAttr(t *testing.T) {
+	vlan1 := 1
+	vlan10 := 10
+	cases := []struct {
+		name                  string
+		cmds                  []string
+		expectedConfiguration *v1alpha1.SwitchPortConfigurationSpec
+	}{
+		{
+			name:                  "default port",
+			expectedConfiguration: &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan1},
+		},
+		{
+			name:                  "trunk port allowing all VLANs",
+			cmds:                  []string{"switchport mode trunk", "shutdown"},
+			expectedConfiguration: &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan1, TaggedVLANRange: "2-4094", Disable: true},
+		},
+		{
+			name:                  "native VLAN isn't allowed",
+			cmds:                  []string{"switchport mode trunk", "switchport trunk native vlan 10", "switchport trunk allowed vlan 20-21"},
+			expectedConfiguration: &v1alpha1.SwitchPortConfigurationSpec{TaggedVLANRange: "20-21"},
+		},
+		{
+			name: "changed access-list",
+			cmds: []string{
+				"ip access-list NETWORK-OPERATOR-Ethernet1",
+				"remark network-operator index=0 ipVersion=4 action=deny protocol=ALL",
+				"deny ip any any",
+				"remark network-operator index=1 ipVersion=4 action=allow protocol=UDP",
+				"permit tcp any any",
+				"exit",
+				"interface Ethernet1",
+				"switchport access vlan 10",
+				"ip access-group NETWORK-OPERATOR-Ethernet1 in",
+			},
+			expectedConfiguration: &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan10, ACLs: []v1alpha1.ACL{
+				{IPVersion: "4", Action: "deny", Protocol: "ALL"},
+			}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server := eapitest.NewServer("admin", "password", "Ethernet1")
+			err := server.Configure(append([]string{"interface Ethernet1"}, c.cmds...)...)
+			if err != nil {
+				t.Fatalf("configure failed: %v", err)
+			}
+			backend := serve(t, server, &credentials.Credentials{Username: "admin", Password: "password"})
+			configuration, err := backend.GetPortAttr(context.Background(), "Ethernet1")
+			if err != nil {
+				t.Fatalf("GetPortAttr failed: %v", err)
+			}
+			if !reflect.DeepEqual(configuration, c.expectedConfiguration) {
+				t.Errorf("expected configuration: %+v, got: %+v", c.expectedConfiguration, configuration)
+			}
+		})
+	}
+}
+
+func TestInventory(t *testing.T) {
+	server := eapitest.NewServer("admin", "password", "Ethernet1", "Ethernet2", "Ethernet3")
+	err := server.Configure(
+		"vlan 10", "exit", "vlan 11", "exit", "vlan 20", "exit",
+		"interface Ethernet1", "switchport mode trunk", "switchport trunk native vlan 10", "switchport trunk allowed vlan 10-11", "exit",
+		"interface Ethernet3", "switchport mode trunk", "switchport trunk allowed vlan none",
+	)
+	if err != nil {
+		t.Fatalf("configure failed: %v", err)
+	}
+	inventory := serve(t, server, &credentials.Credentials{Username: "admin", Password: "password"}).(backends.Inventory)
+
+	ports, err := inventory.ListPortVLANs(context.Background())
+	if err != nil {
+		t.Fatalf("ListPortVLANs failed: %v", err)
+	}
+	if expected := map[string][]int{"Ethernet1": {10, 11}, "Ethernet2": {1}}; !reflect.DeepEqual(ports, expected) {
+		t.Errorf("expected ports: %v, got: %v", expected, ports)
+	}
+	vlans, err := inventory.ListVLANs(context.Background())
+	if err != nil {
+		t.Fatalf("ListVLANs failed: %v", err)
+	}
+	if expected := []int{1, 10, 11, 20}; !reflect.DeepEqual(vlans, expected) {
+		t.Errorf("expected VLANs: %v, got: %v", expected, vlans)
+	}
+}
+
+func TestEAPIError(t *testing.T) {
+	vlan := 4095
+	mtu := 1500
+	cases := []struct {
+		name              string
+		port              string
+		configuration     *v1alpha1.SwitchPortConfigurationSpec
+		credentials       *credentials.Credentials
+		expectedErrorType backends.ErrorType
+	}{
+		{
+			name:              "port not found",
+			port:              "Ethernet9",
+			configuration:     &v1alpha1.SwitchPortConfigurationSpec{},
+			expectedErrorType: backends.PortNotFound,
+		},
+		{
+			name:              "vlan out of range",
+			port:              "Ethernet1",
+			configuration:     &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan},
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+		{
+			name:              "MTU",
+			port:              "Ethernet1",
+			configuration:     &v1alpha1.SwitchPortConfigurationSpec{MTU: &mtu},
+			expectedErrorType: backends.Unsupported,
+		},
+		{
+			name:              "ACL without ipVersion",
+			port:              "Ethernet1",
+			configuration:     &v1alpha1.SwitchPortConfigurationSpec{ACLs: []v1alpha1.ACL{{Action: "deny", Protocol: "ALL"}}},
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+		{
+			name: "ICMP with port range",
+			port: "Ethernet1",
+			configuration: &v1alpha1.SwitchPortConfigurationSpec{ACLs: []v1alpha1.ACL{
+				{IPVersion: "4", Action: "deny", Protocol: "ICMP", DestinationPortRange: "22"},
+			}},
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+		{
+			name:              "wrong password",
+			port:              "Ethernet1",
+			configuration:     &v1alpha1.SwitchPortConfigurationSpec{},
+			credentials:       &credentials.Credentials{Username: "admin", Password: "wrong"},
+			expectedErrorType: backends.Authentication,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server := eapitest.NewServer("admin", "password", "Ethernet1")
+			cert := c.credentials
+			if cert == nil {
+				cert = &credentials.Credentials{Username: "admin", Password: "password"}
+			}
+			backend := serve(t, server, cert)
+			err := backend.SetPortAttr(context.Background(), c.port, c.configuration)
+			if errorType := backends.TypeOf(err); errorType != c.expectedErrorType {
+				t.Errorf("expected error type: %q, got: %v", c.expectedErrorType, err)
+			}
+		})
+	}
+}
+
+func TestUntrustedCertificate(t *testing.T) {
+	ts := httptest.NewTLSServer(eapitest.NewServer("admin", "password"))
+	defer ts.Close()
+
+	cases := []struct {
+		name              string
+		options           map[string]interface{}
+		expectedErrorType backends.ErrorType
+	}{
+		{
+			name:              "system CA",
+			expectedErrorType: backends.Transient,
+		},
+		{
+			name:    "insecure",
+			options: map[string]interface{}{"insecureSkipVerify": "true"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			backend, err := New(context.Background(), &provider.SwitchConfiguration{
+				Host:        ts.URL,
+				Backend:     "eapi",
+				Credentials: &credentials.Credentials{Username: "admin", Password: "password"},
+				Options:     c.options,
+			})
+			if err != nil {
+				t.Fatalf("new backend failed: %v", err)
+			}
+			err = backend.IsAvailable(context.Background())
+			if errorType := backends.TypeOf(err); errorType != c.expectedErrorType {
+				t.Errorf("expected error type: %q, got: %v", c.expectedErrorType, err)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	cases := []struct {
+		name               string
+		host               string
+		options            map[string]interface{}
+		withoutCredentials bool
+		expectedURL        string
+		expectedErrorType  backends.ErrorType
+	}{
+		{
+			name:        "host",
+			host:        "192.168.0.1",
+			expectedURL: "https://192.168.0.1/command-api",
+		},
+		{
+			name:        "host with port",
+			host:        "switch.example.com:8443",
+			expectedURL: "https://switch.example.com:8443/command-api",
+		},
+		{
+			name:        "URL",
+			host:        "http://192.168.0.1:8080",
+			expectedURL: "http://192.168.0.1:8080/command-api",
+		},
+		{
+			name:              "unsupported scheme",
+			host:              "ssh://192.168.0.1",
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+		{
+			name:              "empty host",
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+		{
+			name:               "without credentials",
+			host:               "192.168.0.1",
+			withoutCredentials: true,
+			expectedErrorType:  backends.InvalidConfiguration,
+		},
+		{
+			name:              "invalid caBundle",
+			host:              "192.168.0.1",
+			options:           map[string]interface{}{"caBundle": "certificate"},
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+		{
+			name:              "invalid insecureSkipVerify",
+			host:              "192.168.0.1",
+			options:           map[string]interface{}{"insecureSkipVerify": "yes"},
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+		{
+			name:              "unknown option",
+			host:              "192.168.0.1",
+			options:           map[string]interface{}{"database": "4"},
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cert := &credentials.Credentials{Username: "admin", Password: "password"}
+			if c.withoutCredentials {
+				cert = nil
+			}
+			backend, err := New(context.Background(), &provider.SwitchConfiguration{
+				Host: c.host, Backend: "eapi", Credentials: cert, Options: c.options,
+			})
+			if errorType := backends.TypeOf(err); errorType != c.expectedErrorType {
+				t.Errorf("expected error type: %q, got: %v", c.expectedErrorType, err)
+			}
+			if err == nil && backend.(*eapi).url != c.expectedURL {
+				t.Errorf("expected URL: %s, got: %s", c.expectedURL, backend.(*eapi).url)
+			}
+		})
+	}
+}
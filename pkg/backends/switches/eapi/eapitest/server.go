@@ -0,0 +1,533 @@
+// Package eapitest provides an eAPI stand-in of Arista EOS, it's an
+// http.Handler running the commands used by the eapi backend on an in-memory
+// configuration, the commands are checked like the CLI of EOS.
+package eapitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	ustrings "github.com/Hellcatlk/network-operator/pkg/utils/strings"
+)
+
+// Interface is the switchport configuration of an interface
+type Interface struct {
+	Mode       string
+	AccessVLAN int
+	NativeVLAN int
+	NativeTag  bool
+	// AllowedVLANs is empty if all VLANs are allowed
+	AllowedVLANs string
+	Shutdown     bool
+	// AccessGroups are the ingress access-lists of `ip` and `ipv6`
+	AccessGroups map[string]string
+}
+
+// defaultInterface return the configuration of a new interface
+func defaultInterface() *Interface {
+	return &Interface{Mode: "access", AccessVLAN: 1, NativeVLAN: 1, AccessGroups: map[string]string{}}
+}
+
+// lines return the running-config of the interface, the default lines are
+// hidden like EOS
+func (i *Interface) lines() []string {
+	lines := []string{}
+	if i.Mode == "trunk" {
+		lines = append(lines, "switchport mode trunk")
+	}
+	if i.AccessVLAN != 1 {
+		lines = append(lines, fmt.Sprintf("switchport access vlan %d", i.AccessVLAN))
+	}
+	if i.NativeTag {
+		lines = append(lines, "switchport trunk native vlan tag")
+	} else if i.NativeVLAN != 1 {
+		lines = append(lines, fmt.Sprintf("switchport trunk native vlan %d", i.NativeVLAN))
+	}
+	if i.AllowedVLANs != "" {
+		lines = append(lines, "switchport trunk allowed vlan "+i.AllowedVLANs)
+	}
+	for _, keyword := range []string{"ip", "ipv6"} {
+		if name := i.AccessGroups[keyword]; name != "" {
+			lines = append(lines, keyword+" access-group "+name+" in")
+		}
+	}
+	if i.Shutdown {
+		lines = append(lines, "shutdown")
+	}
+	return lines
+}
+
+// section return the section of the interface in the running-config in JSON
+func (i *Interface) section() map[string]interface{} {
+	lines := map[string]interface{}{}
+	for _, line := range i.lines() {
+		lines[line] = nil
+	}
+	return map[string]interface{}{"cmds": lines, "comments": []string{}}
+}
+
+// Server is the eAPI stand-in
+type Server struct {
+	mutex      sync.Mutex
+	username   string
+	password   string
+	vlans      map[int]bool
+	interfaces map[string]*Interface
+	// accessLists are the entries of access-lists by `ip <name>` and `ipv6 <name>`
+	accessLists map[string][]string
+}
+
+// NewServer return a server which has the interfaces in the default
+// configuration and VLAN 1, the requests are authenticated by the username
+// and password
+func NewServer(username string, password string, interfaces ...string) *Server {
+	s := &Server{
+		username:    username,
+		password:    password,
+		vlans:       map[int]bool{1: true},
+		interfaces:  map[string]*Interface{},
+		accessLists: map[string][]string{},
+	}
+	for _, name := range interfaces {
+		s.interfaces[name] = defaultInterface()
+	}
+	return s
+}
+
+// Interface return a copy of the configuration of interface, it's nil if the
+// interface doesn't exist
+func (s *Server) Interface(name string) *Interface {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	i := s.interfaces[name]
+	if i == nil {
+		return nil
+	}
+	copied := *i
+	copied.AccessGroups = map[string]string{}
+	for keyword, acl := range i.AccessGroups {
+		copied.AccessGroups[keyword] = acl
+	}
+	return &copied
+}
+
+// AccessList return the entries of access-list, the keyword is `ip` or `ipv6`,
+// it's nil if the access-list doesn't exist
+func (s *Server) AccessList(keyword string, name string) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]string(nil), s.accessLists[keyword+" "+name]...)
+}
+
+// VLANs return the sorted VLANs
+func (s *Server) VLANs() []int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	vlans := []int{}
+	for vid := range s.vlans {
+		vlans = append(vlans, vid)
+	}
+	sort.Ints(vlans)
+	return vlans
+}
+
+// Configure runs the commands in the configuration mode, it's used to
+// prepare the configuration of tests
+func (s *Server) Configure(cmds ...string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	state := &session{mode: "config"}
+	for _, cmd := range cmds {
+		_, err := s.run(state, cmd)
+		if err != nil {
+			return fmt.Errorf("%q failed: %v", cmd, err)
+		}
+	}
+	return nil
+}
+
+// The JSON-RPC 2.0 of eAPI
+type request struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  struct {
+		Version int      `json:"version"`
+		Cmds    []string `json:"cmds"`
+		Format  string   `json:"format"`
+	} `json:"params"`
+	ID string `json:"id"`
+}
+
+type rpcError struct {
+	Code    int           `json:"code"`
+	Message string        `json:"message"`
+	Data    []interface{} `json:"data,omitempty"`
+}
+
+type response struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      string        `json:"id"`
+	Result  []interface{} `json:"result,omitempty"`
+	Error   *rpcError     `json:"error,omitempty"`
+}
+
+// ServeHTTP runs the commands of `runCmds` in order, the commands after the
+// failed one aren't run and the previous ones aren't rolled back like EOS
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/command-api" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	username, password, ok := r.BasicAuth()
+	if !ok || username != s.username || password != s.password {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	req := &request{}
+	err := json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		writeResponse(w, &response{JSONRPC: "2.0", Error: &rpcError{Code: -32700, Message: "Parse error"}})
+		return
+	}
+	resp := &response{JSONRPC: "2.0", ID: req.ID}
+	if req.Method != "runCmds" || req.Params.Version != 1 || req.Params.Format != "json" {
+		resp.Error = &rpcError{Code: -32602, Message: "Invalid params"}
+		writeResponse(w, resp)
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	state := &session{mode: "exec"}
+	results := []interface{}{}
+	for i, cmd := range req.Params.Cmds {
+		result, err := s.run(state, cmd)
+		if err != nil {
+			resp.Error = &rpcError{
+				Code:    1002,
+				Message: fmt.Sprintf("CLI command %d of %d '%s' failed: invalid command", i+1, len(req.Params.Cmds), cmd),
+				Data:    append(results, map[string]interface{}{"errors": []string{err.Error()}}),
+			}
+			writeResponse(w, resp)
+			return
+		}
+		results = append(results, result)
+	}
+	resp.Result = results
+	writeResponse(w, resp)
+}
+
+func writeResponse(w http.ResponseWriter, resp *response) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// session is the CLI session of a request
+type session struct {
+	// mode is `exec`, `enable`, `config`, `config-vlan`, `config-if` or `config-acl`
+	mode string
+	// target is the interface or access-list being configured
+	target string
+}
+
+// run runs the command and return its result, the mutex must be locked
+func (s *Server) run(state *session, cmd string) (interface{}, error) {
+	cmd = strings.TrimSpace(cmd)
+	if strings.HasPrefix(cmd, "show ") {
+		return s.show(cmd)
+	}
+
+	switch {
+	case cmd == "enable":
+		if state.mode == "exec" {
+			state.mode = "enable"
+		}
+		return map[string]interface{}{}, nil
+	case cmd == "configure":
+		if state.mode != "enable" {
+			return nil, fmt.Errorf("Invalid input (privileged mode required)")
+		}
+		state.mode = "config"
+		return map[string]interface{}{}, nil
+	case cmd == "end" && strings.HasPrefix(state.mode, "config"):
+		state.mode, state.target = "enable", ""
+		return map[string]interface{}{}, nil
+	case cmd == "exit" && strings.HasPrefix(state.mode, "config-"):
+		state.mode, state.target = "config", ""
+		return map[string]interface{}{}, nil
+	}
+
+	var err error
+	switch state.mode {
+	case "config", "config-vlan", "config-if", "config-acl":
+		// The commands of configuration mode can be run in the sub-modes like EOS
+		err = s.configure(state, cmd)
+		if err == errInvalidInput && state.mode == "config-if" {
+			err = s.configureInterface(s.interfaces[state.target], cmd)
+		}
+		if err == errInvalidInput && state.mode == "config-acl" {
+			err = s.configureAccessList(state.target, cmd)
+		}
+	default:
+		err = errInvalidInput
+	}
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{}, nil
+}
+
+// errInvalidInput is the error of the unknown commands
+var errInvalidInput = fmt.Errorf("Invalid input")
+
+// show runs the show commands
+func (s *Server) show(cmd string) (interface{}, error) {
+	fields := strings.Fields(cmd)
+	switch {
+	case cmd == "show version":
+		return map[string]interface{}{"modelName": "vEOS-lab", "version": "4.28.0F", "serialNumber": "eapitest"}, nil
+	case len(fields) == 3 && fields[1] == "interfaces":
+		i := s.interfaces[fields[2]]
+		if i == nil {
+			return nil, fmt.Errorf("Interface does not exist")
+		}
+		status := "connected"
+		if i.Shutdown {
+			status = "disabled"
+		}
+		return map[string]interface{}{
+			"interfaces": map[string]interface{}{fields[2]: map[string]interface{}{"name": fields[2], "interfaceStatus": status}},
+		}, nil
+	case cmd == "show vlan":
+		vlans := map[string]interface{}{}
+		for vid := range s.vlans {
+			vlans[strconv.Itoa(vid)] = map[string]interface{}{"name": fmt.Sprintf("VLAN%04d", vid), "status": "active"}
+		}
+		return map[string]interface{}{"vlans": vlans}, nil
+	case cmd == "show running-config":
+		sections := map[string]interface{}{}
+		for name, i := range s.interfaces {
+			sections["interface "+name] = i.section()
+		}
+		return map[string]interface{}{"cmds": sections, "header": []string{}}, nil
+	case len(fields) == 4 && fields[1] == "running-config" && fields[2] == "interfaces":
+		sections := map[string]interface{}{}
+		if i := s.interfaces[fields[3]]; i != nil {
+			sections["interface "+fields[3]] = i.section()
+		}
+		return map[string]interface{}{"cmds": sections, "header": []string{}}, nil
+	case len(fields) == 4 && (fields[1] == "ip" || fields[1] == "ipv6") && fields[2] == "access-lists":
+		lists := []interface{}{}
+		if entries, ok := s.accessLists[fields[1]+" "+fields[3]]; ok {
+			sequence := []interface{}{}
+			for i, entry := range entries {
+				sequence = append(sequence, map[string]interface{}{"sequenceNumber": (i + 1) * 10, "text": entry})
+			}
+			lists = append(lists, map[string]interface{}{"name": fields[3], "sequence": sequence})
+		}
+		return map[string]interface{}{"aclList": lists}, nil
+	}
+	return nil, errInvalidInput
+}
+
+// configure runs the commands of configuration mode
+func (s *Server) configure(state *session, cmd string) error {
+	fields := strings.Fields(cmd)
+	switch {
+	case len(fields) == 2 && fields[0] == "vlan":
+		vlans, err := parseVLANs(fields[1])
+		if err != nil {
+			return err
+		}
+		for _, vid := range vlans {
+			s.vlans[vid] = true
+		}
+		state.mode, state.target = "config-vlan", fields[1]
+	case len(fields) == 2 && fields[0] == "interface":
+		if s.interfaces[fields[1]] == nil {
+			return fmt.Errorf("Interface does not exist")
+		}
+		state.mode, state.target = "config-if", fields[1]
+	case len(fields) == 3 && (fields[0] == "ip" || fields[0] == "ipv6") && fields[1] == "access-list":
+		key := fields[0] + " " + fields[2]
+		if _, ok := s.accessLists[key]; !ok {
+			s.accessLists[key] = []string{}
+		}
+		state.mode, state.target = "config-acl", key
+	case len(fields) == 4 && fields[0] == "no" && (fields[1] == "ip" || fields[1] == "ipv6") && fields[2] == "access-list":
+		delete(s.accessLists, fields[1]+" "+fields[3])
+	default:
+		return errInvalidInput
+	}
+	return nil
+}
+
+// configureInterface runs the commands of interface configuration mode
+func (s *Server) configureInterface(i *Interface, cmd string) error {
+	fields := strings.Fields(cmd)
+	switch {
+	case cmd == "switchport":
+	case cmd == "shutdown":
+		i.Shutdown = true
+	case cmd == "no shutdown":
+		i.Shutdown = false
+	case cmd == "switchport mode access" || cmd == "switchport mode trunk":
+		i.Mode = fields[2]
+	case len(fields) == 4 && strings.HasPrefix(cmd, "switchport access vlan "):
+		vid, err := parseVLAN(fields[3])
+		if err != nil {
+			return err
+		}
+		i.AccessVLAN = vid
+	case cmd == "no switchport access vlan":
+		i.AccessVLAN = 1
+	case cmd == "switchport trunk native vlan tag":
+		i.NativeVLAN, i.NativeTag = 1, true
+	case len(fields) == 5 && strings.HasPrefix(cmd, "switchport trunk native vlan "):
+		vid, err := parseVLAN(fields[4])
+		if err != nil {
+			return err
+		}
+		i.NativeVLAN, i.NativeTag = vid, false
+	case cmd == "no switchport trunk native vlan":
+		i.NativeVLAN, i.NativeTag = 1, false
+	case len(fields) == 5 && strings.HasPrefix(cmd, "switchport trunk allowed vlan "):
+		switch fields[4] {
+		case "all":
+			i.AllowedVLANs = ""
+		case "none":
+			i.AllowedVLANs = "none"
+		default:
+			vlans, err := parseVLANs(fields[4])
+			if err != nil {
+				return err
+			}
+			i.AllowedVLANs = ustrings.SliceToRange(vlans)
+		}
+	case cmd == "no switchport trunk allowed vlan":
+		i.AllowedVLANs = ""
+	case len(fields) == 4 && (fields[0] == "ip" || fields[0] == "ipv6") && fields[1] == "access-group" && fields[3] == "in":
+		if _, ok := s.accessLists[fields[0]+" "+fields[2]]; !ok {
+			return fmt.Errorf("Access-list %s doesn't exist", fields[2])
+		}
+		i.AccessGroups[fields[0]] = fields[2]
+	case len(fields) == 5 && fields[0] == "no" && (fields[1] == "ip" || fields[1] == "ipv6") && fields[2] == "access-group" && fields[4] == "in":
+		delete(i.AccessGroups, fields[1])
+	default:
+		return errInvalidInput
+	}
+	return nil
+}
+
+// protocols are the protocols of the rules for `ip` and `ipv6` access-lists
+var protocols = map[string][]string{
+	"ip":   {"ip", "tcp", "udp", "icmp"},
+	"ipv6": {"ipv6", "tcp", "udp", "icmpv6"},
+}
+
+// configureAccessList runs the commands of access-list configuration mode, the
+// rules are appended to the access-list
+func (s *Server) configureAccessList(key string, cmd string) error {
+	fields := strings.Fields(cmd)
+	if len(fields) > 1 && fields[0] == "remark" {
+		s.accessLists[key] = append(s.accessLists[key], cmd)
+		return nil
+	}
+	if len(fields) < 4 || (fields[0] != "permit" && fields[0] != "deny") {
+		return errInvalidInput
+	}
+
+	keyword := strings.Fields(key)[0]
+	if !ustrings.SliceContains(protocols[keyword], fields[1]) {
+		return errInvalidInput
+	}
+	rest := fields[2:]
+	for _, direction := range []string{"source", "destination"} {
+		var err error
+		rest, err = parseMatch(keyword, fields[1], rest)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %v", direction, err)
+		}
+	}
+	if len(rest) != 0 {
+		return errInvalidInput
+	}
+	s.accessLists[key] = append(s.accessLists[key], cmd)
+	return nil
+}
+
+// parseMatch parses the address and ports of the rule, return the rest fields
+func parseMatch(keyword string, protocol string, fields []string) ([]string, error) {
+	if len(fields) == 0 {
+		return nil, errInvalidInput
+	}
+	switch {
+	case fields[0] == "any":
+		fields = fields[1:]
+	case fields[0] == "host" && len(fields) > 1:
+		if strings.Contains(fields[1], ":") != (keyword == "ipv6") {
+			return nil, fmt.Errorf("invalid address %s", fields[1])
+		}
+		fields = fields[2:]
+	case strings.Contains(fields[0], "/"):
+		if strings.Contains(fields[0], ":") != (keyword == "ipv6") {
+			return nil, fmt.Errorf("invalid prefix %s", fields[0])
+		}
+		fields = fields[1:]
+	default:
+		return nil, errInvalidInput
+	}
+
+	count := 0
+	switch {
+	case len(fields) > 1 && fields[0] == "eq":
+		count = 1
+	case len(fields) > 2 && fields[0] == "range":
+		count = 2
+	default:
+		return fields, nil
+	}
+	if protocol != "tcp" && protocol != "udp" {
+		return nil, fmt.Errorf("ports require tcp or udp")
+	}
+	for _, port := range fields[1 : 1+count] {
+		if value, err := strconv.Atoi(port); err != nil || value < 0 || value > 65535 {
+			return nil, fmt.Errorf("invalid port %s", port)
+		}
+	}
+	return fields[1+count:], nil
+}
+
+func parseVLAN(value string) (int, error) {
+	vid, err := strconv.Atoi(value)
+	if err != nil || vid < 1 || vid > 4094 {
+		return 0, fmt.Errorf("Invalid input (VLAN %s is out of range 1-4094)", value)
+	}
+	return vid, nil
+}
+
+func parseVLANs(list string) ([]int, error) {
+	vlans, err := ustrings.RangeToSlice(list)
+	if err != nil || len(vlans) == 0 {
+		return nil, fmt.Errorf("Invalid input (invalid VLAN list %s)", list)
+	}
+	for _, vid := range vlans {
+		if _, err := parseVLAN(strconv.Itoa(vid)); err != nil {
+			return nil, err
+		}
+	}
+	return vlans, nil
+}
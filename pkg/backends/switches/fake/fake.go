@@ -1,38 +1,465 @@
+// Package fake is the switch backend simulating switches in memory, the
+// switches are identified by their hosts and kept by the process, so the
+// configuration of ports is remembered across the backends. The VLANs are
+// limited, the fields of configuration can be unsupported, and the faults are
+// injected by the options for testing.
 package fake
 
 import (
 	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/Hellcatlk/network-operator/api/v1alpha1"
 	"github.com/Hellcatlk/network-operator/pkg/backends"
 	"github.com/Hellcatlk/network-operator/pkg/provider"
+	ustrings "github.com/Hellcatlk/network-operator/pkg/utils/strings"
 )
 
-// New return fake backend
+var (
+	mutex    sync.Mutex
+	switches = map[string]*Switch{}
+)
+
+// New return the simulated switch of host, the switch is created if it
+// doesn't exist, and its options are replaced by the configuration
 func New(ctx context.Context, config *provider.SwitchConfiguration) (backends.Switch, error) {
-	return &fake{}, nil
+	if config == nil {
+		return nil, fmt.Errorf("configure of switch is nil")
+	}
+	o, err := parseOptions(config.Options)
+	if err != nil {
+		return nil, backends.NewError(backends.InvalidConfiguration, err)
+	}
+
+	mutex.Lock()
+	s, ok := switches[config.Host]
+	if !ok {
+		s = &Switch{ports: map[string]*v1alpha1.SwitchPortConfigurationSpec{}, vlans: map[int]bool{1: true}}
+		switches[config.Host] = s
+	}
+	mutex.Unlock()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.rand == nil || o.seed != s.options.seed {
+		seed := o.seed
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		s.rand = rand.New(rand.NewSource(seed)) // #nosec G404
+	}
+	s.options = o
+	return s, nil
 }
 
-// Test just for test
-type fake struct {
+// Lookup return the simulated switch of host, it's nil if the switch hasn't
+// been created by New
+func Lookup(host string) *Switch {
+	mutex.Lock()
+	defer mutex.Unlock()
+	return switches[host]
+}
+
+// Delete forgets the simulated switch of host
+func Delete(host string) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	delete(switches, host)
+}
+
+// Switch is a simulated switch, its ports are access ports of VLAN 1 by
+// default
+type Switch struct {
+	mutex   sync.Mutex
+	ports   map[string]*v1alpha1.SwitchPortConfigurationSpec
+	vlans   map[int]bool
+	options *options
+	rand    *rand.Rand
+}
+
+// options of the simulated switch
+type options struct {
+	// ports are the ports of switch, any port exists if it's empty
+	ports []string
+	// vlans are the VLANs can be used, it's 1-4094 if it's empty
+	vlans []int
+	// maxVLANs is the size of VLAN table, it's unlimited if it's 0
+	maxVLANs int
+	// latency delays every operation
+	latency time.Duration
+	// timeout blocks every operation until the context is done
+	timeout bool
+	// authFailure fails every operation with the Authentication error
+	authFailure bool
+	// unreachable fails every operation with the Transient error
+	unreachable bool
+	// failPorts are the ports whose changes fail with the Transient error
+	// after their VLANs are changed
+	failPorts []string
+	// driftPercent is the percent of reading a port changed out of band
+	driftPercent int
+	// seed is the seed of drift, it's random if it's 0
+	seed int64
+	// capabilities are the fields of configuration supported
+	capabilities *v1alpha1.SwitchCapabilities
+}
+
+// parseOptions parse the options of backend, the unknown options are ignored
+func parseOptions(values map[string]interface{}) (*options, error) {
+	o := &options{capabilities: backends.AllCapabilities()}
+	var err error
+	for key, value := range values {
+		v := fmt.Sprint(value)
+		switch key {
+		case "ports":
+			o.ports = splitList(v)
+		case "vlanRange":
+			o.vlans, err = ustrings.RangeToSlice(v)
+			if err == nil {
+				for _, vid := range o.vlans {
+					if vid < 1 || vid > 4094 {
+						err = fmt.Errorf("VLAN %d is out of range 1-4094", vid)
+					}
+				}
+			}
+		case "maxVLANs":
+			o.maxVLANs, err = strconv.Atoi(v)
+		case "latency":
+			o.latency, err = time.ParseDuration(v)
+		case "timeout":
+			o.timeout, err = strconv.ParseBool(v)
+		case "authFailure":
+			o.authFailure, err = strconv.ParseBool(v)
+		case "unreachable":
+			o.unreachable, err = strconv.ParseBool(v)
+		case "failPorts":
+			o.failPorts = splitList(v)
+		case "driftPercent":
+			o.driftPercent, err = strconv.Atoi(v)
+			if err == nil && (o.driftPercent < 0 || o.driftPercent > 100) {
+				err = fmt.Errorf("it isn't in range 0-100")
+			}
+		case "seed":
+			o.seed, err = strconv.ParseInt(v, 10, 64)
+		case "unsupported":
+			err = o.unsupport(splitList(v))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid option %s %q of fake backend: %v", key, v, err)
+		}
+	}
+	return o, nil
+}
+
+// unsupport removes the fields from the capabilities
+func (o *options) unsupport(fields []string) error {
+	supported := map[string]*bool{
+		"acls":        &o.capabilities.ACLs,
+		"taggedVLANs": &o.capabilities.TaggedVLANs,
+		"nativeVLAN":  &o.capabilities.NativeVLAN,
+		"disable":     &o.capabilities.Disable,
+		"mtu":         &o.capabilities.MTU,
+	}
+	for _, field := range fields {
+		if supported[field] == nil {
+			return fmt.Errorf("unknown field %q", field)
+		}
+		*supported[field] = false
+	}
+	return nil
+}
+
+// splitList return the items of comma-separated list
+func splitList(list string) []string {
+	items := []string{}
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
 
 // IsAvailable check switch is available or not
-func (t *fake) IsAvailable() error {
+func (s *Switch) IsAvailable(ctx context.Context) error {
+	return s.begin(ctx, "")
+}
+
+// GetPortAttr return the configuration of port, it may be drifted by the
+// driftPercent option
+func (s *Switch) GetPortAttr(ctx context.Context, port string) (*v1alpha1.SwitchPortConfigurationSpec, error) {
+	err := s.begin(ctx, port)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.rand.Intn(100) < s.options.driftPercent {
+		s.drift(port)
+	}
+	return s.port(port).DeepCopy(), nil
+}
+
+// SetPortAttr set the configuration of port, the VLANs are created if they
+// don't exist
+func (s *Switch) SetPortAttr(ctx context.Context, port string, configuration *v1alpha1.SwitchPortConfigurationSpec) error {
+	err := s.begin(ctx, port)
+	if err != nil {
+		return err
+	}
+	if configuration == nil {
+		configuration = &v1alpha1.SwitchPortConfigurationSpec{}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	err = s.options.capabilities.Verify(configuration)
+	if err != nil {
+		return backends.NewError(backends.Unsupported, err)
+	}
+	err = s.createVLANs(configuration)
+	if err != nil {
+		return err
+	}
+	if ustrings.SliceContains(s.options.failPorts, port) {
+		current := s.port(port)
+		current.UntaggedVLAN = copyVLAN(configuration.UntaggedVLAN)
+		current.TaggedVLANRange = configuration.TaggedVLANRange
+		return backends.Errorf(backends.Transient, "configure port %s failed after its VLANs are changed", port)
+	}
+	s.ports[port] = configuration.DeepCopy()
+	return nil
+}
+
+// ResetPort set the port to an access port of VLAN 1 and enables it, the MTU
+// is kept
+func (s *Switch) ResetPort(ctx context.Context, port string, configuration *v1alpha1.SwitchPortConfigurationSpec) error {
+	err := s.begin(ctx, port)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	vlan := 1
+	current := s.port(port)
+	current.UntaggedVLAN = &vlan
+	current.TaggedVLANRange = ""
+	if ustrings.SliceContains(s.options.failPorts, port) {
+		return backends.Errorf(backends.Transient, "reset port %s failed after its VLANs are changed", port)
+	}
+	s.ports[port] = &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: current.UntaggedVLAN, MTU: current.MTU}
 	return nil
 }
 
-// GetPortAttr just for test
-func (t *fake) GetPortAttr(ctx context.Context, name string) (*v1alpha1.SwitchPortConfigurationSpec, error) {
-	return &v1alpha1.SwitchPortConfigurationSpec{}, nil
+// Capabilities return the fields supported, they are all of the fields except
+// the ones of the unsupported option
+func (s *Switch) Capabilities(ctx context.Context) (*v1alpha1.SwitchCapabilities, error) {
+	err := s.begin(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	capabilities := *s.options.capabilities
+	return &capabilities, nil
 }
 
-// SetPortAttr just for test
-func (t *fake) SetPortAttr(ctx context.Context, name string, configuration *v1alpha1.SwitchPortConfigurationSpec) error {
+// ListPortVLANs return the VLANs of the ports, the ports which haven't been
+// configured are in VLAN 1
+func (s *Switch) ListPortVLANs(ctx context.Context) (map[string][]int, error) {
+	err := s.begin(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	ports := map[string][]int{}
+	for _, port := range s.options.ports {
+		ports[port] = []int{1}
+	}
+	for port, configuration := range s.ports {
+		vlanRange, err := configuration.VLANRange()
+		if err != nil {
+			return nil, backends.NewError(backends.InvalidConfiguration, err)
+		}
+		vlans, err := ustrings.RangeToSlice(vlanRange)
+		if err != nil {
+			return nil, backends.NewError(backends.InvalidConfiguration, err)
+		}
+		if len(vlans) == 0 {
+			delete(ports, port)
+			continue
+		}
+		ports[port] = vlans
+	}
+	return ports, nil
+}
+
+// ListVLANs return the VLANs created on the switch in order
+func (s *Switch) ListVLANs(ctx context.Context) ([]int, error) {
+	err := s.begin(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	return s.VLANs(), nil
+}
+
+// Port return the configuration of port, it's nil if the port doesn't exist
+func (s *Switch) Port(port string) *v1alpha1.SwitchPortConfigurationSpec {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if len(s.options.ports) != 0 && !ustrings.SliceContains(s.options.ports, port) {
+		return nil
+	}
+	return s.port(port).DeepCopy()
+}
+
+// SetPort changes the configuration of port out of band, the faults and the
+// limits of VLANs aren't applied, the port is set to the default one if the
+// configuration is nil
+func (s *Switch) SetPort(port string, configuration *v1alpha1.SwitchPortConfigurationSpec) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if configuration == nil {
+		delete(s.ports, port)
+		return
+	}
+	s.ports[port] = configuration.DeepCopy()
+}
+
+// VLANs return the VLANs in order
+func (s *Switch) VLANs() []int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	vlans := []int{}
+	for vid := range s.vlans {
+		vlans = append(vlans, vid)
+	}
+	sort.Ints(vlans)
+	return vlans
+}
+
+// begin applies the faults to the operation of port, the port is checked if
+// it isn't empty
+func (s *Switch) begin(ctx context.Context, port string) error {
+	s.mutex.Lock()
+	o := s.options
+	s.mutex.Unlock()
+
+	if o.latency != 0 {
+		select {
+		case <-ctx.Done():
+			return backends.FromContext(ctx)
+		case <-time.After(o.latency):
+		}
+	}
+	if o.timeout {
+		<-ctx.Done()
+		return backends.FromContext(ctx)
+	}
+	if o.unreachable {
+		return backends.Errorf(backends.Transient, "connect switch failed: connection refused")
+	}
+	if o.authFailure {
+		return backends.Errorf(backends.Authentication, "authenticate switch failed: invalid username or password")
+	}
+	if port != "" && len(o.ports) != 0 && !ustrings.SliceContains(o.ports, port) {
+		return backends.Errorf(backends.PortNotFound, "port %s isn't found", port)
+	}
 	return nil
 }
 
-// ResetPort just for test
-func (t *fake) ResetPort(ctx context.Context, name string, configuration *v1alpha1.SwitchPortConfigurationSpec) error {
+// port return the configuration of port, the default one is created if it
+// doesn't exist
+func (s *Switch) port(port string) *v1alpha1.SwitchPortConfigurationSpec {
+	if _, ok := s.ports[port]; !ok {
+		vlan := 1
+		s.ports[port] = &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan}
+	}
+	return s.ports[port]
+}
+
+// createVLANs creates the VLANs of configuration, it fails if the VLANs
+// aren't in the vlanRange or the table of VLANs is full
+func (s *Switch) createVLANs(configuration *v1alpha1.SwitchPortConfigurationSpec) error {
+	vlans, err := ustrings.RangeToSlice(configuration.TaggedVLANRange)
+	if err != nil {
+		return backends.NewError(backends.InvalidConfiguration, err)
+	}
+	if configuration.UntaggedVLAN != nil {
+		vlans = append(vlans, *configuration.UntaggedVLAN)
+	}
+
+	created := map[int]bool{}
+	for _, vid := range vlans {
+		if vid < 1 || vid > 4094 || (len(s.options.vlans) != 0 && !containsVLAN(s.options.vlans, vid)) {
+			return backends.Errorf(backends.InvalidConfiguration, "VLAN %d isn't in the range of switch", vid)
+		}
+		if !s.vlans[vid] {
+			created[vid] = true
+		}
+	}
+	if s.options.maxVLANs != 0 && len(s.vlans)+len(created) > s.options.maxVLANs {
+		return backends.Errorf(backends.InvalidConfiguration, "create %d VLANs failed, the table of %d VLANs is full", len(created), s.options.maxVLANs)
+	}
+	for vid := range created {
+		s.vlans[vid] = true
+	}
 	return nil
 }
+
+// drift changes the port randomly like an out of band change
+func (s *Switch) drift(port string) {
+	current := s.port(port)
+	vlans := s.options.vlans
+	if len(vlans) == 0 {
+		vlans, _ = ustrings.RangeToSlice("1-4094")
+	}
+	vid := vlans[s.rand.Intn(len(vlans))]
+
+	switch s.rand.Intn(3) {
+	case 0:
+		current.Disable = !current.Disable
+	case 1:
+		if current.UntaggedVLAN != nil && *current.UntaggedVLAN == vid {
+			current.UntaggedVLAN = nil
+		} else {
+			current.UntaggedVLAN = copyVLAN(&vid)
+		}
+		s.vlans[vid] = true
+	default:
+		if current.TaggedVLANRange != "" {
+			current.TaggedVLANRange = ""
+		} else {
+			current.TaggedVLANRange = strconv.Itoa(vid)
+			s.vlans[vid] = true
+		}
+	}
+}
+
+func containsVLAN(vlans []int, vid int) bool {
+	for _, v := range vlans {
+		if v == vid {
+			return true
+		}
+	}
+	return false
+}
+
+func copyVLAN(vid *int) *int {
+	if vid == nil {
+		return nil
+	}
+	v := *vid
+	return &v
+}
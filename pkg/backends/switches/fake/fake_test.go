@@ -0,0 +1,348 @@
+package fake
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Hellcatlk/network-operator/api/v1alpha1"
+	"github.com/Hellcatlk/network-operator/pkg/backends"
+	"github.com/Hellcatlk/network-operator/pkg/provider"
+)
+
+// newSwitch return a simulated switch which is forgotten after the test
+func newSwitch(t *testing.T, options map[string]interface{}) *Switch {
+	backend, err := New(context.Background(), &provider.SwitchConfiguration{Host: t.Name(), Backend: "fake", Options: options})
+	if err != nil {
+		t.Fatalf("new backend failed: %v", err)
+	}
+	t.Cleanup(func() { Delete(t.Name()) })
+	return backend.(*Switch)
+}
+
+func TestFake(t *testing.T) {
+	vlan1 := 1
+	vlan10 := 10
+	mtu := 9000
+	cases := []struct {
+		name          string
+		configuration *v1alpha1.SwitchPortConfigurationSpec
+		expectedVLANs []int
+		expectedReset *v1alpha1.SwitchPortConfigurationSpec
+	}{
+		{
+			name:          "access port",
+			configuration: &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan10},
+			expectedVLANs: []int{1, 10, 20},
+			expectedReset: &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan1},
+		},
+		{
+			name: "trunk port",
+			configuration: &v1alpha1.SwitchPortConfigurationSpec{
+				UntaggedVLAN: &vlan10, TaggedVLANRange: "11-12", Disable: true, MTU: &mtu,
+				ACLs: []v1alpha1.ACL{{IPVersion: "4", Action: "deny", Protocol: "ALL"}},
+			},
+			expectedVLANs: []int{1, 10, 11, 12, 20},
+			expectedReset: &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan1, MTU: &mtu},
+		},
+		{
+			name:          "port without VLAN",
+			configuration: &v1alpha1.SwitchPortConfigurationSpec{},
+			expectedVLANs: []int{1, 20},
+			expectedReset: &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan1},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := newSwitch(t, map[string]interface{}{"ports": "port0,port1"})
+			if configuration := s.Port("port0"); !reflect.DeepEqual(configuration, &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan1}) {
+				t.Errorf("expected default port, got: %+v", configuration)
+			}
+
+			// Configure twice, the second one replaces the first one
+			err := s.SetPortAttr(context.Background(), "port0", &v1alpha1.SwitchPortConfigurationSpec{TaggedVLANRange: "20"})
+			if err != nil {
+				t.Fatalf("SetPortAttr failed: %v", err)
+			}
+			err = s.SetPortAttr(context.Background(), "port0", c.configuration)
+			if err != nil {
+				t.Fatalf("SetPortAttr failed: %v", err)
+			}
+			configuration, err := s.GetPortAttr(context.Background(), "port0")
+			if err != nil {
+				t.Fatalf("GetPortAttr failed: %v", err)
+			}
+			if !reflect.DeepEqual(configuration, c.configuration) {
+				t.Errorf("expected configuration: %+v, got: %+v", c.configuration, configuration)
+			}
+			if vlans := s.VLANs(); !reflect.DeepEqual(vlans, c.expectedVLANs) {
+				t.Errorf("expected VLANs: %v, got: %v", c.expectedVLANs, vlans)
+			}
+
+			// The state is kept by the switch of host
+			backend, err := New(context.Background(), &provider.SwitchConfiguration{Host: t.Name(), Backend: "fake"})
+			if err != nil {
+				t.Fatalf("new backend failed: %v", err)
+			}
+			err = backend.ResetPort(context.Background(), "port0", c.configuration)
+			if err != nil {
+				t.Fatalf("ResetPort failed: %v", err)
+			}
+			if configuration := s.Port("port0"); !reflect.DeepEqual(configuration, c.expectedReset) {
+				t.Errorf("expected configuration: %+v, got: %+v", c.expectedReset, configuration)
+			}
+			if vlans := s.VLANs(); !reflect.DeepEqual(vlans, c.expectedVLANs) {
+				t.Errorf("expected VLANs are kept: %v, got: %v", c.expectedVLANs, vlans)
+			}
+		})
+	}
+}
+
+func TestFakeError(t *testing.T) {
+	vlan1 := 1
+	vlan10 := 10
+	vlan200 := 200
+	cases := []struct {
+		name              string
+		options           map[string]interface{}
+		port              string
+		configuration     *v1alpha1.SwitchPortConfigurationSpec
+		expectedErrorType backends.ErrorType
+		expectedPort      *v1alpha1.SwitchPortConfigurationSpec
+	}{
+		{
+			name:              "port not found",
+			options:           map[string]interface{}{"ports": "port0"},
+			port:              "port1",
+			expectedErrorType: backends.PortNotFound,
+		},
+		{
+			name:              "VLAN out of range",
+			options:           map[string]interface{}{"vlanRange": "1-100"},
+			port:              "port0",
+			configuration:     &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan200},
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+		{
+			name:              "VLAN table is full",
+			options:           map[string]interface{}{"maxVLANs": "3"},
+			port:              "port0",
+			configuration:     &v1alpha1.SwitchPortConfigurationSpec{TaggedVLANRange: "10-12"},
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+		{
+			name:              "unsupported field",
+			options:           map[string]interface{}{"unsupported": "disable, mtu"},
+			port:              "port0",
+			configuration:     &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan10, Disable: true},
+			expectedErrorType: backends.Unsupported,
+			expectedPort:      &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan1},
+		},
+		{
+			name:              "authentication failure",
+			options:           map[string]interface{}{"authFailure": "true"},
+			port:              "port0",
+			expectedErrorType: backends.Authentication,
+		},
+		{
+			name:              "unreachable",
+			options:           map[string]interface{}{"unreachable": "true"},
+			port:              "port0",
+			expectedErrorType: backends.Transient,
+		},
+		{
+			name:              "timeout",
+			options:           map[string]interface{}{"timeout": "true"},
+			port:              "port0",
+			expectedErrorType: backends.Transient,
+		},
+		{
+			name:              "latency exceeds deadline",
+			options:           map[string]interface{}{"latency": "1m"},
+			port:              "port0",
+			expectedErrorType: backends.Transient,
+		},
+		{
+			name:              "partial failure",
+			options:           map[string]interface{}{"failPorts": "port0"},
+			port:              "port0",
+			configuration:     &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan10, Disable: true},
+			expectedErrorType: backends.Transient,
+			expectedPort:      &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan10},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := newSwitch(t, c.options)
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			err := s.SetPortAttr(ctx, c.port, c.configuration)
+			if errorType := backends.TypeOf(err); errorType != c.expectedErrorType {
+				t.Errorf("expected error type: %q, got: %v", c.expectedErrorType, err)
+			}
+			if c.expectedPort != nil && !reflect.DeepEqual(s.Port(c.port), c.expectedPort) {
+				t.Errorf("expected port: %+v, got: %+v", c.expectedPort, s.Port(c.port))
+			}
+		})
+	}
+}
+
+func TestDrift(t *testing.T) {
+	vlan := 10
+	configuration := &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan, TaggedVLANRange: "11"}
+	s := newSwitch(t, map[string]interface{}{"driftPercent": "100", "seed": "1", "vlanRange": "1-20"})
+	err := s.SetPortAttr(context.Background(), "port0", configuration)
+	if err != nil {
+		t.Fatalf("SetPortAttr failed: %v", err)
+	}
+
+	drifted, err := s.GetPortAttr(context.Background(), "port0")
+	if err != nil {
+		t.Fatalf("GetPortAttr failed: %v", err)
+	}
+	if configuration.IsEqual(drifted) {
+		t.Errorf("expected port is drifted, got: %+v", drifted)
+	}
+	if port := s.Port("port0"); !reflect.DeepEqual(port, drifted) {
+		t.Errorf("expected drift is kept: %+v, got: %+v", drifted, port)
+	}
+
+	// The drift is stopped by the options
+	_, err = New(context.Background(), &provider.SwitchConfiguration{Host: t.Name(), Backend: "fake"})
+	if err != nil {
+		t.Fatalf("new backend failed: %v", err)
+	}
+	current, err := s.GetPortAttr(context.Background(), "port0")
+	if err != nil {
+		t.Fatalf("GetPortAttr failed: %v", err)
+	}
+	if !reflect.DeepEqual(current, drifted) {
+		t.Errorf("expected port: %+v, got: %+v", drifted, current)
+	}
+}
+
+func TestInventory(t *testing.T) {
+	vlan := 10
+	s := newSwitch(t, map[string]interface{}{"ports": "port0,port1,port2"})
+	err := s.SetPortAttr(context.Background(), "port0", &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan, TaggedVLANRange: "11-12"})
+	if err != nil {
+		t.Fatalf("SetPortAttr failed: %v", err)
+	}
+	err = s.SetPortAttr(context.Background(), "port1", &v1alpha1.SwitchPortConfigurationSpec{})
+	if err != nil {
+		t.Fatalf("SetPortAttr failed: %v", err)
+	}
+
+	ports, err := s.ListPortVLANs(context.Background())
+	if err != nil {
+		t.Fatalf("ListPortVLANs failed: %v", err)
+	}
+	if expected := map[string][]int{"port0": {10, 11, 12}, "port2": {1}}; !reflect.DeepEqual(ports, expected) {
+		t.Errorf("expected ports: %v, got: %v", expected, ports)
+	}
+	vlans, err := s.ListVLANs(context.Background())
+	if err != nil {
+		t.Fatalf("ListVLANs failed: %v", err)
+	}
+	if expected := []int{1, 10, 11, 12}; !reflect.DeepEqual(vlans, expected) {
+		t.Errorf("expected VLANs: %v, got: %v", expected, vlans)
+	}
+
+	_, err = newSwitch(t, map[string]interface{}{"unreachable": "true"}).ListVLANs(context.Background())
+	if !backends.IsTransient(err) {
+		t.Errorf("expected transient error, got: %v", err)
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	cases := []struct {
+		name                 string
+		options              map[string]interface{}
+		expectedCapabilities *v1alpha1.SwitchCapabilities
+		expectedErrorType    backends.ErrorType
+	}{
+		{
+			name:                 "all fields",
+			expectedCapabilities: backends.AllCapabilities(),
+		},
+		{
+			name:                 "unsupported fields",
+			options:              map[string]interface{}{"unsupported": "acls,nativeVLAN"},
+			expectedCapabilities: &v1alpha1.SwitchCapabilities{TaggedVLANs: true, Disable: true, MTU: true},
+		},
+		{
+			name:              "unreachable",
+			options:           map[string]interface{}{"unreachable": "true"},
+			expectedErrorType: backends.Transient,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			capabilities, err := newSwitch(t, c.options).Capabilities(context.Background())
+			if errorType := backends.TypeOf(err); errorType != c.expectedErrorType {
+				t.Errorf("expected error type: %q, got: %v", c.expectedErrorType, err)
+			}
+			if !reflect.DeepEqual(capabilities, c.expectedCapabilities) {
+				t.Errorf("expected capabilities: %+v, got: %+v", c.expectedCapabilities, capabilities)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	cases := []struct {
+		name          string
+		options       map[string]interface{}
+		expectedError bool
+	}{
+		{
+			name: "all options",
+			options: map[string]interface{}{
+				"ports": "port0, port1", "vlanRange": "1-100", "maxVLANs": "10", "latency": "10ms", "timeout": "false",
+				"authFailure": "false", "unreachable": "false", "failPorts": "port1", "driftPercent": "10", "seed": "1",
+				"unsupported": "acls,taggedVLANs,nativeVLAN,disable,mtu",
+			},
+		},
+		{
+			name:          "invalid vlanRange",
+			options:       map[string]interface{}{"vlanRange": "1-5000"},
+			expectedError: true,
+		},
+		{
+			name:          "invalid latency",
+			options:       map[string]interface{}{"latency": "10"},
+			expectedError: true,
+		},
+		{
+			name:          "driftPercent out of range",
+			options:       map[string]interface{}{"driftPercent": "101"},
+			expectedError: true,
+		},
+		{
+			name:          "unknown unsupported field",
+			options:       map[string]interface{}{"unsupported": "vlan"},
+			expectedError: true,
+		},
+		{
+			name:    "unknown option",
+			options: map[string]interface{}{"bridge": "br0"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := New(context.Background(), &provider.SwitchConfiguration{Host: t.Name(), Backend: "fake", Options: c.options})
+			defer Delete(t.Name())
+			if (err != nil) != c.expectedError {
+				t.Errorf("expected error: %v, got: %v", c.expectedError, err)
+			}
+			if err != nil && backends.TypeOf(err) != backends.InvalidConfiguration {
+				t.Errorf("expected error type: %q, got: %v", backends.InvalidConfiguration, err)
+			}
+		})
+	}
+}
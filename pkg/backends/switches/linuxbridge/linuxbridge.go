@@ -0,0 +1,336 @@
+// Package linuxbridge is the switch backend which configures the ports of
+// Linux bridge by netlink, include the switchdev ports offloaded to hardware.
+package linuxbridge
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Hellcatlk/network-operator/api/v1alpha1"
+	"github.com/Hellcatlk/network-operator/pkg/backends"
+	"github.com/Hellcatlk/network-operator/pkg/provider"
+	ustrings "github.com/Hellcatlk/network-operator/pkg/utils/strings"
+)
+
+// netnsDirectory is the directory of the network namespaces named by `ip netns`
+const netnsDirectory string = "/var/run/netns"
+
+// The flags of bridge VLAN, see include/uapi/linux/if_bridge.h
+const (
+	vlanFlagPVID     uint16 = 0x2
+	vlanFlagUntagged uint16 = 0x4
+)
+
+// link is a network interface
+type link struct {
+	index int32
+	up    bool
+	mtu   int
+	// The index of bridge, it's 0 if the link isn't a port of bridge
+	master int32
+}
+
+// netlink is the operations of netlink used by the backend, the VLANs are
+// the map from VLAN ID to the flags
+type netlink interface {
+	getLink(name string) (*link, error)
+	getVLANs(index int32) (map[uint16]uint16, error)
+	listVLANs() (map[string]map[uint16]uint16, error)
+	setVLANs(index int32, vlans map[uint16]uint16) error
+	deleteVLANs(index int32, vids []uint16) error
+	setMTU(index int32, mtu int) error
+	setUp(index int32, up bool) error
+	close()
+}
+
+// New return linuxbridge backend, the host is empty or `localhost` for the
+// network namespace of operator, or `netns:<name|path>` for another network
+// namespace of the same host
+func New(ctx context.Context, config *provider.SwitchConfiguration) (backends.Switch, error) {
+	if config == nil {
+		return nil, fmt.Errorf("configure of switch is nil")
+	}
+
+	for key := range config.Options {
+		return nil, backends.Errorf(backends.InvalidConfiguration, "unknown option %q of linuxbridge backend", key)
+	}
+
+	netns, err := parseHost(config.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	return &linuxBridge{
+		netns: netns,
+		dial:  dial,
+	}, nil
+}
+
+// parseHost return the path of network namespace, it's empty for the network
+// namespace of operator
+func parseHost(host string) (string, error) {
+	switch {
+	case host == "" || host == "localhost":
+		return "", nil
+	case strings.HasPrefix(host, "netns:"):
+		netns := strings.TrimPrefix(host, "netns:")
+		if filepath.IsAbs(netns) {
+			return netns, nil
+		}
+		if netns == "" || strings.Contains(netns, "/") {
+			return "", backends.Errorf(backends.InvalidConfiguration, "invalid network namespace %q", netns)
+		}
+		return filepath.Join(netnsDirectory, netns), nil
+	default:
+		return "", backends.Errorf(backends.InvalidConfiguration,
+			"host %q isn't local, run linuxbridge backend on the host as a plugin", host)
+	}
+}
+
+// linuxBridge backend
+type linuxBridge struct {
+	netns string
+	dial  func(ctx context.Context, netns string) (netlink, error)
+}
+
+// IsAvailable check the network namespace can be configured
+func (l *linuxBridge) IsAvailable(ctx context.Context) error {
+	n, err := l.dial(ctx, l.netns)
+	if err != nil {
+		return err
+	}
+	n.close()
+	return nil
+}
+
+// GetPortAttr return the VLANs, admin state and MTU of the port
+func (l *linuxBridge) GetPortAttr(ctx context.Context, port string) (*v1alpha1.SwitchPortConfigurationSpec, error) {
+	n, err := l.dial(ctx, l.netns)
+	if err != nil {
+		return nil, err
+	}
+	defer n.close()
+
+	link, err := n.getLink(port)
+	if err != nil {
+		return nil, err
+	}
+	mtu := link.mtu
+	configuration := &v1alpha1.SwitchPortConfigurationSpec{
+		Disable: !link.up,
+		MTU:     &mtu,
+	}
+	if link.master == 0 {
+		return configuration, nil
+	}
+
+	vlans, err := n.getVLANs(link.index)
+	if err != nil {
+		return nil, err
+	}
+	configuration.UntaggedVLAN, configuration.TaggedVLANRange = fromVLANs(vlans)
+	return configuration, nil
+}
+
+// SetPortAttr set the VLANs, admin state and MTU of the port, the untagged
+// VLAN is the PVID of the port
+func (l *linuxBridge) SetPortAttr(ctx context.Context, port string, configuration *v1alpha1.SwitchPortConfigurationSpec) error {
+	if len(configuration.ACLs) != 0 {
+		return backends.Errorf(backends.Unsupported, "ACLs aren't supported by linuxbridge backend")
+	}
+	vlans, err := toVLANs(configuration)
+	if err != nil {
+		return err
+	}
+
+	n, err := l.dial(ctx, l.netns)
+	if err != nil {
+		return err
+	}
+	defer n.close()
+
+	link, err := n.getLink(port)
+	if err != nil {
+		return err
+	}
+	if link.master == 0 && len(vlans) != 0 {
+		return backends.Errorf(backends.InvalidConfiguration, "%s isn't a port of bridge", port)
+	}
+
+	if configuration.MTU != nil && *configuration.MTU != link.mtu {
+		err = n.setMTU(link.index, *configuration.MTU)
+		if err != nil {
+			return err
+		}
+	}
+	if link.master != 0 {
+		err = syncVLANs(n, link.index, vlans)
+		if err != nil {
+			return err
+		}
+	}
+	if link.up == configuration.Disable {
+		return n.setUp(link.index, !configuration.Disable)
+	}
+	return nil
+}
+
+// ResetPort remove the VLANs of the port and enable it, the MTU is kept
+func (l *linuxBridge) ResetPort(ctx context.Context, port string, configuration *v1alpha1.SwitchPortConfigurationSpec) error {
+	n, err := l.dial(ctx, l.netns)
+	if err != nil {
+		return err
+	}
+	defer n.close()
+
+	link, err := n.getLink(port)
+	if err != nil {
+		return err
+	}
+	if link.master != 0 {
+		err = syncVLANs(n, link.index, map[uint16]uint16{})
+		if err != nil {
+			return err
+		}
+	}
+	if !link.up {
+		return n.setUp(link.index, true)
+	}
+	return nil
+}
+
+// Capabilities return the fields supported by linuxbridge backend, the ACLs
+// aren't supported
+func (l *linuxBridge) Capabilities(ctx context.Context) (*v1alpha1.SwitchCapabilities, error) {
+	return &v1alpha1.SwitchCapabilities{TaggedVLANs: true, NativeVLAN: true, Disable: true, MTU: true}, nil
+}
+
+// ListPortVLANs return the VLANs of the bridge ports having VLANs
+func (l *linuxBridge) ListPortVLANs(ctx context.Context) (map[string][]int, error) {
+	n, err := l.dial(ctx, l.netns)
+	if err != nil {
+		return nil, err
+	}
+	defer n.close()
+
+	ports, err := n.listVLANs()
+	if err != nil {
+		return nil, err
+	}
+	result := map[string][]int{}
+	for port, vlans := range ports {
+		if len(vlans) != 0 {
+			result[port] = sortedVLANs(vlans)
+		}
+	}
+	return result, nil
+}
+
+// ListVLANs return the VLANs of the bridge ports in order, a VLAN of bridge
+// exists only on its ports
+func (l *linuxBridge) ListVLANs(ctx context.Context) ([]int, error) {
+	n, err := l.dial(ctx, l.netns)
+	if err != nil {
+		return nil, err
+	}
+	defer n.close()
+
+	ports, err := n.listVLANs()
+	if err != nil {
+		return nil, err
+	}
+	union := map[uint16]uint16{}
+	for _, vlans := range ports {
+		for vid := range vlans {
+			union[vid] = 0
+		}
+	}
+	return sortedVLANs(union), nil
+}
+
+// sortedVLANs return the IDs of VLANs in order
+func sortedVLANs(vlans map[uint16]uint16) []int {
+	vids := []int{}
+	for vid := range vlans {
+		vids = append(vids, int(vid))
+	}
+	sort.Ints(vids)
+	return vids
+}
+
+// syncVLANs deletes the VLANs which aren't expected and sets the VLANs whose
+// flags are different
+func syncVLANs(n netlink, index int32, expected map[uint16]uint16) error {
+	current, err := n.getVLANs(index)
+	if err != nil {
+		return err
+	}
+
+	deleted := []uint16{}
+	for vid := range current {
+		if _, ok := expected[vid]; !ok {
+			deleted = append(deleted, vid)
+		}
+	}
+	sort.Slice(deleted, func(i, j int) bool { return deleted[i] < deleted[j] })
+	changed := map[uint16]uint16{}
+	for vid, flags := range expected {
+		if currentFlags, ok := current[vid]; !ok || currentFlags != flags {
+			changed[vid] = flags
+		}
+	}
+
+	if len(deleted) != 0 {
+		err = n.deleteVLANs(index, deleted)
+		if err != nil {
+			return err
+		}
+	}
+	if len(changed) != 0 {
+		return n.setVLANs(index, changed)
+	}
+	return nil
+}
+
+// toVLANs return the VLANs of the configuration, the untagged VLAN is the PVID
+// and egresses untagged
+func toVLANs(configuration *v1alpha1.SwitchPortConfigurationSpec) (map[uint16]uint16, error) {
+	vids, err := ustrings.RangeToSlice(configuration.TaggedVLANRange)
+	if err != nil {
+		return nil, backends.NewError(backends.InvalidConfiguration, err)
+	}
+	if configuration.UntaggedVLAN != nil {
+		vids = append(vids, *configuration.UntaggedVLAN)
+	}
+
+	vlans := map[uint16]uint16{}
+	for _, vid := range vids {
+		if vid < 1 || vid > 4094 {
+			return nil, backends.Errorf(backends.InvalidConfiguration, "VLAN %d is out of range 1-4094", vid)
+		}
+		vlans[uint16(vid)] = 0
+	}
+	if configuration.UntaggedVLAN != nil {
+		vlans[uint16(*configuration.UntaggedVLAN)] = vlanFlagPVID | vlanFlagUntagged
+	}
+	return vlans, nil
+}
+
+// fromVLANs return the untagged VLAN and the range of tagged VLANs, the PVID
+// is the untagged VLAN
+func fromVLANs(vlans map[uint16]uint16) (*int, string) {
+	var untagged *int
+	tagged := []int{}
+	for vid, flags := range vlans {
+		if flags&vlanFlagPVID != 0 {
+			pvid := int(vid)
+			untagged = &pvid
+			continue
+		}
+		tagged = append(tagged, int(vid))
+	}
+	return untagged, ustrings.SliceToRange(tagged)
+}
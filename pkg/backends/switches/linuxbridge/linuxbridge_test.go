@@ -0,0 +1,291 @@
+package linuxbridge
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/Hellcatlk/network-operator/api/v1alpha1"
+	"github.com/Hellcatlk/network-operator/pkg/backends"
+	"github.com/Hellcatlk/network-operator/pkg/provider"
+)
+
+// fakeNetlink is an in-memory netlink, which has a bridge port `eth1` and a
+// link `eth2` which isn't a port of bridge
+type fakeNetlink struct {
+	links map[string]*link
+	vlans map[int32]map[uint16]uint16
+}
+
+func newFakeNetlink() *fakeNetlink {
+	return &fakeNetlink{
+		links: map[string]*link{
+			"eth1": {index: 2, up: true, mtu: 1500, master: 1},
+			"eth2": {index: 3, up: true, mtu: 1500},
+		},
+		// The default PVID of bridge port is 1
+		vlans: map[int32]map[uint16]uint16{2: {1: vlanFlagPVID | vlanFlagUntagged}},
+	}
+}
+
+func (f *fakeNetlink) linkByIndex(index int32) *link {
+	for _, l := range f.links {
+		if l.index == index {
+			return l
+		}
+	}
+	return nil
+}
+
+func (f *fakeNetlink) getLink(name string) (*link, error) {
+	l := f.links[name]
+	if l == nil {
+		return nil, backends.Errorf(backends.PortNotFound, "port %s doesn't exist", name)
+	}
+	copied := *l
+	return &copied, nil
+}
+
+func (f *fakeNetlink) getVLANs(index int32) (map[uint16]uint16, error) {
+	vlans := map[uint16]uint16{}
+	for vid, flags := range f.vlans[index] {
+		vlans[vid] = flags
+	}
+	return vlans, nil
+}
+
+func (f *fakeNetlink) listVLANs() (map[string]map[uint16]uint16, error) {
+	ports := map[string]map[uint16]uint16{}
+	for name, l := range f.links {
+		if l.master != 0 {
+			ports[name], _ = f.getVLANs(l.index)
+		}
+	}
+	return ports, nil
+}
+
+func (f *fakeNetlink) setVLANs(index int32, vlans map[uint16]uint16) error {
+	if f.linkByIndex(index).master == 0 {
+		return backends.Errorf(backends.Unsupported, "operation not supported")
+	}
+	if f.vlans[index] == nil {
+		f.vlans[index] = map[uint16]uint16{}
+	}
+	for vid, flags := range vlans {
+		// A port has only one PVID
+		if flags&vlanFlagPVID != 0 {
+			for v := range f.vlans[index] {
+				f.vlans[index][v] &^= vlanFlagPVID
+			}
+		}
+		f.vlans[index][vid] = flags
+	}
+	return nil
+}
+
+func (f *fakeNetlink) deleteVLANs(index int32, vids []uint16) error {
+	for _, vid := range vids {
+		delete(f.vlans[index], vid)
+	}
+	return nil
+}
+
+func (f *fakeNetlink) setMTU(index int32, mtu int) error {
+	f.linkByIndex(index).mtu = mtu
+	return nil
+}
+
+func (f *fakeNetlink) setUp(index int32, up bool) error {
+	f.linkByIndex(index).up = up
+	return nil
+}
+
+func (f *fakeNetlink) close() {}
+
+func newFakeBackend(f *fakeNetlink) *linuxBridge {
+	return &linuxBridge{
+		dial: func(ctx context.Context, netns string) (netlink, error) {
+			return f, nil
+		},
+	}
+}
+
+func TestLinuxBridge(t *testing.T) {
+	vlan := 10
+	mtu := 9000
+	cases := []struct {
+		name          string
+		configuration *v1alpha1.SwitchPortConfigurationSpec
+		expectedVLANs map[uint16]uint16
+	}{
+		{
+			name:          "access port",
+			configuration: &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan},
+			expectedVLANs: map[uint16]uint16{10: vlanFlagPVID | vlanFlagUntagged},
+		},
+		{
+			name:          "trunk port with untagged vlan",
+			configuration: &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan, TaggedVLANRange: "1,11-12"},
+			expectedVLANs: map[uint16]uint16{1: 0, 10: vlanFlagPVID | vlanFlagUntagged, 11: 0, 12: 0},
+		},
+		{
+			name:          "disabled trunk port with MTU",
+			configuration: &v1alpha1.SwitchPortConfigurationSpec{TaggedVLANRange: "11", Disable: true, MTU: &mtu},
+			expectedVLANs: map[uint16]uint16{11: 0},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			f := newFakeNetlink()
+			backend := newFakeBackend(f)
+
+			err := backend.SetPortAttr(context.Background(), "eth1", c.configuration)
+			if err != nil {
+				t.Fatalf("SetPortAttr failed: %v", err)
+			}
+			if !reflect.DeepEqual(f.vlans[2], c.expectedVLANs) {
+				t.Errorf("expected VLANs: %v, got: %v", c.expectedVLANs, f.vlans[2])
+			}
+
+			configuration, err := backend.GetPortAttr(context.Background(), "eth1")
+			if err != nil {
+				t.Fatalf("GetPortAttr failed: %v", err)
+			}
+			if !c.configuration.IsEqual(configuration) {
+				t.Errorf("expected configuration: %+v, got: %+v", c.configuration, configuration)
+			}
+
+			err = backend.ResetPort(context.Background(), "eth1", c.configuration)
+			if err != nil {
+				t.Fatalf("ResetPort failed: %v", err)
+			}
+			if len(f.vlans[2]) != 0 || !f.links["eth1"].up {
+				t.Errorf("expected port is reset, got VLANs: %v, up: %v", f.vlans[2], f.links["eth1"].up)
+			}
+		})
+	}
+}
+
+func TestInventory(t *testing.T) {
+	f := newFakeNetlink()
+	f.links["eth3"] = &link{index: 4, up: true, mtu: 1500, master: 1}
+	backend := newFakeBackend(f)
+	vlan := 10
+	err := backend.SetPortAttr(context.Background(), "eth1", &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan, TaggedVLANRange: "11-12"})
+	if err != nil {
+		t.Fatalf("SetPortAttr failed: %v", err)
+	}
+
+	ports, err := backend.ListPortVLANs(context.Background())
+	if err != nil {
+		t.Fatalf("ListPortVLANs failed: %v", err)
+	}
+	if expected := map[string][]int{"eth1": {10, 11, 12}}; !reflect.DeepEqual(ports, expected) {
+		t.Errorf("expected ports: %v, got: %v", expected, ports)
+	}
+	vlans, err := backend.ListVLANs(context.Background())
+	if err != nil {
+		t.Fatalf("ListVLANs failed: %v", err)
+	}
+	if expected := []int{10, 11, 12}; !reflect.DeepEqual(vlans, expected) {
+		t.Errorf("expected VLANs: %v, got: %v", expected, vlans)
+	}
+}
+
+func TestLinuxBridgeError(t *testing.T) {
+	vlan := 4095
+	cases := []struct {
+		name              string
+		port              string
+		configuration     *v1alpha1.SwitchPortConfigurationSpec
+		expectedErrorType backends.ErrorType
+	}{
+		{
+			name:              "port not found",
+			port:              "eth3",
+			configuration:     &v1alpha1.SwitchPortConfigurationSpec{},
+			expectedErrorType: backends.PortNotFound,
+		},
+		{
+			name:              "vlan out of range",
+			port:              "eth1",
+			configuration:     &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan},
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+		{
+			name:              "not a port of bridge",
+			port:              "eth2",
+			configuration:     &v1alpha1.SwitchPortConfigurationSpec{TaggedVLANRange: "10"},
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+		{
+			name:              "ACLs",
+			port:              "eth1",
+			configuration:     &v1alpha1.SwitchPortConfigurationSpec{ACLs: []v1alpha1.ACL{{Action: "deny"}}},
+			expectedErrorType: backends.Unsupported,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			backend := newFakeBackend(newFakeNetlink())
+			err := backend.SetPortAttr(context.Background(), c.port, c.configuration)
+			if errorType := backends.TypeOf(err); errorType != c.expectedErrorType {
+				t.Errorf("expected error type: %q, got: %v", c.expectedErrorType, err)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	cases := []struct {
+		name              string
+		host              string
+		options           map[string]interface{}
+		expectedNetns     string
+		expectedErrorType backends.ErrorType
+	}{
+		{
+			name: "localhost",
+			host: "localhost",
+		},
+		{
+			name:          "named network namespace",
+			host:          "netns:switch",
+			expectedNetns: "/var/run/netns/switch",
+		},
+		{
+			name:          "path of network namespace",
+			host:          "netns:/proc/1/ns/net",
+			expectedNetns: "/proc/1/ns/net",
+		},
+		{
+			name:              "relative path of network namespace",
+			host:              "netns:../switch",
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+		{
+			name:              "remote host",
+			host:              "192.168.0.1",
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+		{
+			name:              "unknown option",
+			options:           map[string]interface{}{"bridge": "br0"},
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			backend, err := New(context.Background(), &provider.SwitchConfiguration{Host: c.host, Backend: "linuxbridge", Options: c.options})
+			if errorType := backends.TypeOf(err); errorType != c.expectedErrorType {
+				t.Fatalf("expected error type: %q, got: %v", c.expectedErrorType, err)
+			}
+			if err == nil && backend.(*linuxBridge).netns != c.expectedNetns {
+				t.Errorf("expected network namespace: %q, got: %q", c.expectedNetns, backend.(*linuxBridge).netns)
+			}
+		})
+	}
+}
@@ -0,0 +1,442 @@
+package linuxbridge
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+	"unsafe"
+
+	"github.com/Hellcatlk/network-operator/pkg/backends"
+	"golang.org/x/sys/unix"
+)
+
+// The constants of bridge netlink, see include/uapi/linux/if_bridge.h and
+// include/uapi/linux/rtnetlink.h
+const (
+	iflaBridgeVLANInfo uint16 = 2
+	rtextFilterBRVLAN  uint32 = 0x2
+)
+
+// attrTypeMask clears the flags of attribute type
+const attrTypeMask uint16 = ^uint16(unix.NLA_F_NESTED | unix.NLA_F_NET_BYTEORDER)
+
+// nativeEndian is the byte order of netlink messages
+var nativeEndian binary.ByteOrder = binary.BigEndian
+
+func init() {
+	i := uint16(1)
+	if *(*byte)(unsafe.Pointer(&i)) == 1 {
+		nativeEndian = binary.LittleEndian
+	}
+}
+
+// socket is a NETLINK_ROUTE socket
+type socket struct {
+	fd  int
+	seq uint32
+}
+
+// dial opens a netlink socket in the network namespace, the deadline of
+// context is the timeout of every request
+func dial(ctx context.Context, netns string) (netlink, error) {
+	if ctx.Err() != nil {
+		return nil, backends.FromContext(ctx)
+	}
+
+	fd, err := openSocket(netns)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout := time.Until(deadline)
+		if timeout < time.Millisecond {
+			timeout = time.Millisecond
+		}
+		tv := unix.NsecToTimeval(timeout.Nanoseconds())
+		for _, opt := range []int{unix.SO_RCVTIMEO, unix.SO_SNDTIMEO} {
+			err = unix.SetsockoptTimeval(fd, unix.SOL_SOCKET, opt, &tv)
+			if err != nil {
+				unix.Close(fd)
+				return nil, errnoError(err)
+			}
+		}
+	}
+	return &socket{fd: fd}, nil
+}
+
+// openSocket opens a netlink socket in the network namespace, the socket
+// keeps working in the network namespace after the thread leaves it
+func openSocket(netns string) (int, error) {
+	if netns == "" {
+		return newSocket()
+	}
+
+	ns, err := os.Open(netns)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return -1, backends.Errorf(backends.InvalidConfiguration, "network namespace %s doesn't exist", netns)
+		}
+		return -1, backends.NewError(backends.Transient, err)
+	}
+	defer ns.Close()
+
+	type result struct {
+		fd  int
+		err error
+	}
+	results := make(chan result, 1)
+	go func() {
+		// The thread isn't unlocked, so it's destroyed rather than reused by
+		// other goroutines after entering the network namespace
+		runtime.LockOSThread()
+		err := unix.Setns(int(ns.Fd()), unix.CLONE_NEWNET)
+		if err != nil {
+			results <- result{fd: -1, err: errnoError(fmt.Errorf("enter network namespace %s: %w", netns, err))}
+			return
+		}
+		fd, err := newSocket()
+		results <- result{fd: fd, err: err}
+	}()
+	r := <-results
+	return r.fd, r.err
+}
+
+// newSocket opens a netlink socket in the network namespace of current thread
+func newSocket() (int, error) {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_RAW|unix.SOCK_CLOEXEC, unix.NETLINK_ROUTE)
+	if err != nil {
+		return -1, errnoError(err)
+	}
+	err = unix.Bind(fd, &unix.SockaddrNetlink{Family: unix.AF_NETLINK})
+	if err != nil {
+		unix.Close(fd)
+		return -1, errnoError(err)
+	}
+	return fd, nil
+}
+
+// close closes the socket
+func (s *socket) close() {
+	unix.Close(s.fd)
+}
+
+// getLink return the link by name
+func (s *socket) getLink(name string) (*link, error) {
+	payload := ifInfoMsg(unix.AF_UNSPEC, 0, 0, 0)
+	payload = appendAttr(payload, unix.IFLA_IFNAME, append([]byte(name), 0))
+	replies, err := s.request(unix.RTM_GETLINK, unix.NLM_F_ACK, payload)
+	if err != nil {
+		if backends.TypeOf(err) == backends.PortNotFound {
+			return nil, backends.Errorf(backends.PortNotFound, "port %s doesn't exist", name)
+		}
+		return nil, err
+	}
+	if len(replies) == 0 {
+		return nil, backends.Errorf(backends.Transient, "no reply of port %s", name)
+	}
+
+	l, attrs, err := parseLink(replies[0])
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range attrs {
+		switch {
+		case a.typ == unix.IFLA_MTU && len(a.data) >= 4:
+			l.mtu = int(nativeEndian.Uint32(a.data))
+		case a.typ == unix.IFLA_MASTER && len(a.data) >= 4:
+			l.master = int32(nativeEndian.Uint32(a.data))
+		}
+	}
+	return l, nil
+}
+
+// getVLANs return the VLANs of the bridge port, only the PVID and untagged
+// flags are returned
+func (s *socket) getVLANs(index int32) (map[uint16]uint16, error) {
+	ports, err := s.dumpVLANs()
+	if err != nil {
+		return nil, err
+	}
+	if port := ports[index]; port != nil {
+		return port.vlans, nil
+	}
+	return map[uint16]uint16{}, nil
+}
+
+// listVLANs return the VLANs of every bridge port by the name of port, the
+// bridges themselves aren't included
+func (s *socket) listVLANs() (map[string]map[uint16]uint16, error) {
+	ports, err := s.dumpVLANs()
+	if err != nil {
+		return nil, err
+	}
+	vlans := map[string]map[uint16]uint16{}
+	for index, port := range ports {
+		if port.master == 0 || port.master == index {
+			continue
+		}
+		vlans[port.name] = port.vlans
+	}
+	return vlans, nil
+}
+
+// bridgePort is a link dumped by the bridge family with its VLANs
+type bridgePort struct {
+	name   string
+	master int32
+	vlans  map[uint16]uint16
+}
+
+// dumpVLANs return the links dumped by the bridge family by index, only the
+// PVID and untagged flags of VLANs are returned
+func (s *socket) dumpVLANs() (map[int32]*bridgePort, error) {
+	payload := ifInfoMsg(unix.AF_BRIDGE, 0, 0, 0)
+	mask := make([]byte, 4)
+	nativeEndian.PutUint32(mask, rtextFilterBRVLAN)
+	payload = appendAttr(payload, unix.IFLA_EXT_MASK, mask)
+	replies, err := s.request(unix.RTM_GETLINK, unix.NLM_F_DUMP, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	ports := map[int32]*bridgePort{}
+	for _, reply := range replies {
+		l, attrs, err := parseLink(reply)
+		if err != nil {
+			return nil, err
+		}
+		port := ports[l.index]
+		if port == nil {
+			port = &bridgePort{vlans: map[uint16]uint16{}}
+			ports[l.index] = port
+		}
+		for _, a := range attrs {
+			switch {
+			case a.typ == unix.IFLA_IFNAME:
+				port.name = strings.TrimRight(string(a.data), "\x00")
+			case a.typ == unix.IFLA_MASTER && len(a.data) >= 4:
+				port.master = int32(nativeEndian.Uint32(a.data))
+			case a.typ == unix.IFLA_AF_SPEC:
+				infos, err := parseAttrs(a.data)
+				if err != nil {
+					return nil, err
+				}
+				for _, info := range infos {
+					if info.typ != iflaBridgeVLANInfo || len(info.data) < 4 {
+						continue
+					}
+					flags := nativeEndian.Uint16(info.data[0:2])
+					vid := nativeEndian.Uint16(info.data[2:4])
+					port.vlans[vid] = flags & (vlanFlagPVID | vlanFlagUntagged)
+				}
+			}
+		}
+	}
+	return ports, nil
+}
+
+// setVLANs adds the VLANs to the bridge port or changes their flags
+func (s *socket) setVLANs(index int32, vlans map[uint16]uint16) error {
+	vids := []uint16{}
+	for vid := range vlans {
+		vids = append(vids, vid)
+	}
+	sort.Slice(vids, func(i, j int) bool { return vids[i] < vids[j] })
+	_, err := s.request(unix.RTM_SETLINK, unix.NLM_F_ACK, vlanMsg(index, vids, vlans))
+	return err
+}
+
+// deleteVLANs deletes the VLANs from the bridge port
+func (s *socket) deleteVLANs(index int32, vids []uint16) error {
+	_, err := s.request(unix.RTM_DELLINK, unix.NLM_F_ACK, vlanMsg(index, vids, nil))
+	return err
+}
+
+// setMTU set the MTU of the link
+func (s *socket) setMTU(index int32, mtu int) error {
+	data := make([]byte, 4)
+	nativeEndian.PutUint32(data, uint32(mtu))
+	payload := appendAttr(ifInfoMsg(unix.AF_UNSPEC, index, 0, 0), unix.IFLA_MTU, data)
+	_, err := s.request(unix.RTM_SETLINK, unix.NLM_F_ACK, payload)
+	return err
+}
+
+// setUp set the admin state of the link
+func (s *socket) setUp(index int32, up bool) error {
+	var flags uint32
+	if up {
+		flags = unix.IFF_UP
+	}
+	_, err := s.request(unix.RTM_SETLINK, unix.NLM_F_ACK, ifInfoMsg(unix.AF_UNSPEC, index, flags, unix.IFF_UP))
+	return err
+}
+
+// request sends the message and return the payloads of replies, the error of
+// kernel is returned as error
+func (s *socket) request(typ uint16, flags uint16, payload []byte) ([][]byte, error) {
+	s.seq++
+	b := make([]byte, unix.NLMSG_HDRLEN, unix.NLMSG_HDRLEN+len(payload))
+	nativeEndian.PutUint32(b[0:4], uint32(unix.NLMSG_HDRLEN+len(payload)))
+	nativeEndian.PutUint16(b[4:6], typ)
+	nativeEndian.PutUint16(b[6:8], flags|unix.NLM_F_REQUEST)
+	nativeEndian.PutUint32(b[8:12], s.seq)
+	b = append(b, payload...)
+	err := unix.Sendto(s.fd, b, 0, &unix.SockaddrNetlink{Family: unix.AF_NETLINK})
+	if err != nil {
+		return nil, errnoError(err)
+	}
+
+	replies := [][]byte{}
+	for {
+		b, err = s.receive()
+		if err != nil {
+			return nil, err
+		}
+		for len(b) >= unix.NLMSG_HDRLEN {
+			length := int(nativeEndian.Uint32(b[0:4]))
+			if length < unix.NLMSG_HDRLEN || length > len(b) {
+				return nil, backends.Errorf(backends.Transient, "invalid netlink message")
+			}
+			msgType := nativeEndian.Uint16(b[4:6])
+			seq := nativeEndian.Uint32(b[8:12])
+			data := b[unix.NLMSG_HDRLEN:length]
+			b = b[align(length):]
+			if seq != s.seq {
+				continue
+			}
+
+			switch msgType {
+			case unix.NLMSG_DONE, unix.NLMSG_ERROR:
+				// The error is 0 for acknowledgement
+				if len(data) >= 4 {
+					if errno := int32(nativeEndian.Uint32(data[0:4])); errno != 0 {
+						return nil, errnoError(unix.Errno(-errno))
+					}
+				}
+				return replies, nil
+			default:
+				replies = append(replies, data)
+			}
+		}
+	}
+}
+
+// receive return the next datagram of socket
+func (s *socket) receive() ([]byte, error) {
+	// Peek the length of datagram, a dump can be larger than a page
+	n, _, err := unix.Recvfrom(s.fd, nil, unix.MSG_PEEK|unix.MSG_TRUNC)
+	if err != nil {
+		return nil, errnoError(err)
+	}
+	b := make([]byte, n)
+	n, _, err = unix.Recvfrom(s.fd, b, 0)
+	if err != nil {
+		return nil, errnoError(err)
+	}
+	return b[:n], nil
+}
+
+// errnoError return the typed error of errno
+func errnoError(err error) error {
+	var errno unix.Errno
+	if !errors.As(err, &errno) {
+		return backends.NewError(backends.Transient, err)
+	}
+	switch errno {
+	case unix.ENODEV:
+		return backends.NewError(backends.PortNotFound, err)
+	case unix.EPERM, unix.EACCES:
+		return backends.NewError(backends.Authentication, err)
+	case unix.EOPNOTSUPP, unix.EAFNOSUPPORT:
+		return backends.NewError(backends.Unsupported, err)
+	case unix.EINVAL, unix.ERANGE:
+		return backends.NewError(backends.InvalidConfiguration, err)
+	default:
+		return backends.NewError(backends.Transient, err)
+	}
+}
+
+// attr is a netlink attribute, the flags of type are cleared
+type attr struct {
+	typ  uint16
+	data []byte
+}
+
+// ifInfoMsg return the struct ifinfomsg
+func ifInfoMsg(family uint8, index int32, flags uint32, change uint32) []byte {
+	b := make([]byte, unix.SizeofIfInfomsg)
+	b[0] = family
+	nativeEndian.PutUint32(b[4:8], uint32(index))
+	nativeEndian.PutUint32(b[8:12], flags)
+	nativeEndian.PutUint32(b[12:16], change)
+	return b
+}
+
+// vlanMsg return the message to set or delete the VLANs of the bridge port
+func vlanMsg(index int32, vids []uint16, vlans map[uint16]uint16) []byte {
+	var spec []byte
+	for _, vid := range vids {
+		info := make([]byte, 4)
+		nativeEndian.PutUint16(info[0:2], vlans[vid])
+		nativeEndian.PutUint16(info[2:4], vid)
+		spec = appendAttr(spec, iflaBridgeVLANInfo, info)
+	}
+	return appendAttr(ifInfoMsg(unix.AF_BRIDGE, index, 0, 0), unix.IFLA_AF_SPEC|unix.NLA_F_NESTED, spec)
+}
+
+// parseLink return the link and the attributes of the RTM_NEWLINK message
+func parseLink(b []byte) (*link, []attr, error) {
+	if len(b) < unix.SizeofIfInfomsg {
+		return nil, nil, backends.Errorf(backends.Transient, "invalid link message")
+	}
+	attrs, err := parseAttrs(b[unix.SizeofIfInfomsg:])
+	if err != nil {
+		return nil, nil, err
+	}
+	return &link{
+		index: int32(nativeEndian.Uint32(b[4:8])),
+		up:    nativeEndian.Uint32(b[8:12])&unix.IFF_UP != 0,
+	}, attrs, nil
+}
+
+// appendAttr appends the attribute to b
+func appendAttr(b []byte, typ uint16, data []byte) []byte {
+	length := unix.SizeofRtAttr + len(data)
+	header := make([]byte, unix.SizeofRtAttr)
+	nativeEndian.PutUint16(header[0:2], uint16(length))
+	nativeEndian.PutUint16(header[2:4], typ)
+	b = append(b, header...)
+	b = append(b, data...)
+	return append(b, make([]byte, align(length)-length)...)
+}
+
+// parseAttrs return the attributes of b
+func parseAttrs(b []byte) ([]attr, error) {
+	attrs := []attr{}
+	for len(b) >= unix.SizeofRtAttr {
+		length := int(nativeEndian.Uint16(b[0:2]))
+		if length < unix.SizeofRtAttr || length > len(b) {
+			return nil, backends.Errorf(backends.Transient, "invalid netlink attribute")
+		}
+		attrs = append(attrs, attr{
+			typ:  nativeEndian.Uint16(b[2:4]) & attrTypeMask,
+			data: b[unix.SizeofRtAttr:length],
+		})
+		if align(length) >= len(b) {
+			break
+		}
+		b = b[align(length):]
+	}
+	return attrs, nil
+}
+
+// align return the length aligned to 4 bytes
+func align(length int) int {
+	return (length + unix.NLA_ALIGNTO - 1) &^ (unix.NLA_ALIGNTO - 1)
+}
@@ -0,0 +1,193 @@
+package linuxbridge
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Hellcatlk/network-operator/api/v1alpha1"
+	"github.com/Hellcatlk/network-operator/pkg/backends"
+	"github.com/Hellcatlk/network-operator/pkg/provider"
+	"golang.org/x/sys/unix"
+)
+
+func TestAttrs(t *testing.T) {
+	b := appendAttr(nil, unix.IFLA_IFNAME, []byte("eth1\x00"))
+	b = appendAttr(b, unix.IFLA_AF_SPEC|unix.NLA_F_NESTED, appendAttr(nil, iflaBridgeVLANInfo, []byte{1, 2, 3, 4}))
+	if len(b)%4 != 0 {
+		t.Fatalf("expected attributes are aligned, got length %d", len(b))
+	}
+
+	attrs, err := parseAttrs(b)
+	if err != nil {
+		t.Fatalf("parseAttrs failed: %v", err)
+	}
+	if len(attrs) != 2 {
+		t.Fatalf("expected 2 attributes, got: %v", attrs)
+	}
+	if attrs[0].typ != unix.IFLA_IFNAME || string(attrs[0].data) != "eth1\x00" {
+		t.Errorf("unexpected attribute: %+v", attrs[0])
+	}
+	if attrs[1].typ != unix.IFLA_AF_SPEC {
+		t.Errorf("expected the nested flag is cleared, got type: %#x", attrs[1].typ)
+	}
+	infos, err := parseAttrs(attrs[1].data)
+	if err != nil || len(infos) != 1 || !reflect.DeepEqual(infos[0].data, []byte{1, 2, 3, 4}) {
+		t.Errorf("unexpected nested attributes: %+v, %v", infos, err)
+	}
+
+	_, err = parseAttrs([]byte{16, 0, 1, 0})
+	if err == nil {
+		t.Errorf("expected error of truncated attribute")
+	}
+}
+
+// ip runs the ip command
+func ip(args ...string) error {
+	output, err := exec.Command("ip", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("ip %v: %v: %s", args, err, output)
+	}
+	return nil
+}
+
+// newNetns creates a network namespace with a veth pair, it's skipped if the
+// network namespace can't be created
+func newNetns(t *testing.T) string {
+	if os.Geteuid() != 0 {
+		t.Skip("network namespace requires root")
+	}
+	if _, err := exec.LookPath("ip"); err != nil {
+		t.Skip("ip command isn't found")
+	}
+
+	netns := fmt.Sprintf("linuxbridge-test-%d", os.Getpid())
+	err := ip("netns", "add", netns)
+	if err != nil {
+		t.Skipf("can't create network namespace: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = ip("netns", "delete", netns)
+	})
+	err = ip("-n", netns, "link", "add", "eth1", "type", "veth", "peer", "name", "host1")
+	if err != nil {
+		t.Skipf("can't create veth: %v", err)
+	}
+	return netns
+}
+
+func TestNetlink(t *testing.T) {
+	netns := newNetns(t)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	backend, err := New(ctx, &provider.SwitchConfiguration{Host: "netns:" + netns, Backend: "linuxbridge"})
+	if err != nil {
+		t.Fatalf("new backend failed: %v", err)
+	}
+
+	err = backend.IsAvailable(ctx)
+	if err != nil {
+		t.Fatalf("IsAvailable failed: %v", err)
+	}
+
+	t.Run("link", func(t *testing.T) {
+		mtu := 9000
+		configuration := &v1alpha1.SwitchPortConfigurationSpec{MTU: &mtu}
+		err := backend.SetPortAttr(ctx, "eth1", configuration)
+		if err != nil {
+			t.Fatalf("SetPortAttr failed: %v", err)
+		}
+		actual, err := backend.GetPortAttr(ctx, "eth1")
+		if err != nil {
+			t.Fatalf("GetPortAttr failed: %v", err)
+		}
+		if !configuration.IsEqual(actual) {
+			t.Errorf("expected configuration: %+v, got: %+v", configuration, actual)
+		}
+
+		configuration.Disable = true
+		err = backend.SetPortAttr(ctx, "eth1", configuration)
+		if err != nil {
+			t.Fatalf("SetPortAttr failed: %v", err)
+		}
+		actual, err = backend.GetPortAttr(ctx, "eth1")
+		if err != nil {
+			t.Fatalf("GetPortAttr failed: %v", err)
+		}
+		if !actual.Disable {
+			t.Errorf("expected port is disabled, got: %+v", actual)
+		}
+
+		err = backend.ResetPort(ctx, "eth1", configuration)
+		if err != nil {
+			t.Fatalf("ResetPort failed: %v", err)
+		}
+		actual, err = backend.GetPortAttr(ctx, "eth1")
+		if err != nil {
+			t.Fatalf("GetPortAttr failed: %v", err)
+		}
+		if actual.Disable {
+			t.Errorf("expected port is enabled, got: %+v", actual)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := backend.GetPortAttr(ctx, "eth2")
+		if errorType := backends.TypeOf(err); errorType != backends.PortNotFound {
+			t.Errorf("expected error type: %q, got: %v", backends.PortNotFound, err)
+		}
+
+		err = backend.SetPortAttr(ctx, "eth1", &v1alpha1.SwitchPortConfigurationSpec{TaggedVLANRange: "10"})
+		if errorType := backends.TypeOf(err); errorType != backends.InvalidConfiguration {
+			t.Errorf("expected error type: %q, got: %v", backends.InvalidConfiguration, err)
+		}
+	})
+
+	t.Run("bridge", func(t *testing.T) {
+		err := ip("-n", netns, "link", "add", "br0", "type", "bridge", "vlan_filtering", "1")
+		if err != nil {
+			t.Skipf("can't create bridge: %v", err)
+		}
+		err = ip("-n", netns, "link", "set", "eth1", "master", "br0")
+		if err != nil {
+			t.Fatalf("can't add port to bridge: %v", err)
+		}
+
+		vlan := 10
+		configuration := &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan, TaggedVLANRange: "11-13,20"}
+		err = backend.SetPortAttr(ctx, "eth1", configuration)
+		if err != nil {
+			t.Fatalf("SetPortAttr failed: %v", err)
+		}
+		actual, err := backend.GetPortAttr(ctx, "eth1")
+		if err != nil {
+			t.Fatalf("GetPortAttr failed: %v", err)
+		}
+		if !configuration.IsEqual(actual) {
+			t.Errorf("expected configuration: %+v, got: %+v", configuration, actual)
+		}
+		ports, err := backend.(backends.Inventory).ListPortVLANs(ctx)
+		if err != nil {
+			t.Fatalf("ListPortVLANs failed: %v", err)
+		}
+		if expected := map[string][]int{"eth1": {10, 11, 12, 13, 20}}; !reflect.DeepEqual(ports, expected) {
+			t.Errorf("expected ports: %v, got: %v", expected, ports)
+		}
+
+		err = backend.ResetPort(ctx, "eth1", configuration)
+		if err != nil {
+			t.Fatalf("ResetPort failed: %v", err)
+		}
+		actual, err = backend.GetPortAttr(ctx, "eth1")
+		if err != nil {
+			t.Fatalf("GetPortAttr failed: %v", err)
+		}
+		if actual.UntaggedVLAN != nil || actual.TaggedVLANRange != "" {
+			t.Errorf("expected VLANs are removed, got: %+v", actual)
+		}
+	})
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+// +build !linux
+
+package linuxbridge
+
+import (
+	"context"
+
+	"github.com/Hellcatlk/network-operator/pkg/backends"
+)
+
+// dial return error, because netlink is only supported by Linux
+func dial(ctx context.Context, netns string) (netlink, error) {
+	return nil, backends.Errorf(backends.Unsupported, "linuxbridge backend is only supported on Linux")
+}
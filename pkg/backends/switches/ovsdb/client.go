@@ -0,0 +1,215 @@
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/Hellcatlk/network-operator/pkg/backends"
+)
+
+// The OVSDB management protocol, see RFC 7047
+
+// request is a JSON-RPC 1.0 request
+type request struct {
+	Method string        `json:"method"`
+	Params []interface{} `json:"params"`
+	ID     interface{}   `json:"id"`
+}
+
+// message is a JSON-RPC 1.0 request or response
+type message struct {
+	Method string            `json:"method,omitempty"`
+	Params []json.RawMessage `json:"params,omitempty"`
+	Result json.RawMessage   `json:"result,omitempty"`
+	Error  json.RawMessage   `json:"error,omitempty"`
+	ID     json.RawMessage   `json:"id"`
+}
+
+// operation is an operation of `transact`
+type operation struct {
+	Op      string                 `json:"op"`
+	Table   string                 `json:"table"`
+	Where   [][]interface{}        `json:"where"`
+	Columns []string               `json:"columns,omitempty"`
+	Row     map[string]interface{} `json:"row,omitempty"`
+}
+
+// operationResult is the result of an operation of `transact`
+type operationResult struct {
+	Count   *int                         `json:"count,omitempty"`
+	Rows    []map[string]json.RawMessage `json:"rows,omitempty"`
+	Error   string                       `json:"error,omitempty"`
+	Details string                       `json:"details,omitempty"`
+}
+
+// conn is a connection to the OVSDB server
+type conn struct {
+	conn    net.Conn
+	decoder *json.Decoder
+	encoder *json.Encoder
+	lastID  int
+	stop    chan struct{}
+}
+
+// dial connects to the OVSDB server, the connection is closed when the context is done
+func dial(ctx context.Context, network string, address string) (*conn, error) {
+	dialer := &net.Dialer{}
+	c, err := dialer.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, backends.NewError(backends.Transient, err)
+	}
+
+	result := &conn{
+		conn:    c,
+		decoder: json.NewDecoder(c),
+		encoder: json.NewEncoder(c),
+		stop:    make(chan struct{}),
+	}
+	// Interrupt the reading and writing when the context is done
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = c.SetDeadline(time.Now())
+		case <-result.stop:
+		}
+	}()
+	return result, nil
+}
+
+// close closes the connection
+func (c *conn) close() {
+	close(c.stop)
+	c.conn.Close()
+}
+
+// call sends a request and waits for the response with the same id, the
+// `echo` requests of server are answered while waiting
+func (c *conn) call(ctx context.Context, method string, params []interface{}, result interface{}) error {
+	c.lastID++
+	id := c.lastID
+	if params == nil {
+		params = []interface{}{}
+	}
+	err := c.encoder.Encode(request{Method: method, Params: params, ID: id})
+	if err != nil {
+		return c.connectionError(ctx, err)
+	}
+
+	for {
+		m := &message{}
+		err = c.decoder.Decode(m)
+		if err != nil {
+			return c.connectionError(ctx, err)
+		}
+
+		if m.Method == "echo" {
+			err = c.encoder.Encode(map[string]interface{}{"result": m.Params, "error": nil, "id": m.ID})
+			if err != nil {
+				return c.connectionError(ctx, err)
+			}
+			continue
+		}
+		if m.Method != "" || string(m.ID) != fmt.Sprint(id) {
+			// Notifications and the responses of other requests
+			continue
+		}
+
+		if len(m.Error) != 0 && string(m.Error) != "null" {
+			return backends.Errorf(backends.Transient, "%s failed: %s", method, m.Error)
+		}
+		err = json.Unmarshal(m.Result, result)
+		if err != nil {
+			return backends.Errorf(backends.Transient, "invalid result of %s: %v", method, err)
+		}
+		return nil
+	}
+}
+
+// listDBs return the names of databases
+func (c *conn) listDBs(ctx context.Context) ([]string, error) {
+	dbs := []string{}
+	err := c.call(ctx, "list_dbs", nil, &dbs)
+	return dbs, err
+}
+
+// transact runs the operations in a transaction, the error of operation is returned as error
+func (c *conn) transact(ctx context.Context, database string, operations ...operation) ([]operationResult, error) {
+	params := []interface{}{database}
+	for _, op := range operations {
+		params = append(params, op)
+	}
+
+	results := []operationResult{}
+	err := c.call(ctx, "transact", params, &results)
+	if err != nil {
+		return nil, err
+	}
+	// The result of the commit is appended when it failed
+	for _, result := range results {
+		if result.Error != "" {
+			return nil, transactError(result)
+		}
+	}
+	if len(results) < len(operations) {
+		return nil, backends.Errorf(backends.Transient, "transact returned %d results for %d operations", len(results), len(operations))
+	}
+	return results, nil
+}
+
+// connectionError return the context error if the connection is interrupted by the context
+func (c *conn) connectionError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return backends.FromContext(ctx)
+	}
+	return backends.NewError(backends.Transient, err)
+}
+
+// transactError return the typed error of the failed operation
+func transactError(result operationResult) error {
+	err := fmt.Errorf("%s: %s", result.Error, result.Details)
+	switch result.Error {
+	case "constraint violation", "domain error", "range error", "referential integrity violation":
+		return backends.NewError(backends.InvalidConfiguration, err)
+	case "not supported":
+		return backends.NewError(backends.Unsupported, err)
+	default:
+		return backends.NewError(backends.Transient, err)
+	}
+}
+
+// set return the set of values
+func set(values ...interface{}) []interface{} {
+	if values == nil {
+		values = []interface{}{}
+	}
+	return []interface{}{"set", values}
+}
+
+// decodeSet return the elements of the set, the set of one element may be the element itself
+func decodeSet(raw json.RawMessage) ([]json.RawMessage, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	array := []json.RawMessage{}
+	if json.Unmarshal(raw, &array) != nil || len(array) != 2 {
+		return []json.RawMessage{raw}, nil
+	}
+
+	var kind string
+	if json.Unmarshal(array[0], &kind) != nil {
+		return []json.RawMessage{raw}, nil
+	}
+	switch kind {
+	case "set":
+		elements := []json.RawMessage{}
+		err := json.Unmarshal(array[1], &elements)
+		return elements, err
+	case "uuid", "named-uuid":
+		return []json.RawMessage{raw}, nil
+	default:
+		return nil, fmt.Errorf("unexpected value %s", raw)
+	}
+}
@@ -0,0 +1,318 @@
+// Package ovsdb is the switch backend which configures the ports of Open
+// vSwitch by the OVSDB management protocol directly.
+package ovsdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Hellcatlk/network-operator/api/v1alpha1"
+	"github.com/Hellcatlk/network-operator/pkg/backends"
+	"github.com/Hellcatlk/network-operator/pkg/provider"
+	ustrings "github.com/Hellcatlk/network-operator/pkg/utils/strings"
+)
+
+// defaultDatabase is the database of Open vSwitch
+const defaultDatabase string = "Open_vSwitch"
+
+// defaultPort is the port registered by IANA for OVSDB
+const defaultPort string = "6640"
+
+// New return ovsdb backend, the host is `tcp:<host>:<port>`, `unix:<path>` or
+// `<host>` which uses the default port
+func New(ctx context.Context, config *provider.SwitchConfiguration) (backends.Switch, error) {
+	if config == nil {
+		return nil, fmt.Errorf("configure of switch is nil")
+	}
+
+	database := defaultDatabase
+	for key, value := range config.Options {
+		switch key {
+		case "database":
+			database, _ = value.(string)
+		default:
+			return nil, backends.Errorf(backends.InvalidConfiguration, "unknown option %q of ovsdb backend", key)
+		}
+	}
+	if database == "" {
+		return nil, backends.Errorf(backends.InvalidConfiguration, "database of ovsdb backend is empty")
+	}
+
+	network, address, err := parseHost(config.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ovsdb{
+		network:  network,
+		address:  address,
+		database: database,
+	}, nil
+}
+
+// parseHost return the network and address of the OVSDB server
+func parseHost(host string) (string, string, error) {
+	switch {
+	case strings.HasPrefix(host, "tcp:"):
+		return "tcp", strings.TrimPrefix(host, "tcp:"), nil
+	case strings.HasPrefix(host, "unix:"):
+		return "unix", strings.TrimPrefix(host, "unix:"), nil
+	case strings.HasPrefix(host, "ssl:"):
+		return "", "", backends.Errorf(backends.Unsupported, "ssl connection of ovsdb isn't supported")
+	case host == "":
+		return "", "", backends.Errorf(backends.InvalidConfiguration, "host of ovsdb backend is empty")
+	default:
+		return "tcp", host + ":" + defaultPort, nil
+	}
+}
+
+// ovsdb backend
+type ovsdb struct {
+	network  string
+	address  string
+	database string
+}
+
+// IsAvailable check the database exists in the OVSDB server
+func (o *ovsdb) IsAvailable(ctx context.Context) error {
+	c, err := dial(ctx, o.network, o.address)
+	if err != nil {
+		return err
+	}
+	defer c.close()
+
+	dbs, err := c.listDBs(ctx)
+	if err != nil {
+		return err
+	}
+	if !ustrings.SliceContains(dbs, o.database) {
+		return backends.Errorf(backends.InvalidConfiguration, "database %s doesn't exist", o.database)
+	}
+	return nil
+}
+
+// GetPortAttr get the VLANs of the port
+func (o *ovsdb) GetPortAttr(ctx context.Context, port string) (*v1alpha1.SwitchPortConfigurationSpec, error) {
+	c, err := dial(ctx, o.network, o.address)
+	if err != nil {
+		return nil, err
+	}
+	defer c.close()
+
+	results, err := c.transact(ctx, o.database, operation{
+		Op:      "select",
+		Table:   "Port",
+		Where:   [][]interface{}{{"name", "==", port}},
+		Columns: []string{"tag", "trunks", "vlan_mode"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(results[0].Rows) == 0 {
+		return nil, backends.Errorf(backends.PortNotFound, "port %s doesn't exist", port)
+	}
+
+	configuration, err := decodePort(results[0].Rows[0])
+	if err != nil {
+		return nil, backends.Errorf(backends.Transient, "invalid row of port %s: %v", port, err)
+	}
+	return configuration, nil
+}
+
+// ListPortVLANs return the VLANs of the ports having tag or trunks, the ports
+// without them carry all VLANs and aren't included
+func (o *ovsdb) ListPortVLANs(ctx context.Context) (map[string][]int, error) {
+	c, err := dial(ctx, o.network, o.address)
+	if err != nil {
+		return nil, err
+	}
+	defer c.close()
+
+	results, err := c.transact(ctx, o.database, operation{
+		Op:      "select",
+		Table:   "Port",
+		Where:   [][]interface{}{},
+		Columns: []string{"name", "tag", "trunks", "vlan_mode"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ports := map[string][]int{}
+	for _, row := range results[0].Rows {
+		var name string
+		err = json.Unmarshal(row["name"], &name)
+		if err != nil {
+			return nil, backends.Errorf(backends.Transient, "invalid name of port: %v", err)
+		}
+		configuration, err := decodePort(row)
+		if err != nil {
+			return nil, backends.Errorf(backends.Transient, "invalid row of port %s: %v", name, err)
+		}
+		vlans, _ := ustrings.RangeToSlice(configuration.TaggedVLANRange)
+		if configuration.UntaggedVLAN != nil {
+			vlans = append(vlans, *configuration.UntaggedVLAN)
+		}
+		if len(vlans) != 0 {
+			sort.Ints(vlans)
+			ports[name] = vlans
+		}
+	}
+	return ports, nil
+}
+
+// ListVLANs return the VLANs of the ports in order, Open vSwitch has no table
+// of VLANs
+func (o *ovsdb) ListVLANs(ctx context.Context) ([]int, error) {
+	ports, err := o.ListPortVLANs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	union := map[int]bool{}
+	for _, vlans := range ports {
+		for _, vid := range vlans {
+			union[vid] = true
+		}
+	}
+	vlans := []int{}
+	for vid := range union {
+		vlans = append(vlans, vid)
+	}
+	sort.Ints(vlans)
+	return vlans, nil
+}
+
+// SetPortAttr set the VLANs of the port, the port is an access port if only
+// untagged VLAN is set, otherwise it's a trunk port
+func (o *ovsdb) SetPortAttr(ctx context.Context, port string, configuration *v1alpha1.SwitchPortConfigurationSpec) error {
+	if len(configuration.ACLs) != 0 {
+		return backends.Errorf(backends.Unsupported, "ACLs aren't supported by ovsdb backend")
+	}
+	if configuration.Disable {
+		return backends.Errorf(backends.Unsupported, "disabling port isn't supported by ovsdb backend")
+	}
+	if configuration.MTU != nil {
+		return backends.Errorf(backends.Unsupported, "MTU isn't supported by ovsdb backend")
+	}
+
+	vlans, err := ustrings.RangeToSlice(configuration.TaggedVLANRange)
+	if err != nil {
+		return backends.NewError(backends.InvalidConfiguration, err)
+	}
+	trunks := []interface{}{}
+	for _, vlan := range vlans {
+		trunks = append(trunks, vlan)
+	}
+
+	row := map[string]interface{}{
+		"tag":       set(),
+		"trunks":    set(trunks...),
+		"vlan_mode": set(),
+	}
+	switch {
+	case configuration.UntaggedVLAN != nil && len(vlans) == 0:
+		row["tag"] = *configuration.UntaggedVLAN
+		row["vlan_mode"] = "access"
+	case configuration.UntaggedVLAN != nil:
+		row["tag"] = *configuration.UntaggedVLAN
+		row["vlan_mode"] = "native-untagged"
+	case len(vlans) != 0:
+		row["vlan_mode"] = "trunk"
+	}
+
+	return o.updatePort(ctx, port, row)
+}
+
+// ResetPort clear the VLANs of the port
+func (o *ovsdb) ResetPort(ctx context.Context, port string, configuration *v1alpha1.SwitchPortConfigurationSpec) error {
+	return o.updatePort(ctx, port, map[string]interface{}{
+		"tag":       set(),
+		"trunks":    set(),
+		"vlan_mode": set(),
+	})
+}
+
+// Capabilities return the fields supported by ovsdb backend, only the VLANs
+// of ports are configured
+func (o *ovsdb) Capabilities(ctx context.Context) (*v1alpha1.SwitchCapabilities, error) {
+	return &v1alpha1.SwitchCapabilities{TaggedVLANs: true, NativeVLAN: true}, nil
+}
+
+// updatePort updates the columns of the port
+func (o *ovsdb) updatePort(ctx context.Context, port string, row map[string]interface{}) error {
+	c, err := dial(ctx, o.network, o.address)
+	if err != nil {
+		return err
+	}
+	defer c.close()
+
+	results, err := c.transact(ctx, o.database, operation{
+		Op:    "update",
+		Table: "Port",
+		Where: [][]interface{}{{"name", "==", port}},
+		Row:   row,
+	})
+	if err != nil {
+		return err
+	}
+	if results[0].Count == nil || *results[0].Count == 0 {
+		return backends.Errorf(backends.PortNotFound, "port %s doesn't exist", port)
+	}
+	return nil
+}
+
+// decodePort return the configuration of the row of Port table
+func decodePort(row map[string]json.RawMessage) (*v1alpha1.SwitchPortConfigurationSpec, error) {
+	tags, err := decodeIntegers(row["tag"])
+	if err != nil {
+		return nil, err
+	}
+	trunks, err := decodeIntegers(row["trunks"])
+	if err != nil {
+		return nil, err
+	}
+	modes, err := decodeSet(row["vlan_mode"])
+	if err != nil {
+		return nil, err
+	}
+	mode := ""
+	if len(modes) != 0 {
+		err = json.Unmarshal(modes[0], &mode)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	configuration := &v1alpha1.SwitchPortConfigurationSpec{}
+	// The tag of trunk port is ignored by Open vSwitch
+	if len(tags) != 0 && mode != "trunk" {
+		configuration.UntaggedVLAN = &tags[0]
+	}
+	// The trunks of access port are ignored by Open vSwitch
+	if mode != "access" && !(mode == "" && len(tags) != 0) {
+		configuration.TaggedVLANRange = ustrings.SliceToRange(trunks)
+	}
+	return configuration, nil
+}
+
+// decodeIntegers return the integers of the set
+func decodeIntegers(raw json.RawMessage) ([]int, error) {
+	elements, err := decodeSet(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []int{}
+	for _, element := range elements {
+		var value int
+		err = json.Unmarshal(element, &value)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, value)
+	}
+	return result, nil
+}
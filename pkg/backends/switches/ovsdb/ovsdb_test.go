@@ -0,0 +1,214 @@
+package ovsdb
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Hellcatlk/network-operator/api/v1alpha1"
+	"github.com/Hellcatlk/network-operator/pkg/backends"
+	"github.com/Hellcatlk/network-operator/pkg/backends/switches/ovsdb/ovsdbtest"
+	"github.com/Hellcatlk/network-operator/pkg/provider"
+)
+
+// serve serves the in-memory server on a unix socket and return the backend connected to it
+func serve(t *testing.T, server *ovsdbtest.Server) backends.Switch {
+	socket := filepath.Join(t.TempDir(), "db.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		_ = server.Serve(listener)
+	}()
+
+	backend, err := New(context.Background(), &provider.SwitchConfiguration{
+		Host:    "unix:" + socket,
+		Backend: "ovsdb",
+	})
+	if err != nil {
+		t.Fatalf("new backend failed: %v", err)
+	}
+	return backend
+}
+
+func TestOVSDB(t *testing.T) {
+	vlan := 10
+	cases := []struct {
+		name          string
+		configuration *v1alpha1.SwitchPortConfigurationSpec
+		expectedPort  ovsdbtest.Port
+	}{
+		{
+			name:          "access port",
+			configuration: &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan},
+			expectedPort:  ovsdbtest.Port{Tag: []int{10}, Trunks: []int{}, VLANMode: []string{"access"}},
+		},
+		{
+			name:          "trunk port with native vlan",
+			configuration: &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan, TaggedVLANRange: "11-13,20"},
+			expectedPort:  ovsdbtest.Port{Tag: []int{10}, Trunks: []int{11, 12, 13, 20}, VLANMode: []string{"native-untagged"}},
+		},
+		{
+			name:          "trunk port",
+			configuration: &v1alpha1.SwitchPortConfigurationSpec{TaggedVLANRange: "11"},
+			expectedPort:  ovsdbtest.Port{Tag: []int{}, Trunks: []int{11}, VLANMode: []string{"trunk"}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server := ovsdbtest.NewServer("eth1")
+			backend := serve(t, server)
+
+			err := backend.IsAvailable(context.Background())
+			if err != nil {
+				t.Fatalf("IsAvailable failed: %v", err)
+			}
+
+			err = backend.SetPortAttr(context.Background(), "eth1", c.configuration)
+			if err != nil {
+				t.Fatalf("SetPortAttr failed: %v", err)
+			}
+			port, _ := server.Port("eth1")
+			if !reflect.DeepEqual(port, c.expectedPort) {
+				t.Errorf("expected port: %+v, got: %+v", c.expectedPort, port)
+			}
+
+			configuration, err := backend.GetPortAttr(context.Background(), "eth1")
+			if err != nil {
+				t.Fatalf("GetPortAttr failed: %v", err)
+			}
+			if !c.configuration.IsEqual(configuration) {
+				t.Errorf("expected configuration: %+v, got: %+v", c.configuration, configuration)
+			}
+
+			err = backend.ResetPort(context.Background(), "eth1", c.configuration)
+			if err != nil {
+				t.Fatalf("ResetPort failed: %v", err)
+			}
+			port, _ = server.Port("eth1")
+			if !reflect.DeepEqual(port, ovsdbtest.Port{Tag: []int{}, Trunks: []int{}, VLANMode: []string{}}) {
+				t.Errorf("expected port is reset, got: %+v", port)
+			}
+		})
+	}
+}
+
+func TestInventory(t *testing.T) {
+	server := ovsdbtest.NewServer("eth1", "eth2", "eth3")
+	backend := serve(t, server)
+	vlan := 10
+	err := backend.SetPortAttr(context.Background(), "eth1", &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan, TaggedVLANRange: "11-12"})
+	if err != nil {
+		t.Fatalf("SetPortAttr failed: %v", err)
+	}
+	err = backend.SetPortAttr(context.Background(), "eth2", &v1alpha1.SwitchPortConfigurationSpec{TaggedVLANRange: "12,20"})
+	if err != nil {
+		t.Fatalf("SetPortAttr failed: %v", err)
+	}
+
+	inventory := backend.(backends.Inventory)
+	ports, err := inventory.ListPortVLANs(context.Background())
+	if err != nil {
+		t.Fatalf("ListPortVLANs failed: %v", err)
+	}
+	if expected := map[string][]int{"eth1": {10, 11, 12}, "eth2": {12, 20}}; !reflect.DeepEqual(ports, expected) {
+		t.Errorf("expected ports: %v, got: %v", expected, ports)
+	}
+	vlans, err := inventory.ListVLANs(context.Background())
+	if err != nil {
+		t.Fatalf("ListVLANs failed: %v", err)
+	}
+	if expected := []int{10, 11, 12, 20}; !reflect.DeepEqual(vlans, expected) {
+		t.Errorf("expected VLANs: %v, got: %v", expected, vlans)
+	}
+}
+
+func TestOVSDBError(t *testing.T) {
+	vlan := 4096
+	mtu := 9000
+	cases := []struct {
+		name              string
+		port              string
+		configuration     *v1alpha1.SwitchPortConfigurationSpec
+		expectedErrorType backends.ErrorType
+	}{
+		{
+			name:              "port not found",
+			port:              "eth2",
+			configuration:     &v1alpha1.SwitchPortConfigurationSpec{},
+			expectedErrorType: backends.PortNotFound,
+		},
+		{
+			name:              "vlan out of range",
+			port:              "eth1",
+			configuration:     &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan},
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+		{
+			name:              "ACLs",
+			port:              "eth1",
+			configuration:     &v1alpha1.SwitchPortConfigurationSpec{ACLs: []v1alpha1.ACL{{Action: "deny"}}},
+			expectedErrorType: backends.Unsupported,
+		},
+		{
+			name:              "MTU",
+			port:              "eth1",
+			configuration:     &v1alpha1.SwitchPortConfigurationSpec{MTU: &mtu},
+			expectedErrorType: backends.Unsupported,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			backend := serve(t, ovsdbtest.NewServer("eth1"))
+			err := backend.SetPortAttr(context.Background(), c.port, c.configuration)
+			if errorType := backends.TypeOf(err); errorType != c.expectedErrorType {
+				t.Errorf("expected error type: %q, got: %v", c.expectedErrorType, err)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	cases := []struct {
+		name              string
+		host              string
+		options           map[string]interface{}
+		expectedErrorType backends.ErrorType
+	}{
+		{
+			name: "host without port",
+			host: "192.168.0.1",
+		},
+		{
+			name:    "custom database",
+			host:    "tcp:192.168.0.1:6641",
+			options: map[string]interface{}{"database": "OVN_Northbound"},
+		},
+		{
+			name:              "ssl",
+			host:              "ssl:192.168.0.1:6640",
+			expectedErrorType: backends.Unsupported,
+		},
+		{
+			name:              "unknown option",
+			host:              "192.168.0.1",
+			options:           map[string]interface{}{"bridge": "br0"},
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := New(context.Background(), &provider.SwitchConfiguration{Host: c.host, Backend: "ovsdb", Options: c.options})
+			if errorType := backends.TypeOf(err); errorType != c.expectedErrorType {
+				t.Errorf("expected error type: %q, got: %v", c.expectedErrorType, err)
+			}
+		})
+	}
+}
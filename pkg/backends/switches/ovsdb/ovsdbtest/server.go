@@ -0,0 +1,348 @@
+// Package ovsdbtest provides an in-memory OVSDB server, which implements the
+// Port table of Open vSwitch used by the ovsdb backend.
+package ovsdbtest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+)
+
+// Database is the only database served
+const Database string = "Open_vSwitch"
+
+// Port is a row of Port table, the empty slice means the column is empty
+type Port struct {
+	Tag      []int
+	Trunks   []int
+	VLANMode []string
+}
+
+// Server is an in-memory OVSDB server
+type Server struct {
+	mutex sync.Mutex
+	ports map[string]*Port
+}
+
+// NewServer return a server with the ports
+func NewServer(ports ...string) *Server {
+	s := &Server{ports: map[string]*Port{}}
+	for _, port := range ports {
+		s.ports[port] = &Port{}
+	}
+	return s
+}
+
+// Port return a copy of the port
+func (s *Server) Port(name string) (Port, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	port := s.ports[name]
+	if port == nil {
+		return Port{}, false
+	}
+	return Port{
+		Tag:      append([]int{}, port.Tag...),
+		Trunks:   append([]int{}, port.Trunks...),
+		VLANMode: append([]string{}, port.VLANMode...),
+	}, true
+}
+
+// Serve serves the connections of listener until it's closed
+func (s *Server) Serve(listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go s.serveConn(conn)
+	}
+}
+
+type message struct {
+	Method string            `json:"method,omitempty"`
+	Params []json.RawMessage `json:"params,omitempty"`
+	ID     json.RawMessage   `json:"id"`
+}
+
+type response struct {
+	Result interface{}     `json:"result"`
+	Error  interface{}     `json:"error"`
+	ID     json.RawMessage `json:"id"`
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	defer conn.Close()
+	decoder := json.NewDecoder(conn)
+	encoder := json.NewEncoder(conn)
+
+	// The real server sends echo to check the liveness of clients
+	err := encoder.Encode(map[string]interface{}{"method": "echo", "params": []interface{}{}, "id": "echo"})
+	if err != nil {
+		return
+	}
+
+	for {
+		m := &message{}
+		err := decoder.Decode(m)
+		if err != nil {
+			return
+		}
+
+		r := &response{ID: m.ID}
+		switch m.Method {
+		case "":
+			// The response of echo
+			continue
+		case "echo":
+			r.Result = m.Params
+		case "list_dbs":
+			r.Result = []string{Database}
+		case "transact":
+			r.Result, r.Error = s.transact(m.Params)
+		default:
+			r.Error = "unknown method"
+		}
+		err = encoder.Encode(r)
+		if err != nil {
+			return
+		}
+	}
+}
+
+type operation struct {
+	Op      string                     `json:"op"`
+	Table   string                     `json:"table"`
+	Where   [][]json.RawMessage        `json:"where"`
+	Columns []string                   `json:"columns"`
+	Row     map[string]json.RawMessage `json:"row"`
+}
+
+// operationError is the result of the failed operation
+type operationError struct {
+	Error   string `json:"error"`
+	Details string `json:"details,omitempty"`
+}
+
+// transact runs the operations, the changes are discarded if any operation fails
+func (s *Server) transact(params []json.RawMessage) (interface{}, interface{}) {
+	if len(params) == 0 {
+		return nil, "missing database"
+	}
+	var database string
+	if json.Unmarshal(params[0], &database) != nil || database != Database {
+		return nil, "unknown database"
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// Operate on a copy of the table, so the transaction is atomic
+	ports := map[string]*Port{}
+	for name, port := range s.ports {
+		copied := *port
+		ports[name] = &copied
+	}
+
+	results := []interface{}{}
+	for _, param := range params[1:] {
+		op := &operation{}
+		err := json.Unmarshal(param, op)
+		if err != nil {
+			return nil, err.Error()
+		}
+
+		result, opErr := operate(ports, op)
+		if opErr != nil {
+			results = append(results, opErr)
+			return results, nil
+		}
+		results = append(results, result)
+	}
+
+	s.ports = ports
+	return results, nil
+}
+
+// operate runs the operation on the ports
+func operate(ports map[string]*Port, op *operation) (interface{}, *operationError) {
+	if op.Table != "Port" {
+		return nil, &operationError{Error: "unknown table", Details: op.Table}
+	}
+	// The conditions are required by select and update like ovsdb-server
+	if op.Where == nil {
+		return nil, &operationError{Error: "syntax error", Details: "where is required"}
+	}
+	names, opErr := match(ports, op.Where)
+	if opErr != nil {
+		return nil, opErr
+	}
+
+	switch op.Op {
+	case "select":
+		rows := []map[string]interface{}{}
+		for _, name := range names {
+			rows = append(rows, encodePort(name, ports[name], op.Columns))
+		}
+		return map[string]interface{}{"rows": rows}, nil
+
+	case "update":
+		for _, name := range names {
+			for column, value := range op.Row {
+				opErr = setColumn(ports[name], column, value)
+				if opErr != nil {
+					return nil, opErr
+				}
+			}
+		}
+		return map[string]interface{}{"count": len(names)}, nil
+
+	default:
+		return nil, &operationError{Error: "not supported", Details: op.Op}
+	}
+}
+
+// match return the names of ports matching the conditions, only `name == <value>` is supported
+func match(ports map[string]*Port, where [][]json.RawMessage) ([]string, *operationError) {
+	names := []string{}
+	for name := range ports {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, condition := range where {
+		var column, function, value string
+		if len(condition) != 3 ||
+			json.Unmarshal(condition[0], &column) != nil ||
+			json.Unmarshal(condition[1], &function) != nil ||
+			json.Unmarshal(condition[2], &value) != nil ||
+			column != "name" || function != "==" {
+			return nil, &operationError{Error: "not supported", Details: "only name == <string> is supported"}
+		}
+		if ports[value] == nil {
+			return []string{}, nil
+		}
+		names = []string{value}
+	}
+	return names, nil
+}
+
+// encodePort encodes the columns of port, a set of one element is encoded as
+// the element like the real server
+func encodePort(name string, port *Port, columns []string) map[string]interface{} {
+	row := map[string]interface{}{}
+	for _, column := range columns {
+		switch column {
+		case "name":
+			row[column] = name
+		case "tag":
+			row[column] = encodeSet(port.Tag)
+		case "trunks":
+			row[column] = encodeSet(port.Trunks)
+		case "vlan_mode":
+			row[column] = encodeSet(port.VLANMode)
+		}
+	}
+	return row
+}
+
+func encodeSet(values interface{}) interface{} {
+	switch v := values.(type) {
+	case []int:
+		if len(v) == 1 {
+			return v[0]
+		}
+		return []interface{}{"set", append([]int{}, v...)}
+	case []string:
+		if len(v) == 1 {
+			return v[0]
+		}
+		return []interface{}{"set", append([]string{}, v...)}
+	}
+	return nil
+}
+
+// setColumn set the column of port with the constraints of Open vSwitch schema
+func setColumn(port *Port, column string, value json.RawMessage) *operationError {
+	switch column {
+	case "tag":
+		tags, err := decodeIntegers(value, 0, 4095)
+		if err != nil || len(tags) > 1 {
+			return &operationError{Error: "constraint violation", Details: fmt.Sprintf("invalid tag %s", value)}
+		}
+		port.Tag = tags
+	case "trunks":
+		trunks, err := decodeIntegers(value, 0, 4095)
+		if err != nil {
+			return &operationError{Error: "constraint violation", Details: fmt.Sprintf("invalid trunks %s", value)}
+		}
+		sort.Ints(trunks)
+		port.Trunks = trunks
+	case "vlan_mode":
+		modes, err := decodeStrings(value)
+		if err != nil || len(modes) > 1 {
+			return &operationError{Error: "constraint violation", Details: fmt.Sprintf("invalid vlan_mode %s", value)}
+		}
+		for _, mode := range modes {
+			switch mode {
+			case "access", "trunk", "native-tagged", "native-untagged", "dot1q-tunnel":
+			default:
+				return &operationError{Error: "constraint violation", Details: fmt.Sprintf("%s is not one of the allowed values", mode)}
+			}
+		}
+		port.VLANMode = modes
+	default:
+		return &operationError{Error: "unknown column", Details: column}
+	}
+	return nil
+}
+
+// elements return the elements of the set, or the atom itself
+func elements(value json.RawMessage) []json.RawMessage {
+	array := []json.RawMessage{}
+	var kind string
+	if json.Unmarshal(value, &array) == nil && len(array) == 2 &&
+		json.Unmarshal(array[0], &kind) == nil && kind == "set" {
+		result := []json.RawMessage{}
+		if json.Unmarshal(array[1], &result) == nil {
+			return result
+		}
+	}
+	return []json.RawMessage{value}
+}
+
+func decodeIntegers(value json.RawMessage, min int, max int) ([]int, error) {
+	result := []int{}
+	for _, element := range elements(value) {
+		var v int
+		err := json.Unmarshal(element, &v)
+		if err != nil {
+			return nil, err
+		}
+		if v < min || v > max {
+			return nil, fmt.Errorf("%d is out of range", v)
+		}
+		result = append(result, v)
+	}
+	return result, nil
+}
+
+func decodeStrings(value json.RawMessage) ([]string, error) {
+	result := []string{}
+	for _, element := range elements(value) {
+		var v string
+		err := json.Unmarshal(element, &v)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, v)
+	}
+	return result, nil
+}
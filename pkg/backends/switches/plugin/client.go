@@ -0,0 +1,57 @@
+package plugin
+
+import (
+	"crypto/tls"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/Hellcatlk/network-operator/pkg/backends"
+	"google.golang.org/grpc"
+	grpccredentials "google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// conns are shared by the backends, so the connections to the plugins are reused
+var (
+	connsMutex sync.Mutex
+	conns      = map[string]*grpc.ClientConn{}
+)
+
+// clientFor return the client of the plugin listening on the endpoint
+func clientFor(endpoint string) (SwitchClient, error) {
+	connsMutex.Lock()
+	defer connsMutex.Unlock()
+
+	if conn := conns[endpoint]; conn != nil {
+		return NewSwitchClient(conn), nil
+	}
+
+	transport, err := transportCredentials(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	// The connection is established by the first call
+	conn, err := grpc.Dial(endpoint, grpc.WithTransportCredentials(transport))
+	if err != nil {
+		return nil, backends.NewError(backends.InvalidConfiguration, err)
+	}
+	conns[endpoint] = conn
+	return NewSwitchClient(conn), nil
+}
+
+// transportCredentials return the credentials of the endpoint, the endpoint is
+// `unix://<path>`, or `<host>:<port>` which is connected with TLS because the
+// credentials of switch are sent to the plugin
+func transportCredentials(endpoint string) (grpccredentials.TransportCredentials, error) {
+	if strings.HasPrefix(endpoint, "unix://") {
+		return insecure.NewCredentials(), nil
+	}
+
+	host, _, err := net.SplitHostPort(endpoint)
+	if err != nil || host == "" {
+		return nil, backends.Errorf(backends.InvalidConfiguration, "endpoint %q must be `unix://<path>` or `<host>:<port>`", endpoint)
+	}
+	// The server name is the host of endpoint
+	return grpccredentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12}), nil
+}
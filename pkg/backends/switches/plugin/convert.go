@@ -0,0 +1,134 @@
+package plugin
+
+import (
+	"github.com/Hellcatlk/network-operator/api/v1alpha1"
+	"github.com/Hellcatlk/network-operator/pkg/backends"
+	"github.com/Hellcatlk/network-operator/pkg/provider"
+	"github.com/Hellcatlk/network-operator/pkg/utils/credentials"
+)
+
+// The conversions between the messages of switch.proto and the types used by backends
+
+// toSwitchConfiguration return the message of configuration, the options of
+// plugin must be strings as the options of PluginSwitch
+func toSwitchConfiguration(config *provider.SwitchConfiguration) (*SwitchConfiguration, error) {
+	out := &SwitchConfiguration{
+		Os:      config.OS,
+		Host:    config.Host,
+		Options: map[string]string{},
+	}
+	if config.Credentials != nil {
+		out.Username = config.Credentials.Username
+		out.Password = config.Credentials.Password
+	}
+	for key, value := range config.Options {
+		s, ok := value.(string)
+		if !ok {
+			return nil, backends.Errorf(backends.InvalidConfiguration, "option %s must be a string, got %T", key, value)
+		}
+		out.Options[key] = s
+	}
+	return out, nil
+}
+
+// fromSwitchConfiguration return the configuration whose options are strings
+func fromSwitchConfiguration(config *SwitchConfiguration) *provider.SwitchConfiguration {
+	out := &provider.SwitchConfiguration{
+		OS:   config.GetOs(),
+		Host: config.GetHost(),
+		Credentials: &credentials.Credentials{
+			Username: config.GetUsername(),
+			Password: config.GetPassword(),
+		},
+		Backend: "plugin",
+		Options: map[string]interface{}{},
+	}
+	for key, value := range config.GetOptions() {
+		out.Options[key] = value
+	}
+	return out
+}
+
+func toPortConfiguration(configuration *v1alpha1.SwitchPortConfigurationSpec) *PortConfiguration {
+	if configuration == nil {
+		return nil
+	}
+
+	out := &PortConfiguration{
+		TaggedVlanRange: configuration.TaggedVLANRange,
+		Disable:         configuration.Disable,
+	}
+	for _, acl := range configuration.ACLs {
+		out.Acls = append(out.Acls, &ACL{
+			IpVersion:            acl.IPVersion,
+			Action:               acl.Action,
+			Protocol:             acl.Protocol,
+			SourceIp:             acl.SourceIP,
+			SourcePortRange:      acl.SourcePortRange,
+			DestinationIp:        acl.DestinationIP,
+			DestinationPortRange: acl.DestinationPortRange,
+		})
+	}
+	if configuration.UntaggedVLAN != nil {
+		vlan := int32(*configuration.UntaggedVLAN)
+		out.UntaggedVlan = &vlan
+	}
+	if configuration.MTU != nil {
+		mtu := int32(*configuration.MTU)
+		out.Mtu = &mtu
+	}
+	return out
+}
+
+// fromPortConfiguration return the configuration, it's empty if the message isn't set
+func fromPortConfiguration(configuration *PortConfiguration) *v1alpha1.SwitchPortConfigurationSpec {
+	out := &v1alpha1.SwitchPortConfigurationSpec{
+		TaggedVLANRange: configuration.GetTaggedVlanRange(),
+		Disable:         configuration.GetDisable(),
+	}
+	for _, acl := range configuration.GetAcls() {
+		out.ACLs = append(out.ACLs, v1alpha1.ACL{
+			IPVersion:            acl.GetIpVersion(),
+			Action:               acl.GetAction(),
+			Protocol:             acl.GetProtocol(),
+			SourceIP:             acl.GetSourceIp(),
+			SourcePortRange:      acl.GetSourcePortRange(),
+			DestinationIP:        acl.GetDestinationIp(),
+			DestinationPortRange: acl.GetDestinationPortRange(),
+		})
+	}
+	if configuration.UntaggedVlan != nil {
+		vlan := int(configuration.GetUntaggedVlan())
+		out.UntaggedVLAN = &vlan
+	}
+	if configuration.Mtu != nil {
+		mtu := int(configuration.GetMtu())
+		out.MTU = &mtu
+	}
+	return out
+}
+
+func toCapabilities(capabilities *v1alpha1.SwitchCapabilities) *Capabilities {
+	if capabilities == nil {
+		return nil
+	}
+	return &Capabilities{
+		Acls:        capabilities.ACLs,
+		TaggedVlans: capabilities.TaggedVLANs,
+		NativeVlan:  capabilities.NativeVLAN,
+		Disable:     capabilities.Disable,
+		Mtu:         capabilities.MTU,
+	}
+}
+
+// fromCapabilities return the capabilities, none of the fields is supported if
+// the message isn't set
+func fromCapabilities(capabilities *Capabilities) *v1alpha1.SwitchCapabilities {
+	return &v1alpha1.SwitchCapabilities{
+		ACLs:        capabilities.GetAcls(),
+		TaggedVLANs: capabilities.GetTaggedVlans(),
+		NativeVLAN:  capabilities.GetNativeVlan(),
+		Disable:     capabilities.GetDisable(),
+		MTU:         capabilities.GetMtu(),
+	}
+}
@@ -0,0 +1,63 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/Hellcatlk/network-operator/pkg/backends"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The errors of backends are typed by the status code of gRPC, see switch.proto
+
+// errorFromStatus return the typed error of the failed call
+func errorFromStatus(ctx context.Context, endpoint string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() != nil {
+		return backends.FromContext(ctx)
+	}
+
+	s := status.Convert(err)
+	switch s.Code() {
+	case codes.Unauthenticated, codes.PermissionDenied:
+		return backends.NewError(backends.Authentication, errors.New(s.Message()))
+	case codes.Unimplemented:
+		return backends.NewError(backends.Unsupported, errors.New(s.Message()))
+	case codes.InvalidArgument:
+		return backends.NewError(backends.InvalidConfiguration, errors.New(s.Message()))
+	case codes.NotFound:
+		return backends.NewError(backends.PortNotFound, errors.New(s.Message()))
+	default:
+		return backends.Errorf(backends.Transient, "call plugin %s failed: %s", endpoint, s.Message())
+	}
+}
+
+// statusFromError return the status of the error returned by backend
+func statusFromError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() != nil {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+
+	// The type is carried by the code, so it isn't repeated in the message
+	errorType := backends.TypeOf(err)
+	message := strings.TrimPrefix(err.Error(), string(errorType)+": ")
+	switch errorType {
+	case backends.Authentication:
+		return status.Error(codes.Unauthenticated, message)
+	case backends.Unsupported:
+		return status.Error(codes.Unimplemented, message)
+	case backends.InvalidConfiguration:
+		return status.Error(codes.InvalidArgument, message)
+	case backends.PortNotFound:
+		return status.Error(codes.NotFound, message)
+	default:
+		return status.Error(codes.Unavailable, message)
+	}
+}
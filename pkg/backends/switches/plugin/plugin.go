@@ -0,0 +1,106 @@
+// Package plugin is the switch backend which delegates the operations to an
+// out-of-tree plugin over gRPC, the contract is defined by switch.proto.
+package plugin
+
+import (
+	"context"
+
+	"github.com/Hellcatlk/network-operator/api/v1alpha1"
+	"github.com/Hellcatlk/network-operator/pkg/backends"
+	"github.com/Hellcatlk/network-operator/pkg/provider"
+)
+
+// New return plugin backend, the plugin is set by the `endpoint` option, or
+// by the `executable` option which is started by the operator
+func New(ctx context.Context, config *provider.SwitchConfiguration) (backends.Switch, error) {
+	endpoint, _ := config.Options["endpoint"].(string)
+	executable, _ := config.Options["executable"].(string)
+
+	switch {
+	case endpoint != "" && executable != "":
+		return nil, backends.Errorf(backends.InvalidConfiguration, "only one of endpoint and executable can be set")
+	case executable != "":
+		var err error
+		endpoint, err = start(ctx, executable)
+		if err != nil {
+			return nil, err
+		}
+	case endpoint == "":
+		return nil, backends.Errorf(backends.InvalidConfiguration, "endpoint or executable is required")
+	}
+
+	client, err := clientFor(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	// The options of plugin itself aren't sent to the plugin
+	options := map[string]interface{}{}
+	for key, value := range config.Options {
+		if key != "endpoint" && key != "executable" {
+			options[key] = value
+		}
+	}
+	switchConfig, err := toSwitchConfiguration(&provider.SwitchConfiguration{
+		OS:          config.OS,
+		Host:        config.Host,
+		Credentials: config.Credentials,
+		Options:     options,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &plugin{
+		endpoint: endpoint,
+		client:   client,
+		config:   switchConfig,
+	}, nil
+}
+
+// plugin sends the operations to the plugin with the configuration of switch
+type plugin struct {
+	endpoint string
+	client   SwitchClient
+	config   *SwitchConfiguration
+}
+
+// IsAvailable check switch is available or not
+func (p *plugin) IsAvailable(ctx context.Context) error {
+	_, err := p.client.IsAvailable(ctx, &IsAvailableRequest{Switch: p.config})
+	return errorFromStatus(ctx, p.endpoint, err)
+}
+
+// GetPortAttr get the port's configuration
+func (p *plugin) GetPortAttr(ctx context.Context, port string) (*v1alpha1.SwitchPortConfigurationSpec, error) {
+	out, err := p.client.GetPortAttr(ctx, &GetPortAttrRequest{Switch: p.config, Port: port})
+	if err != nil {
+		return nil, errorFromStatus(ctx, p.endpoint, err)
+	}
+	return fromPortConfiguration(out.GetConfiguration()), nil
+}
+
+// SetPortAttr set configure to the switchport
+func (p *plugin) SetPortAttr(ctx context.Context, port string, configuration *v1alpha1.SwitchPortConfigurationSpec) error {
+	_, err := p.client.SetPortAttr(ctx, &SetPortAttrRequest{Switch: p.config, Port: port, Configuration: toPortConfiguration(configuration)})
+	return errorFromStatus(ctx, p.endpoint, err)
+}
+
+// ResetPort remove all configure of the port
+func (p *plugin) ResetPort(ctx context.Context, port string, configuration *v1alpha1.SwitchPortConfigurationSpec) error {
+	_, err := p.client.ResetPort(ctx, &ResetPortRequest{Switch: p.config, Port: port, Configuration: toPortConfiguration(configuration)})
+	return errorFromStatus(ctx, p.endpoint, err)
+}
+
+// Capabilities return the fields supported by the plugin, all of them are
+// supported if the plugin doesn't implement the method
+func (p *plugin) Capabilities(ctx context.Context) (*v1alpha1.SwitchCapabilities, error) {
+	out, err := p.client.Capabilities(ctx, &CapabilitiesRequest{Switch: p.config})
+	err = errorFromStatus(ctx, p.endpoint, err)
+	if backends.TypeOf(err) == backends.Unsupported {
+		return backends.AllCapabilities(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return fromCapabilities(out.GetCapabilities()), nil
+}
@@ -0,0 +1,292 @@
+package plugin
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Hellcatlk/network-operator/api/v1alpha1"
+	"github.com/Hellcatlk/network-operator/pkg/backends"
+	"github.com/Hellcatlk/network-operator/pkg/provider"
+	"github.com/Hellcatlk/network-operator/pkg/utils/credentials"
+)
+
+// recorder is a backend which records the last call and returns err
+type recorder struct {
+	config        *provider.SwitchConfiguration
+	port          string
+	configuration *v1alpha1.SwitchPortConfigurationSpec
+	// capabilities are unsupported if they are nil, like the old plugins
+	capabilities *v1alpha1.SwitchCapabilities
+	deadline     bool
+	err          error
+}
+
+func (r *recorder) IsAvailable(ctx context.Context) error {
+	_, r.deadline = ctx.Deadline()
+	return r.err
+}
+
+func (r *recorder) GetPortAttr(ctx context.Context, port string) (*v1alpha1.SwitchPortConfigurationSpec, error) {
+	r.port = port
+	return r.configuration, r.err
+}
+
+func (r *recorder) SetPortAttr(ctx context.Context, port string, configuration *v1alpha1.SwitchPortConfigurationSpec) error {
+	r.port, r.configuration = port, configuration
+	return r.err
+}
+
+func (r *recorder) ResetPort(ctx context.Context, port string, configuration *v1alpha1.SwitchPortConfigurationSpec) error {
+	r.port, r.configuration = port, configuration
+	return r.err
+}
+
+func (r *recorder) Capabilities(ctx context.Context) (*v1alpha1.SwitchCapabilities, error) {
+	if r.capabilities == nil {
+		return nil, backends.Errorf(backends.Unsupported, "unknown method Capabilities")
+	}
+	return r.capabilities, r.err
+}
+
+// serveRecorder serves the recorder on a unix socket and return the backend connected to it
+func serveRecorder(t *testing.T, r *recorder) backends.Switch {
+	socket := filepath.Join(t.TempDir(), "plugin.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		_ = Serve(listener, func(ctx context.Context, config *provider.SwitchConfiguration) (backends.Switch, error) {
+			r.config = config
+			return r, nil
+		})
+	}()
+
+	backend, err := New(context.Background(), &provider.SwitchConfiguration{
+		OS:          "junos",
+		Host:        "192.168.0.1",
+		Credentials: &credentials.Credentials{Username: "admin", Password: "secret"},
+		Backend:     "plugin",
+		Options: map[string]interface{}{
+			"endpoint": "unix://" + socket,
+			"bridge":   "br0",
+		},
+	})
+	if err != nil {
+		t.Fatalf("new backend failed: %v", err)
+	}
+	return backend
+}
+
+func TestPlugin(t *testing.T) {
+	vlan := 10
+	mtu := 9000
+	configuration := &v1alpha1.SwitchPortConfigurationSpec{
+		ACLs: []v1alpha1.ACL{
+			{IPVersion: "4", Action: "allow", Protocol: "TCP", SourceIP: "10.0.0.0/8", DestinationPortRange: "22"},
+		},
+		UntaggedVLAN:    &vlan,
+		TaggedVLANRange: "11-20,30",
+		Disable:         true,
+		MTU:             &mtu,
+	}
+
+	r := &recorder{}
+	backend := serveRecorder(t, r)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	err := backend.IsAvailable(ctx)
+	if err != nil {
+		t.Fatalf("IsAvailable failed: %v", err)
+	}
+	if !r.deadline {
+		t.Errorf("expected the deadline is propagated to plugin")
+	}
+	expectedConfig := &provider.SwitchConfiguration{
+		OS:          "junos",
+		Host:        "192.168.0.1",
+		Credentials: &credentials.Credentials{Username: "admin", Password: "secret"},
+		Backend:     "plugin",
+		Options:     map[string]interface{}{"bridge": "br0"},
+	}
+	if !reflect.DeepEqual(r.config, expectedConfig) {
+		t.Errorf("expected switch configuration: %+v, got: %+v", expectedConfig, r.config)
+	}
+
+	err = backend.SetPortAttr(ctx, "eth1", configuration)
+	if err != nil {
+		t.Fatalf("SetPortAttr failed: %v", err)
+	}
+	if r.port != "eth1" || !reflect.DeepEqual(r.configuration, configuration) {
+		t.Errorf("expected port eth1 with configuration: %+v, got: %s %+v", configuration, r.port, r.configuration)
+	}
+
+	got, err := backend.GetPortAttr(ctx, "eth2")
+	if err != nil {
+		t.Fatalf("GetPortAttr failed: %v", err)
+	}
+	if r.port != "eth2" || !reflect.DeepEqual(got, configuration) {
+		t.Errorf("expected configuration: %+v, got: %+v", configuration, got)
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	cases := []struct {
+		name                 string
+		capabilities         *v1alpha1.SwitchCapabilities
+		expectedCapabilities *v1alpha1.SwitchCapabilities
+	}{
+		{
+			name:                 "some fields",
+			capabilities:         &v1alpha1.SwitchCapabilities{TaggedVLANs: true, MTU: true},
+			expectedCapabilities: &v1alpha1.SwitchCapabilities{TaggedVLANs: true, MTU: true},
+		},
+		{
+			name:                 "no field",
+			capabilities:         &v1alpha1.SwitchCapabilities{},
+			expectedCapabilities: &v1alpha1.SwitchCapabilities{},
+		},
+		{
+			name:                 "unimplemented",
+			expectedCapabilities: backends.AllCapabilities(),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			backend := serveRecorder(t, &recorder{capabilities: c.capabilities})
+			capabilities, err := backend.Capabilities(context.Background())
+			if err != nil {
+				t.Fatalf("Capabilities failed: %v", err)
+			}
+			if !reflect.DeepEqual(capabilities, c.expectedCapabilities) {
+				t.Errorf("expected capabilities: %+v, got: %+v", c.expectedCapabilities, capabilities)
+			}
+		})
+	}
+}
+
+func TestPluginError(t *testing.T) {
+	cases := []struct {
+		name              string
+		err               error
+		expectedErrorType backends.ErrorType
+	}{
+		{
+			name:              "authentication",
+			err:               backends.Errorf(backends.Authentication, "permission denied"),
+			expectedErrorType: backends.Authentication,
+		},
+		{
+			name:              "port not found",
+			err:               backends.Errorf(backends.PortNotFound, "no such port: 100%% eth9"),
+			expectedErrorType: backends.PortNotFound,
+		},
+		{
+			name:              "untyped error",
+			err:               context.DeadlineExceeded,
+			expectedErrorType: backends.Transient,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			backend := serveRecorder(t, &recorder{err: c.err})
+			err := backend.ResetPort(context.Background(), "eth1", &v1alpha1.SwitchPortConfigurationSpec{})
+			if errorType := backends.TypeOf(err); errorType != c.expectedErrorType {
+				t.Errorf("expected error type: %q, got: %v", c.expectedErrorType, err)
+			}
+			if c.expectedErrorType != backends.Transient && err.Error() != c.err.Error() {
+				t.Errorf("expected error: %v, got: %v", c.err, err)
+			}
+		})
+	}
+}
+
+func TestPluginUnavailable(t *testing.T) {
+	backend, err := New(context.Background(), &provider.SwitchConfiguration{
+		Backend: "plugin",
+		Options: map[string]interface{}{"endpoint": "unix://" + filepath.Join(t.TempDir(), "not-existed.sock")},
+	})
+	if err != nil {
+		t.Fatalf("new backend failed: %v", err)
+	}
+	err = backend.IsAvailable(context.Background())
+	if !backends.IsTransient(err) {
+		t.Errorf("expected transient error, got: %v", err)
+	}
+}
+
+func TestPluginPlaintextEndpoint(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	r := &recorder{}
+	go func() {
+		_ = Serve(listener, func(ctx context.Context, config *provider.SwitchConfiguration) (backends.Switch, error) {
+			r.config = config
+			return r, nil
+		})
+	}()
+
+	backend, err := New(context.Background(), &provider.SwitchConfiguration{
+		Credentials: &credentials.Credentials{Username: "admin", Password: "secret"},
+		Backend:     "plugin",
+		Options:     map[string]interface{}{"endpoint": listener.Addr().String()},
+	})
+	if err != nil {
+		t.Fatalf("new backend failed: %v", err)
+	}
+	err = backend.IsAvailable(context.Background())
+	if !backends.IsTransient(err) {
+		t.Errorf("expected transient error, got: %v", err)
+	}
+	if r.config != nil {
+		t.Errorf("expected the credentials aren't sent without TLS, got: %+v", r.config)
+	}
+}
+
+func TestNewInvalidConfiguration(t *testing.T) {
+	cases := []struct {
+		name    string
+		options map[string]interface{}
+	}{
+		{
+			name:    "neither endpoint nor executable",
+			options: map[string]interface{}{},
+		},
+		{
+			name:    "both endpoint and executable",
+			options: map[string]interface{}{"endpoint": "127.0.0.1:5000", "executable": "vendor-plugin"},
+		},
+		{
+			name:    "executable out of directory",
+			options: map[string]interface{}{"executable": "../bin/sh"},
+		},
+		{
+			name:    "endpoint without port",
+			options: map[string]interface{}{"endpoint": "plugin.example.com"},
+		},
+		{
+			name:    "option isn't string",
+			options: map[string]interface{}{"endpoint": "unix:///run/plugin.sock", "vlan": 10},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := New(context.Background(), &provider.SwitchConfiguration{Backend: "plugin", Options: c.options})
+			if backends.TypeOf(err) != backends.InvalidConfiguration {
+				t.Errorf("expected invalid configuration error, got: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/Hellcatlk/network-operator/pkg/backends"
+)
+
+// startTimeout is the time the plugin can spend on listening on the endpoint
+const startTimeout time.Duration = time.Second * 10
+
+// directory is the only directory the executables of plugins can be started from
+var directory string = "/plugins"
+
+// SetDirectory sets the directory of the executables of plugins, it's set
+// before the controllers start
+func SetDirectory(dir string) {
+	directory = dir
+}
+
+// process is a running plugin started by the operator
+type process struct {
+	endpoint string
+	// exited is closed when the process exits
+	exited chan struct{}
+}
+
+// processes are the plugins started by the operator, they are shared by all
+// of the switches using the same executable
+var (
+	processesMutex sync.Mutex
+	processes      = map[string]*process{}
+)
+
+// start starts the executable if it isn't running, and return the endpoint
+// after the plugin listens on it
+func start(ctx context.Context, executable string) (string, error) {
+	// Any executable in the image could be run by the users who can create
+	// PluginSwitch, so only the executables in the directory are allowed
+	if executable != filepath.Base(executable) || executable == "." || executable == ".." {
+		return "", backends.Errorf(backends.InvalidConfiguration, "executable %q must be a file name in %s", executable, directory)
+	}
+
+	processesMutex.Lock()
+	p := processes[executable]
+	running := p != nil
+	if running {
+		select {
+		case <-p.exited:
+			running = false
+		default:
+		}
+	}
+	if !running {
+		var err error
+		p, err = run(executable)
+		if err != nil {
+			processesMutex.Unlock()
+			return "", err
+		}
+		processes[executable] = p
+	}
+	processesMutex.Unlock()
+
+	err := p.wait(ctx)
+	if err != nil {
+		return "", err
+	}
+	return p.endpoint, nil
+}
+
+// run starts the executable listening on a unix socket
+func run(executable string) (*process, error) {
+	socket := filepath.Join(os.TempDir(), "network-operator-plugin-"+executable+".sock")
+	cmd := exec.Command(filepath.Join(directory, executable)) // #nosec
+	cmd.Env = append(os.Environ(), EndpointEnv+"=unix://"+socket)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	// Remove the socket left by the last run, so wait doesn't see it
+	_ = os.Remove(socket)
+	err := cmd.Start()
+	if err != nil {
+		return nil, backends.NewError(backends.InvalidConfiguration, fmt.Errorf("start plugin %s failed: %v", executable, err))
+	}
+
+	p := &process{
+		endpoint: "unix://" + socket,
+		exited:   make(chan struct{}),
+	}
+	go func() {
+		_ = cmd.Wait()
+		close(p.exited)
+	}()
+	return p, nil
+}
+
+// wait waits until the plugin listens on the socket
+func (p *process) wait(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, startTimeout)
+	defer cancel()
+
+	socket := p.endpoint[len("unix://"):]
+	ticker := time.NewTicker(time.Millisecond * 100)
+	defer ticker.Stop()
+	for {
+		_, err := os.Stat(socket)
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-p.exited:
+			return backends.Errorf(backends.Transient, "plugin exited before listening on %s", socket)
+		case <-ctx.Done():
+			return backends.Errorf(backends.Transient, "plugin didn't listen on %s in time", socket)
+		case <-ticker.C:
+		}
+	}
+}
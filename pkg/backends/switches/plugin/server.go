@@ -0,0 +1,138 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/Hellcatlk/network-operator/pkg/backends"
+	"github.com/Hellcatlk/network-operator/pkg/provider"
+	"google.golang.org/grpc"
+)
+
+// EndpointEnv is the environment variable of the endpoint which the plugin
+// started by the operator must listen on
+const EndpointEnv string = "NETWORK_OPERATOR_PLUGIN_ENDPOINT"
+
+// NewFunc return the backend of the switch, it has the same signature as the
+// in-tree backends, so they can be served as plugins without any change
+type NewFunc func(context.Context, *provider.SwitchConfiguration) (backends.Switch, error)
+
+// Main serves the backend on the endpoint set by the operator, it's used as the
+// main function of plugins
+func Main(new NewFunc) error {
+	endpoint := os.Getenv(EndpointEnv)
+	if endpoint == "" {
+		return fmt.Errorf("%s isn't set", EndpointEnv)
+	}
+	return ListenAndServe(endpoint, new)
+}
+
+// ListenAndServe listens on the endpoint and serves the backend, the endpoint is
+// `unix://<path>` or `<host>:<port>`. The manager only connects to `<host>:<port>`
+// with TLS, so the TLS credentials must be set by opts for it
+func ListenAndServe(endpoint string, new NewFunc, opts ...grpc.ServerOption) error {
+	network, address := "tcp", endpoint
+	if strings.HasPrefix(endpoint, "unix://") {
+		network, address = "unix", strings.TrimPrefix(endpoint, "unix://")
+		// Remove the socket left by the last run
+		_ = os.Remove(address)
+	}
+
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		return err
+	}
+	if network == "unix" {
+		// The credentials are sent to the plugin, so the socket is only accessible by owner
+		err = os.Chmod(address, 0600)
+		if err != nil {
+			listener.Close()
+			return err
+		}
+	}
+
+	return Serve(listener, new, opts...)
+}
+
+// Serve serves the backend on the listener until it's closed, the options of
+// server such as the TLS credentials are set by opts
+func Serve(listener net.Listener, new NewFunc, opts ...grpc.ServerOption) error {
+	server := grpc.NewServer(opts...)
+	RegisterSwitchServer(server, &handler{new: new})
+	err := server.Serve(listener)
+	if errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+	return err
+}
+
+// handler implements `Switch` service with the backend created for every call
+type handler struct {
+	UnimplementedSwitchServer
+	new NewFunc
+}
+
+// backend return the backend of the switch configuration in the request
+func (h *handler) backend(ctx context.Context, config *SwitchConfiguration) (backends.Switch, error) {
+	if config == nil {
+		return nil, backends.Errorf(backends.InvalidConfiguration, "switch configuration is required")
+	}
+	return h.new(ctx, fromSwitchConfiguration(config))
+}
+
+// IsAvailable implements SwitchServer interface
+func (h *handler) IsAvailable(ctx context.Context, in *IsAvailableRequest) (*IsAvailableResponse, error) {
+	backend, err := h.backend(ctx, in.GetSwitch())
+	if err == nil {
+		err = backend.IsAvailable(ctx)
+	}
+	return &IsAvailableResponse{}, statusFromError(ctx, err)
+}
+
+// GetPortAttr implements SwitchServer interface
+func (h *handler) GetPortAttr(ctx context.Context, in *GetPortAttrRequest) (*GetPortAttrResponse, error) {
+	backend, err := h.backend(ctx, in.GetSwitch())
+	if err != nil {
+		return nil, statusFromError(ctx, err)
+	}
+	configuration, err := backend.GetPortAttr(ctx, in.GetPort())
+	if err != nil {
+		return nil, statusFromError(ctx, err)
+	}
+	return &GetPortAttrResponse{Configuration: toPortConfiguration(configuration)}, nil
+}
+
+// SetPortAttr implements SwitchServer interface
+func (h *handler) SetPortAttr(ctx context.Context, in *SetPortAttrRequest) (*SetPortAttrResponse, error) {
+	backend, err := h.backend(ctx, in.GetSwitch())
+	if err == nil {
+		err = backend.SetPortAttr(ctx, in.GetPort(), fromPortConfiguration(in.GetConfiguration()))
+	}
+	return &SetPortAttrResponse{}, statusFromError(ctx, err)
+}
+
+// ResetPort implements SwitchServer interface
+func (h *handler) ResetPort(ctx context.Context, in *ResetPortRequest) (*ResetPortResponse, error) {
+	backend, err := h.backend(ctx, in.GetSwitch())
+	if err == nil {
+		err = backend.ResetPort(ctx, in.GetPort(), fromPortConfiguration(in.GetConfiguration()))
+	}
+	return &ResetPortResponse{}, statusFromError(ctx, err)
+}
+
+// Capabilities implements SwitchServer interface
+func (h *handler) Capabilities(ctx context.Context, in *CapabilitiesRequest) (*CapabilitiesResponse, error) {
+	backend, err := h.backend(ctx, in.GetSwitch())
+	if err != nil {
+		return nil, statusFromError(ctx, err)
+	}
+	capabilities, err := backend.Capabilities(ctx)
+	if err != nil {
+		return nil, statusFromError(ctx, err)
+	}
+	return &CapabilitiesResponse{Capabilities: toCapabilities(capabilities)}, nil
+}
@@ -0,0 +1,108 @@
+package restconf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Hellcatlk/network-operator/api/v1alpha1"
+)
+
+// descriptionPrefix is the prefix of the description of ACL entries, the
+// description records the ACL
+const descriptionPrefix string = "network-operator"
+
+// aclName return the name of the ACL sets of the port
+func aclName(port string) string {
+	return "NETWORK-OPERATOR-" + port
+}
+
+// validateACL check the ACL can be mapped onto the models
+func validateACL(acl v1alpha1.ACL) error {
+	if (acl.IPVersion != "4" && acl.IPVersion != "6") || (acl.Action != "allow" && acl.Action != "deny") {
+		return fmt.Errorf("ipVersion, action and protocol are required by restconf backend")
+	}
+	switch acl.Protocol {
+	case "TCP", "UDP":
+	case "ICMP", "ALL":
+		if acl.SourcePortRange != "" || acl.DestinationPortRange != "" {
+			return fmt.Errorf("port range requires TCP or UDP")
+		}
+	default:
+		return fmt.Errorf("ipVersion, action and protocol are required by restconf backend")
+	}
+	for _, ip := range []string{acl.SourceIP, acl.DestinationIP} {
+		if strings.ContainsAny(ip, " =") || (ip != "" && strings.Contains(ip, ":") != (acl.IPVersion == "6")) {
+			return fmt.Errorf("%q isn't an IPv%s address", ip, acl.IPVersion)
+		}
+	}
+	for _, list := range []string{acl.SourcePortRange, acl.DestinationPortRange} {
+		if list == "" {
+			continue
+		}
+		for _, r := range strings.Split(list, ",") {
+			bounds := strings.Split(r, "-")
+			if len(bounds) > 2 {
+				return fmt.Errorf("invalid port range %q", r)
+			}
+			for _, bound := range bounds {
+				port, err := strconv.Atoi(bound)
+				if err != nil || port < 0 || port > 65535 || strconv.Itoa(port) != bound {
+					return fmt.Errorf("invalid port %q", bound)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// description return the description recording the index and ACL, the empty
+// fields are omitted
+func description(index int, acl v1alpha1.ACL) string {
+	text := descriptionPrefix + " index=" + strconv.Itoa(index)
+	for _, field := range aclFields(&acl) {
+		if *field.value != "" {
+			text += " " + field.name + "=" + *field.value
+		}
+	}
+	return text
+}
+
+// parseDescription return the index and ACL recorded by the description
+func parseDescription(text string) (int, v1alpha1.ACL, bool) {
+	acl := v1alpha1.ACL{}
+	if !strings.HasPrefix(text, descriptionPrefix+" ") {
+		return 0, acl, false
+	}
+	index := -1
+	fields := aclFields(&acl)
+	for _, pair := range strings.Fields(strings.TrimPrefix(text, descriptionPrefix)) {
+		if strings.HasPrefix(pair, "index=") {
+			index, _ = strconv.Atoi(strings.TrimPrefix(pair, "index="))
+		}
+		for _, field := range fields {
+			if strings.HasPrefix(pair, field.name+"=") {
+				*field.value = strings.TrimPrefix(pair, field.name+"=")
+			}
+		}
+	}
+	return index, acl, index >= 0
+}
+
+// aclField is a field of ACL recorded by description
+type aclField struct {
+	name  string
+	value *string
+}
+
+func aclFields(acl *v1alpha1.ACL) []aclField {
+	return []aclField{
+		{"ipVersion", &acl.IPVersion},
+		{"action", &acl.Action},
+		{"protocol", &acl.Protocol},
+		{"sourceIP", &acl.SourceIP},
+		{"sourcePortRange", &acl.SourcePortRange},
+		{"destinationIP", &acl.DestinationIP},
+		{"destinationPortRange", &acl.DestinationPortRange},
+	}
+}
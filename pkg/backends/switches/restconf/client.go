@@ -0,0 +1,198 @@
+package restconf
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Hellcatlk/network-operator/pkg/backends"
+)
+
+// The media types of RESTCONF (RFC 8040) and YANG Patch (RFC 8072)
+const (
+	mediaTypeData  string = "application/yang-data+json"
+	mediaTypePatch string = "application/yang-patch+json"
+)
+
+// getFunc gets the data resource of path into v, it return false if the
+// resource doesn't exist
+type getFunc func(ctx context.Context, path string, v interface{}) (bool, error)
+
+// edit is an edit of YANG Patch, the target is the path of data resource and
+// the value has the member of target node
+type edit struct {
+	EditID    string      `json:"edit-id"`
+	Operation string      `json:"operation"`
+	Target    string      `json:"target"`
+	Value     interface{} `json:"value,omitempty"`
+}
+
+// restconfError is an error of RESTCONF
+type restconfError struct {
+	ErrorType    string `json:"error-type"`
+	ErrorTag     string `json:"error-tag"`
+	ErrorPath    string `json:"error-path"`
+	ErrorMessage string `json:"error-message"`
+}
+
+type errorList struct {
+	Error []restconfError `json:"error"`
+}
+
+// errorResponse is the body of the failed request, the errors of YANG Patch
+// are in `yang-patch-status`
+type errorResponse struct {
+	Errors      *errorList `json:"ietf-restconf:errors"`
+	PatchStatus *struct {
+		Errors     *errorList `json:"errors"`
+		EditStatus struct {
+			Edit []struct {
+				EditID string     `json:"edit-id"`
+				Errors *errorList `json:"errors"`
+			} `json:"edit"`
+		} `json:"edit-status"`
+	} `json:"ietf-yang-patch:yang-patch-status"`
+}
+
+// escape return the key of list entry in path, `,` separates the keys
+func escape(key string) string {
+	return strings.ReplaceAll(url.PathEscape(key), ",", "%2C")
+}
+
+// get gets the data resource of path into v, the root resource is got if the
+// path is empty
+func (r *restconf) get(ctx context.Context, path string, v interface{}) (bool, error) {
+	u := r.root
+	if path != "" {
+		u += "/data/" + path
+	}
+	resp, err := r.do(ctx, http.MethodGet, u, "", nil)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound && path != "" {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, responseError(resp)
+	}
+	if v == nil {
+		return true, nil
+	}
+	err = json.NewDecoder(resp.Body).Decode(v)
+	if err != nil {
+		return false, backends.Errorf(backends.Transient, "invalid data of %s: %v", path, err)
+	}
+	return true, nil
+}
+
+// patch applies the edits by YANG Patch on the datastore
+func (r *restconf) patch(ctx context.Context, edits []edit) error {
+	for i := range edits {
+		edits[i].EditID = strconv.Itoa(i + 1)
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"ietf-yang-patch:yang-patch": map[string]interface{}{
+			"patch-id": "network-operator",
+			"edit":     edits,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := r.do(ctx, http.MethodPatch, r.root+"/data", mediaTypePatch, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return responseError(resp)
+	}
+	return nil
+}
+
+func (r *restconf) do(ctx context.Context, method string, u string, contentType string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, backends.NewError(backends.InvalidConfiguration, err)
+	}
+	req.Header.Set("Accept", mediaTypeData)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.SetBasicAuth(r.credentials.Username, r.credentials.Password)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, backends.FromContext(ctx)
+		}
+		return nil, backends.NewError(backends.Transient, err)
+	}
+	return resp, nil
+}
+
+// responseError return the typed error of the failed response by the status
+// and the tag of its first error
+func responseError(resp *http.Response) error {
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	body := &errorResponse{}
+	_ = json.Unmarshal(b, body)
+
+	all := []restconfError{}
+	if body.Errors != nil {
+		all = append(all, body.Errors.Error...)
+	}
+	if body.PatchStatus != nil {
+		if body.PatchStatus.Errors != nil {
+			all = append(all, body.PatchStatus.Errors.Error...)
+		}
+		for _, e := range body.PatchStatus.EditStatus.Edit {
+			if e.Errors != nil {
+				all = append(all, e.Errors.Error...)
+			}
+		}
+	}
+
+	messages := []string{}
+	for _, e := range all {
+		message := e.ErrorTag
+		if e.ErrorMessage != "" {
+			message += ": " + e.ErrorMessage
+		}
+		if e.ErrorPath != "" {
+			message += " (" + e.ErrorPath + ")"
+		}
+		messages = append(messages, message)
+	}
+	err := fmt.Errorf("RESTCONF responded %s: %s", resp.Status, strings.Join(messages, "; "))
+
+	tag := ""
+	if len(all) != 0 {
+		tag = all[0].ErrorTag
+	}
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden || tag == "access-denied":
+		return backends.NewError(backends.Authentication, err)
+	case tag == "operation-not-supported":
+		return backends.NewError(backends.Unsupported, err)
+	case tag == "in-use" || tag == "lock-denied" || tag == "resource-denied" || tag == "operation-failed":
+		return backends.NewError(backends.Transient, err)
+	case resp.StatusCode == http.StatusNotFound:
+		return backends.Errorf(backends.InvalidConfiguration, "%v, check RESTCONF is enabled", err)
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		return backends.NewError(backends.InvalidConfiguration, err)
+	default:
+		return backends.NewError(backends.Transient, err)
+	}
+}
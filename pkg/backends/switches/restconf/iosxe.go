@@ -0,0 +1,226 @@
+package restconf
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+
+	"github.com/Hellcatlk/network-operator/api/v1alpha1"
+	"github.com/Hellcatlk/network-operator/pkg/backends"
+	ustrings "github.com/Hellcatlk/network-operator/pkg/utils/strings"
+)
+
+// iosXENative maps the configuration onto the native model of Cisco IOS XE,
+// the VLANs are the switchport of Cisco-IOS-XE-switch. ACLs aren't supported
+type iosXENative struct{}
+
+// iosXEInterfaceName splits the name of interface into the type and the number
+var iosXEInterfaceName = regexp.MustCompile(`^([A-Za-z][A-Za-z-]*)([0-9][0-9/.:]*)$`)
+
+type iosXEInterface struct {
+	Name string `json:"name"`
+	MTU  *int   `json:"mtu"`
+	// Shutdown is `[null]` if the interface is shut down
+	Shutdown   json.RawMessage `json:"shutdown"`
+	Switchport *struct {
+		Mode *struct {
+			Trunk json.RawMessage `json:"trunk"`
+		} `json:"Cisco-IOS-XE-switch:mode"`
+		Access *struct {
+			VLAN *struct {
+				VLAN *int `json:"vlan"`
+			} `json:"vlan"`
+		} `json:"Cisco-IOS-XE-switch:access"`
+		Trunk *struct {
+			Allowed *struct {
+				VLAN *struct {
+					VLANs string          `json:"vlans"`
+					None  json.RawMessage `json:"none"`
+				} `json:"vlan"`
+			} `json:"allowed"`
+			Native *struct {
+				VLANID *int `json:"vlan-id"`
+			} `json:"native"`
+		} `json:"Cisco-IOS-XE-switch:trunk"`
+	} `json:"switchport"`
+}
+
+// interfacePath return the path of interface in Cisco-IOS-XE-native, the
+// interfaces are the lists of their types
+func (iosXENative) interfacePath(port string) (string, error) {
+	matches := iosXEInterfaceName.FindStringSubmatch(port)
+	if matches == nil {
+		return "", backends.Errorf(backends.InvalidConfiguration, "invalid interface name %q of IOS XE", port)
+	}
+	return "Cisco-IOS-XE-native:native/interface/" + matches[1] + "=" + escape(matches[2]), nil
+}
+
+// capabilities return the fields except the ACLs
+func (iosXENative) capabilities() *v1alpha1.SwitchCapabilities {
+	return &v1alpha1.SwitchCapabilities{TaggedVLANs: true, NativeVLAN: true, Disable: true, MTU: true}
+}
+
+// edits return the edits of the switchport, shutdown and mtu of interface, the
+// missing VLANs are created
+func (x iosXENative) edits(port string, configuration *v1alpha1.SwitchPortConfigurationSpec) ([]edit, error) {
+	if len(configuration.ACLs) != 0 {
+		return nil, backends.Errorf(backends.Unsupported, "ACLs aren't supported by the cisco-ios-xe-native model")
+	}
+	tagged, vlans, err := vlanIDs(configuration)
+	if err != nil {
+		return nil, err
+	}
+	path, err := x.interfacePath(port)
+	if err != nil {
+		return nil, err
+	}
+	switchport := "/" + path + "/switchport"
+
+	edits := []edit{}
+	if len(vlans) != 0 {
+		entries := []interface{}{}
+		for _, vid := range vlans {
+			entries = append(entries, map[string]interface{}{"id": vid})
+		}
+		edits = append(edits, edit{
+			Operation: "merge",
+			Target:    "/Cisco-IOS-XE-native:native/vlan",
+			Value:     map[string]interface{}{"Cisco-IOS-XE-native:vlan": map[string]interface{}{"Cisco-IOS-XE-vlan:vlan-list": entries}},
+		})
+	}
+	if configuration.MTU != nil {
+		edits = append(edits, edit{
+			Operation: "replace",
+			Target:    "/" + path + "/mtu",
+			Value:     map[string]interface{}{"Cisco-IOS-XE-native:mtu": *configuration.MTU},
+		})
+	}
+	if configuration.Disable {
+		edits = append(edits, edit{
+			Operation: "replace",
+			Target:    "/" + path + "/shutdown",
+			Value:     map[string]interface{}{"Cisco-IOS-XE-native:shutdown": []interface{}{nil}},
+		})
+	} else {
+		edits = append(edits, edit{Operation: "remove", Target: "/" + path + "/shutdown"})
+	}
+
+	// The interface is an access port if only untagged VLAN is set, otherwise
+	// it's a trunk port whose native VLAN is the untagged VLAN
+	if len(tagged) == 0 && configuration.UntaggedVLAN != nil {
+		return append(edits,
+			iosXEMode(switchport, "access"),
+			edit{
+				Operation: "replace",
+				Target:    switchport + "/Cisco-IOS-XE-switch:access/vlan",
+				Value:     map[string]interface{}{"Cisco-IOS-XE-switch:vlan": map[string]interface{}{"vlan": *configuration.UntaggedVLAN}},
+			},
+			edit{Operation: "remove", Target: switchport + "/Cisco-IOS-XE-switch:trunk/allowed"},
+			edit{Operation: "remove", Target: switchport + "/Cisco-IOS-XE-switch:trunk/native"},
+		), nil
+	}
+
+	allowed := map[string]interface{}{"none": []interface{}{nil}}
+	if len(vlans) != 0 {
+		allowed = map[string]interface{}{"vlans": ustrings.SliceToRange(vlans)}
+	}
+	edits = append(edits,
+		iosXEMode(switchport, "trunk"),
+		edit{Operation: "remove", Target: switchport + "/Cisco-IOS-XE-switch:access/vlan"},
+		edit{
+			Operation: "replace",
+			Target:    switchport + "/Cisco-IOS-XE-switch:trunk/allowed",
+			Value:     map[string]interface{}{"Cisco-IOS-XE-switch:allowed": map[string]interface{}{"vlan": allowed}},
+		},
+	)
+	if configuration.UntaggedVLAN != nil {
+		edits = append(edits, edit{
+			Operation: "replace",
+			Target:    switchport + "/Cisco-IOS-XE-switch:trunk/native",
+			Value:     map[string]interface{}{"Cisco-IOS-XE-switch:native": map[string]interface{}{"vlan-id": *configuration.UntaggedVLAN}},
+		})
+	} else {
+		edits = append(edits, edit{Operation: "remove", Target: switchport + "/Cisco-IOS-XE-switch:trunk/native"})
+	}
+	return edits, nil
+}
+
+// iosXEMode return the edit of the mode of switchport
+func iosXEMode(switchport string, mode string) edit {
+	return edit{
+		Operation: "replace",
+		Target:    switchport + "/Cisco-IOS-XE-switch:mode",
+		Value:     map[string]interface{}{"Cisco-IOS-XE-switch:mode": map[string]interface{}{mode: map[string]interface{}{}}},
+	}
+}
+
+// resetEdits return the edits setting the interface to an access port of the
+// default VLAN and bringing it up
+func (x iosXENative) resetEdits(port string) ([]edit, error) {
+	path, err := x.interfacePath(port)
+	if err != nil {
+		return nil, err
+	}
+	switchport := "/" + path + "/switchport"
+	return []edit{
+		{Operation: "remove", Target: "/" + path + "/shutdown"},
+		iosXEMode(switchport, "access"),
+		{Operation: "remove", Target: switchport + "/Cisco-IOS-XE-switch:access/vlan"},
+		{Operation: "remove", Target: switchport + "/Cisco-IOS-XE-switch:trunk/allowed"},
+		{Operation: "remove", Target: switchport + "/Cisco-IOS-XE-switch:trunk/native"},
+	}, nil
+}
+
+// read return the configuration of the switchport, shutdown and mtu of
+// interface, the defaults of IOS XE are used for the missing nodes
+func (x iosXENative) read(ctx context.Context, get getFunc, port string) (*v1alpha1.SwitchPortConfigurationSpec, error) {
+	path, err := x.interfacePath(port)
+	if err != nil {
+		return nil, err
+	}
+	data := map[string][]iosXEInterface{}
+	found, err := get(ctx, path, &data)
+	if err != nil {
+		return nil, err
+	}
+	interfaces := data["Cisco-IOS-XE-native:"+iosXEInterfaceName.FindStringSubmatch(port)[1]]
+	if !found || len(interfaces) == 0 {
+		return nil, backends.Errorf(backends.PortNotFound, "interface %s doesn't exist", port)
+	}
+	i := interfaces[0]
+
+	configuration := &v1alpha1.SwitchPortConfigurationSpec{Disable: len(i.Shutdown) != 0, MTU: i.MTU}
+	s := i.Switchport
+	if s == nil || s.Mode == nil || len(s.Mode.Trunk) == 0 {
+		vlan := 1
+		if s != nil && s.Access != nil && s.Access.VLAN != nil && s.Access.VLAN.VLAN != nil {
+			vlan = *s.Access.VLAN.VLAN
+		}
+		configuration.UntaggedVLAN = &vlan
+		return configuration, nil
+	}
+
+	allowed := "1-4094"
+	native := 1
+	if s.Trunk != nil && s.Trunk.Allowed != nil && s.Trunk.Allowed.VLAN != nil {
+		allowed = s.Trunk.Allowed.VLAN.VLANs
+	}
+	if s.Trunk != nil && s.Trunk.Native != nil && s.Trunk.Native.VLANID != nil {
+		native = *s.Trunk.Native.VLANID
+	}
+	vlans, err := ustrings.RangeToSlice(allowed)
+	if err != nil {
+		return nil, backends.Errorf(backends.Transient, "invalid allowed VLANs %q", allowed)
+	}
+	tagged := []int{}
+	for _, vid := range vlans {
+		if vid == native {
+			untagged := vid
+			configuration.UntaggedVLAN = &untagged
+			continue
+		}
+		tagged = append(tagged, vid)
+	}
+	configuration.TaggedVLANRange = ustrings.SliceToRange(tagged)
+	return configuration, nil
+}
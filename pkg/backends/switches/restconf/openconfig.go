@@ -0,0 +1,463 @@
+package restconf
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Hellcatlk/network-operator/api/v1alpha1"
+	"github.com/Hellcatlk/network-operator/pkg/backends"
+	ustrings "github.com/Hellcatlk/network-operator/pkg/utils/strings"
+)
+
+// openConfig maps the configuration onto the OpenConfig models, the VLANs are
+// the switched-vlan of openconfig-vlan, the ACLs are the ingress ACL sets of
+// openconfig-acl
+type openConfig struct{}
+
+// The types of ACL set for the IP versions
+var ocACLTypes = map[string]string{
+	"4": "openconfig-acl:ACL_IPV4",
+	"6": "openconfig-acl:ACL_IPV6",
+}
+
+type ocInterface struct {
+	Name   string `json:"name"`
+	Config struct {
+		Enabled *bool `json:"enabled"`
+		MTU     *int  `json:"mtu"`
+	} `json:"config"`
+	Ethernet *struct {
+		SwitchedVLAN *struct {
+			Config ocSwitchedVLANConfig `json:"config"`
+		} `json:"openconfig-vlan:switched-vlan"`
+	} `json:"openconfig-if-ethernet:ethernet"`
+}
+
+type ocSwitchedVLANConfig struct {
+	InterfaceMode string `json:"interface-mode"`
+	AccessVLAN    *int   `json:"access-vlan,omitempty"`
+	NativeVLAN    *int   `json:"native-vlan,omitempty"`
+	// TrunkVLANs are the VLAN IDs or the ranges `<low>..<high>`
+	TrunkVLANs []json.RawMessage `json:"trunk-vlans,omitempty"`
+}
+
+type ocACLSet struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Config struct {
+		Name string `json:"name"`
+		Type string `json:"type"`
+	} `json:"config"`
+	ACLEntries struct {
+		ACLEntry []ocACLEntry `json:"acl-entry"`
+	} `json:"acl-entries"`
+}
+
+type ocACLEntry struct {
+	SequenceID int `json:"sequence-id"`
+	Config     struct {
+		SequenceID  int    `json:"sequence-id"`
+		Description string `json:"description"`
+	} `json:"config"`
+	IPv4      *ocIP        `json:"ipv4,omitempty"`
+	IPv6      *ocIP        `json:"ipv6,omitempty"`
+	Transport *ocTransport `json:"transport,omitempty"`
+	Actions   struct {
+		Config struct {
+			ForwardingAction string `json:"forwarding-action"`
+		} `json:"config"`
+	} `json:"actions"`
+}
+
+type ocTransport struct {
+	Config struct {
+		// The ports are the port numbers or the ranges `<low>..<high>`
+		SourcePort      json.RawMessage `json:"source-port,omitempty"`
+		DestinationPort json.RawMessage `json:"destination-port,omitempty"`
+	} `json:"config"`
+}
+
+type ocIP struct {
+	Config struct {
+		SourceAddress      string `json:"source-address,omitempty"`
+		DestinationAddress string `json:"destination-address,omitempty"`
+		// The protocol is an identity of openconfig-packet-match-types or the number
+		Protocol json.RawMessage `json:"protocol,omitempty"`
+	} `json:"config"`
+}
+
+type ocIngressACLSet struct {
+	SetName string `json:"set-name"`
+	Type    string `json:"type"`
+	Config  struct {
+		SetName string `json:"set-name"`
+		Type    string `json:"type"`
+	} `json:"config"`
+}
+
+// interfacePath return the path of interface in openconfig-interfaces
+func (openConfig) interfacePath(port string) (string, error) {
+	return "openconfig-interfaces:interfaces/interface=" + escape(port), nil
+}
+
+// capabilities return all of fields, they are mapped onto the models
+func (openConfig) capabilities() *v1alpha1.SwitchCapabilities {
+	return backends.AllCapabilities()
+}
+
+func ocACLSetPath(port string, ipVersion string) string {
+	return "openconfig-acl:acl/acl-sets/acl-set=" + escape(aclName(port)) + "," + escape(ocACLTypes[ipVersion])
+}
+
+func ocACLInterfacePath(port string) string {
+	return "openconfig-acl:acl/interfaces/interface=" + escape(port)
+}
+
+// edits return the edits of the switched-vlan, enabled, mtu and ingress ACL
+// sets of interface, the missing VLANs are created
+func (o openConfig) edits(port string, configuration *v1alpha1.SwitchPortConfigurationSpec) ([]edit, error) {
+	tagged, vlans, err := vlanIDs(configuration)
+	if err != nil {
+		return nil, err
+	}
+	sets, err := ocACLSets(port, configuration.ACLs)
+	if err != nil {
+		return nil, err
+	}
+	path, _ := o.interfacePath(port)
+
+	edits := []edit{}
+	if len(vlans) != 0 {
+		entries := []interface{}{}
+		for _, vid := range vlans {
+			entries = append(entries, map[string]interface{}{"vlan-id": vid, "config": map[string]interface{}{"vlan-id": vid}})
+		}
+		edits = append(edits, edit{
+			Operation: "merge",
+			Target:    "/openconfig-network-instance:network-instances/network-instance=default/vlans",
+			Value:     map[string]interface{}{"openconfig-network-instance:vlans": map[string]interface{}{"vlan": entries}},
+		})
+	}
+	// The ACL sets are created before they are bound
+	for _, version := range []string{"4", "6"} {
+		if set, ok := sets[version]; ok {
+			edits = append(edits, edit{
+				Operation: "replace",
+				Target:    "/" + ocACLSetPath(port, version),
+				Value:     map[string]interface{}{"openconfig-acl:acl-set": []ocACLSet{set}},
+			})
+		}
+	}
+
+	edits = append(edits, edit{
+		Operation: "replace",
+		Target:    "/" + path + "/config/enabled",
+		Value:     map[string]interface{}{"openconfig-interfaces:enabled": !configuration.Disable},
+	})
+	if configuration.MTU != nil {
+		edits = append(edits, edit{
+			Operation: "replace",
+			Target:    "/" + path + "/config/mtu",
+			Value:     map[string]interface{}{"openconfig-interfaces:mtu": *configuration.MTU},
+		})
+	}
+	edits = append(edits, edit{
+		Operation: "replace",
+		Target:    "/" + path + "/openconfig-if-ethernet:ethernet/openconfig-vlan:switched-vlan/config",
+		Value:     map[string]interface{}{"openconfig-vlan:config": ocSwitchedVLAN(configuration.UntaggedVLAN, tagged)},
+	})
+
+	if len(sets) != 0 {
+		ingress := []ocIngressACLSet{}
+		for _, version := range []string{"4", "6"} {
+			if set, ok := sets[version]; ok {
+				binding := ocIngressACLSet{SetName: set.Name, Type: set.Type}
+				binding.Config.SetName, binding.Config.Type = set.Name, set.Type
+				ingress = append(ingress, binding)
+			}
+		}
+		edits = append(edits, edit{
+			Operation: "merge",
+			Target:    "/" + ocACLInterfacePath(port),
+			Value: map[string]interface{}{"openconfig-acl:interface": []interface{}{
+				map[string]interface{}{"id": port, "config": map[string]interface{}{"id": port}},
+			}},
+		}, edit{
+			Operation: "replace",
+			Target:    "/" + ocACLInterfacePath(port) + "/ingress-acl-sets",
+			Value:     map[string]interface{}{"openconfig-acl:ingress-acl-sets": map[string]interface{}{"ingress-acl-set": ingress}},
+		})
+	} else {
+		edits = append(edits, edit{Operation: "remove", Target: "/" + ocACLInterfacePath(port) + "/ingress-acl-sets"})
+	}
+	// The ACL sets are removed after they are unbound
+	for _, version := range []string{"4", "6"} {
+		if _, ok := sets[version]; !ok {
+			edits = append(edits, edit{Operation: "remove", Target: "/" + ocACLSetPath(port, version)})
+		}
+	}
+	return edits, nil
+}
+
+// ocSwitchedVLAN return the config of switched-vlan, the interface is an access
+// port if only untagged VLAN is set, otherwise it's a trunk port whose native
+// VLAN is the untagged VLAN
+func ocSwitchedVLAN(untagged *int, tagged []int) ocSwitchedVLANConfig {
+	if len(tagged) == 0 && untagged != nil {
+		return ocSwitchedVLANConfig{InterfaceMode: "ACCESS", AccessVLAN: untagged}
+	}
+	config := ocSwitchedVLANConfig{InterfaceMode: "TRUNK", NativeVLAN: untagged}
+	for _, vid := range tagged {
+		config.TrunkVLANs = append(config.TrunkVLANs, json.RawMessage(strconv.Itoa(vid)))
+	}
+	return config
+}
+
+// resetEdits return the edits setting the interface to an access port of the
+// default VLAN, enabling it and removing its ACL sets
+func (o openConfig) resetEdits(port string) ([]edit, error) {
+	path, _ := o.interfacePath(port)
+	return []edit{
+		{
+			Operation: "replace",
+			Target:    "/" + path + "/config/enabled",
+			Value:     map[string]interface{}{"openconfig-interfaces:enabled": true},
+		},
+		{
+			Operation: "replace",
+			Target:    "/" + path + "/openconfig-if-ethernet:ethernet/openconfig-vlan:switched-vlan/config",
+			Value:     map[string]interface{}{"openconfig-vlan:config": ocSwitchedVLANConfig{InterfaceMode: "ACCESS"}},
+		},
+		{Operation: "remove", Target: "/" + ocACLInterfacePath(port) + "/ingress-acl-sets"},
+		{Operation: "remove", Target: "/" + ocACLSetPath(port, "4")},
+		{Operation: "remove", Target: "/" + ocACLSetPath(port, "6")},
+	}, nil
+}
+
+// read return the configuration of the interface and its ingress ACL sets
+func (o openConfig) read(ctx context.Context, get getFunc, port string) (*v1alpha1.SwitchPortConfigurationSpec, error) {
+	path, _ := o.interfacePath(port)
+	data := struct {
+		Interface []ocInterface `json:"openconfig-interfaces:interface"`
+	}{}
+	found, err := get(ctx, path, &data)
+	if err != nil {
+		return nil, err
+	}
+	if !found || len(data.Interface) == 0 {
+		return nil, backends.Errorf(backends.PortNotFound, "interface %s doesn't exist", port)
+	}
+	i := data.Interface[0]
+
+	configuration := &v1alpha1.SwitchPortConfigurationSpec{
+		Disable: i.Config.Enabled != nil && !*i.Config.Enabled,
+		MTU:     i.Config.MTU,
+	}
+	if i.Ethernet != nil && i.Ethernet.SwitchedVLAN != nil {
+		config := i.Ethernet.SwitchedVLAN.Config
+		if config.InterfaceMode == "TRUNK" {
+			configuration.UntaggedVLAN = config.NativeVLAN
+			tagged, err := ocTrunkVLANs(config.TrunkVLANs)
+			if err != nil {
+				return nil, err
+			}
+			configuration.TaggedVLANRange = ustrings.SliceToRange(tagged)
+		} else {
+			// The access VLAN is the default VLAN if it isn't set
+			vlan := 1
+			if config.AccessVLAN != nil {
+				vlan = *config.AccessVLAN
+			}
+			configuration.UntaggedVLAN = &vlan
+		}
+	}
+
+	bindings := struct {
+		IngressACLSets struct {
+			IngressACLSet []ocIngressACLSet `json:"ingress-acl-set"`
+		} `json:"openconfig-acl:ingress-acl-sets"`
+	}{}
+	_, err = get(ctx, ocACLInterfacePath(port)+"/ingress-acl-sets", &bindings)
+	if err != nil {
+		return nil, err
+	}
+	acls := map[int]v1alpha1.ACL{}
+	for _, version := range []string{"4", "6"} {
+		bound := false
+		for _, binding := range bindings.IngressACLSets.IngressACLSet {
+			bound = bound || (binding.SetName == aclName(port) && binding.Type == ocACLTypes[version])
+		}
+		if !bound {
+			continue
+		}
+		sets := struct {
+			ACLSet []ocACLSet `json:"openconfig-acl:acl-set"`
+		}{}
+		_, err = get(ctx, ocACLSetPath(port, version), &sets)
+		if err != nil {
+			return nil, err
+		}
+		for _, set := range sets.ACLSet {
+			fromOCACLEntries(set.ACLEntries.ACLEntry, acls)
+		}
+	}
+	indexes := []int{}
+	for index := range acls {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	for _, index := range indexes {
+		configuration.ACLs = append(configuration.ACLs, acls[index])
+	}
+	return configuration, nil
+}
+
+// ocTrunkVLANs return the VLANs of trunk-vlans
+func ocTrunkVLANs(trunkVLANs []json.RawMessage) ([]int, error) {
+	vlans := []int{}
+	for _, raw := range trunkVLANs {
+		var vid int
+		if json.Unmarshal(raw, &vid) == nil {
+			vlans = append(vlans, vid)
+			continue
+		}
+		var r string
+		err := json.Unmarshal(raw, &r)
+		if err != nil {
+			return nil, backends.Errorf(backends.Transient, "invalid trunk-vlans %s", raw)
+		}
+		bounds, err := ustrings.RangeToSlice(strings.Replace(r, "..", "-", 1))
+		if err != nil {
+			return nil, backends.Errorf(backends.Transient, "invalid trunk-vlans %s", raw)
+		}
+		vlans = append(vlans, bounds...)
+	}
+	return vlans, nil
+}
+
+// ocACLSets return the ACL sets of the port for the IP versions, every ACL is
+// split into the entries of every pair of source and destination port ranges
+func ocACLSets(port string, acls []v1alpha1.ACL) (map[string]ocACLSet, error) {
+	sets := map[string]ocACLSet{}
+	for i, acl := range acls {
+		set, ok := sets[acl.IPVersion]
+		if !ok {
+			set = ocACLSet{Name: aclName(port), Type: ocACLTypes[acl.IPVersion]}
+			set.Config.Name, set.Config.Type = set.Name, set.Type
+		}
+		entries, err := ocACLEntries(i, acl, (len(set.ACLEntries.ACLEntry)+1)*10)
+		if err != nil {
+			return nil, backends.Errorf(backends.InvalidConfiguration, "invalid ACL %d: %v", i, err)
+		}
+		set.ACLEntries.ACLEntry = append(set.ACLEntries.ACLEntry, entries...)
+		sets[acl.IPVersion] = set
+	}
+	return sets, nil
+}
+
+// ocACLEntries return the entries of the ACL from the sequence, the entries
+// are described by the ACL so that it can be read back
+func ocACLEntries(index int, acl v1alpha1.ACL, sequence int) ([]ocACLEntry, error) {
+	err := validateACL(acl)
+	if err != nil {
+		return nil, err
+	}
+
+	ip := &ocIP{}
+	ip.Config.SourceAddress = prefix(acl.SourceIP, acl.IPVersion)
+	ip.Config.DestinationAddress = prefix(acl.DestinationIP, acl.IPVersion)
+	switch {
+	case acl.Protocol == "ICMP" && acl.IPVersion == "6":
+		ip.Config.Protocol = json.RawMessage("58")
+	case acl.Protocol != "ALL":
+		ip.Config.Protocol = json.RawMessage(`"openconfig-packet-match-types:IP_` + acl.Protocol + `"`)
+	}
+	action := "openconfig-acl:ACCEPT"
+	if acl.Action == "deny" {
+		action = "openconfig-acl:DROP"
+	}
+
+	entries := []ocACLEntry{}
+	for _, source := range portRanges(acl.SourcePortRange) {
+		for _, destination := range portRanges(acl.DestinationPortRange) {
+			entry := ocACLEntry{SequenceID: sequence}
+			entry.Config.SequenceID = sequence
+			entry.Config.Description = description(index, acl)
+			entry.Actions.Config.ForwardingAction = action
+			// The empty container is omitted, the switch doesn't return it
+			switch {
+			case ip.Config.SourceAddress == "" && ip.Config.DestinationAddress == "" && ip.Config.Protocol == nil:
+			case acl.IPVersion == "6":
+				entry.IPv6 = ip
+			default:
+				entry.IPv4 = ip
+			}
+			if source != nil || destination != nil {
+				entry.Transport = &ocTransport{}
+				entry.Transport.Config.SourcePort = source
+				entry.Transport.Config.DestinationPort = destination
+			}
+			entries = append(entries, entry)
+			sequence += 10
+		}
+	}
+	return entries, nil
+}
+
+// prefix return the prefix of the address, the host address is a prefix of
+// the full length
+func prefix(ip string, ipVersion string) string {
+	if ip == "" || strings.Contains(ip, "/") {
+		return ip
+	}
+	if ipVersion == "6" {
+		return ip + "/128"
+	}
+	return ip + "/32"
+}
+
+// portRanges return the ports of the comma separated list validated by
+// validateACL, it's [nil] for the empty list
+func portRanges(list string) []json.RawMessage {
+	if list == "" {
+		return []json.RawMessage{nil}
+	}
+	ports := []json.RawMessage{}
+	for _, r := range strings.Split(list, ",") {
+		if strings.Contains(r, "-") {
+			ports = append(ports, json.RawMessage(`"`+strings.Replace(r, "-", "..", 1)+`"`))
+		} else {
+			ports = append(ports, json.RawMessage(r))
+		}
+	}
+	return ports
+}
+
+// fromOCACLEntries adds the ACLs of the entries created by ocACLEntries to acls
+// by their indexes, the ACL is ignored if its entries are changed
+func fromOCACLEntries(entries []ocACLEntry, acls map[int]v1alpha1.ACL) {
+	sort.Slice(entries, func(i, j int) bool { return entries[i].SequenceID < entries[j].SequenceID })
+	for i, entry := range entries {
+		if i > 0 && entries[i-1].Config.Description == entry.Config.Description {
+			continue
+		}
+		index, acl, ok := parseDescription(entry.Config.Description)
+		if !ok {
+			continue
+		}
+		expected, err := ocACLEntries(index, acl, entry.SequenceID)
+		if err != nil || len(entries) < i+len(expected) {
+			continue
+		}
+		// Compare them in JSON, the raw values are compacted by the server
+		actual, _ := json.Marshal(entries[i : i+len(expected)])
+		regenerated, _ := json.Marshal(expected)
+		if reflect.DeepEqual(actual, regenerated) {
+			acls[index] = acl
+		}
+	}
+}
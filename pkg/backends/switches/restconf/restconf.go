@@ -0,0 +1,224 @@
+// Package restconf is the switch backend which configures the ports by
+// RESTCONF (RFC 8040), the configuration of port is mapped onto the YANG data
+// of the model selected by the OS of switch.
+package restconf
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Hellcatlk/network-operator/api/v1alpha1"
+	"github.com/Hellcatlk/network-operator/pkg/backends"
+	"github.com/Hellcatlk/network-operator/pkg/provider"
+	"github.com/Hellcatlk/network-operator/pkg/utils/credentials"
+	"github.com/Hellcatlk/network-operator/pkg/utils/httpclient"
+	ustrings "github.com/Hellcatlk/network-operator/pkg/utils/strings"
+)
+
+// defaultRoot is the RESTCONF root resource
+const defaultRoot string = "/restconf"
+
+// models are the YANG models which the configuration can be mapped onto
+var models = map[string]model{
+	"openconfig":          openConfig{},
+	"cisco-ios-xe-native": iosXENative{},
+}
+
+// osModels are the models used by default for the OS
+var osModels = map[string]string{
+	"":           "openconfig",
+	"openconfig": "openconfig",
+	"eos":        "openconfig",
+	"junos":      "openconfig",
+	"nxos":       "openconfig",
+	"ios-xe":     "cisco-ios-xe-native",
+}
+
+// New return restconf backend, the host is `<host>[:<port>]` which uses HTTPS,
+// or the URL of RESTCONF root resource
+func New(ctx context.Context, config *provider.SwitchConfiguration) (backends.Switch, error) {
+	if config == nil {
+		return nil, fmt.Errorf("configure of switch is nil")
+	}
+	if config.Credentials == nil {
+		return nil, backends.Errorf(backends.InvalidConfiguration, "credentials of restconf backend are required")
+	}
+
+	name := osModels[config.OS]
+	var caBundle string
+	var insecureSkipVerify bool
+	for key, value := range config.Options {
+		s, ok := value.(string)
+		if !ok {
+			return nil, backends.Errorf(backends.InvalidConfiguration, "option %q of restconf backend isn't a string", key)
+		}
+		switch key {
+		case "model":
+			name = s
+		case "caBundle":
+			caBundle = s
+		case "insecureSkipVerify":
+			insecure, err := strconv.ParseBool(s)
+			if err != nil {
+				return nil, backends.Errorf(backends.InvalidConfiguration, "invalid insecureSkipVerify %q of restconf backend", s)
+			}
+			insecureSkipVerify = insecure
+		default:
+			return nil, backends.Errorf(backends.InvalidConfiguration, "unknown option %q of restconf backend", key)
+		}
+	}
+	if name == "" {
+		return nil, backends.Errorf(backends.InvalidConfiguration, "no model of os %q, set the model option of restconf backend", config.OS)
+	}
+	m := models[name]
+	if m == nil {
+		return nil, backends.Errorf(backends.InvalidConfiguration, "unknown model %q of restconf backend", name)
+	}
+
+	root, err := parseHost(config.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := httpclient.Get(root, caBundle, insecureSkipVerify)
+	if err != nil {
+		return nil, backends.Errorf(backends.InvalidConfiguration, "caBundle of restconf backend has no PEM certificate")
+	}
+
+	return &restconf{
+		root:        root,
+		credentials: config.Credentials,
+		model:       m,
+		client:      client,
+	}, nil
+}
+
+// parseHost return the URL of RESTCONF root resource
+func parseHost(host string) (string, error) {
+	if host == "" {
+		return "", backends.Errorf(backends.InvalidConfiguration, "host of restconf backend is empty")
+	}
+	if !strings.Contains(host, "://") {
+		return "https://" + host + defaultRoot, nil
+	}
+
+	u, err := url.Parse(host)
+	if err != nil {
+		return "", backends.NewError(backends.InvalidConfiguration, err)
+	}
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return "", backends.Errorf(backends.InvalidConfiguration, "scheme %q of restconf backend isn't supported", u.Scheme)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = defaultRoot
+	}
+	return strings.TrimSuffix(u.String(), "/"), nil
+}
+
+// restconf backend
+type restconf struct {
+	root        string
+	credentials *credentials.Credentials
+	client      *http.Client
+	model       model
+}
+
+// IsAvailable check the RESTCONF root resource can be got
+func (r *restconf) IsAvailable(ctx context.Context) error {
+	_, err := r.get(ctx, "", nil)
+	return err
+}
+
+// GetPortAttr return the configuration of port read from the model
+func (r *restconf) GetPortAttr(ctx context.Context, port string) (*v1alpha1.SwitchPortConfigurationSpec, error) {
+	err := r.checkPort(ctx, port)
+	if err != nil {
+		return nil, err
+	}
+	return r.model.read(ctx, r.get, port)
+}
+
+// SetPortAttr set the configuration of port by a YANG Patch, the edits are
+// applied together or not at all
+func (r *restconf) SetPortAttr(ctx context.Context, port string, configuration *v1alpha1.SwitchPortConfigurationSpec) error {
+	edits, err := r.model.edits(port, configuration)
+	if err != nil {
+		return err
+	}
+	err = r.checkPort(ctx, port)
+	if err != nil {
+		return err
+	}
+	return r.patch(ctx, edits)
+}
+
+// ResetPort reset the port to the default configuration of model
+func (r *restconf) ResetPort(ctx context.Context, port string, configuration *v1alpha1.SwitchPortConfigurationSpec) error {
+	edits, err := r.model.resetEdits(port)
+	if err != nil {
+		return err
+	}
+	err = r.checkPort(ctx, port)
+	if err != nil {
+		return err
+	}
+	return r.patch(ctx, edits)
+}
+
+// Capabilities return the fields which can be mapped onto the model
+func (r *restconf) Capabilities(ctx context.Context) (*v1alpha1.SwitchCapabilities, error) {
+	return r.model.capabilities(), nil
+}
+
+// checkPort check the interface of port exists, the edits may create it
+// otherwise
+func (r *restconf) checkPort(ctx context.Context, port string) error {
+	path, err := r.model.interfacePath(port)
+	if err != nil {
+		return err
+	}
+	found, err := r.get(ctx, path, nil)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return backends.Errorf(backends.PortNotFound, "interface %s doesn't exist", port)
+	}
+	return nil
+}
+
+// model maps the configuration of port onto the YANG data of a model
+type model interface {
+	// interfacePath return the path of the interface of port
+	interfacePath(port string) (string, error)
+	// edits return the edits configuring the port
+	edits(port string, configuration *v1alpha1.SwitchPortConfigurationSpec) ([]edit, error)
+	// resetEdits return the edits resetting the port
+	resetEdits(port string) ([]edit, error)
+	// read return the configuration of port
+	read(ctx context.Context, get getFunc, port string) (*v1alpha1.SwitchPortConfigurationSpec, error)
+	// capabilities return the fields which can be mapped onto the model
+	capabilities() *v1alpha1.SwitchCapabilities
+}
+
+// vlanIDs return the tagged VLANs and all the VLANs of the configuration
+func vlanIDs(configuration *v1alpha1.SwitchPortConfigurationSpec) ([]int, []int, error) {
+	tagged, err := ustrings.RangeToSlice(configuration.TaggedVLANRange)
+	if err != nil {
+		return nil, nil, backends.NewError(backends.InvalidConfiguration, err)
+	}
+	vlans := append([]int{}, tagged...)
+	if configuration.UntaggedVLAN != nil {
+		vlans = append(vlans, *configuration.UntaggedVLAN)
+	}
+	for _, vid := range vlans {
+		if vid < 1 || vid > 4094 {
+			return nil, nil, backends.Errorf(backends.InvalidConfiguration, "VLAN %d is out of range 1-4094", vid)
+		}
+	}
+	return tagged, vlans, nil
+}
@@ -0,0 +1,533 @@
+package restconf
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/Hellcatlk/network-operator/api/v1alpha1"
+	"github.com/Hellcatlk/network-operator/pkg/backends"
+	"github.com/Hellcatlk/network-operator/pkg/backends/switches/restconf/restconftest"
+	"github.com/Hellcatlk/network-operator/pkg/provider"
+	"github.com/Hellcatlk/network-operator/pkg/utils/credentials"
+)
+
+const (
+	ocInterface1    string = "openconfig-interfaces:interfaces/interface=Ethernet1%2F1"
+	ocSwitchedVLAN1 string = ocInterface1 + "/openconfig-if-ethernet:ethernet/openconfig-vlan:switched-vlan/config"
+	ocACLSet1IPv4   string = "openconfig-acl:acl/acl-sets/acl-set=NETWORK-OPERATOR-Ethernet1%2F1,openconfig-acl:ACL_IPV4"
+	iosXEInterface1 string = "Cisco-IOS-XE-native:native/interface/GigabitEthernet=1%2F0%2F1"
+	iosXEInterface2 string = "Cisco-IOS-XE-native:native/interface/GigabitEthernet=1%2F0%2F2"
+)
+
+// serve serves the stand-in by HTTPS and return the backend trusting its
+// certificate
+func serve(t *testing.T, server *restconftest.Server, os string, cert *credentials.Credentials) backends.Switch {
+	ts := httptest.NewTLSServer(server)
+	t.Cleanup(ts.Close)
+
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+	backend, err := New(context.Background(), &provider.SwitchConfiguration{
+		OS:          os,
+		Host:        ts.URL,
+		Backend:     "restconf",
+		Credentials: cert,
+		Options:     map[string]interface{}{"caBundle": string(caBundle)},
+	})
+	if err != nil {
+		t.Fatalf("new backend failed: %v", err)
+	}
+	return backend
+}
+
+// newServer return the stand-in of the model with two interfaces
+func newServer(t *testing.T, model string) (*restconftest.Server, string) {
+	interfaces := map[string][]string{
+		"openconfig":          {"Ethernet1/1", "Ethernet2/1"},
+		"cisco-ios-xe-native": {"GigabitEthernet1/0/1", "GigabitEthernet1/0/2"},
+	}[model]
+	server, err := restconftest.NewServer("admin", "password", model, interfaces...)
+	if err != nil {
+		t.Fatalf("new server failed: %v", err)
+	}
+	return server, interfaces[0]
+}
+
+func TestRESTCONF(t *testing.T) {
+	vlan := 10
+	mtu := 9000
+	cases := []struct {
+		name          string
+		os            string
+		configuration *v1alpha1.SwitchPortConfigurationSpec
+		// expectedData are the data in JSON by their paths, the empty one
+		// means the data doesn't exist
+		expectedData map[string]string
+	}{
+		{
+			name:          "openconfig access port",
+			os:            "eos",
+			configuration: &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan},
+			expectedData: map[string]string{
+				ocSwitchedVLAN1:                  `{"access-vlan":10,"interface-mode":"ACCESS"}`,
+				ocInterface1 + "/config/enabled": `true`,
+				ocACLSet1IPv4:                    "",
+				"openconfig-network-instance:network-instances/network-instance=default/vlans/vlan=10/config": `{"vlan-id":10}`,
+			},
+		},
+		{
+			name:          "openconfig disabled trunk port",
+			os:            "junos",
+			configuration: &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan, TaggedVLANRange: "11-12", Disable: true, MTU: &mtu},
+			expectedData: map[string]string{
+				ocSwitchedVLAN1:          `{"interface-mode":"TRUNK","native-vlan":10,"trunk-vlans":[11,12]}`,
+				ocInterface1 + "/config": `{"enabled":false,"mtu":9000,"name":"Ethernet1/1","type":"iana-if-type:ethernetCsmacd"}`,
+			},
+		},
+		{
+			name:          "openconfig trunk port without untagged VLAN",
+			os:            "nxos",
+			configuration: &v1alpha1.SwitchPortConfigurationSpec{TaggedVLANRange: "11,13"},
+			expectedData: map[string]string{
+				ocSwitchedVLAN1: `{"interface-mode":"TRUNK","trunk-vlans":[11,13]}`,
+			},
+		},
+		{
+			name:          "openconfig port without VLAN",
+			configuration: &v1alpha1.SwitchPortConfigurationSpec{},
+			expectedData: map[string]string{
+				ocSwitchedVLAN1: `{"interface-mode":"TRUNK"}`,
+				"openconfig-acl:acl/interfaces/interface=Ethernet1%2F1/ingress-acl-sets": "",
+			},
+		},
+		{
+			name: "openconfig ACLs",
+			configuration: &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan, ACLs: []v1alpha1.ACL{
+				{IPVersion: "6", Action: "deny", Protocol: "ALL", DestinationIP: "fd00::/8"},
+				{IPVersion: "4", Action: "allow", Protocol: "TCP", SourceIP: "10.0.0.1", DestinationPortRange: "22,8000-8080"},
+			}},
+			expectedData: map[string]string{
+				ocACLSet1IPv4 + "/acl-entries/acl-entry=10/ipv4/config":                                                                              `{"protocol":"openconfig-packet-match-types:IP_TCP","source-address":"10.0.0.1/32"}`,
+				ocACLSet1IPv4 + "/acl-entries/acl-entry=10/transport/config":                                                                         `{"destination-port":22}`,
+				ocACLSet1IPv4 + "/acl-entries/acl-entry=20/transport/config":                                                                         `{"destination-port":"8000..8080"}`,
+				ocACLSet1IPv4 + "/acl-entries/acl-entry=30":                                                                                          "",
+				"openconfig-acl:acl/acl-sets/acl-set=NETWORK-OPERATOR-Ethernet1%2F1,openconfig-acl:ACL_IPV6/acl-entries/acl-entry=10/actions/config": `{"forwarding-action":"openconfig-acl:DROP"}`,
+			},
+		},
+		{
+			name:          "IOS XE access port",
+			os:            "ios-xe",
+			configuration: &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan},
+			expectedData: map[string]string{
+				iosXEInterface1 + "/switchport":                                  `{"Cisco-IOS-XE-switch:access":{"vlan":{"vlan":10}},"Cisco-IOS-XE-switch:mode":{"access":{}}}`,
+				iosXEInterface1 + "/shutdown":                                    "",
+				"Cisco-IOS-XE-native:native/vlan/Cisco-IOS-XE-vlan:vlan-list=10": `{"id":10}`,
+			},
+		},
+		{
+			name:          "IOS XE disabled trunk port",
+			os:            "ios-xe",
+			configuration: &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan, TaggedVLANRange: "11-12", Disable: true, MTU: &mtu},
+			expectedData: map[string]string{
+				iosXEInterface1 + "/switchport": `{"Cisco-IOS-XE-switch:mode":{"trunk":{}},"Cisco-IOS-XE-switch:trunk":{"allowed":{"vlan":{"vlans":"10-12"}},"native":{"vlan-id":10}}}`,
+				iosXEInterface1 + "/shutdown":   `[null]`,
+				iosXEInterface1 + "/mtu":        `9000`,
+			},
+		},
+		{
+			name:          "IOS XE trunk port without untagged VLAN",
+			os:            "ios-xe",
+			configuration: &v1alpha1.SwitchPortConfigurationSpec{TaggedVLANRange: "11,13"},
+			expectedData: map[string]string{
+				iosXEInterface1 + "/switchport": `{"Cisco-IOS-XE-switch:mode":{"trunk":{}},"Cisco-IOS-XE-switch:trunk":{"allowed":{"vlan":{"vlans":"11,13"}}}}`,
+			},
+		},
+		{
+			name:          "IOS XE port without VLAN",
+			os:            "ios-xe",
+			configuration: &v1alpha1.SwitchPortConfigurationSpec{},
+			expectedData: map[string]string{
+				iosXEInterface1 + "/switchport": `{"Cisco-IOS-XE-switch:mode":{"trunk":{}},"Cisco-IOS-XE-switch:trunk":{"allowed":{"vlan":{"none":[null]}}}}`,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			model := osModels[c.os]
+			server, port := newServer(t, model)
+			backend := serve(t, server, c.os, &credentials.Credentials{Username: "admin", Password: "password"})
+
+			err := backend.IsAvailable(context.Background())
+			if err != nil {
+				t.Fatalf("IsAvailable failed: %v", err)
+			}
+
+			// Configure twice, the second one replaces the first one
+			first := &v1alpha1.SwitchPortConfigurationSpec{TaggedVLANRange: "20"}
+			if model == "openconfig" {
+				first.ACLs = []v1alpha1.ACL{{IPVersion: "4", Action: "deny", Protocol: "UDP", SourcePortRange: "53"}}
+			}
+			err = backend.SetPortAttr(context.Background(), port, first)
+			if err != nil {
+				t.Fatalf("SetPortAttr failed: %v", err)
+			}
+			err = backend.SetPortAttr(context.Background(), port, c.configuration)
+			if err != nil {
+				t.Fatalf("SetPortAttr failed: %v", err)
+			}
+			for path, expected := range c.expectedData {
+				if data, _ := server.Get(path); data != expected {
+					t.Errorf("expected %s: %s, got: %s", path, expected, data)
+				}
+			}
+
+			configuration, err := backend.GetPortAttr(context.Background(), port)
+			if err != nil {
+				t.Fatalf("GetPortAttr failed: %v", err)
+			}
+			if !c.configuration.IsEqual(configuration) {
+				t.Errorf("expected configuration: %+v, got: %+v", c.configuration, configuration)
+			}
+
+			err = backend.ResetPort(context.Background(), port, c.configuration)
+			if err != nil {
+				t.Fatalf("ResetPort failed: %v", err)
+			}
+			vlan1 := 1
+			configuration, err = backend.GetPortAttr(context.Background(), port)
+			if err != nil {
+				t.Fatalf("GetPortAttr failed: %v", err)
+			}
+			expected := &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan1, MTU: c.configuration.MTU}
+			if !reflect.DeepEqual(configuration, expected) {
+				t.Errorf("expected reset configuration: %+v, got: %+v", expected, configuration)
+			}
+			if model == "cisco-ios-xe-native" {
+				data1, _ := server.Get(iosXEInterface1 + "/switchport")
+				data2, _ := server.Get(iosXEInterface2 + "/switchport")
+				if data1 != data2 {
+					t.Errorf("expected switchport: %s, got: %s", data2, data1)
+				}
+			}
+			if data, ok := server.Get(ocACLSet1IPv4); ok {
+				t.Errorf("expected ACL set is removed, got: %s", data)
+			}
+		})
+	}
+}
+
+func TestGetPortAttr(t *testing.T) {
+	vlan1 := 1
+	vlan10 := 10
+	cases := []struct {
+		name                  string
+		model                 string
+		data                  map[string]string
+		expectedConfiguration *v1alpha1.SwitchPortConfigurationSpec
+	}{
+		{
+			name:                  "openconfig default port",
+			model:                 "openconfig",
+			expectedConfiguration: &v1alpha1.SwitchPortConfigurationSpec{},
+		},
+		{
+			name:  "openconfig access port of default VLAN",
+			model: "openconfig",
+			data: map[string]string{
+				ocSwitchedVLAN1: `{"interface-mode":"ACCESS"}`,
+			},
+			expectedConfiguration: &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan1},
+		},
+		{
+			name:  "openconfig trunk VLAN ranges",
+			model: "openconfig",
+			data: map[string]string{
+				"openconfig-network-instance:network-instances/network-instance=default/vlans": `{"vlan":[{"vlan-id":1},{"vlan-id":5},{"vlan-id":10},{"vlan-id":11},{"vlan-id":12}]}`,
+				ocSwitchedVLAN1: `{"interface-mode":"TRUNK","native-vlan":1,"trunk-vlans":[5,"10..12"]}`,
+			},
+			expectedConfiguration: &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan1, TaggedVLANRange: "5,10-12"},
+		},
+		{
+			name:  "openconfig changed ACL entry",
+			model: "openconfig",
+			data: map[string]string{
+				ocACLSet1IPv4: `[{"name":"NETWORK-OPERATOR-Ethernet1/1","type":"openconfig-acl:ACL_IPV4",` +
+					`"config":{"name":"NETWORK-OPERATOR-Ethernet1/1","type":"openconfig-acl:ACL_IPV4"},"acl-entries":{"acl-entry":[` +
+					`{"sequence-id":10,"config":{"sequence-id":10,"description":"network-operator index=0 ipVersion=4 action=deny protocol=ALL"},` +
+					`"actions":{"config":{"forwarding-action":"openconfig-acl:DROP"}}},` +
+					`{"sequence-id":20,"config":{"sequence-id":20,"description":"network-operator index=1 ipVersion=4 action=allow protocol=UDP"},` +
+					`"ipv4":{"config":{"protocol":"openconfig-packet-match-types:IP_TCP"}},"actions":{"config":{"forwarding-action":"openconfig-acl:ACCEPT"}}}]}}]`,
+				"openconfig-acl:acl/interfaces/interface=Ethernet1%2F1": `[{"id":"Ethernet1/1","config":{"id":"Ethernet1/1"},` +
+					`"ingress-acl-sets":{"ingress-acl-set":[{"set-name":"NETWORK-OPERATOR-Ethernet1/1","type":"openconfig-acl:ACL_IPV4"}]}}]`,
+			},
+			expectedConfiguration: &v1alpha1.SwitchPortConfigurationSpec{ACLs: []v1alpha1.ACL{
+				{IPVersion: "4", Action: "deny", Protocol: "ALL"},
+			}},
+		},
+		{
+			name:                  "IOS XE default port",
+			model:                 "cisco-ios-xe-native",
+			expectedConfiguration: &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan1},
+		},
+		{
+			name:  "IOS XE trunk port allowing all VLANs",
+			model: "cisco-ios-xe-native",
+			data: map[string]string{
+				iosXEInterface1 + "/switchport/Cisco-IOS-XE-switch:mode": `{"trunk":{}}`,
+				iosXEInterface1 + "/shutdown":                            `[null]`,
+			},
+			expectedConfiguration: &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan1, TaggedVLANRange: "2-4094", Disable: true},
+		},
+		{
+			name:  "IOS XE native VLAN isn't allowed",
+			model: "cisco-ios-xe-native",
+			data: map[string]string{
+				iosXEInterface1 + "/switchport/Cisco-IOS-XE-switch:mode":  `{"trunk":{}}`,
+				iosXEInterface1 + "/switchport/Cisco-IOS-XE-switch:trunk": `{"allowed":{"vlan":{"vlans":"20-21"}},"native":{"vlan-id":10}}`,
+			},
+			expectedConfiguration: &v1alpha1.SwitchPortConfigurationSpec{TaggedVLANRange: "20-21"},
+		},
+		{
+			name:  "IOS XE access port",
+			model: "cisco-ios-xe-native",
+			data: map[string]string{
+				iosXEInterface1 + "/switchport/Cisco-IOS-XE-switch:access": `{"vlan":{"vlan":10}}`,
+			},
+			expectedConfiguration: &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan10},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server, port := newServer(t, c.model)
+			// The VLANs are replaced before the interfaces using them
+			for _, path := range []string{
+				"openconfig-network-instance:network-instances/network-instance=default/vlans",
+			} {
+				if value, ok := c.data[path]; ok {
+					if err := server.Replace(path, value); err != nil {
+						t.Fatalf("replace %s failed: %v", path, err)
+					}
+				}
+			}
+			for path, value := range c.data {
+				if err := server.Replace(path, value); err != nil {
+					t.Fatalf("replace %s failed: %v", path, err)
+				}
+			}
+
+			backend := serve(t, server, "", &credentials.Credentials{Username: "admin", Password: "password"})
+			if c.model != "openconfig" {
+				backend.(*restconf).model = models[c.model]
+			}
+			configuration, err := backend.GetPortAttr(context.Background(), port)
+			if err != nil {
+				t.Fatalf("GetPortAttr failed: %v", err)
+			}
+			if !reflect.DeepEqual(configuration, c.expectedConfiguration) {
+				t.Errorf("expected configuration: %+v, got: %+v", c.expectedConfiguration, configuration)
+			}
+		})
+	}
+}
+
+func TestRESTCONFError(t *testing.T) {
+	vlan := 4095
+	mtu := 1000
+	cases := []struct {
+		name              string
+		os                string
+		port              string
+		configuration     *v1alpha1.SwitchPortConfigurationSpec
+		credentials       *credentials.Credentials
+		locked            bool
+		expectedErrorType backends.ErrorType
+	}{
+		{
+			name:              "openconfig port not found",
+			port:              "Ethernet9",
+			configuration:     &v1alpha1.SwitchPortConfigurationSpec{},
+			expectedErrorType: backends.PortNotFound,
+		},
+		{
+			name:              "IOS XE port not found",
+			os:                "ios-xe",
+			port:              "GigabitEthernet1/0/9",
+			configuration:     &v1alpha1.SwitchPortConfigurationSpec{},
+			expectedErrorType: backends.PortNotFound,
+		},
+		{
+			name:              "invalid IOS XE interface name",
+			os:                "ios-xe",
+			port:              "1/0/1",
+			configuration:     &v1alpha1.SwitchPortConfigurationSpec{},
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+		{
+			name:              "vlan out of range",
+			port:              "Ethernet1/1",
+			configuration:     &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan},
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+		{
+			name:              "ACL without ipVersion",
+			port:              "Ethernet1/1",
+			configuration:     &v1alpha1.SwitchPortConfigurationSpec{ACLs: []v1alpha1.ACL{{Action: "deny", Protocol: "ALL"}}},
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+		{
+			name:              "IOS XE ACLs",
+			os:                "ios-xe",
+			port:              "GigabitEthernet1/0/1",
+			configuration:     &v1alpha1.SwitchPortConfigurationSpec{ACLs: []v1alpha1.ACL{{IPVersion: "4", Action: "deny", Protocol: "ALL"}}},
+			expectedErrorType: backends.Unsupported,
+		},
+		{
+			name:              "MTU rejected by switch",
+			os:                "ios-xe",
+			port:              "GigabitEthernet1/0/1",
+			configuration:     &v1alpha1.SwitchPortConfigurationSpec{MTU: &mtu},
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+		{
+			name:              "locked datastore",
+			port:              "Ethernet1/1",
+			configuration:     &v1alpha1.SwitchPortConfigurationSpec{},
+			locked:            true,
+			expectedErrorType: backends.Transient,
+		},
+		{
+			name:              "wrong password",
+			port:              "Ethernet1/1",
+			configuration:     &v1alpha1.SwitchPortConfigurationSpec{},
+			credentials:       &credentials.Credentials{Username: "admin", Password: "wrong"},
+			expectedErrorType: backends.Authentication,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server, _ := newServer(t, osModels[c.os])
+			server.SetLocked(c.locked)
+			cert := c.credentials
+			if cert == nil {
+				cert = &credentials.Credentials{Username: "admin", Password: "password"}
+			}
+			backend := serve(t, server, c.os, cert)
+			err := backend.SetPortAttr(context.Background(), c.port, c.configuration)
+			if errorType := backends.TypeOf(err); errorType != c.expectedErrorType {
+				t.Errorf("expected error type: %q, got: %v", c.expectedErrorType, err)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	cases := []struct {
+		name               string
+		os                 string
+		host               string
+		options            map[string]interface{}
+		withoutCredentials bool
+		expectedRoot       string
+		expectedModel      string
+		expectedErrorType  backends.ErrorType
+	}{
+		{
+			name:          "host",
+			host:          "192.168.0.1",
+			expectedRoot:  "https://192.168.0.1/restconf",
+			expectedModel: "openconfig",
+		},
+		{
+			name:          "URL",
+			host:          "http://192.168.0.1:8080",
+			expectedRoot:  "http://192.168.0.1:8080/restconf",
+			expectedModel: "openconfig",
+		},
+		{
+			name:          "URL with root",
+			host:          "https://switch.example.com/api/restconf/",
+			expectedRoot:  "https://switch.example.com/api/restconf",
+			expectedModel: "openconfig",
+		},
+		{
+			name:          "model of os",
+			os:            "ios-xe",
+			host:          "192.168.0.1",
+			expectedRoot:  "https://192.168.0.1/restconf",
+			expectedModel: "cisco-ios-xe-native",
+		},
+		{
+			name:          "model option",
+			os:            "ios-xe",
+			host:          "192.168.0.1",
+			options:       map[string]interface{}{"model": "openconfig"},
+			expectedRoot:  "https://192.168.0.1/restconf",
+			expectedModel: "openconfig",
+		},
+		{
+			name:              "unknown os",
+			os:                "vyos",
+			host:              "192.168.0.1",
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+		{
+			name:              "unknown model",
+			host:              "192.168.0.1",
+			options:           map[string]interface{}{"model": "ietf-interfaces"},
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+		{
+			name:              "unsupported scheme",
+			host:              "ssh://192.168.0.1",
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+		{
+			name:              "empty host",
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+		{
+			name:               "without credentials",
+			host:               "192.168.0.1",
+			withoutCredentials: true,
+			expectedErrorType:  backends.InvalidConfiguration,
+		},
+		{
+			name:              "invalid caBundle",
+			host:              "192.168.0.1",
+			options:           map[string]interface{}{"caBundle": "certificate"},
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+		{
+			name:              "unknown option",
+			host:              "192.168.0.1",
+			options:           map[string]interface{}{"database": "4"},
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cert := &credentials.Credentials{Username: "admin", Password: "password"}
+			if c.withoutCredentials {
+				cert = nil
+			}
+			backend, err := New(context.Background(), &provider.SwitchConfiguration{
+				OS: c.os, Host: c.host, Backend: "restconf", Credentials: cert, Options: c.options,
+			})
+			if errorType := backends.TypeOf(err); errorType != c.expectedErrorType {
+				t.Errorf("expected error type: %q, got: %v", c.expectedErrorType, err)
+			}
+			if err != nil {
+				return
+			}
+			if r := backend.(*restconf); r.root != c.expectedRoot || r.model != models[c.expectedModel] {
+				t.Errorf("expected root %s and model %s, got: %s and %T", c.expectedRoot, c.expectedModel, r.root, r.model)
+			}
+		})
+	}
+}
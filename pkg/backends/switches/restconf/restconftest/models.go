@@ -0,0 +1,356 @@
+package restconftest
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	ustrings "github.com/Hellcatlk/network-operator/pkg/utils/strings"
+)
+
+// model is the schema of the datastore which the server knows
+type model struct {
+	// lists are the keys of the lists by their schema paths, `*` matches any
+	// node name
+	lists map[string][]string
+	// fixedLists are the lists whose entries can't be created, they are the
+	// physical interfaces
+	fixedLists map[string]bool
+	seed       func(interfaces []string) (map[string]interface{}, error)
+	validate   func(data map[string]interface{}) *restconfError
+}
+
+var models = map[string]*model{
+	"openconfig": {
+		lists: map[string][]string{
+			"openconfig-interfaces:interfaces/interface":                                {"name"},
+			"openconfig-network-instance:network-instances/network-instance":            {"name"},
+			"openconfig-network-instance:network-instances/network-instance/vlans/vlan": {"vlan-id"},
+			"openconfig-acl:acl/acl-sets/acl-set":                                       {"name", "type"},
+			"openconfig-acl:acl/acl-sets/acl-set/acl-entries/acl-entry":                 {"sequence-id"},
+			"openconfig-acl:acl/interfaces/interface":                                   {"id"},
+			"openconfig-acl:acl/interfaces/interface/ingress-acl-sets/ingress-acl-set":  {"set-name", "type"},
+		},
+		fixedLists: map[string]bool{"openconfig-interfaces:interfaces/interface": true},
+		seed:       ocSeed,
+		validate:   ocValidate,
+	},
+	"cisco-ios-xe-native": {
+		lists: map[string][]string{
+			"Cisco-IOS-XE-native:native/interface/*":                      {"name"},
+			"Cisco-IOS-XE-native:native/vlan/Cisco-IOS-XE-vlan:vlan-list": {"id"},
+		},
+		fixedLists: map[string]bool{"Cisco-IOS-XE-native:native/interface/*": true},
+		seed:       iosXESeed,
+		validate:   iosXEValidate,
+	},
+}
+
+// keys return the keys of the list, the last node name of schema matches `*`
+func (m *model) keys(schema string) []string {
+	if keys, ok := m.lists[schema]; ok {
+		return keys
+	}
+	return m.lists[wildcard(schema)]
+}
+
+// fixed check the entries of the list can't be created
+func (m *model) fixed(schema string) bool {
+	return m.fixedLists[schema] || m.fixedLists[wildcard(schema)]
+}
+
+func wildcard(schema string) string {
+	if i := strings.LastIndex(schema, "/"); i >= 0 {
+		return schema[:i+1] + "*"
+	}
+	return schema
+}
+
+func ocSeed(interfaces []string) (map[string]interface{}, error) {
+	entries := []interface{}{}
+	for _, name := range interfaces {
+		entries = append(entries, map[string]interface{}{
+			"name":   name,
+			"config": map[string]interface{}{"name": name, "type": "iana-if-type:ethernetCsmacd", "enabled": true},
+		})
+	}
+	return map[string]interface{}{
+		"openconfig-interfaces:interfaces": map[string]interface{}{"interface": entries},
+		"openconfig-network-instance:network-instances": map[string]interface{}{
+			"network-instance": []interface{}{map[string]interface{}{
+				"name":   "default",
+				"config": map[string]interface{}{"name": "default"},
+				"vlans": map[string]interface{}{"vlan": []interface{}{
+					map[string]interface{}{"vlan-id": 1.0, "config": map[string]interface{}{"vlan-id": 1.0}},
+				}},
+			}},
+		},
+	}, nil
+}
+
+// ocValidate validates the interfaces, VLANs and ACLs of OpenConfig
+func ocValidate(data map[string]interface{}) *restconfError {
+	vlans := map[int]bool{}
+	for _, instance := range list(data, "openconfig-network-instance:network-instances", "network-instance") {
+		if container(instance)["name"] != "default" {
+			continue
+		}
+		for _, vlan := range list(container(instance), "vlans", "vlan") {
+			vid, ok := integer(container(vlan)["vlan-id"])
+			if !ok || vid < 1 || vid > 4094 {
+				return newError("invalid-value", "/openconfig-network-instance:network-instances", "invalid vlan-id %v", container(vlan)["vlan-id"])
+			}
+			vlans[vid] = true
+		}
+	}
+
+	interfaces := map[string]bool{}
+	for _, i := range list(data, "openconfig-interfaces:interfaces", "interface") {
+		name := fmt.Sprint(container(i)["name"])
+		interfaces[name] = true
+		path := "/openconfig-interfaces:interfaces/interface=" + name
+		config := container(container(i)["config"])
+		if enabled, ok := config["enabled"]; ok {
+			if _, ok := enabled.(bool); !ok {
+				return newError("invalid-value", path+"/config/enabled", "enabled isn't a boolean")
+			}
+		}
+		if mtu, ok := config["mtu"]; ok {
+			if m, ok := integer(mtu); !ok || m < 64 || m > 9216 {
+				return newError("invalid-value", path+"/config/mtu", "mtu %v is out of range 64-9216", mtu)
+			}
+		}
+
+		ethernet := container(container(i)["openconfig-if-ethernet:ethernet"])
+		switched := container(container(ethernet["openconfig-vlan:switched-vlan"])["config"])
+		if len(switched) == 0 {
+			continue
+		}
+		path += "/openconfig-if-ethernet:ethernet/openconfig-vlan:switched-vlan/config"
+		ids := []interface{}{}
+		switch switched["interface-mode"] {
+		case "ACCESS":
+			if _, ok := switched["trunk-vlans"]; ok {
+				return newError("invalid-value", path, "trunk-vlans is set on the access interface")
+			}
+			ids = append(ids, switched["access-vlan"])
+		case "TRUNK":
+			if _, ok := switched["access-vlan"]; ok {
+				return newError("invalid-value", path, "access-vlan is set on the trunk interface")
+			}
+			ids = append(ids, switched["native-vlan"])
+			trunk, _ := switched["trunk-vlans"].([]interface{})
+			for _, vlan := range trunk {
+				if r, ok := vlan.(string); ok {
+					bounds, err := ustrings.RangeToSlice(strings.Replace(r, "..", "-", 1))
+					if err != nil || len(bounds) == 0 {
+						return newError("invalid-value", path, "invalid trunk-vlans %q", r)
+					}
+					for _, vid := range bounds {
+						ids = append(ids, float64(vid))
+					}
+					continue
+				}
+				ids = append(ids, vlan)
+			}
+		default:
+			return newError("invalid-value", path, "invalid interface-mode %v", switched["interface-mode"])
+		}
+		for _, id := range ids {
+			if id == nil {
+				continue
+			}
+			vid, ok := integer(id)
+			if !ok || !vlans[vid] {
+				return newError("invalid-value", path, "VLAN %v doesn't exist", id)
+			}
+		}
+	}
+
+	sets := map[string]bool{}
+	for _, set := range list(data, "openconfig-acl:acl", "acl-sets", "acl-set") {
+		name := fmt.Sprint(container(set)["name"])
+		setType := container(set)["type"]
+		path := fmt.Sprintf("/openconfig-acl:acl/acl-sets/acl-set=%s,%v", name, setType)
+		family := map[interface{}]string{"openconfig-acl:ACL_IPV4": "ipv4", "openconfig-acl:ACL_IPV6": "ipv6"}[setType]
+		if family == "" {
+			return newError("invalid-value", path, "invalid type %v", setType)
+		}
+		config := container(container(set)["config"])
+		if config["name"] != container(set)["name"] || config["type"] != setType {
+			return newError("invalid-value", path+"/config", "config doesn't match the keys")
+		}
+		for _, entry := range list(container(set), "acl-entries", "acl-entry") {
+			e := container(entry)
+			if err := ocValidateEntry(path, family, e); err != nil {
+				return err
+			}
+		}
+		sets[name+","+fmt.Sprint(setType)] = true
+	}
+
+	for _, binding := range list(data, "openconfig-acl:acl", "interfaces", "interface") {
+		id := fmt.Sprint(container(binding)["id"])
+		path := "/openconfig-acl:acl/interfaces/interface=" + id
+		if !interfaces[id] {
+			return newError("invalid-value", path, "interface %s doesn't exist", id)
+		}
+		for _, set := range list(container(binding), "ingress-acl-sets", "ingress-acl-set") {
+			key := fmt.Sprint(container(set)["set-name"]) + "," + fmt.Sprint(container(set)["type"])
+			if !sets[key] {
+				return newError("invalid-value", path, "ACL set %s doesn't exist", key)
+			}
+		}
+	}
+	return nil
+}
+
+// ocPortPattern is the port of openconfig-packet-match-types
+var ocPortPattern = regexp.MustCompile(`^(ANY|[0-9]+|[0-9]+\.\.[0-9]+)$`)
+
+func ocValidateEntry(set string, family string, entry map[string]interface{}) *restconfError {
+	path := fmt.Sprintf("%s/acl-entries/acl-entry=%v", set, entry["sequence-id"])
+	if _, ok := integer(entry["sequence-id"]); !ok {
+		return newError("invalid-value", path, "invalid sequence-id")
+	}
+	switch container(container(entry["actions"])["config"])["forwarding-action"] {
+	case "openconfig-acl:ACCEPT", "openconfig-acl:DROP", "openconfig-acl:REJECT":
+	default:
+		return newError("invalid-value", path+"/actions", "invalid forwarding-action")
+	}
+	for _, f := range []string{"ipv4", "ipv6"} {
+		if _, ok := entry[f]; ok && f != family {
+			return newError("invalid-value", path+"/"+f, "%s doesn't match the type of ACL set", f)
+		}
+	}
+	ip := container(container(entry[family])["config"])
+	for _, field := range []string{"source-address", "destination-address"} {
+		if address, ok := ip[field].(string); ok && !strings.Contains(address, "/") {
+			return newError("invalid-value", path+"/"+family, "%s %s isn't a prefix", field, address)
+		}
+	}
+
+	transport := container(container(entry["transport"])["config"])
+	if len(transport) == 0 {
+		return nil
+	}
+	switch ip["protocol"] {
+	case "openconfig-packet-match-types:IP_TCP", "openconfig-packet-match-types:IP_UDP", 6.0, 17.0:
+	default:
+		return newError("invalid-value", path+"/transport", "the ports need protocol TCP or UDP")
+	}
+	for _, field := range []string{"source-port", "destination-port"} {
+		if port, ok := transport[field]; ok && !ocPortPattern.MatchString(fmt.Sprint(port)) {
+			return newError("invalid-value", path+"/transport", "invalid %s %v", field, port)
+		}
+	}
+	return nil
+}
+
+// iosXEInterfaceName splits the name of interface into the type and the number
+var iosXEInterfaceName = regexp.MustCompile(`^([A-Za-z][A-Za-z-]*)([0-9][0-9/.:]*)$`)
+
+func iosXESeed(interfaces []string) (map[string]interface{}, error) {
+	types := map[string]interface{}{}
+	for _, name := range interfaces {
+		matches := iosXEInterfaceName.FindStringSubmatch(name)
+		if matches == nil {
+			return nil, fmt.Errorf("invalid interface name %q", name)
+		}
+		entries, _ := types[matches[1]].([]interface{})
+		types[matches[1]] = append(entries, map[string]interface{}{
+			"name": matches[2],
+			"switchport": map[string]interface{}{
+				"Cisco-IOS-XE-switch:mode": map[string]interface{}{"access": map[string]interface{}{}},
+			},
+		})
+	}
+	return map[string]interface{}{
+		"Cisco-IOS-XE-native:native": map[string]interface{}{
+			"interface": types,
+			"vlan": map[string]interface{}{
+				"Cisco-IOS-XE-vlan:vlan-list": []interface{}{map[string]interface{}{"id": 1.0}},
+			},
+		},
+	}, nil
+}
+
+// iosXEValidate validates the switchport, mtu and VLANs of IOS XE
+func iosXEValidate(data map[string]interface{}) *restconfError {
+	native := container(data["Cisco-IOS-XE-native:native"])
+	for _, vlan := range list(native, "vlan", "Cisco-IOS-XE-vlan:vlan-list") {
+		vid, ok := integer(container(vlan)["id"])
+		if !ok || vid < 1 || vid > 4094 {
+			return newError("invalid-value", "/Cisco-IOS-XE-native:native/vlan", "invalid VLAN id %v", container(vlan)["id"])
+		}
+	}
+
+	for interfaceType, entries := range container(native["interface"]) {
+		interfaces, _ := entries.([]interface{})
+		for _, i := range interfaces {
+			path := fmt.Sprintf("/Cisco-IOS-XE-native:native/interface/%s=%v", interfaceType, container(i)["name"])
+			if mtu, ok := container(i)["mtu"]; ok {
+				if m, ok := integer(mtu); !ok || m < 1500 || m > 9198 {
+					return newError("invalid-value", path+"/mtu", "mtu %v is out of range 1500-9198", mtu)
+				}
+			}
+
+			switchport := container(container(i)["switchport"])
+			mode := container(switchport["Cisco-IOS-XE-switch:mode"])
+			_, access := mode["access"]
+			_, trunk := mode["trunk"]
+			if access == trunk {
+				return newError("invalid-value", path+"/switchport", "the mode must be either access or trunk")
+			}
+			vlan := container(container(switchport["Cisco-IOS-XE-switch:access"])["vlan"])
+			if v, ok := vlan["vlan"]; ok {
+				if vid, ok := integer(v); !ok || vid < 1 || vid > 4094 {
+					return newError("invalid-value", path+"/switchport", "invalid access VLAN %v", v)
+				}
+			}
+			trunkConfig := container(switchport["Cisco-IOS-XE-switch:trunk"])
+			allowed := container(container(trunkConfig["allowed"])["vlan"])
+			if vlans, ok := allowed["vlans"]; ok {
+				ids, err := ustrings.RangeToSlice(fmt.Sprint(vlans))
+				if err != nil || len(ids) == 0 {
+					return newError("invalid-value", path+"/switchport", "invalid allowed VLANs %v", vlans)
+				}
+				for _, vid := range ids {
+					if vid < 1 || vid > 4094 {
+						return newError("invalid-value", path+"/switchport", "allowed VLAN %d is out of range 1-4094", vid)
+					}
+				}
+			}
+			if v, ok := container(trunkConfig["native"])["vlan-id"]; ok {
+				if vid, ok := integer(v); !ok || vid < 1 || vid > 4094 {
+					return newError("invalid-value", path+"/switchport", "invalid native VLAN %v", v)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// container return the node as a container, it's empty if the node isn't
+func container(node interface{}) map[string]interface{} {
+	c, _ := node.(map[string]interface{})
+	return c
+}
+
+// list return the entries of the list under the containers of names
+func list(node map[string]interface{}, names ...string) []interface{} {
+	for _, name := range names[:len(names)-1] {
+		node = container(node[name])
+	}
+	entries, _ := node[names[len(names)-1]].([]interface{})
+	return entries
+}
+
+// integer return the number as an integer
+func integer(v interface{}) (int, bool) {
+	f, ok := v.(float64)
+	if !ok || f != float64(int(f)) {
+		return 0, false
+	}
+	return int(f), true
+}
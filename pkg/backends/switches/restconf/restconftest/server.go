@@ -0,0 +1,566 @@
+// Package restconftest provides a RESTCONF stand-in, it's an http.Handler
+// serving the GET of data resources and the YANG Patch of the datastore. The
+// datastore is seeded with the interfaces of the model, and it's validated
+// against the model after every patch like the commit of a switch.
+package restconftest
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Server is the RESTCONF stand-in, the root resource is `/restconf`
+type Server struct {
+	mutex    sync.Mutex
+	username string
+	password string
+	model    *model
+	locked   bool
+	data     map[string]interface{}
+}
+
+// NewServer return a server whose datastore has the interfaces of the model,
+// `openconfig` or `cisco-ios-xe-native`, the requests are authenticated by
+// the username and password
+func NewServer(username string, password string, modelName string, interfaces ...string) (*Server, error) {
+	m := models[modelName]
+	if m == nil {
+		return nil, fmt.Errorf("unknown model %q", modelName)
+	}
+	data, err := m.seed(interfaces)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{username: username, password: password, model: m, data: data}, nil
+}
+
+// Get return the node of path in JSON, the members of object are sorted
+func (s *Server) Get(path string) (string, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	segments, err := parsePath(path)
+	if err != nil {
+		return "", false
+	}
+	node, ok := s.lookup(s.data, segments)
+	if !ok {
+		return "", false
+	}
+	b, _ := json.Marshal(node)
+	return string(b), true
+}
+
+// Replace replaces the node of path by the value in JSON, it's used to prepare
+// the datastore of tests
+func (s *Server) Replace(path string, value string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var v interface{}
+	err := json.Unmarshal([]byte(value), &v)
+	if err != nil {
+		return err
+	}
+	segments, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+	data := deepCopy(s.data).(map[string]interface{})
+	last := segments[len(segments)-1]
+	if e := s.apply(data, segments, "replace", map[string]interface{}{last.name: v}); e != nil {
+		return fmt.Errorf("%s: %s", e.ErrorTag, e.ErrorMessage)
+	}
+	s.data = data
+	return nil
+}
+
+// SetLocked makes the patches fail with `lock-denied` like the datastore is
+// locked by another session
+func (s *Server) SetLocked(locked bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.locked = locked
+}
+
+// restconfError is an error of RESTCONF
+type restconfError struct {
+	ErrorType    string `json:"error-type"`
+	ErrorTag     string `json:"error-tag"`
+	ErrorPath    string `json:"error-path,omitempty"`
+	ErrorMessage string `json:"error-message,omitempty"`
+}
+
+func newError(tag string, path string, format string, a ...interface{}) *restconfError {
+	return &restconfError{ErrorType: "application", ErrorTag: tag, ErrorPath: path, ErrorMessage: fmt.Sprintf(format, a...)}
+}
+
+// status return the HTTP status of the error tag, see RFC 8040 section 7
+func (e *restconfError) status() int {
+	switch e.ErrorTag {
+	case "access-denied":
+		return http.StatusForbidden
+	case "in-use", "lock-denied", "resource-denied", "data-exists", "data-missing":
+		return http.StatusConflict
+	case "operation-not-supported":
+		return http.StatusMethodNotAllowed
+	case "operation-failed":
+		return http.StatusInternalServerError
+	}
+	return http.StatusBadRequest
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/yang-data+json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, e *restconfError) {
+	writeJSON(w, status, map[string]interface{}{"ietf-restconf:errors": map[string]interface{}{"error": []*restconfError{e}}})
+}
+
+// ServeHTTP serves the root resource, GET of data resources and YANG Patch
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	username, password, ok := r.BasicAuth()
+	if !ok || username != s.username || password != s.password {
+		writeError(w, http.StatusUnauthorized, newError("access-denied", "", "authentication failed"))
+		return
+	}
+
+	path := r.URL.EscapedPath()
+	switch {
+	case path == "/restconf" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"ietf-restconf:restconf": map[string]interface{}{"data": map[string]interface{}{}, "operations": map[string]interface{}{}, "yang-library-version": "2019-01-04"},
+		})
+	case strings.HasPrefix(path, "/restconf/data/") && r.Method == http.MethodGet:
+		s.serveGet(w, strings.TrimPrefix(path, "/restconf/data/"))
+	case path == "/restconf/data" && r.Method == http.MethodPatch:
+		s.servePatch(w, r)
+	case path == "/restconf" || strings.HasPrefix(path, "/restconf/data"):
+		writeError(w, http.StatusMethodNotAllowed, newError("operation-not-supported", "", "%s isn't supported", r.Method))
+	default:
+		writeError(w, http.StatusNotFound, newError("invalid-value", "", "%s isn't found", path))
+	}
+}
+
+func (s *Server) serveGet(w http.ResponseWriter, path string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	segments, err := parsePath(path)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, newError("invalid-value", path, "%v", err))
+		return
+	}
+	node, ok := s.lookup(s.data, segments)
+	if !ok {
+		writeError(w, http.StatusNotFound, newError("invalid-value", path, "uri keypath not found"))
+		return
+	}
+	last := segments[len(segments)-1]
+	if last.keys != nil {
+		node = []interface{}{node}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{qualifiedName(segments): node})
+}
+
+// yangPatch is the request of YANG Patch (RFC 8072)
+type yangPatch struct {
+	Patch struct {
+		PatchID string `json:"patch-id"`
+		Edit    []struct {
+			EditID    string                 `json:"edit-id"`
+			Operation string                 `json:"operation"`
+			Target    string                 `json:"target"`
+			Value     map[string]interface{} `json:"value"`
+		} `json:"edit"`
+	} `json:"ietf-yang-patch:yang-patch"`
+}
+
+// servePatch applies the edits of YANG Patch in order, they are applied
+// together or not at all
+func (s *Server) servePatch(w http.ResponseWriter, r *http.Request) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/yang-patch+json" {
+		writeError(w, http.StatusUnsupportedMediaType, newError("operation-not-supported", "", "media type %q isn't supported", mediaType))
+		return
+	}
+	patch := &yangPatch{}
+	err := json.NewDecoder(r.Body).Decode(patch)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, newError("malformed-message", "", "%v", err))
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.locked {
+		writeError(w, http.StatusConflict, newError("lock-denied", "", "the datastore is locked by another session"))
+		return
+	}
+
+	data := deepCopy(s.data).(map[string]interface{})
+	for _, e := range patch.Patch.Edit {
+		segments, err := parsePath(e.Target)
+		var failed *restconfError
+		if err != nil {
+			failed = newError("invalid-value", e.Target, "%v", err)
+		} else {
+			failed = s.apply(data, segments, e.Operation, e.Value)
+		}
+		if failed != nil {
+			writeJSON(w, failed.status(), map[string]interface{}{
+				"ietf-yang-patch:yang-patch-status": map[string]interface{}{
+					"patch-id": patch.Patch.PatchID,
+					"edit-status": map[string]interface{}{"edit": []interface{}{
+						map[string]interface{}{"edit-id": e.EditID, "errors": map[string]interface{}{"error": []*restconfError{failed}}},
+					}},
+				},
+			})
+			return
+		}
+	}
+	if failed := s.model.validate(data); failed != nil {
+		writeJSON(w, failed.status(), map[string]interface{}{
+			"ietf-yang-patch:yang-patch-status": map[string]interface{}{
+				"patch-id": patch.Patch.PatchID,
+				"errors":   map[string]interface{}{"error": []*restconfError{failed}},
+			},
+		})
+		return
+	}
+	s.data = data
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"ietf-yang-patch:yang-patch-status": map[string]interface{}{"patch-id": patch.Patch.PatchID, "ok": []interface{}{nil}},
+	})
+}
+
+// segment is a segment of the path of data resource, the keys are set for
+// the list entries
+type segment struct {
+	name string
+	keys []string
+}
+
+// parsePath parses the path of data resource, the keys are percent-decoded
+func parsePath(path string) ([]segment, error) {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil, fmt.Errorf("empty path")
+	}
+	segments := []segment{}
+	for _, part := range strings.Split(path, "/") {
+		name, keys, hasKeys := cut(part, "=")
+		name, err := url.PathUnescape(name)
+		if err != nil || name == "" {
+			return nil, fmt.Errorf("invalid segment %q", part)
+		}
+		seg := segment{name: name}
+		if hasKeys {
+			for _, key := range strings.Split(keys, ",") {
+				key, err = url.PathUnescape(key)
+				if err != nil {
+					return nil, fmt.Errorf("invalid segment %q", part)
+				}
+				seg.keys = append(seg.keys, key)
+			}
+		}
+		segments = append(segments, seg)
+	}
+	if !strings.Contains(segments[0].name, ":") {
+		return nil, fmt.Errorf("the first segment %q isn't qualified by module", segments[0].name)
+	}
+	return segments, nil
+}
+
+func cut(s string, sep string) (string, string, bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+// qualifiedName return the name of the last segment qualified by its module
+func qualifiedName(segments []segment) string {
+	name := segments[len(segments)-1].name
+	if strings.Contains(name, ":") {
+		return name
+	}
+	for i := len(segments) - 1; i >= 0; i-- {
+		if module, _, ok := cut(segments[i].name, ":"); ok {
+			return module + ":" + name
+		}
+	}
+	return name
+}
+
+// localName return the name without module
+func localName(name string) string {
+	if _, local, ok := cut(name, ":"); ok {
+		return local
+	}
+	return name
+}
+
+// schemaPath return the path of schema node of the segments
+func schemaPath(segments []segment) string {
+	names := []string{}
+	for _, seg := range segments {
+		names = append(names, seg.name)
+	}
+	return strings.Join(names, "/")
+}
+
+// lookup return the node of path
+func (s *Server) lookup(data map[string]interface{}, segments []segment) (interface{}, bool) {
+	var node interface{} = data
+	for i, seg := range segments {
+		container, ok := node.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		node, ok = container[seg.name]
+		if !ok {
+			return nil, false
+		}
+		if seg.keys != nil {
+			list, _ := node.([]interface{})
+			index := s.find(list, schemaPath(segments[:i+1]), seg.keys)
+			if index < 0 {
+				return nil, false
+			}
+			node = list[index]
+		}
+	}
+	return node, true
+}
+
+// find return the index of the list entry which has the keys, it's -1 if the
+// entry doesn't exist
+func (s *Server) find(list []interface{}, schema string, keys []string) int {
+	names := s.model.keys(schema)
+	if len(names) != len(keys) {
+		return -1
+	}
+	for i, entry := range list {
+		fields, _ := entry.(map[string]interface{})
+		matched := true
+		for j, name := range names {
+			matched = matched && fmt.Sprint(fields[name]) == keys[j]
+		}
+		if matched {
+			return i
+		}
+	}
+	return -1
+}
+
+// apply applies the operation of edit on the data, the missing parents of
+// target are created except the fixed list entries like interfaces
+func (s *Server) apply(data map[string]interface{}, segments []segment, operation string, value map[string]interface{}) *restconfError {
+	target := "/" + schemaPath(segments)
+	create := operation == "create" || operation == "merge" || operation == "replace"
+
+	// Find the container of the target node, the parents are kept to remove
+	// the containers emptied by the removal
+	container := data
+	parents := []parent{}
+	for i, seg := range segments[:len(segments)-1] {
+		child, ok := container[seg.name]
+		if !ok {
+			if !create {
+				return s.missing(operation, target)
+			}
+			if seg.keys != nil {
+				child = []interface{}{}
+			} else {
+				child = map[string]interface{}{}
+			}
+			container[seg.name] = child
+		}
+		if seg.keys == nil {
+			next, ok := child.(map[string]interface{})
+			if !ok {
+				return newError("invalid-value", target, "%s isn't a container", seg.name)
+			}
+			parents = append(parents, parent{container, seg.name})
+			container = next
+			continue
+		}
+
+		// The list entry isn't removed, so are its parents
+		parents = nil
+		schema := schemaPath(segments[:i+1])
+		list, _ := child.([]interface{})
+		index := s.find(list, schema, seg.keys)
+		if index < 0 {
+			if !create {
+				return s.missing(operation, target)
+			}
+			if s.model.fixed(schema) {
+				return newError("invalid-value", target, "%s %s doesn't exist", seg.name, strings.Join(seg.keys, ","))
+			}
+			entry := map[string]interface{}{}
+			for j, name := range s.model.keys(schema) {
+				entry[name] = seg.keys[j]
+			}
+			container[seg.name] = append(list, entry)
+			container = entry
+			continue
+		}
+		container = list[index].(map[string]interface{})
+	}
+
+	last := segments[len(segments)-1]
+	schema := schemaPath(segments)
+	var v interface{}
+	if create {
+		if len(value) != 1 {
+			return newError("invalid-value", target, "the value must have only the target node")
+		}
+		for name, member := range value {
+			if localName(name) != localName(last.name) {
+				return newError("invalid-value", target, "the value has %s instead of the target node", name)
+			}
+			v = member
+		}
+	}
+
+	if last.keys == nil {
+		_, exists := container[last.name]
+		switch {
+		case operation == "create" && exists:
+			return newError("data-exists", target, "the data already exists")
+		case operation == "delete" && !exists:
+			return newError("data-missing", target, "the data doesn't exist")
+		case operation == "delete" || operation == "remove":
+			delete(container, last.name)
+			prune(parents, container)
+		case operation == "merge":
+			container[last.name] = s.merge(container[last.name], v, schema)
+		case create:
+			container[last.name] = v
+		default:
+			return newError("operation-not-supported", target, "operation %q isn't supported", operation)
+		}
+		return nil
+	}
+
+	// The target is a list entry
+	list, _ := container[last.name].([]interface{})
+	index := s.find(list, schema, last.keys)
+	if create {
+		entries, ok := v.([]interface{})
+		if !ok || len(entries) != 1 || s.find(entries, schema, last.keys) != 0 {
+			return newError("invalid-value", target, "the value must be the list entry of target")
+		}
+		v = entries[0]
+		if index < 0 && s.model.fixed(schema) {
+			return newError("invalid-value", target, "%s %s doesn't exist", last.name, strings.Join(last.keys, ","))
+		}
+	}
+	switch {
+	case operation == "create" && index >= 0:
+		return newError("data-exists", target, "the data already exists")
+	case operation == "delete" && index < 0:
+		return newError("data-missing", target, "the data doesn't exist")
+	case (operation == "delete" || operation == "remove") && index >= 0:
+		container[last.name] = append(list[:index], list[index+1:]...)
+		if len(container[last.name].([]interface{})) == 0 {
+			delete(container, last.name)
+			prune(parents, container)
+		}
+	case operation == "remove":
+	case create && index < 0:
+		container[last.name] = append(list, v)
+	case operation == "merge":
+		list[index] = s.merge(list[index], v, schema)
+	case create:
+		list[index] = v
+	default:
+		return newError("operation-not-supported", target, "operation %q isn't supported", operation)
+	}
+	return nil
+}
+
+// parent is the container of a node by the name
+type parent struct {
+	container map[string]interface{}
+	name      string
+}
+
+// prune removes the container and its parents if they are empty
+func prune(parents []parent, container map[string]interface{}) {
+	for i := len(parents) - 1; i >= 0 && len(container) == 0; i-- {
+		delete(parents[i].container, parents[i].name)
+		container = parents[i].container
+	}
+}
+
+// missing return the error of the operation whose target is missing, it's
+// nil for `remove`
+func (s *Server) missing(operation string, target string) *restconfError {
+	if operation == "remove" {
+		return nil
+	}
+	return newError("data-missing", target, "the data doesn't exist")
+}
+
+// merge merges the value into the node, the list entries are merged by their
+// keys and the leaf-lists are merged by their values
+func (s *Server) merge(node interface{}, value interface{}, schema string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		container, ok := node.(map[string]interface{})
+		if !ok {
+			return v
+		}
+		for name, member := range v {
+			container[name] = s.merge(container[name], member, schema+"/"+name)
+		}
+		return container
+	case []interface{}:
+		list, ok := node.([]interface{})
+		if !ok {
+			return v
+		}
+		names := s.model.keys(schema)
+		for _, element := range v {
+			index := -1
+			if entry, ok := element.(map[string]interface{}); ok && len(names) != 0 {
+				keys := []string{}
+				for _, name := range names {
+					keys = append(keys, fmt.Sprint(entry[name]))
+				}
+				index = s.find(list, schema, keys)
+			} else {
+				for i, existing := range list {
+					if fmt.Sprint(existing) == fmt.Sprint(element) {
+						index = i
+					}
+				}
+			}
+			if index < 0 {
+				list = append(list, element)
+			} else {
+				list[index] = s.merge(list[index], element, schema)
+			}
+		}
+		return list
+	}
+	return value
+}
+
+func deepCopy(v interface{}) interface{} {
+	b, _ := json.Marshal(v)
+	var copied interface{}
+	_ = json.Unmarshal(b, &copied)
+	return copied
+}
@@ -0,0 +1,217 @@
+package sonic
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Hellcatlk/network-operator/api/v1alpha1"
+	"github.com/Hellcatlk/network-operator/pkg/backends"
+)
+
+// maxPriority is the priority of the first rule, the rules of an ACL table are
+// matched from the highest priority
+const maxPriority int = 9999
+
+// The IP protocol numbers of ACL_RULE
+var protocolNumbers = map[string]map[string]string{
+	"4": {"TCP": "6", "UDP": "17", "ICMP": "1"},
+	"6": {"TCP": "6", "UDP": "17", "ICMP": "58"},
+}
+
+// aclTable return the name of ACL table of the port for the IP version
+func aclTable(port string, ipVersion string) string {
+	return "NETWORK_OPERATOR_" + port + "_V" + ipVersion
+}
+
+// toACLEntries return the ACL_TABLE and ACL_RULE entries of the port, every ACL
+// is split into the rules of every pair of source and destination port ranges
+func toACLEntries(port string, acls []v1alpha1.ACL) (map[string]map[string]string, error) {
+	entries := map[string]map[string]string{}
+	priority := maxPriority
+	for i, acl := range acls {
+		protocols := protocolNumbers[acl.IPVersion]
+		if protocols == nil || acl.Protocol == "" || (acl.Action != "allow" && acl.Action != "deny") {
+			return nil, backends.Errorf(backends.InvalidConfiguration, "ipVersion, action and protocol of ACL %d are required by sonic backend", i)
+		}
+		fields := map[string]string{"PACKET_ACTION": "FORWARD"}
+		if acl.Action == "deny" {
+			fields["PACKET_ACTION"] = "DROP"
+		}
+		if acl.Protocol != "ALL" {
+			fields["IP_PROTOCOL"] = protocols[acl.Protocol]
+			if fields["IP_PROTOCOL"] == "" {
+				return nil, backends.Errorf(backends.InvalidConfiguration, "unknown protocol %q of ACL %d", acl.Protocol, i)
+			}
+		}
+		for field, ip := range map[string]string{"SRC_IP": acl.SourceIP, "DST_IP": acl.DestinationIP} {
+			if ip == "" {
+				continue
+			}
+			if strings.Contains(ip, ":") != (acl.IPVersion == "6") {
+				return nil, backends.Errorf(backends.InvalidConfiguration, "%s isn't an IPv%s address of ACL %d", ip, acl.IPVersion, i)
+			}
+			if acl.IPVersion == "6" {
+				field += "V6"
+			}
+			fields[field] = ip
+		}
+
+		sources, err := portRanges(acl.SourcePortRange)
+		if err != nil {
+			return nil, backends.Errorf(backends.InvalidConfiguration, "invalid source port range of ACL %d: %v", i, err)
+		}
+		destinations, err := portRanges(acl.DestinationPortRange)
+		if err != nil {
+			return nil, backends.Errorf(backends.InvalidConfiguration, "invalid destination port range of ACL %d: %v", i, err)
+		}
+		if (len(sources) > 1 || len(destinations) > 1 || sources[0] != "" || destinations[0] != "") &&
+			acl.Protocol != "TCP" && acl.Protocol != "UDP" {
+			return nil, backends.Errorf(backends.InvalidConfiguration, "port range of ACL %d requires TCP or UDP", i)
+		}
+
+		table := aclTable(port, acl.IPVersion)
+		entries["ACL_TABLE|"+table] = map[string]string{
+			"policy_desc": "network-operator " + port,
+			"type":        map[string]string{"4": "L3", "6": "L3V6"}[acl.IPVersion],
+			"stage":       "ingress",
+			"ports@":      port,
+		}
+		j := 0
+		for _, source := range sources {
+			for _, destination := range destinations {
+				rule := map[string]string{"PRIORITY": strconv.Itoa(priority)}
+				for field, value := range fields {
+					rule[field] = value
+				}
+				setPortField(rule, "L4_SRC_PORT", source)
+				setPortField(rule, "L4_DST_PORT", destination)
+				entries[fmt.Sprintf("ACL_RULE|%s|RULE_%d_%d", table, i, j)] = rule
+				priority--
+				j++
+			}
+		}
+	}
+	return entries, nil
+}
+
+// fromACLRules return the ACLs of the ACL_RULE entries created by toACLEntries
+func fromACLRules(rules map[string]map[string]string) []v1alpha1.ACL {
+	type rule struct {
+		acl, index int
+		table      string
+		fields     map[string]string
+	}
+	sorted := []rule{}
+	for key, fields := range rules {
+		parts := strings.Split(key, "|")
+		if len(parts) != 3 {
+			continue
+		}
+		var r rule
+		_, err := fmt.Sscanf(parts[2], "RULE_%d_%d", &r.acl, &r.index)
+		if err != nil {
+			continue
+		}
+		r.table, r.fields = parts[1], fields
+		sorted = append(sorted, r)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].acl != sorted[j].acl {
+			return sorted[i].acl < sorted[j].acl
+		}
+		return sorted[i].index < sorted[j].index
+	})
+
+	acls := []v1alpha1.ACL{}
+	var sources, destinations []string
+	for i, r := range sorted {
+		if i == 0 || r.acl != sorted[i-1].acl {
+			acls = append(acls, fromRuleFields(r.table, r.fields))
+			sources, destinations = nil, nil
+		}
+		acl := &acls[len(acls)-1]
+		sources = appendUnique(sources, portField(r.fields, "L4_SRC_PORT"))
+		destinations = appendUnique(destinations, portField(r.fields, "L4_DST_PORT"))
+		acl.SourcePortRange = strings.Join(sources, ",")
+		acl.DestinationPortRange = strings.Join(destinations, ",")
+	}
+	if len(acls) == 0 {
+		return nil
+	}
+	return acls
+}
+
+// fromRuleFields return the ACL of the rule without port ranges
+func fromRuleFields(table string, fields map[string]string) v1alpha1.ACL {
+	acl := v1alpha1.ACL{
+		IPVersion:     strings.TrimPrefix(table[strings.LastIndex(table, "_")+1:], "V"),
+		Action:        "allow",
+		Protocol:      "ALL",
+		SourceIP:      fields["SRC_IP"] + fields["SRC_IPV6"],
+		DestinationIP: fields["DST_IP"] + fields["DST_IPV6"],
+	}
+	if fields["PACKET_ACTION"] == "DROP" {
+		acl.Action = "deny"
+	}
+	for protocol, number := range protocolNumbers[acl.IPVersion] {
+		if fields["IP_PROTOCOL"] == number {
+			acl.Protocol = protocol
+		}
+	}
+	return acl
+}
+
+// portRanges return the port ranges of the comma separated list, it's [""]
+// for the empty list
+func portRanges(list string) ([]string, error) {
+	if list == "" {
+		return []string{""}, nil
+	}
+	ranges := strings.Split(list, ",")
+	for _, r := range ranges {
+		bounds := strings.Split(r, "-")
+		if len(bounds) > 2 {
+			return nil, fmt.Errorf("invalid range %q", r)
+		}
+		for _, bound := range bounds {
+			port, err := strconv.Atoi(bound)
+			if err != nil || port < 0 || port > 65535 {
+				return nil, fmt.Errorf("invalid port %q", bound)
+			}
+		}
+	}
+	return ranges, nil
+}
+
+// setPortField sets the field of the single port or the port range
+func setPortField(fields map[string]string, field string, ports string) {
+	switch {
+	case ports == "":
+	case strings.Contains(ports, "-"):
+		fields[field+"_RANGE"] = ports
+	default:
+		fields[field] = ports
+	}
+}
+
+// portField return the single port or the port range of the field
+func portField(fields map[string]string, field string) string {
+	if ports, ok := fields[field+"_RANGE"]; ok {
+		return ports
+	}
+	return fields[field]
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	if value == "" {
+		return values
+	}
+	return append(values, value)
+}
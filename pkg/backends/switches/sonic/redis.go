@@ -0,0 +1,253 @@
+package sonic
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Hellcatlk/network-operator/pkg/backends"
+)
+
+// The Redis serialization protocol (RESP2), see https://redis.io/topics/protocol
+
+// redisError is the error reply of Redis
+type redisError string
+
+// Error implements error interface
+func (e redisError) Error() string {
+	return string(e)
+}
+
+// conn is a connection to Redis
+type conn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	stop   chan struct{}
+}
+
+// dial connects to Redis, the connection is closed when the context is done
+func dial(ctx context.Context, network string, address string) (*conn, error) {
+	dialer := &net.Dialer{}
+	c, err := dialer.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, backends.NewError(backends.Transient, err)
+	}
+
+	result := &conn{
+		conn:   c,
+		reader: bufio.NewReader(c),
+		stop:   make(chan struct{}),
+	}
+	// Interrupt the reading and writing when the context is done
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = c.SetDeadline(time.Now())
+		case <-result.stop:
+		}
+	}()
+	return result, nil
+}
+
+// close closes the connection
+func (c *conn) close() {
+	close(c.stop)
+	c.conn.Close()
+}
+
+// do sends the command and return the reply, the reply is a string, an
+// integer, nil or a slice of them
+func (c *conn) do(ctx context.Context, args ...string) (interface{}, error) {
+	err := c.send(args)
+	if err != nil {
+		return nil, c.connectionError(ctx, err)
+	}
+	reply, err := c.receive()
+	if err != nil {
+		if e, ok := err.(redisError); ok {
+			return nil, replyError(args[0], e)
+		}
+		return nil, c.connectionError(ctx, err)
+	}
+	return reply, nil
+}
+
+// strings sends the command and return the reply as strings
+func (c *conn) strings(ctx context.Context, args ...string) ([]string, error) {
+	reply, err := c.do(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	values, _ := reply.([]interface{})
+	result := []string{}
+	for _, value := range values {
+		s, _ := value.(string)
+		result = append(result, s)
+	}
+	return result, nil
+}
+
+// hash return the fields of the hash, it's nil if the hash doesn't exist
+func (c *conn) hash(ctx context.Context, key string) (map[string]string, error) {
+	values, err := c.strings(ctx, "HGETALL", key)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+	fields := map[string]string{}
+	for i := 0; i+1 < len(values); i += 2 {
+		fields[values[i]] = values[i+1]
+	}
+	return fields, nil
+}
+
+// transaction runs the commands in MULTI/EXEC, the commands are pipelined
+func (c *conn) transaction(ctx context.Context, commands [][]string) error {
+	if len(commands) == 0 {
+		return nil
+	}
+
+	all := append([][]string{{"MULTI"}}, commands...)
+	all = append(all, []string{"EXEC"})
+	for _, args := range all {
+		err := c.send(args)
+		if err != nil {
+			return c.connectionError(ctx, err)
+		}
+	}
+
+	// The replies of MULTI and the queued commands, the first error aborts
+	// the transaction
+	var queueErr error
+	for i := range all[:len(all)-1] {
+		_, err := c.receive()
+		if e, ok := err.(redisError); ok {
+			if queueErr == nil {
+				queueErr = replyError(all[i][0], e)
+			}
+			continue
+		}
+		if err != nil {
+			return c.connectionError(ctx, err)
+		}
+	}
+	reply, err := c.receive()
+	if queueErr != nil {
+		return queueErr
+	}
+	if e, ok := err.(redisError); ok {
+		return replyError("EXEC", e)
+	}
+	if err != nil {
+		return c.connectionError(ctx, err)
+	}
+
+	results, _ := reply.([]interface{})
+	for i, result := range results {
+		if e, ok := result.(redisError); ok {
+			return replyError(commands[i][0], e)
+		}
+	}
+	return nil
+}
+
+// send writes the command as an array of bulk strings
+func (c *conn) send(args []string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	_, err := io.WriteString(c.conn, b.String())
+	return err
+}
+
+// receive reads a reply, the error reply is returned as redisError, and the
+// error replies in an array are returned as elements
+func (c *conn) receive() (interface{}, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || !strings.HasSuffix(line, "\r\n") {
+		return nil, fmt.Errorf("invalid reply %q", line)
+	}
+	kind, value := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return value, nil
+	case '-':
+		return nil, redisError(value)
+	case ':':
+		return strconv.ParseInt(value, 10, 64)
+	case '$':
+		length, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, err
+		}
+		if length < 0 {
+			return nil, nil
+		}
+		b := make([]byte, length+2)
+		_, err = io.ReadFull(c.reader, b)
+		if err != nil {
+			return nil, err
+		}
+		return string(b[:length]), nil
+	case '*':
+		length, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, err
+		}
+		if length < 0 {
+			return nil, nil
+		}
+		elements := make([]interface{}, 0, length)
+		for i := 0; i < length; i++ {
+			element, err := c.receive()
+			if e, ok := err.(redisError); ok {
+				elements = append(elements, e)
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, element)
+		}
+		return elements, nil
+	default:
+		return nil, fmt.Errorf("invalid reply %q", line)
+	}
+}
+
+// connectionError return the context error if the connection is interrupted by the context
+func (c *conn) connectionError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return backends.FromContext(ctx)
+	}
+	return backends.NewError(backends.Transient, err)
+}
+
+// replyError return the typed error of the error reply
+func replyError(command string, err redisError) error {
+	e := fmt.Errorf("%s failed: %w", strings.ToUpper(command), err)
+	switch {
+	case strings.HasPrefix(string(err), "NOAUTH"),
+		strings.HasPrefix(string(err), "WRONGPASS"),
+		strings.HasPrefix(string(err), "NOPERM"),
+		strings.Contains(string(err), "invalid password"):
+		return backends.NewError(backends.Authentication, e)
+	case strings.HasPrefix(string(err), "ERR unknown command"):
+		return backends.NewError(backends.Unsupported, e)
+	default:
+		return backends.NewError(backends.Transient, e)
+	}
+}
@@ -0,0 +1,435 @@
+// Package sonic is the switch backend which configures the ports of SONiC by
+// writing the config_db in Redis directly.
+package sonic
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Hellcatlk/network-operator/api/v1alpha1"
+	"github.com/Hellcatlk/network-operator/pkg/backends"
+	"github.com/Hellcatlk/network-operator/pkg/provider"
+	"github.com/Hellcatlk/network-operator/pkg/utils/credentials"
+	ustrings "github.com/Hellcatlk/network-operator/pkg/utils/strings"
+)
+
+// defaultDatabase is the index of config_db
+const defaultDatabase string = "4"
+
+// defaultPort is the port of Redis
+const defaultPort string = "6379"
+
+// New return sonic backend, the host is `tcp:<host>:<port>`, `unix:<path>` or
+// `<host>` which uses the default port of Redis
+func New(ctx context.Context, config *provider.SwitchConfiguration) (backends.Switch, error) {
+	if config == nil {
+		return nil, fmt.Errorf("configure of switch is nil")
+	}
+
+	database := defaultDatabase
+	for key, value := range config.Options {
+		switch key {
+		case "database":
+			database = fmt.Sprint(value)
+		default:
+			return nil, backends.Errorf(backends.InvalidConfiguration, "unknown option %q of sonic backend", key)
+		}
+	}
+	if index, err := strconv.Atoi(database); err != nil || index < 0 {
+		return nil, backends.Errorf(backends.InvalidConfiguration, "database %q of sonic backend isn't an index", database)
+	}
+
+	network, address, err := parseHost(config.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sonic{
+		network:     network,
+		address:     address,
+		database:    database,
+		credentials: config.Credentials,
+	}, nil
+}
+
+// parseHost return the network and address of Redis
+func parseHost(host string) (string, string, error) {
+	switch {
+	case strings.HasPrefix(host, "tcp:"):
+		return "tcp", strings.TrimPrefix(host, "tcp:"), nil
+	case strings.HasPrefix(host, "unix:"):
+		return "unix", strings.TrimPrefix(host, "unix:"), nil
+	case host == "":
+		return "", "", backends.Errorf(backends.InvalidConfiguration, "host of sonic backend is empty")
+	default:
+		return "tcp", host + ":" + defaultPort, nil
+	}
+}
+
+// sonic backend
+type sonic struct {
+	network     string
+	address     string
+	database    string
+	credentials *credentials.Credentials
+}
+
+// connect connects to Redis, authenticates and selects config_db
+func (s *sonic) connect(ctx context.Context) (*conn, error) {
+	c, err := dial(ctx, s.network, s.address)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.credentials != nil && s.credentials.Password != "" {
+		args := []string{"AUTH", s.credentials.Password}
+		if s.credentials.Username != "" {
+			args = []string{"AUTH", s.credentials.Username, s.credentials.Password}
+		}
+		_, err = c.do(ctx, args...)
+		if err != nil {
+			c.close()
+			return nil, err
+		}
+	}
+	_, err = c.do(ctx, "SELECT", s.database)
+	if err != nil {
+		c.close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// IsAvailable check config_db can be accessed
+func (s *sonic) IsAvailable(ctx context.Context) error {
+	c, err := s.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.close()
+
+	_, err = c.do(ctx, "PING")
+	return err
+}
+
+// GetPortAttr return the VLANs, ACLs, admin status and MTU of the port
+func (s *sonic) GetPortAttr(ctx context.Context, port string) (*v1alpha1.SwitchPortConfigurationSpec, error) {
+	c, err := s.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.close()
+
+	fields, err := getPort(ctx, c, port)
+	if err != nil {
+		return nil, err
+	}
+	configuration := &v1alpha1.SwitchPortConfigurationSpec{
+		// The default admin status of SONiC is down
+		Disable: fields["admin_status"] != "up",
+	}
+	if mtu, err := strconv.Atoi(fields["mtu"]); err == nil {
+		configuration.MTU = &mtu
+	}
+
+	members, err := getMembers(ctx, c, port)
+	if err != nil {
+		return nil, err
+	}
+	tagged := []int{}
+	for vid, mode := range members {
+		if mode == "untagged" {
+			untagged := vid
+			configuration.UntaggedVLAN = &untagged
+			continue
+		}
+		tagged = append(tagged, vid)
+	}
+	configuration.TaggedVLANRange = ustrings.SliceToRange(tagged)
+
+	rules, err := getEntries(ctx, c, "ACL_RULE|"+escapePattern(aclTable(port, ""))+"*|*")
+	if err != nil {
+		return nil, err
+	}
+	configuration.ACLs = fromACLRules(rules)
+	return configuration, nil
+}
+
+// SetPortAttr set the VLANs, ACLs, admin status and MTU of the port, the
+// missing VLANs are created, and the ACLs are written to the ACL tables of the
+// port
+func (s *sonic) SetPortAttr(ctx context.Context, port string, configuration *v1alpha1.SwitchPortConfigurationSpec) error {
+	vlans, err := ustrings.RangeToSlice(configuration.TaggedVLANRange)
+	if err != nil {
+		return backends.NewError(backends.InvalidConfiguration, err)
+	}
+	members := map[int]string{}
+	for _, vid := range vlans {
+		members[vid] = "tagged"
+	}
+	if configuration.UntaggedVLAN != nil {
+		members[*configuration.UntaggedVLAN] = "untagged"
+	}
+	for vid := range members {
+		if vid < 1 || vid > 4094 {
+			return backends.Errorf(backends.InvalidConfiguration, "VLAN %d is out of range 1-4094", vid)
+		}
+	}
+	acls, err := toACLEntries(port, configuration.ACLs)
+	if err != nil {
+		return err
+	}
+
+	c, err := s.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.close()
+
+	_, err = getPort(ctx, c, port)
+	if err != nil {
+		return err
+	}
+	commands, err := resetCommands(ctx, c, port, members)
+	if err != nil {
+		return err
+	}
+
+	for _, vid := range sortedVLANs(members) {
+		vlan := "Vlan" + strconv.Itoa(vid)
+		exists, err := c.do(ctx, "EXISTS", "VLAN|"+vlan)
+		if err != nil {
+			return err
+		}
+		if exists == int64(0) {
+			commands = append(commands, hset("VLAN|"+vlan, map[string]string{"vlanid": strconv.Itoa(vid)}))
+		}
+		commands = append(commands, hset("VLAN_MEMBER|"+vlan+"|"+port, map[string]string{"tagging_mode": members[vid]}))
+	}
+
+	// The tables are created before their rules
+	keys := []string{}
+	for key := range acls {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if strings.HasPrefix(key, "ACL_TABLE|") {
+			commands = append(commands, hset(key, acls[key]))
+		}
+	}
+	for _, key := range keys {
+		if strings.HasPrefix(key, "ACL_RULE|") {
+			commands = append(commands, hset(key, acls[key]))
+		}
+	}
+
+	status := map[string]string{"admin_status": "up"}
+	if configuration.Disable {
+		status["admin_status"] = "down"
+	}
+	if configuration.MTU != nil {
+		status["mtu"] = strconv.Itoa(*configuration.MTU)
+	}
+	commands = append(commands, hset("PORT|"+port, status))
+
+	return c.transaction(ctx, commands)
+}
+
+// ResetPort remove the port from its VLANs, delete its ACLs and set it up,
+// the VLANs and the MTU are kept
+func (s *sonic) ResetPort(ctx context.Context, port string, configuration *v1alpha1.SwitchPortConfigurationSpec) error {
+	c, err := s.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.close()
+
+	_, err = getPort(ctx, c, port)
+	if err != nil {
+		return err
+	}
+	commands, err := resetCommands(ctx, c, port, nil)
+	if err != nil {
+		return err
+	}
+	commands = append(commands, hset("PORT|"+port, map[string]string{"admin_status": "up"}))
+	return c.transaction(ctx, commands)
+}
+
+// Capabilities return the fields supported by sonic backend, all of them are
+// supported
+func (s *sonic) Capabilities(ctx context.Context) (*v1alpha1.SwitchCapabilities, error) {
+	return backends.AllCapabilities(), nil
+}
+
+// ListPortVLANs return the VLANs of the ports which are members of VLANs
+func (s *sonic) ListPortVLANs(ctx context.Context) (map[string][]int, error) {
+	c, err := s.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.close()
+
+	keys, err := c.strings(ctx, "KEYS", "VLAN_MEMBER|*")
+	if err != nil {
+		return nil, err
+	}
+	ports := map[string][]int{}
+	for _, key := range keys {
+		// The key is `VLAN_MEMBER|Vlan<vid>|<port>`
+		fields := strings.SplitN(key, "|", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		vid, err := strconv.Atoi(strings.TrimPrefix(fields[1], "Vlan"))
+		if err != nil {
+			continue
+		}
+		ports[fields[2]] = append(ports[fields[2]], vid)
+	}
+	for _, vlans := range ports {
+		sort.Ints(vlans)
+	}
+	return ports, nil
+}
+
+// ListVLANs return the VLANs of the VLAN table in order
+func (s *sonic) ListVLANs(ctx context.Context) ([]int, error) {
+	c, err := s.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.close()
+
+	keys, err := c.strings(ctx, "KEYS", "VLAN|*")
+	if err != nil {
+		return nil, err
+	}
+	vlans := []int{}
+	for _, key := range keys {
+		vid, err := strconv.Atoi(strings.TrimPrefix(key, "VLAN|Vlan"))
+		if err != nil {
+			continue
+		}
+		vlans = append(vlans, vid)
+	}
+	sort.Ints(vlans)
+	return vlans, nil
+}
+
+// resetCommands return the commands removing the port from the VLANs which
+// aren't in members and deleting the ACLs of the port
+func resetCommands(ctx context.Context, c *conn, port string, members map[int]string) ([][]string, error) {
+	current, err := getMembers(ctx, c, port)
+	if err != nil {
+		return nil, err
+	}
+	commands := [][]string{}
+	for _, vid := range sortedVLANs(current) {
+		if _, ok := members[vid]; !ok {
+			commands = append(commands, []string{"DEL", "VLAN_MEMBER|Vlan" + strconv.Itoa(vid) + "|" + port})
+		}
+	}
+
+	// The rules are deleted before their tables
+	pattern := escapePattern(aclTable(port, "")) + "*"
+	for _, prefix := range []string{"ACL_RULE|", "ACL_TABLE|"} {
+		keys, err := c.strings(ctx, "KEYS", prefix+pattern)
+		if err != nil {
+			return nil, err
+		}
+		if len(keys) != 0 {
+			sort.Strings(keys)
+			commands = append(commands, append([]string{"DEL"}, keys...))
+		}
+	}
+	return commands, nil
+}
+
+// getPort return the fields of the port
+func getPort(ctx context.Context, c *conn, port string) (map[string]string, error) {
+	fields, err := c.hash(ctx, "PORT|"+port)
+	if err != nil {
+		return nil, err
+	}
+	if fields == nil {
+		return nil, backends.Errorf(backends.PortNotFound, "port %s doesn't exist", port)
+	}
+	return fields, nil
+}
+
+// getMembers return the tagging mode of the VLANs of the port
+func getMembers(ctx context.Context, c *conn, port string) (map[int]string, error) {
+	entries, err := getEntries(ctx, c, "VLAN_MEMBER|*|"+escapePattern(port))
+	if err != nil {
+		return nil, err
+	}
+	members := map[int]string{}
+	for key, fields := range entries {
+		vlan := strings.Split(key, "|")[1]
+		vid, err := strconv.Atoi(strings.TrimPrefix(vlan, "Vlan"))
+		if err != nil {
+			continue
+		}
+		members[vid] = fields["tagging_mode"]
+	}
+	return members, nil
+}
+
+// getEntries return the fields of the keys matching the pattern
+func getEntries(ctx context.Context, c *conn, pattern string) (map[string]map[string]string, error) {
+	keys, err := c.strings(ctx, "KEYS", pattern)
+	if err != nil {
+		return nil, err
+	}
+	entries := map[string]map[string]string{}
+	for _, key := range keys {
+		fields, err := c.hash(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if fields != nil {
+			entries[key] = fields
+		}
+	}
+	return entries, nil
+}
+
+// hset return the HSET command of the fields
+func hset(key string, fields map[string]string) []string {
+	names := []string{}
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	command := []string{"HSET", key}
+	for _, name := range names {
+		command = append(command, name, fields[name])
+	}
+	return command
+}
+
+// escapePattern escapes the special characters of the glob-style pattern
+func escapePattern(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(`*?[]\`, r) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func sortedVLANs(members map[int]string) []int {
+	vids := []int{}
+	for vid := range members {
+		vids = append(vids, vid)
+	}
+	sort.Ints(vids)
+	return vids
+}
@@ -0,0 +1,289 @@
+package sonic
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Hellcatlk/network-operator/api/v1alpha1"
+	"github.com/Hellcatlk/network-operator/pkg/backends"
+	"github.com/Hellcatlk/network-operator/pkg/backends/switches/sonic/sonictest"
+	"github.com/Hellcatlk/network-operator/pkg/provider"
+	"github.com/Hellcatlk/network-operator/pkg/utils/credentials"
+)
+
+// serve serves the in-memory server on a unix socket and return the backend connected to it
+func serve(t *testing.T, server *sonictest.Server, cert *credentials.Credentials) backends.Switch {
+	socket := filepath.Join(t.TempDir(), "redis.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		_ = server.Serve(listener)
+	}()
+
+	backend, err := New(context.Background(), &provider.SwitchConfiguration{
+		Host:        "unix:" + socket,
+		Backend:     "sonic",
+		Credentials: cert,
+	})
+	if err != nil {
+		t.Fatalf("new backend failed: %v", err)
+	}
+	return backend
+}
+
+func TestSONiC(t *testing.T) {
+	vlan := 10
+	mtu := 1500
+	cases := []struct {
+		name            string
+		configuration   *v1alpha1.SwitchPortConfigurationSpec
+		expectedEntries map[string]map[string]string
+	}{
+		{
+			name:          "access port",
+			configuration: &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan},
+			expectedEntries: map[string]map[string]string{
+				"VLAN|Vlan10":                  {"vlanid": "10"},
+				"VLAN_MEMBER|Vlan10|Ethernet0": {"tagging_mode": "untagged"},
+			},
+		},
+		{
+			name:          "disabled trunk port with MTU",
+			configuration: &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan, TaggedVLANRange: "11-12", Disable: true, MTU: &mtu},
+			expectedEntries: map[string]map[string]string{
+				"VLAN_MEMBER|Vlan10|Ethernet0": {"tagging_mode": "untagged"},
+				"VLAN_MEMBER|Vlan11|Ethernet0": {"tagging_mode": "tagged"},
+				"VLAN_MEMBER|Vlan12|Ethernet0": {"tagging_mode": "tagged"},
+			},
+		},
+		{
+			name: "ACLs",
+			configuration: &v1alpha1.SwitchPortConfigurationSpec{ACLs: []v1alpha1.ACL{
+				{IPVersion: "4", Action: "allow", Protocol: "TCP", SourceIP: "10.0.0.0/8", DestinationPortRange: "22,8000-8080"},
+				{IPVersion: "6", Action: "deny", Protocol: "ALL", DestinationIP: "fd00::/8"},
+			}},
+			expectedEntries: map[string]map[string]string{
+				"ACL_TABLE|NETWORK_OPERATOR_Ethernet0_V4": {"policy_desc": "network-operator Ethernet0", "type": "L3", "stage": "ingress", "ports@": "Ethernet0"},
+				"ACL_TABLE|NETWORK_OPERATOR_Ethernet0_V6": {"policy_desc": "network-operator Ethernet0", "type": "L3V6", "stage": "ingress", "ports@": "Ethernet0"},
+				"ACL_RULE|NETWORK_OPERATOR_Ethernet0_V4|RULE_0_0": {
+					"PRIORITY": "9999", "PACKET_ACTION": "FORWARD", "IP_PROTOCOL": "6", "SRC_IP": "10.0.0.0/8", "L4_DST_PORT": "22",
+				},
+				"ACL_RULE|NETWORK_OPERATOR_Ethernet0_V4|RULE_0_1": {
+					"PRIORITY": "9998", "PACKET_ACTION": "FORWARD", "IP_PROTOCOL": "6", "SRC_IP": "10.0.0.0/8", "L4_DST_PORT_RANGE": "8000-8080",
+				},
+				"ACL_RULE|NETWORK_OPERATOR_Ethernet0_V6|RULE_1_0": {"PRIORITY": "9997", "PACKET_ACTION": "DROP", "DST_IPV6": "fd00::/8"},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server := sonictest.NewServer("Ethernet0", "Ethernet4")
+			backend := serve(t, server, nil)
+
+			err := backend.IsAvailable(context.Background())
+			if err != nil {
+				t.Fatalf("IsAvailable failed: %v", err)
+			}
+
+			// Configure twice, the second one replaces the entries of the first one
+			err = backend.SetPortAttr(context.Background(), "Ethernet0", &v1alpha1.SwitchPortConfigurationSpec{
+				TaggedVLANRange: "20",
+				ACLs:            []v1alpha1.ACL{{IPVersion: "4", Action: "deny", Protocol: "UDP", SourcePortRange: "53"}},
+			})
+			if err != nil {
+				t.Fatalf("SetPortAttr failed: %v", err)
+			}
+			err = backend.SetPortAttr(context.Background(), "Ethernet0", c.configuration)
+			if err != nil {
+				t.Fatalf("SetPortAttr failed: %v", err)
+			}
+			err = server.Validate()
+			if err != nil {
+				t.Errorf("invalid config_db: %v", err)
+			}
+			for key, expected := range c.expectedEntries {
+				if fields := server.Hash(key); !reflect.DeepEqual(fields, expected) {
+					t.Errorf("expected %s: %v, got: %v", key, expected, fields)
+				}
+			}
+			if members := server.Keys("VLAN_MEMBER|Vlan20|*"); len(members) != 0 {
+				t.Errorf("expected the old member is removed, got: %v", members)
+			}
+			port := server.Hash("PORT|Ethernet0")
+			if (port["admin_status"] == "down") != c.configuration.Disable {
+				t.Errorf("unexpected admin_status: %s", port["admin_status"])
+			}
+
+			configuration, err := backend.GetPortAttr(context.Background(), "Ethernet0")
+			if err != nil {
+				t.Fatalf("GetPortAttr failed: %v", err)
+			}
+			if !c.configuration.IsEqual(configuration) {
+				t.Errorf("expected configuration: %+v, got: %+v", c.configuration, configuration)
+			}
+
+			err = backend.ResetPort(context.Background(), "Ethernet0", c.configuration)
+			if err != nil {
+				t.Fatalf("ResetPort failed: %v", err)
+			}
+			if keys := append(server.Keys("VLAN_MEMBER|*"), server.Keys("ACL_*")...); len(keys) != 0 {
+				t.Errorf("expected port is reset, got: %v", keys)
+			}
+			if server.Hash("VLAN|Vlan20") == nil {
+				t.Errorf("expected VLAN is kept")
+			}
+		})
+	}
+}
+
+func TestInventory(t *testing.T) {
+	vlan := 10
+	server := sonictest.NewServer("Ethernet0", "Ethernet4")
+	backend := serve(t, server, nil)
+	err := backend.SetPortAttr(context.Background(), "Ethernet0", &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan, TaggedVLANRange: "11-12"})
+	if err != nil {
+		t.Fatalf("SetPortAttr failed: %v", err)
+	}
+	err = backend.SetPortAttr(context.Background(), "Ethernet4", &v1alpha1.SwitchPortConfigurationSpec{TaggedVLANRange: "20"})
+	if err != nil {
+		t.Fatalf("SetPortAttr failed: %v", err)
+	}
+	err = backend.ResetPort(context.Background(), "Ethernet4", nil)
+	if err != nil {
+		t.Fatalf("ResetPort failed: %v", err)
+	}
+
+	inventory := backend.(backends.Inventory)
+	ports, err := inventory.ListPortVLANs(context.Background())
+	if err != nil {
+		t.Fatalf("ListPortVLANs failed: %v", err)
+	}
+	if expected := map[string][]int{"Ethernet0": {10, 11, 12}}; !reflect.DeepEqual(ports, expected) {
+		t.Errorf("expected ports: %v, got: %v", expected, ports)
+	}
+	vlans, err := inventory.ListVLANs(context.Background())
+	if err != nil {
+		t.Fatalf("ListVLANs failed: %v", err)
+	}
+	if expected := []int{10, 11, 12, 20}; !reflect.DeepEqual(vlans, expected) {
+		t.Errorf("expected VLANs: %v, got: %v", expected, vlans)
+	}
+}
+
+func TestSONiCError(t *testing.T) {
+	vlan := 4095
+	cases := []struct {
+		name              string
+		port              string
+		configuration     *v1alpha1.SwitchPortConfigurationSpec
+		credentials       *credentials.Credentials
+		expectedErrorType backends.ErrorType
+	}{
+		{
+			name:              "port not found",
+			port:              "Ethernet8",
+			configuration:     &v1alpha1.SwitchPortConfigurationSpec{},
+			expectedErrorType: backends.PortNotFound,
+		},
+		{
+			name:              "vlan out of range",
+			port:              "Ethernet0",
+			configuration:     &v1alpha1.SwitchPortConfigurationSpec{UntaggedVLAN: &vlan},
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+		{
+			name:              "ACL without ipVersion",
+			port:              "Ethernet0",
+			configuration:     &v1alpha1.SwitchPortConfigurationSpec{ACLs: []v1alpha1.ACL{{Action: "deny", Protocol: "ALL"}}},
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+		{
+			name: "ICMP with port range",
+			port: "Ethernet0",
+			configuration: &v1alpha1.SwitchPortConfigurationSpec{ACLs: []v1alpha1.ACL{
+				{IPVersion: "4", Action: "deny", Protocol: "ICMP", DestinationPortRange: "22"},
+			}},
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+		{
+			name:              "wrong password",
+			port:              "Ethernet0",
+			configuration:     &v1alpha1.SwitchPortConfigurationSpec{},
+			credentials:       &credentials.Credentials{Password: "wrong"},
+			expectedErrorType: backends.Authentication,
+		},
+		{
+			name:          "password",
+			port:          "Ethernet0",
+			configuration: &v1alpha1.SwitchPortConfigurationSpec{},
+			credentials:   &credentials.Credentials{Username: "default", Password: "password"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server := sonictest.NewServer("Ethernet0")
+			server.RequirePassword("password")
+			cert := c.credentials
+			if cert == nil {
+				cert = &credentials.Credentials{Password: "password"}
+			}
+			backend := serve(t, server, cert)
+			err := backend.SetPortAttr(context.Background(), c.port, c.configuration)
+			if errorType := backends.TypeOf(err); errorType != c.expectedErrorType {
+				t.Errorf("expected error type: %q, got: %v", c.expectedErrorType, err)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	cases := []struct {
+		name              string
+		host              string
+		options           map[string]interface{}
+		expectedErrorType backends.ErrorType
+	}{
+		{
+			name: "host without port",
+			host: "192.168.0.1",
+		},
+		{
+			name:    "custom database",
+			host:    "tcp:192.168.0.1:6380",
+			options: map[string]interface{}{"database": "5"},
+		},
+		{
+			name:              "invalid database",
+			host:              "192.168.0.1",
+			options:           map[string]interface{}{"database": "config_db"},
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+		{
+			name:              "empty host",
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+		{
+			name:              "unknown option",
+			host:              "192.168.0.1",
+			options:           map[string]interface{}{"bridge": "br0"},
+			expectedErrorType: backends.InvalidConfiguration,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := New(context.Background(), &provider.SwitchConfiguration{Host: c.host, Backend: "sonic", Options: c.options})
+			if errorType := backends.TypeOf(err); errorType != c.expectedErrorType {
+				t.Errorf("expected error type: %q, got: %v", c.expectedErrorType, err)
+			}
+		})
+	}
+}
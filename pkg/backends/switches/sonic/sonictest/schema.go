@@ -0,0 +1,142 @@
+package sonictest
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Validate checks the entries of config_db against the schema of SONiC,
+// include the references between tables
+func (s *Server) Validate() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	db := s.databases[ConfigDB]
+	keys := []string{}
+	for key := range db {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	untagged := map[string]string{}
+	for _, key := range keys {
+		fields := db[key]
+		parts := strings.Split(key, "|")
+		var err error
+		switch parts[0] {
+		case "PORT":
+			err = validatePort(fields)
+		case "VLAN":
+			err = validateVLAN(parts, fields)
+		case "VLAN_MEMBER":
+			err = validateVLANMember(db, parts, fields, untagged)
+		case "ACL_TABLE":
+			err = validateACLTable(db, fields)
+		case "ACL_RULE":
+			err = validateACLRule(db, parts, fields)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %v", key, err)
+		}
+	}
+	return nil
+}
+
+func validatePort(fields map[string]string) error {
+	if status := fields["admin_status"]; status != "up" && status != "down" {
+		return fmt.Errorf("invalid admin_status %q", status)
+	}
+	if mtu, ok := fields["mtu"]; ok {
+		if value, err := strconv.Atoi(mtu); err != nil || value < 68 || value > 9216 {
+			return fmt.Errorf("invalid mtu %q", mtu)
+		}
+	}
+	return nil
+}
+
+func validateVLAN(parts []string, fields map[string]string) error {
+	if len(parts) != 2 || !strings.HasPrefix(parts[1], "Vlan") {
+		return fmt.Errorf("invalid key")
+	}
+	vid, err := strconv.Atoi(fields["vlanid"])
+	if err != nil || vid < 1 || vid > 4094 || parts[1] != "Vlan"+fields["vlanid"] {
+		return fmt.Errorf("invalid vlanid %q", fields["vlanid"])
+	}
+	return nil
+}
+
+func validateVLANMember(db map[string]map[string]string, parts []string, fields map[string]string, untagged map[string]string) error {
+	if len(parts) != 3 {
+		return fmt.Errorf("invalid key")
+	}
+	if db["VLAN|"+parts[1]] == nil {
+		return fmt.Errorf("VLAN %s doesn't exist", parts[1])
+	}
+	if db["PORT|"+parts[2]] == nil {
+		return fmt.Errorf("port %s doesn't exist", parts[2])
+	}
+	switch fields["tagging_mode"] {
+	case "tagged":
+	case "untagged":
+		if vlan, ok := untagged[parts[2]]; ok {
+			return fmt.Errorf("port %s is already an untagged member of %s", parts[2], vlan)
+		}
+		untagged[parts[2]] = parts[1]
+	default:
+		return fmt.Errorf("invalid tagging_mode %q", fields["tagging_mode"])
+	}
+	return nil
+}
+
+func validateACLTable(db map[string]map[string]string, fields map[string]string) error {
+	switch fields["type"] {
+	case "L3", "L3V6", "MIRROR", "MIRRORV6", "CTRLPLANE":
+	default:
+		return fmt.Errorf("invalid type %q", fields["type"])
+	}
+	if stage := fields["stage"]; stage != "" && stage != "ingress" && stage != "egress" {
+		return fmt.Errorf("invalid stage %q", stage)
+	}
+	for _, port := range strings.Split(fields["ports@"], ",") {
+		if port != "" && db["PORT|"+port] == nil {
+			return fmt.Errorf("port %s doesn't exist", port)
+		}
+	}
+	return nil
+}
+
+func validateACLRule(db map[string]map[string]string, parts []string, fields map[string]string) error {
+	if len(parts) != 3 {
+		return fmt.Errorf("invalid key")
+	}
+	table := db["ACL_TABLE|"+parts[1]]
+	if table == nil {
+		return fmt.Errorf("ACL table %s doesn't exist", parts[1])
+	}
+	if _, err := strconv.Atoi(fields["PRIORITY"]); err != nil {
+		return fmt.Errorf("invalid PRIORITY %q", fields["PRIORITY"])
+	}
+	if action := fields["PACKET_ACTION"]; action != "FORWARD" && action != "DROP" {
+		return fmt.Errorf("invalid PACKET_ACTION %q", action)
+	}
+
+	for field := range fields {
+		switch field {
+		case "PRIORITY", "PACKET_ACTION", "IP_PROTOCOL", "L4_SRC_PORT", "L4_DST_PORT",
+			"L4_SRC_PORT_RANGE", "L4_DST_PORT_RANGE":
+		case "SRC_IP", "DST_IP":
+			if table["type"] != "L3" {
+				return fmt.Errorf("%s isn't supported by %s table", field, table["type"])
+			}
+		case "SRC_IPV6", "DST_IPV6":
+			if table["type"] != "L3V6" {
+				return fmt.Errorf("%s isn't supported by %s table", field, table["type"])
+			}
+		default:
+			return fmt.Errorf("unknown field %s", field)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,336 @@
+// Package sonictest provides an in-memory Redis server seeded with the config_db
+// of SONiC, it implements the commands used by the sonic backend and checks
+// the entries against the schema of SONiC.
+package sonictest
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ConfigDB is the index of config_db
+const ConfigDB int = 4
+
+// Server is an in-memory Redis server, only hashes are supported
+type Server struct {
+	mutex     sync.Mutex
+	password  string
+	databases map[int]map[string]map[string]string
+}
+
+// NewServer return a server whose config_db has the ports, the ports are down
+// like a new switch
+func NewServer(ports ...string) *Server {
+	s := &Server{databases: map[int]map[string]map[string]string{ConfigDB: {}}}
+	for i, port := range ports {
+		s.databases[ConfigDB]["PORT|"+port] = map[string]string{
+			"admin_status": "down",
+			"alias":        fmt.Sprintf("etp%d", i+1),
+			"lanes":        fmt.Sprintf("%d,%d,%d,%d", i*4, i*4+1, i*4+2, i*4+3),
+			"mtu":          "9100",
+			"speed":        "100000",
+		}
+	}
+	return s
+}
+
+// RequirePassword requires the clients to authenticate with the password
+func (s *Server) RequirePassword(password string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.password = password
+}
+
+// Hash return a copy of the hash in config_db, it's nil if the key doesn't exist
+func (s *Server) Hash(key string) map[string]string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	fields := s.databases[ConfigDB][key]
+	if fields == nil {
+		return nil
+	}
+	copied := map[string]string{}
+	for name, value := range fields {
+		copied[name] = value
+	}
+	return copied
+}
+
+// Keys return the sorted keys in config_db matching the pattern
+func (s *Server) Keys(pattern string) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return keys(s.databases[ConfigDB], pattern)
+}
+
+// Serve serves the connections of listener until it's closed
+func (s *Server) Serve(listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go s.serveConn(conn)
+	}
+}
+
+// session is the state of a connection
+type session struct {
+	database      int
+	authenticated bool
+	queue         [][]string
+	multi         bool
+	aborted       bool
+}
+
+// errorReply is an error reply
+type errorReply string
+
+// statusReply is a simple string reply
+type statusReply string
+
+func (s *Server) serveConn(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	writer := bufio.NewWriter(conn)
+	state := &session{database: 0}
+
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		writeReply(writer, s.handle(state, args))
+		if writer.Flush() != nil {
+			return
+		}
+	}
+}
+
+// handle handles the command, the commands in MULTI are queued
+func (s *Server) handle(state *session, args []string) interface{} {
+	if len(args) == 0 {
+		return errorReply("ERR empty command")
+	}
+	command := strings.ToUpper(args[0])
+
+	s.mutex.Lock()
+	password := s.password
+	s.mutex.Unlock()
+	if command == "AUTH" {
+		if password == "" {
+			return errorReply("ERR AUTH <password> called without any password configured for the default user")
+		}
+		if args[len(args)-1] != password || len(args) > 3 || len(args) < 2 ||
+			(len(args) == 3 && args[1] != "default") {
+			return errorReply("WRONGPASS invalid username-password pair or user is disabled.")
+		}
+		state.authenticated = true
+		return statusReply("OK")
+	}
+	if password != "" && !state.authenticated {
+		return errorReply("NOAUTH Authentication required.")
+	}
+
+	switch command {
+	case "MULTI":
+		if state.multi {
+			return errorReply("ERR MULTI calls can not be nested")
+		}
+		state.multi, state.aborted, state.queue = true, false, nil
+		return statusReply("OK")
+	case "EXEC":
+		if !state.multi {
+			return errorReply("ERR EXEC without MULTI")
+		}
+		state.multi = false
+		if state.aborted {
+			return errorReply("EXECABORT Transaction discarded because of previous errors.")
+		}
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		replies := []interface{}{}
+		for _, queued := range state.queue {
+			replies = append(replies, s.execute(state, queued))
+		}
+		return replies
+	}
+
+	if state.multi {
+		if !known(command) {
+			state.aborted = true
+			return errorReply(fmt.Sprintf("ERR unknown command `%s`", args[0]))
+		}
+		state.queue = append(state.queue, args)
+		return statusReply("QUEUED")
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.execute(state, args)
+}
+
+func known(command string) bool {
+	switch command {
+	case "PING", "SELECT", "EXISTS", "KEYS", "HGETALL", "HSET", "DEL":
+		return true
+	}
+	return false
+}
+
+// execute executes the command, the mutex must be locked
+func (s *Server) execute(state *session, args []string) interface{} {
+	command := strings.ToUpper(args[0])
+	if !known(command) {
+		return errorReply(fmt.Sprintf("ERR unknown command `%s`", args[0]))
+	}
+	db := s.databases[state.database]
+	if db == nil {
+		db = map[string]map[string]string{}
+		s.databases[state.database] = db
+	}
+
+	switch {
+	case command == "PING":
+		return statusReply("PONG")
+	case command == "SELECT" && len(args) == 2:
+		index, err := strconv.Atoi(args[1])
+		if err != nil || index < 0 || index > 15 {
+			return errorReply("ERR DB index is out of range")
+		}
+		state.database = index
+		return statusReply("OK")
+	case command == "EXISTS" && len(args) >= 2:
+		count := int64(0)
+		for _, key := range args[1:] {
+			if db[key] != nil {
+				count++
+			}
+		}
+		return count
+	case command == "KEYS" && len(args) == 2:
+		result := []interface{}{}
+		for _, key := range keys(db, args[1]) {
+			result = append(result, key)
+		}
+		return result
+	case command == "HGETALL" && len(args) == 2:
+		result := []interface{}{}
+		for name, value := range db[args[1]] {
+			result = append(result, name, value)
+		}
+		return result
+	case command == "HSET" && len(args) >= 4 && len(args)%2 == 0:
+		if db[args[1]] == nil {
+			db[args[1]] = map[string]string{}
+		}
+		added := int64(0)
+		for i := 2; i < len(args); i += 2 {
+			if _, ok := db[args[1]][args[i]]; !ok {
+				added++
+			}
+			db[args[1]][args[i]] = args[i+1]
+		}
+		return added
+	case command == "DEL" && len(args) >= 2:
+		count := int64(0)
+		for _, key := range args[1:] {
+			if db[key] != nil {
+				delete(db, key)
+				count++
+			}
+		}
+		return count
+	default:
+		return errorReply(fmt.Sprintf("ERR wrong number of arguments for '%s' command", strings.ToLower(command)))
+	}
+}
+
+// keys return the sorted keys matching the glob-style pattern
+func keys(db map[string]map[string]string, pattern string) []string {
+	result := []string{}
+	for key := range db {
+		if matched, _ := path.Match(pattern, key); matched {
+			result = append(result, key)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// readCommand reads a command sent as an array of bulk strings
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := readLine(reader)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return nil, fmt.Errorf("inline command isn't supported")
+	}
+	count, err := strconv.Atoi(line[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{}
+	for i := 0; i < count; i++ {
+		line, err = readLine(reader)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(line, "$") {
+			return nil, fmt.Errorf("invalid bulk string %q", line)
+		}
+		length, err := strconv.Atoi(line[1:])
+		if err != nil || length < 0 {
+			return nil, fmt.Errorf("invalid bulk string %q", line)
+		}
+		b := make([]byte, length+2)
+		_, err = io.ReadFull(reader, b)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, string(b[:length]))
+	}
+	return args, nil
+}
+
+func readLine(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(line, "\r\n"), nil
+}
+
+// writeReply writes the reply, strings are written as bulk strings
+func writeReply(writer *bufio.Writer, reply interface{}) {
+	switch r := reply.(type) {
+	case errorReply:
+		fmt.Fprintf(writer, "-%s\r\n", r)
+	case statusReply:
+		fmt.Fprintf(writer, "+%s\r\n", r)
+	case string:
+		fmt.Fprintf(writer, "$%d\r\n%s\r\n", len(r), r)
+	case int64:
+		fmt.Fprintf(writer, ":%d\r\n", r)
+	case []interface{}:
+		fmt.Fprintf(writer, "*%d\r\n", len(r))
+		for _, element := range r {
+			writeReply(writer, element)
+		}
+	default:
+		fmt.Fprintf(writer, "$-1\r\n")
+	}
+}
@@ -6,7 +6,14 @@ import (
 
 	"github.com/Hellcatlk/network-operator/pkg/backends"
 	"github.com/Hellcatlk/network-operator/pkg/backends/switches/ansible"
+	"github.com/Hellcatlk/network-operator/pkg/backends/switches/cli"
+	"github.com/Hellcatlk/network-operator/pkg/backends/switches/eapi"
 	"github.com/Hellcatlk/network-operator/pkg/backends/switches/fake"
+	"github.com/Hellcatlk/network-operator/pkg/backends/switches/linuxbridge"
+	"github.com/Hellcatlk/network-operator/pkg/backends/switches/ovsdb"
+	"github.com/Hellcatlk/network-operator/pkg/backends/switches/plugin"
+	"github.com/Hellcatlk/network-operator/pkg/backends/switches/restconf"
+	"github.com/Hellcatlk/network-operator/pkg/backends/switches/sonic"
 	"github.com/Hellcatlk/network-operator/pkg/provider"
 )
 
@@ -17,6 +24,13 @@ var backendNews map[string]newFuncType = make(map[string]newFuncType)
 func init() {
 	Register("fake", fake.New)
 	Register("ansible", ansible.New)
+	Register("plugin", plugin.New)
+	Register("ovsdb", ovsdb.New)
+	Register("linuxbridge", linuxbridge.New)
+	Register("sonic", sonic.New)
+	Register("eapi", eapi.New)
+	Register("restconf", restconf.New)
+	Register("cli", cli.New)
 }
 
 // Register switch backend
@@ -0,0 +1,126 @@
+package health
+
+import (
+	"sync"
+	"time"
+)
+
+// Tracker tracks the health of switches as circuit breakers. The circuit of
+// a switch opens after `threshold` consecutive failures, then only one probe
+// is allowed after every backoff, and the backoff doubles after every failed
+// probe up to `maxBackoff`. A success closes the circuit.
+type Tracker struct {
+	threshold   int
+	baseBackoff time.Duration
+	maxBackoff  time.Duration
+
+	mutex    sync.Mutex
+	circuits map[string]*circuit
+	// now is used to mock the time in test
+	now func() time.Time
+}
+
+type circuit struct {
+	failures int
+	open     bool
+	backoff  time.Duration
+	probeAt  time.Time
+}
+
+// New return a health tracker, the minimum of threshold is 1
+func New(threshold int, baseBackoff, maxBackoff time.Duration) *Tracker {
+	if threshold < 1 {
+		threshold = 1
+	}
+	if maxBackoff < baseBackoff {
+		maxBackoff = baseBackoff
+	}
+
+	return &Tracker{
+		threshold:   threshold,
+		baseBackoff: baseBackoff,
+		maxBackoff:  maxBackoff,
+		circuits:    make(map[string]*circuit),
+		now:         time.Now,
+	}
+}
+
+// Success records a successful operation and closes the circuit
+func (t *Tracker) Success(key string) {
+	if t == nil {
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	delete(t.circuits, key)
+}
+
+// Failure records a failed operation, return true if the circuit is open
+func (t *Tracker) Failure(key string) bool {
+	if t == nil {
+		return false
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	c, exist := t.circuits[key]
+	if !exist {
+		c = &circuit{}
+		t.circuits[key] = c
+	}
+	c.failures++
+	if c.failures < t.threshold {
+		return false
+	}
+
+	if !c.open {
+		c.open = true
+		c.backoff = t.baseBackoff
+	} else {
+		c.backoff *= 2
+		if c.backoff > t.maxBackoff {
+			c.backoff = t.maxBackoff
+		}
+	}
+	c.probeAt = t.now().Add(c.backoff)
+	return true
+}
+
+// IsOpen return true if the circuit is open
+func (t *Tracker) IsOpen(key string) bool {
+	if t == nil {
+		return false
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	c, exist := t.circuits[key]
+	return exist && c.open
+}
+
+// Allow return true if an operation is allowed. When the circuit is open only
+// one probe is allowed after the backoff, otherwise return the time to wait.
+func (t *Tracker) Allow(key string) (bool, time.Duration) {
+	if t == nil {
+		return true, 0
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	c, exist := t.circuits[key]
+	if !exist || !c.open {
+		return true, 0
+	}
+
+	now := t.now()
+	if now.Before(c.probeAt) {
+		return false, c.probeAt.Sub(now)
+	}
+	// Block the others until the probe finished
+	c.probeAt = now.Add(c.backoff)
+	return true, 0
+}
@@ -0,0 +1,93 @@
+package health
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTracker(t *testing.T) {
+	now := time.Now()
+	tracker := New(2, time.Second*10, time.Second*30)
+	tracker.now = func() time.Time { return now }
+
+	steps := []struct {
+		name          string
+		do            func()
+		expectedOpen  bool
+		expectedAllow bool
+		expectedWait  time.Duration
+	}{
+		{
+			name:          "first failure",
+			do:            func() { tracker.Failure("switch") },
+			expectedOpen:  false,
+			expectedAllow: true,
+		},
+		{
+			name:          "open after threshold",
+			do:            func() { tracker.Failure("switch") },
+			expectedOpen:  true,
+			expectedAllow: false,
+			expectedWait:  time.Second * 10,
+		},
+		{
+			name:          "probe after backoff",
+			do:            func() { now = now.Add(time.Second * 10) },
+			expectedOpen:  true,
+			expectedAllow: true,
+		},
+		{
+			name:          "only one probe",
+			do:            func() {},
+			expectedOpen:  true,
+			expectedAllow: false,
+			expectedWait:  time.Second * 10,
+		},
+		{
+			name:          "backoff doubles after failed probe",
+			do:            func() { tracker.Failure("switch") },
+			expectedOpen:  true,
+			expectedAllow: false,
+			expectedWait:  time.Second * 20,
+		},
+		{
+			name: "backoff is limited",
+			do: func() {
+				now = now.Add(time.Second * 20)
+				tracker.Failure("switch")
+			},
+			expectedOpen:  true,
+			expectedAllow: false,
+			expectedWait:  time.Second * 30,
+		},
+		{
+			name:          "success closes circuit",
+			do:            func() { tracker.Success("switch") },
+			expectedOpen:  false,
+			expectedAllow: true,
+		},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			step.do()
+			if open := tracker.IsOpen("switch"); open != step.expectedOpen {
+				t.Errorf("expected open: %v, got: %v", step.expectedOpen, open)
+			}
+			allow, wait := tracker.Allow("switch")
+			if allow != step.expectedAllow || wait != step.expectedWait {
+				t.Errorf("expected allow: %v wait: %v, got allow: %v wait: %v", step.expectedAllow, step.expectedWait, allow, wait)
+			}
+		})
+	}
+}
+
+func TestNilTracker(t *testing.T) {
+	var tracker *Tracker
+	if tracker.Failure("switch") || tracker.IsOpen("switch") {
+		t.Errorf("nil tracker mustn't open circuit")
+	}
+	if allow, _ := tracker.Allow("switch"); !allow {
+		t.Errorf("nil tracker must allow all operations")
+	}
+}
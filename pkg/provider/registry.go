@@ -0,0 +1,51 @@
+package provider
+
+import (
+	"sort"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+type newFuncType func() Switch
+
+var kinds map[string]newFuncType = make(map[string]newFuncType)
+
+// Register provider switch kind, the instance returned by new is fetched by
+// its name if it's a `client.Object`, so the kind must be added to the scheme
+// of manager as well
+func Register(kind string, new newFuncType) {
+	kinds[kind] = new
+}
+
+// New return an empty instance of the kind, return nil if the kind isn't registered
+func New(kind string) Switch {
+	if kinds[kind] == nil {
+		return nil
+	}
+	return kinds[kind]()
+}
+
+// Kinds return the registered kinds in order
+func Kinds() []string {
+	result := make([]string, 0, len(kinds))
+	for kind := range kinds {
+		result = append(result, kind)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// SecretReferrer is implemented by the provider switches whose configuration
+// is read from secrets, so the switches are reconciled when the secrets change
+type SecretReferrer interface {
+	// ReferencedSecrets return the secrets used by the provider switch
+	ReferencedSecrets() []types.NamespacedName
+}
+
+// ConfigMapReferrer is implemented by the provider switches whose configuration
+// is read from ConfigMaps, so the switches are reconciled when the ConfigMaps
+// change
+type ConfigMapReferrer interface {
+	// ReferencedConfigMaps return the ConfigMaps used by the provider switch
+	ReferencedConfigMaps() []types.NamespacedName
+}
@@ -0,0 +1,59 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// idleConnTimeout is how long an idle keep-alive connection is kept
+const idleConnTimeout time.Duration = 90 * time.Second
+
+// key of the shared clients
+type key struct {
+	host               string
+	caBundle           string
+	insecureSkipVerify bool
+}
+
+var (
+	mutex   sync.Mutex
+	clients = make(map[key]*http.Client)
+)
+
+// Get return the HTTP client shared by the backends of host with the same TLS options, so the
+// connections are reused across reconciles instead of creating a transport every time
+func Get(host string, caBundle string, insecureSkipVerify bool) (*http.Client, error) {
+	k := key{host: host, caBundle: caBundle, insecureSkipVerify: insecureSkipVerify}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if client, exist := clients[k]; exist {
+		return client, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: insecureSkipVerify, // #nosec G402
+	}
+	if caBundle != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(caBundle)) {
+			return nil, fmt.Errorf("caBundle has no PEM certificate")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+			IdleConnTimeout: idleConnTimeout,
+		},
+	}
+	clients[k] = client
+	return client, nil
+}
@@ -0,0 +1,56 @@
+package httpclient
+
+import (
+	"testing"
+)
+
+func TestGet(t *testing.T) {
+	cases := []struct {
+		name               string
+		host               string
+		caBundle           string
+		insecureSkipVerify bool
+		expectedShared     bool
+		expectedError      bool
+	}{
+		{
+			name:           "same host and options",
+			host:           "switch1",
+			expectedShared: true,
+		},
+		{
+			name: "other host",
+			host: "switch2",
+		},
+		{
+			name:               "other options",
+			host:               "switch1",
+			insecureSkipVerify: true,
+		},
+		{
+			name:          "invalid caBundle",
+			host:          "switch1",
+			caBundle:      "certificate",
+			expectedError: true,
+		},
+	}
+
+	shared, err := Get("switch1", "", false)
+	if err != nil {
+		t.Fatalf("get client failed: %v", err)
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client, err := Get(c.host, c.caBundle, c.insecureSkipVerify)
+			if (err != nil) != c.expectedError {
+				t.Errorf("expected error: %v, got: %v", c.expectedError, err)
+			}
+			if err != nil {
+				return
+			}
+			if (client == shared) != c.expectedShared {
+				t.Errorf("expected shared: %v, got: %v", c.expectedShared, client == shared)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package lock
+
+import (
+	"context"
+	"sync"
+)
+
+// Priority of the waiter
+type Priority int
+
+const (
+	// Normal priority
+	Normal Priority = iota
+	// High priority, the waiters with high priority get the lock before the normal waiters
+	High
+)
+
+// KeyedLock limits the number of holders of every key, different keys don't block each other
+type KeyedLock struct {
+	limit   int
+	mutex   sync.Mutex
+	entries map[string]*entry
+}
+
+type entry struct {
+	holders int
+	// The waiters of every priority in FIFO order
+	waiters [High + 1][]chan struct{}
+}
+
+// New return a KeyedLock which allows `limit` holders for every key, the minimum of limit is 1
+func New(limit int) *KeyedLock {
+	if limit < 1 {
+		limit = 1
+	}
+
+	return &KeyedLock{
+		limit:   limit,
+		entries: make(map[string]*entry),
+	}
+}
+
+// Lock acquires the lock of the key, it blocks until the lock is acquired or the context is done.
+// The returned function must be called to release the lock.
+func (l *KeyedLock) Lock(ctx context.Context, key string, priority Priority) (func(), error) {
+	if priority < Normal || priority > High {
+		priority = Normal
+	}
+
+	l.mutex.Lock()
+	e, exist := l.entries[key]
+	if !exist {
+		e = &entry{}
+		l.entries[key] = e
+	}
+	if e.holders < l.limit && e.waiting(priority) == 0 {
+		e.holders++
+		l.mutex.Unlock()
+		return l.unlocker(key), nil
+	}
+	ch := make(chan struct{})
+	e.waiters[priority] = append(e.waiters[priority], ch)
+	l.mutex.Unlock()
+
+	select {
+	case <-ch:
+		return l.unlocker(key), nil
+	case <-ctx.Done():
+		l.mutex.Lock()
+		defer l.mutex.Unlock()
+		select {
+		case <-ch:
+			// The lock has been granted at the same time
+			l.release(key)
+		default:
+			e.remove(priority, ch)
+			if e.holders == 0 && e.empty() {
+				delete(l.entries, key)
+			}
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// unlocker return the function which releases the lock only once
+func (l *KeyedLock) unlocker(key string) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mutex.Lock()
+			defer l.mutex.Unlock()
+			l.release(key)
+		})
+	}
+}
+
+// release the lock and grant it to the waiters, the caller must hold the mutex
+func (l *KeyedLock) release(key string) {
+	e := l.entries[key]
+	e.holders--
+
+	for priority := High; priority >= Normal && e.holders < l.limit; {
+		if len(e.waiters[priority]) == 0 {
+			priority--
+			continue
+		}
+		close(e.waiters[priority][0])
+		e.waiters[priority] = e.waiters[priority][1:]
+		e.holders++
+	}
+
+	if e.holders == 0 && e.empty() {
+		delete(l.entries, key)
+	}
+}
+
+// waiting return the number of waiters whose priority isn't lower than the priority
+func (e *entry) waiting(priority Priority) int {
+	count := 0
+	for p := priority; p <= High; p++ {
+		count += len(e.waiters[p])
+	}
+	return count
+}
+
+// empty return true if there isn't any waiter
+func (e *entry) empty() bool {
+	return e.waiting(Normal) == 0
+}
+
+// remove the waiter
+func (e *entry) remove(priority Priority, ch chan struct{}) {
+	for i, waiter := range e.waiters[priority] {
+		if waiter == ch {
+			e.waiters[priority] = append(e.waiters[priority][:i], e.waiters[priority][i+1:]...)
+			return
+		}
+	}
+}
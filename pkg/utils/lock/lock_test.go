@@ -0,0 +1,133 @@
+package lock
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLockLimit(t *testing.T) {
+	cases := []struct {
+		name          string
+		limit         int
+		key           string
+		expectedError bool
+	}{
+		{
+			name:          "same key exceeds the limit",
+			limit:         1,
+			key:           "switch1",
+			expectedError: true,
+		},
+		{
+			name:          "same key in the limit",
+			limit:         2,
+			key:           "switch1",
+			expectedError: false,
+		},
+		{
+			name:          "different key",
+			limit:         1,
+			key:           "switch2",
+			expectedError: false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			l := New(c.limit)
+			unlock, err := l.Lock(context.Background(), "switch1", Normal)
+			if err != nil {
+				t.Fatalf("Got unexpected error: %v", err)
+			}
+			defer unlock()
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+			defer cancel()
+			unlock2, err := l.Lock(ctx, c.key, Normal)
+			if (err != nil) != c.expectedError {
+				t.Errorf("Got unexpected error: %v", err)
+			}
+			if err == nil {
+				unlock2()
+			}
+		})
+	}
+}
+
+func TestLockPriority(t *testing.T) {
+	l := New(1)
+	unlock, err := l.Lock(context.Background(), "switch", Normal)
+	if err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+
+	order := make(chan string, 3)
+	var wg sync.WaitGroup
+	wait := func(name string, priority Priority) {
+		defer wg.Done()
+		unlock, err := l.Lock(context.Background(), "switch", priority)
+		if err != nil {
+			t.Errorf("Got unexpected error: %v", err)
+			return
+		}
+		order <- name
+		unlock()
+	}
+
+	// Make sure the waiters are queued in order
+	for _, waiter := range []struct {
+		name     string
+		priority Priority
+	}{
+		{"configure1", Normal},
+		{"configure2", Normal},
+		{"clean", High},
+	} {
+		wg.Add(1)
+		go wait(waiter.name, waiter.priority)
+		time.Sleep(time.Millisecond * 20)
+	}
+	unlock()
+
+	wg.Wait()
+	got := []string{<-order, <-order, <-order}
+	expected := []string{"clean", "configure1", "configure2"}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected: %v, got: %v", expected, got)
+	}
+	if len(l.entries) != 0 {
+		t.Errorf("expected no entry left, got: %d", len(l.entries))
+	}
+}
+
+func TestLockCancel(t *testing.T) {
+	l := New(1)
+	unlock, err := l.Lock(context.Background(), "switch", Normal)
+	if err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*20)
+	defer cancel()
+	_, err = l.Lock(ctx, "switch", High)
+	if err == nil {
+		t.Fatalf("expected error when context is done")
+	}
+
+	// The canceled waiter mustn't block the others
+	unlock()
+	unlock()
+	ctx2, cancel2 := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel2()
+	unlock, err = l.Lock(ctx2, "switch", Normal)
+	if err != nil {
+		t.Fatalf("Got unexpected error: %v", err)
+	}
+	unlock()
+	if len(l.entries) != 0 {
+		t.Errorf("expected no entry left, got: %d", len(l.entries))
+	}
+}
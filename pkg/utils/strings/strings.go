@@ -63,6 +63,9 @@ func SliceToRange(nums []int) string {
 	}
 	nums = nums[:j+1]
 	length = len(nums)
+	if length == 1 {
+		return strconv.Itoa(nums[0])
+	}
 
 	formatStr := ""
 	var begin, end int
@@ -228,3 +231,33 @@ func Shrink(a, b string) (string, error) {
 
 	return SliceToRange(arr1), nil
 }
+
+// Intersection return the content both in A and B
+func Intersection(a, b string) (string, error) {
+	if a == "" || b == "" {
+		return "", nil
+	}
+
+	arr1, err := RangeToSlice(a)
+	if err != nil {
+		return "", err
+	}
+
+	arr2, err := RangeToSlice(b)
+	if err != nil {
+		return "", err
+	}
+
+	contained := make(map[int]struct{})
+	for _, v := range arr2 {
+		contained[v] = struct{}{}
+	}
+	result := []int{}
+	for _, v := range arr1 {
+		if _, exist := contained[v]; exist {
+			result = append(result, v)
+		}
+	}
+
+	return SliceToRange(result), nil
+}
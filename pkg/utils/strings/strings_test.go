@@ -231,6 +231,10 @@ func TestSliceToRange(t *testing.T) {
 			arr:      []int{1, 5, 7},
 			expected: "1,5,7",
 		},
+		{
+			arr:      []int{1, 1},
+			expected: "1",
+		},
 	}
 
 	for _, c := range cases {
@@ -316,3 +320,45 @@ func TestShrink(t *testing.T) {
 		})
 	}
 }
+
+func TestIntersection(t *testing.T) {
+	cases := []struct {
+		str1          string
+		str2          string
+		expected      string
+		expectedError bool
+	}{
+		{
+			str1:     "1-5,7",
+			str2:     "",
+			expected: "",
+		},
+		{
+			str1:     "1-5,7",
+			str2:     "4-8",
+			expected: "4-5,7",
+		},
+		{
+			str1:     "1-5",
+			str2:     "6-8",
+			expected: "",
+		},
+		{
+			str1:          "1--5,7",
+			str2:          "6,8",
+			expectedError: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(t.Name(), func(t *testing.T) {
+			got, err := Intersection(c.str1, c.str2)
+			if c.expected != got {
+				t.Errorf("expected: %v, got: %v", c.expected, got)
+			}
+			if (err != nil) != c.expectedError {
+				t.Errorf("got unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -5,6 +5,8 @@ package tools
 import (
 	_ "github.com/securego/gosec/cmd/gosec"
 	_ "golang.org/x/lint/golint"
+	_ "google.golang.org/grpc/cmd/protoc-gen-go-grpc"
+	_ "google.golang.org/protobuf/cmd/protoc-gen-go"
 	_ "sigs.k8s.io/controller-tools/cmd/controller-gen"
 	_ "sigs.k8s.io/kustomize/kustomize/v3"
 )